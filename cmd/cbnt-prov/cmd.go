@@ -16,6 +16,7 @@ import (
 
 	"github.com/linuxboot/fiano/pkg/uefi"
 
+	hwInternal "github.com/9elements/converged-security-suite/v2/pkg/hwapi"
 	"github.com/9elements/converged-security-suite/v2/pkg/provisioning/cbnt"
 	"github.com/9elements/converged-security-suite/v2/pkg/tools"
 	"github.com/linuxboot/fiano/pkg/intel/metadata/manifest"
@@ -67,7 +68,8 @@ type bpmPrintCmd struct {
 }
 
 type acmPrintCmd struct {
-	Path string `arg required name:"path" help:"Path to the ACM binary file." type:"path"`
+	Path        string `arg required name:"path" help:"Path to the ACM binary file." type:"path"`
+	CPUDatabase string `flag optional name:"cpu-db" help:"Path/Filename of a CPU database in YAML or JSON format. Its entries take precedence over the embedded database." type:"path"`
 }
 
 type biosPrintCmd struct {
@@ -300,13 +302,36 @@ func (acmp *acmPrintCmd) Run(ctx *context) error {
 	if err2 != nil {
 		return err2
 	}
+	db := hwInternal.DefaultCPUDatabase()
+	if acmp.CPUDatabase != "" {
+		db, err = hwInternal.LoadCPUDatabase(acmp.CPUDatabase)
+		if err != nil {
+			return err
+		}
+	}
 	acm.PrettyPrint()
+	tools.PrintACMSignature(data)
 	chipsets.PrettyPrint()
 	processors.PrettyPrint()
+	printACMProcessors(processors, db)
 	tpms.PrettyPrint()
 	return nil
 }
 
+// printACMProcessors prints the processors of the CPU database supported by the ACM
+func printACMProcessors(processors *tools.Processors, db *hwInternal.CPUDatabase) {
+	fmt.Println("   --Supported Processors--")
+	for idx := range db.Processors {
+		entry := &db.Processors[idx]
+		for _, id := range processors.IDList {
+			if entry.MatchesACMProcessorID(id.FMS, id.FMSMask, id.PlatformID, id.PlatformMask) {
+				fmt.Printf("      %s (family 0x%x, model 0x%02x)\n", entry.Name, entry.Family, entry.Model)
+				break
+			}
+		}
+	}
+}
+
 func (biosp *biosPrintCmd) Run(ctx *context) error {
 	data, err := ioutil.ReadFile(biosp.Path)
 	if err != nil {
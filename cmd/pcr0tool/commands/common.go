@@ -2,8 +2,11 @@ package commands
 
 import (
 	"fmt"
+	"strings"
+
 	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
 	"github.com/9elements/converged-security-suite/v2/pkg/tpmdetection"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
 )
 
 // FlowCommandLineValues returns a human readable array of all supported pcr attestation flows
@@ -29,3 +32,40 @@ func TPMTypeCommandLineValues() string {
 	}
 	return result
 }
+
+// HashAlgoCommandLineValues returns a human readable array of all supported PCR banks
+func HashAlgoCommandLineValues() string {
+	var result string
+	for _, hashAlgo := range tpmeventlog.HashAlgorithms() {
+		if len(result) > 0 {
+			result += ", "
+		}
+		result += fmt.Sprintf("'%s'", tpmeventlog.HashAlgorithmName(hashAlgo))
+	}
+	return result
+}
+
+// ParseHashAlgo parses a name of a PCR bank (see HashAlgoCommandLineValues).
+func ParseHashAlgo(in string) (tpmeventlog.TPMAlgorithm, error) {
+	hashAlgo, err := tpmeventlog.HashAlgorithmFromName(strings.TrimSpace(in))
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tpmeventlog.NewHasher(hashAlgo); err != nil {
+		return 0, err
+	}
+	return hashAlgo, nil
+}
+
+// ParseHashAlgos parses a comma-separated list of PCR banks (see ParseHashAlgo).
+func ParseHashAlgos(in string) ([]tpmeventlog.TPMAlgorithm, error) {
+	var result []tpmeventlog.TPMAlgorithm
+	for _, name := range strings.Split(in, ",") {
+		hashAlgo, err := ParseHashAlgo(name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, hashAlgo)
+	}
+	return result, nil
+}
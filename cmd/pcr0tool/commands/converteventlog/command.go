@@ -0,0 +1,102 @@
+package converteventlog
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+)
+
+func usageAndExit() {
+	flag.Usage()
+	os.Exit(2)
+}
+
+// Command is the implementation of `commands.Command`.
+type Command struct {
+	eventLog     *string
+	inputFormat  *string
+	output       *string
+	outputFormat *string
+}
+
+// Usage prints the syntax of arguments for this command
+func (cmd Command) Usage() string {
+	return ""
+}
+
+// Description explains what this verb commands to do
+func (cmd Command) Description() string {
+	return "convert TPM Event Log between formats (TCG binary, CEL-TLV, CEL-JSON)"
+}
+
+func formatCommandLineValues() string {
+	var result string
+	for format := tpmeventlog.FormatUndefined + 1; format < tpmeventlog.EndOfFormat; format++ {
+		if result != "" {
+			result += ", "
+		}
+		result += format.String()
+	}
+	return result
+}
+
+// SetupFlagSet is called to allow the command implementation
+// to setup which option flags it has.
+func (cmd *Command) SetupFlagSet(flag *flag.FlagSet) {
+	cmd.eventLog = flag.String("event-log", "/sys/kernel/security/tpm0/binary_bios_measurements", "path to the EventLog to be converted")
+	cmd.inputFormat = flag.String("input-format", tpmeventlog.FormatTCG.String(), "the format of the input EventLog, values: "+formatCommandLineValues())
+	cmd.output = flag.String("output", "", "[optional] path to the file to write the converted EventLog to (default: stdout)")
+	cmd.outputFormat = flag.String("output-format", tpmeventlog.FormatCELJSON.String(), "the format of the output EventLog, values: "+formatCommandLineValues())
+}
+
+// Execute is the main function here. It is responsible to
+// start the execution of the command.
+//
+// `args` are the arguments left unused by verb itself and options.
+func (cmd Command) Execute(args []string) {
+	if len(args) > 0 {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: too many parameters\n")
+		usageAndExit()
+	}
+
+	inputFormat, err := tpmeventlog.FormatFromString(*cmd.inputFormat)
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: invalid value of option 'input-format': %v\n", err)
+		usageAndExit()
+	}
+	outputFormat, err := tpmeventlog.FormatFromString(*cmd.outputFormat)
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: invalid value of option 'output-format': %v\n", err)
+		usageAndExit()
+	}
+
+	eventLogFile, err := os.Open(*cmd.eventLog)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to open EventLog '%s': %v\n", *cmd.eventLog, err)
+		os.Exit(1)
+	}
+	defer eventLogFile.Close()
+
+	eventLog, err := tpmeventlog.ParseFormat(eventLogFile, inputFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to parse EventLog '%s': %v\n", *cmd.eventLog, err)
+		os.Exit(1)
+	}
+
+	output := os.Stdout
+	if *cmd.output != "" {
+		output, err = os.Create(*cmd.output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to create file '%s': %v\n", *cmd.output, err)
+			os.Exit(1)
+		}
+		defer output.Close()
+	}
+
+	if err := eventLog.Encode(output, outputFormat); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to write the EventLog: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -12,7 +12,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/google/go-tpm/tpm2"
 	pkgbytes "github.com/linuxboot/fiano/pkg/bytes"
 	fianoUEFI "github.com/linuxboot/fiano/pkg/uefi"
 
@@ -37,6 +36,24 @@ func usageAndExit() {
 	os.Exit(2)
 }
 
+type diffModeType int
+
+const (
+	diffModeTypeUnknown = diffModeType(iota)
+	diffModeTypeBytes
+	diffModeTypeStructural
+)
+
+func parseDiffModeType(s string) diffModeType {
+	switch s {
+	case "bytes":
+		return diffModeTypeBytes
+	case "structural":
+		return diffModeTypeStructural
+	}
+	return diffModeTypeUnknown
+}
+
 type outputFormatType int
 
 const (
@@ -82,12 +99,14 @@ type Command struct {
 	forceScanArea *string
 	ignoreByteSet *string
 	outputFormat  *string
+	mode          *string
 	flow          *string
 	netPprof      *string
 	deepAnalysis  *bool
 	registers     helpers.FlagRegisters
 	hashFunc      *string
 	tpmDevice     *string
+	pcrFlags      commands.PCRFlags
 }
 
 // Usage prints the syntax of arguments for this command
@@ -97,7 +116,7 @@ func (cmd Command) Usage() string {
 
 // Description explains what this verb commands to do
 func (cmd Command) Description() string {
-	return "find the reason of different PCR0 values between two firmware images"
+	return "find the reason of different PCR0 (or another PCR, see -pcr-index) values between two firmware images"
 }
 
 // SetupFlagSet is called to allow the command implementation
@@ -109,13 +128,15 @@ func (cmd *Command) SetupFlagSet(flag *flag.FlagSet) {
 It makes sense to use this option together with "-force-scan-area bios_region" to scan the whole image, 
 but ignore the overridden bytes. The value is represented in hex characters separated by comma, for example: "00,ff". Default: ""`)
 	cmd.outputFormat = flag.String("output-format", "analyzed-text", `Values: "analyzed-text", "analyzed-json", "json"`)
+	cmd.mode = flag.String("mode", "bytes", `Values: "bytes" (compare the bytes at the same offsets), "structural" (align the images by FFS volumes, files and FIT entries and compare them)`)
 	cmd.flow = flag.String("flow", "auto", "values: "+commands.FlowCommandLineValues())
 	cmd.deepAnalysis = flag.Bool("deep-analysis", false,
 		`Also perform slow procedures to find more byte ranges which could affect the PCR0 calculation. This is experimental feature! Values: "true", "false"`)
 	cmd.netPprof = flag.String("net-pprof", "", `start listening for "net/http/pprof", example value: "127.0.0.1:6060"`)
 	flag.Var(&cmd.registers, "registers", "[optional] file that contains registers as a json array (use value '/dev' to use registers of the local machine)")
-	cmd.hashFunc = flag.String("hash-func", "", `which hash function use to hash measurements and to extend the PCR0; values: "sha1", "sha256"`)
+	cmd.hashFunc = flag.String("hash-func", "", "which hash function use to hash measurements and to extend the PCR0; values: "+commands.HashAlgoCommandLineValues())
 	cmd.tpmDevice = flag.String("tpm-device", "", "[optional] tpm device used for measurements, values: "+commands.TPMTypeCommandLineValues())
+	cmd.pcrFlags.SetupFlagSet(flag)
 }
 
 // Execute is the main function here. It is responsible to
@@ -134,15 +155,31 @@ func (cmd Command) Execute(args []string) {
 		usageAndExit()
 	}
 
+	mode := parseDiffModeType(*cmd.mode)
+	if mode == diffModeTypeUnknown {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "unknown diff mode: '%s'\n", *cmd.mode)
+		usageAndExit()
+	}
+
 	flow, err := pcr.FlowFromString(*cmd.flow)
 	if err != nil {
 		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "unknown attestation flow: '%s'\n", *cmd.flow)
 		usageAndExit()
 	}
 
+	pcrID, err := cmd.pcrFlags.PCRID()
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: %v\n", err)
+		usageAndExit()
+	}
+
 	var measureOpts []pcr.MeasureOption
 	measureOpts = append(measureOpts, pcr.SetFlow(flow))
 
+	pcrMeasureOpts, err := cmd.pcrFlags.MeasureOptions()
+	assertNoError(err)
+	measureOpts = append(measureOpts, pcrMeasureOpts...)
+
 	if *cmd.deepAnalysis {
 		measureOpts = append(measureOpts, pcr.SetFindMissingFakeMeasurements(true))
 	}
@@ -166,11 +203,13 @@ func (cmd Command) Execute(args []string) {
 		measureOpts = append(measureOpts, pcr.SetTPMDevice(tpmDevice))
 	}
 
-	switch strings.ToLower(*cmd.hashFunc) {
-	case "sha1":
-		measureOpts = append(measureOpts, pcr.SetIBBHashDigest(tpm2.AlgSHA1))
-	case "sha256":
-		measureOpts = append(measureOpts, pcr.SetIBBHashDigest(tpm2.AlgSHA256))
+	if *cmd.hashFunc != "" {
+		hashAlgo, err := commands.ParseHashAlgo(*cmd.hashFunc)
+		if err != nil {
+			_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: invalid value of option 'hash-func': %v\n", err)
+			usageAndExit()
+		}
+		measureOpts = append(measureOpts, pcr.SetIBBHashDigest(hashAlgo))
 	}
 
 	firmwareGood, err := uefi.ParseUEFIFirmwareFile(args[0])
@@ -182,7 +221,7 @@ func (cmd Command) Execute(args []string) {
 		assertNoError(err)
 	}
 
-	measurements, _, debugInfo, err := pcr.GetMeasurements(firmwareGood, 0, measureOpts...)
+	measurements, _, debugInfo, err := pcr.GetMeasurements(firmwareGood, pcrID, measureOpts...)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "GetPCRMeasurements error: %v\n", err)
 	}
@@ -190,6 +229,20 @@ func (cmd Command) Execute(args []string) {
 		os.Exit(1)
 	}
 
+	if mode == diffModeTypeStructural {
+		firmwareBad, err := uefi.ParseUEFIFirmwareBytes(firmwareBadData)
+		assertNoError(err)
+		report, err := diff.StructuralDiff(firmwareGood, firmwareBad, measurements, ignoreByteSet)
+		assertNoError(err)
+		switch outputFormat {
+		case outputFormatTypeAnalyzedText:
+			fmt.Print(format.StructuralAsText(report))
+		case outputFormatTypeAnalyzedJSON, outputFormatTypeJSON:
+			outputStructuralJSON(report, debugInfo, measurements)
+		}
+		return
+	}
+
 	var scanRanges pkgbytes.Ranges
 	switch *cmd.forceScanArea {
 	case `bios_region`:
@@ -265,6 +318,20 @@ func outputAnalyzedJSON(
 	fmt.Printf("%s", jsonData)
 }
 
+func outputStructuralJSON(
+	report diff.StructuralReport,
+	debugInfo map[string]interface{},
+	measurements pcr.Measurements,
+) {
+	jsonData, err := json.MarshalIndent(struct {
+		Report       diff.StructuralReport
+		DebugInfo    map[string]interface{}
+		Measurements pcr.Measurements
+	}{report, debugInfo, measurements}, ``, ` `)
+	assertNoError(err)
+	fmt.Printf("%s\n", jsonData)
+}
+
 func outputJSON(
 	diffRanges []pkgbytes.Range,
 	debugInfo map[string]interface{},
@@ -0,0 +1,59 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/diff"
+)
+
+// StructuralAsText formats a structural diff report as a text for a CLI.
+func StructuralAsText(report diff.StructuralReport) string {
+	var result strings.Builder
+
+	count := map[diff.ChangeType]int{}
+	for _, change := range report.Changes {
+		count[change.Type]++
+
+		result.WriteString(fmt.Sprintf("\n%s %s", change.Type, change.Module()))
+		if change.Old != nil && change.Old.Range.Length > 0 {
+			result.WriteString(fmt.Sprintf("; old: 0x%x+0x%x", change.Old.Range.Offset, change.Old.Range.Length))
+		}
+		if change.New != nil && change.New.Range.Length > 0 {
+			result.WriteString(fmt.Sprintf("; new: 0x%x+0x%x", change.New.Range.Offset, change.New.Range.Length))
+		}
+		result.WriteString("\n")
+
+		if len(change.ByteDiffs) > 0 {
+			var bytesChanged uint64
+			for _, r := range change.ByteDiffs {
+				bytesChanged += r.Length
+			}
+			result.WriteString(fmt.Sprintf("bytes differs: %d (in %d ranges)", bytesChanged, len(change.ByteDiffs)))
+			if len(change.ByteDiffs) < rangesThreshold {
+				var ranges []string
+				for _, r := range change.ByteDiffs {
+					ranges = append(ranges, fmt.Sprintf("0x%x+0x%x", r.Offset, r.Length))
+				}
+				result.WriteString(": " + strings.Join(ranges, ", "))
+			}
+			result.WriteString("\n")
+		}
+		if len(change.RelatedMeasurements) > 0 {
+			result.WriteString(fmt.Sprintf("related measurements: %v\n", diff.RelatedMeasurementsLaconic(change.RelatedMeasurements)))
+		}
+	}
+
+	result.WriteString("\nTotal:\n")
+	for _, changeType := range []diff.ChangeType{
+		diff.ChangeTypeAdded,
+		diff.ChangeTypeRemoved,
+		diff.ChangeTypeMoved,
+		diff.ChangeTypeModified,
+		diff.ChangeTypeMovedAndModified,
+	} {
+		result.WriteString(fmt.Sprintf("\t%s: %d\n", changeType, count[changeType]))
+	}
+
+	return result.String()
+}
@@ -0,0 +1,157 @@
+package dumptxtheap
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/tools"
+	"github.com/9elements/go-linux-lowlevel-hw/pkg/hwapi"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Command is the implementation of `commands.Command`.
+type Command struct {
+	outputFile    *string
+	outputDump    *string
+	txtPublicDump *string
+	heapDump      *string
+}
+
+// Usage prints the syntax of arguments for this command
+func (cmd Command) Usage() string {
+	return ""
+}
+
+// Description explains what this verb commands to do
+func (cmd Command) Description() string {
+	return "dump and validate the TXT heap from /dev/mem. Works only on Linux"
+}
+
+// SetupFlagSet is called to allow the command implementation
+// to setup which option flags it has.
+func (cmd *Command) SetupFlagSet(flag *flag.FlagSet) {
+	cmd.outputFile = flag.String("output", "",
+		"[optional] dumps the decoded TXT heap into a file")
+	cmd.outputDump = flag.String("output-dump", "",
+		"[optional] dumps the raw TXT public space followed by the raw TXT heap into a file (to be used with -txt-public-dump)")
+	cmd.txtPublicDump = flag.String("txt-public-dump", "",
+		"[optional] override TXT public space and the TXT heap with a file (as written by -output-dump)")
+	cmd.heapDump = flag.String("heap", "",
+		"[optional] file that contains a raw copy of the TXT heap")
+}
+
+// Execute is the main function here. It is responsible to
+// start the execution of the command.
+//
+// `args` are the arguments left unused by verb itself and options.
+func (cmd Command) Execute(args []string) {
+	if *cmd.txtPublicDump != "" && *cmd.heapDump != "" {
+		panic(fmt.Errorf("cannot use flags -txt-public-dump and -heap together"))
+	}
+	if *cmd.outputDump != "" && *cmd.heapDump != "" {
+		panic(fmt.Errorf("cannot use flags -output-dump and -heap together"))
+	}
+
+	raw, err := cmd.getHeap()
+	if err != nil {
+		panic(err)
+	}
+	if len(*cmd.outputDump) > 0 {
+		err = ioutil.WriteFile(*cmd.outputDump, raw.dump(), 0666)
+		if err != nil {
+			panic(fmt.Sprintf("failed to write data to file %s, err: %v", *cmd.outputDump, err))
+		}
+	}
+
+	heap, parseErr := tools.ParseTXTHeap(raw.heap)
+	printHeap(heap)
+	if parseErr != nil {
+		fmt.Printf("\nWarning: %v\n", parseErr)
+		fmt.Printf("The regions after BiosData are only populated after a measured launch\n")
+	}
+	if err := heap.Validate(parseErr == nil); err != nil {
+		fmt.Printf("\nValidation failed: %v\n", err)
+	} else {
+		fmt.Printf("\nValidation passed\n")
+	}
+
+	if len(*cmd.outputFile) > 0 {
+		b, err := yaml.Marshal(heap)
+		if err != nil {
+			panic(fmt.Sprintf("failed to marshal TXT heap into yaml, err: %v", err))
+		}
+		err = ioutil.WriteFile(*cmd.outputFile, b, 0666)
+		if err != nil {
+			panic(fmt.Sprintf("failed to write data to file %s, err: %v", *cmd.outputFile, err))
+		}
+	}
+}
+
+// rawHeap is a raw copy of the TXT heap and of the TXT public space it
+// was located by (if known).
+type rawHeap struct {
+	public []byte
+	heap   []byte
+}
+
+// dump returns the format read by -txt-public-dump: the TXT public space
+// followed by the TXT heap.
+func (raw rawHeap) dump() []byte {
+	result := make([]byte, 0, tools.TxtPublicSpaceSize+len(raw.heap))
+	result = append(result, raw.public...)
+	return append(result, raw.heap...)
+}
+
+func (cmd Command) getHeap() (rawHeap, error) {
+	if *cmd.heapDump != "" {
+		b, err := ioutil.ReadFile(*cmd.heapDump)
+		if err != nil {
+			return rawHeap{}, fmt.Errorf("unable to read TXT heap from '%s': %w", *cmd.heapDump, err)
+		}
+		return rawHeap{heap: b}, nil
+	}
+
+	if *cmd.txtPublicDump != "" {
+		b, err := ioutil.ReadFile(*cmd.txtPublicDump)
+		if err != nil {
+			return rawHeap{}, fmt.Errorf("unable to read TXT public space from '%s': %w", *cmd.txtPublicDump, err)
+		}
+		return parseDump(b)
+	}
+
+	txtAPI := hwapi.GetAPI()
+	public, err := tools.FetchTXTRegs(txtAPI)
+	if err != nil {
+		return rawHeap{}, err
+	}
+	regs, err := tools.ParseTXTRegs(public)
+	if err != nil {
+		return rawHeap{}, fmt.Errorf("unable to parse TXT public space: %w", err)
+	}
+	heap, err := tools.ReadTXTHeap(txtAPI, regs)
+	if err != nil {
+		return rawHeap{}, err
+	}
+	return rawHeap{public: public, heap: heap}, nil
+}
+
+// parseDump splits a dump written by -output-dump into the TXT public
+// space and the TXT heap. The heap size is taken from the TXT public space.
+func parseDump(b []byte) (rawHeap, error) {
+	if len(b) < tools.TxtPublicSpaceSize {
+		return rawHeap{}, fmt.Errorf("TXT public space dump is too short: %d < %d", len(b), tools.TxtPublicSpaceSize)
+	}
+	public := b[:tools.TxtPublicSpaceSize]
+	regs, err := tools.ParseTXTRegs(public)
+	if err != nil {
+		return rawHeap{}, fmt.Errorf("unable to parse TXT public space: %w", err)
+	}
+	heap := b[tools.TxtPublicSpaceSize:]
+	if regs.HeapSize == 0 || uint64(len(heap)) != uint64(regs.HeapSize) {
+		return rawHeap{}, fmt.Errorf("the dump does not contain the TXT heap: expected 0x%x bytes after the TXT public space, got 0x%x (use -output-dump to create a dump with the heap)",
+			regs.HeapSize, len(heap))
+	}
+	return rawHeap{public: public, heap: heap}, nil
+}
@@ -0,0 +1,87 @@
+package dumptxtheap
+
+import (
+	"fmt"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/tools"
+)
+
+var regionNames = [...]string{"BiosData", "OsMleData", "OsSinitData", "SinitMleData"}
+
+// printHeap outputs the TXT heap in a human-readable format
+func printHeap(heap *tools.TXTHeap) {
+	fmt.Printf("TXT heap, size: 0x%X\n", heap.Size)
+	for idx, region := range heap.Regions {
+		if region.Size == 0 {
+			continue
+		}
+		fmt.Printf("  %-12s offset: 0x%08X, size: 0x%X\n", regionNames[idx], region.Offset, region.Size)
+	}
+
+	d := heap.BiosData
+	fmt.Printf("\nBiosData:\n")
+	fmt.Printf("  Version:           %d\n", d.Version)
+	fmt.Printf("  BiosSinitSize:     0x%X\n", d.BiosSinitSize)
+	fmt.Printf("  LcpPdBase:         0x%X\n", d.Reserved1)
+	fmt.Printf("  LcpPdSize:         0x%X\n", d.Reserved2)
+	fmt.Printf("  NumLogProcs:       %d\n", d.NumLogProcs)
+	fmt.Printf("  SinitFlags:        0x%X\n", d.SinitFlags)
+	if d.MleFlags != nil {
+		fmt.Printf("  MleFlags:          %+v\n", *d.MleFlags)
+	}
+	printExtDataElements(d.ExtDataElements)
+
+	if heap.OsMleData != nil {
+		fmt.Printf("\nOsMleData: %d bytes\n", len(heap.OsMleData))
+	}
+
+	if o := heap.OsSinitData; o != nil {
+		fmt.Printf("\nOsSinitData:\n")
+		fmt.Printf("  Version:           %d\n", o.Version)
+		fmt.Printf("  Flags:             0x%X\n", o.Flags)
+		fmt.Printf("  MLEPageTableBase:  0x%X\n", o.MLEPageTableBase)
+		fmt.Printf("  MLESize:           0x%X\n", o.MLESize)
+		fmt.Printf("  MLEHeaderBase:     0x%X\n", o.MLEHeaderBase)
+		fmt.Printf("  PMRLow:            0x%X+0x%X\n", o.PMRLowBase, o.PMRLowSize)
+		fmt.Printf("  PMRHigh:           0x%X+0x%X\n", o.PMRHighBase, o.PMRHighSize)
+		fmt.Printf("  LCPPO:             0x%X+0x%X\n", o.LCPPOBase, o.LCPPOSize)
+		fmt.Printf("  Capabilities:      0x%X\n", o.Capabilities)
+		fmt.Printf("  RSDPPtr:           0x%X\n", o.RSDPPtr)
+		printExtDataElements(o.ExtDataElements)
+	}
+
+	if s := heap.SinitMleData; s != nil {
+		fmt.Printf("\nSinitMleData:\n")
+		fmt.Printf("  Version:           %d\n", s.Version)
+		fmt.Printf("  BiosACMID:         %X\n", s.BiosACMID)
+		fmt.Printf("  EdxSenterFlags:    0x%X\n", s.EdxSenterFlags)
+		fmt.Printf("  MsegValid:         0x%X\n", s.MsegValid)
+		fmt.Printf("  SinitHash:         %X\n", s.SinitHash)
+		fmt.Printf("  MleHash:           %X\n", s.MleHash)
+		fmt.Printf("  StmHash:           %X\n", s.StmHash)
+		fmt.Printf("  LcpPolicyHash:     %X\n", s.LcpPolicyHash)
+		fmt.Printf("  LcpPolicyControl:  0x%X\n", s.LcpPolicyControl)
+		fmt.Printf("  RlpWakeupAddr:     0x%X\n", s.RlpWakeupAddr)
+		fmt.Printf("  ProcScrtmStatus:   0x%X\n", s.ProcScrtmStatus)
+		fmt.Printf("  DMAR copy:         %d bytes\n", len(s.DMAR))
+		fmt.Printf("  MDRs:\n")
+		for _, mdr := range s.MDRs {
+			fmt.Printf("    0x%016X-0x%016X %s\n", mdr.Base, mdr.Base+mdr.Length, mdr.MemType)
+		}
+		printExtDataElements(s.ExtDataElements)
+	}
+}
+
+func printExtDataElements(elements []tools.TXTHeapExtDataElement) {
+	if len(elements) == 0 {
+		return
+	}
+	fmt.Printf("  Extended data elements:\n")
+	for _, element := range elements {
+		if element.Value != nil {
+			fmt.Printf("    %s: %+v\n", element.Type, element.Value)
+		} else {
+			fmt.Printf("    %s: %d bytes\n", element.Type, len(element.Data))
+		}
+	}
+}
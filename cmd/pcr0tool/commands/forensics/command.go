@@ -0,0 +1,293 @@
+package forensics
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands"
+	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/dumpregisters/helpers"
+	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
+	"github.com/9elements/converged-security-suite/v2/pkg/pcrbruteforcer"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	"github.com/linuxboot/contest/pkg/xcontext/bundles/logrusctx"
+	"github.com/linuxboot/contest/pkg/xcontext/logger"
+	pkgbytes "github.com/linuxboot/fiano/pkg/bytes"
+)
+
+func usageAndExit() {
+	flag.Usage()
+	os.Exit(2)
+}
+
+func assertNoError(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Command is the implementation of `commands.Command`.
+type Command struct {
+	badPCR              *string
+	hashFunc            *string
+	flow                *string
+	registers           helpers.FlagRegisters
+	maxDistance         *uint64
+	exhaustiveSizeLimit *uint64
+	regions             flagRanges
+	ffsHeaders          *bool
+	timeout             *time.Duration
+	format              *string
+	pcrFlags            commands.PCRFlags
+}
+
+// Usage prints the syntax of arguments for this command
+func (cmd Command) Usage() string {
+	return "<firmware>"
+}
+
+// Description explains what this verb commands to do
+func (cmd Command) Description() string {
+	return "check if a bad PCR value could be explained by a few flipped bits in the measured data of a known-good firmware image"
+}
+
+// SetupFlagSet is called to allow the command implementation
+// to setup which option flags it has.
+func (cmd *Command) SetupFlagSet(flag *flag.FlagSet) {
+	defaults := pcrbruteforcer.DefaultBitFlipSearchSettings()
+	cmd.badPCR = flag.String("bad-pcr", "", "the PCR value (in hex) to be explained; its length defines the hash function (unless -hash-func is set)")
+	cmd.hashFunc = flag.String("hash-func", "", "[optional] the hash function of the PCR bank (required to distinguish SM3_256 from SHA256); values: "+commands.HashAlgoCommandLineValues())
+	cmd.flow = flag.String("flow", pcr.FlowAuto.String(), "values: "+commands.FlowCommandLineValues())
+	flag.Var(&cmd.registers, "registers", "[optional] file that contains registers as a json array (use value '/dev' to use registers of the local machine)")
+	cmd.maxDistance = flag.Uint64("max-distance", defaults.MaxDistance, "the maximal amount of flipped bits in a measurement")
+	cmd.exhaustiveSizeLimit = flag.Uint64("exhaustive-size-limit", defaults.ExhaustiveSizeLimit,
+		"measurements with measured data not larger than this (in bytes) are searched exhaustively")
+	flag.Var(&cmd.regions, "region", "[optional] comma-separated list of ranges of the image (in format 'offset:length') to be searched in large measurements")
+	cmd.ffsHeaders = flag.Bool("ffs-headers", true, "search in headers of FFS volumes and files in large measurements")
+	cmd.timeout = flag.Duration("timeout", 0, "[optional] stop the search after this time")
+	cmd.format = flag.String("format", "text", `output format; values: "text", "json"`)
+	cmd.pcrFlags.SetupFlagSet(flag)
+}
+
+type matchInfo struct {
+	pcrbruteforcer.BitFlipMatch
+	NodeNames []string `json:",omitempty"`
+}
+
+type result struct {
+	Flow                string
+	PCRIndex            pcr.ID
+	ExpectedPCRValue    pcr.HexBytes
+	BadPCRValue         pcr.HexBytes
+	Matches             []matchInfo
+	SkippedMeasurements []pcr.MeasurementID `json:",omitempty"`
+	Combinations        uint64
+	Problems            []string `json:",omitempty"`
+}
+
+// Execute is the main function here. It is responsible to
+// start the execution of the command.
+//
+// `args` are the arguments left unused by verb itself and options.
+//
+// Exit code is 1 if the bad PCR value could not be explained by bit flips.
+func (cmd Command) Execute(args []string) {
+	if len(args) < 1 {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: no path to the firmare was specified\n")
+		usageAndExit()
+	}
+	if len(args) > 1 {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: too many parameters\n")
+		usageAndExit()
+	}
+
+	flow, err := pcr.FlowFromString(*cmd.flow)
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "unknown attestation flow: '%s'\n", *cmd.flow)
+		usageAndExit()
+	}
+
+	badPCR, err := hex.DecodeString(strings.TrimPrefix(*cmd.badPCR, "0x"))
+	if err != nil || len(badPCR) == 0 {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: invalid or empty value of option 'bad-pcr': %v\n", err)
+		usageAndExit()
+	}
+	var hashAlgo tpmeventlog.TPMAlgorithm
+	if *cmd.hashFunc != "" {
+		hashAlgo, err = commands.ParseHashAlgo(*cmd.hashFunc)
+		if err != nil {
+			_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: invalid value of option 'hash-func': %v\n", err)
+			usageAndExit()
+		}
+	} else {
+		switch len(badPCR) {
+		case 20:
+			hashAlgo = tpmeventlog.TPMAlgorithmSHA1
+		case 32:
+			hashAlgo = tpmeventlog.TPMAlgorithmSHA256
+		case 48:
+			hashAlgo = tpmeventlog.TPMAlgorithmSHA384
+		case 64:
+			hashAlgo = tpmeventlog.TPMAlgorithmSHA512
+		}
+	}
+	hasher, err := tpmeventlog.NewHasher(hashAlgo)
+	if err != nil || hasher.Size() != len(badPCR) {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: unsupported length of the PCR value: %d\n", len(badPCR))
+		usageAndExit()
+	}
+
+	switch *cmd.format {
+	case "text", "json":
+	default:
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: invalid value of option 'format': '%s'\n", *cmd.format)
+		usageAndExit()
+	}
+
+	pcrID, err := cmd.pcrFlags.PCRID()
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: %v\n", err)
+		usageAndExit()
+	}
+
+	measureOpts := []pcr.MeasureOption{
+		pcr.SetFlow(flow),
+		pcr.SetRegisters(cmd.registers),
+		pcr.SetIBBHashDigest(hashAlgo),
+	}
+	pcrMeasureOpts, err := cmd.pcrFlags.MeasureOptions()
+	assertNoError(err)
+	measureOpts = append(measureOpts, pcrMeasureOpts...)
+
+	firmware, err := uefi.ParseUEFIFirmwareFile(args[0])
+	assertNoError(err)
+
+	measurements, flow, _, err := pcr.GetMeasurements(firmware, pcrID, measureOpts...)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "GetPCRMeasurements error: %v\n", err)
+	}
+	if measurements == nil {
+		os.Exit(1)
+	}
+
+	settings := pcrbruteforcer.DefaultBitFlipSearchSettings()
+	settings.HashAlgo = hashAlgo
+	settings.MaxDistance = *cmd.maxDistance
+	settings.ExhaustiveSizeLimit = *cmd.exhaustiveSizeLimit
+	settings.CandidateRegions = pkgbytes.Ranges(cmd.regions)
+	if *cmd.ffsHeaders {
+		headerRegions, err := pcrbruteforcer.FFSHeaderRegions(firmware)
+		assertNoError(err)
+		settings.CandidateRegions = append(settings.CandidateRegions, headerRegions...)
+	}
+
+	ctx, cancelFn := xcontext.WithCancel(logrusctx.NewContext(logger.LevelInfo))
+	defer cancelFn()
+	if *cmd.timeout > 0 {
+		ctx, cancelFn = xcontext.WithTimeout(ctx, *cmd.timeout)
+		defer cancelFn()
+	}
+	signalCh := make(chan os.Signal, 1)
+	signal.Notify(signalCh, os.Interrupt)
+	go func() {
+		<-signalCh
+		cancelFn()
+	}()
+
+	r := result{
+		Flow:             flow.String(),
+		PCRIndex:         pcrID,
+		ExpectedPCRValue: measurements.Calculate(firmware.Buf(), flow.InitialValue(pcrID), hasher, nil),
+		BadPCRValue:      badPCR,
+	}
+
+	report, err := pcrbruteforcer.SearchBitFlips(ctx, badPCR, flow.InitialValue(pcrID), measurements, firmware.Buf(), settings)
+	switch {
+	case errors.As(err, &pcrbruteforcer.ErrNoBitFlipsRequired{}):
+		fmt.Println("the firmware image reproduces the PCR value, there is nothing to explain")
+		return
+	case err != nil:
+		r.Problems = append(r.Problems, err.Error())
+	}
+	if report != nil {
+		r.SkippedMeasurements = report.SkippedMeasurements
+		r.Combinations = report.Combinations
+		for _, match := range report.Matches {
+			r.Matches = append(r.Matches, matchInfo{
+				BitFlipMatch: match,
+				NodeNames:    nodeNames(firmware, match.ImageRanges()),
+			})
+		}
+	}
+
+	switch *cmd.format {
+	case "json":
+		b, err := json.MarshalIndent(r, "", "  ")
+		assertNoError(err)
+		fmt.Println(string(b))
+	default:
+		printText(r, settings.MaxDistance)
+	}
+
+	if len(r.Matches) == 0 {
+		os.Exit(1)
+	}
+}
+
+func nodeNames(firmware *uefi.UEFI, ranges pkgbytes.Ranges) []string {
+	m := map[string]struct{}{}
+	for _, r := range ranges {
+		for _, name := range firmware.GetNamesByRange(r) {
+			m[name] = struct{}{}
+		}
+	}
+	result := make([]string, 0, len(m))
+	for name := range m {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func printText(r result, maxDistance uint64) {
+	fmt.Printf("flow: %s, %s\n", r.Flow, r.PCRIndex)
+	fmt.Printf("expected %s: %X\n", r.PCRIndex, []byte(r.ExpectedPCRValue))
+	fmt.Printf("bad %s:      %X\n\n", r.PCRIndex, []byte(r.BadPCRValue))
+
+	for _, match := range r.Matches {
+		fmt.Printf("%d bit(s) flipped in measurement %s:\n", len(match.Flips), match.MeasurementID)
+		for _, flip := range match.Flips {
+			if flip.ImageOffset != nil {
+				fmt.Printf("\tdata offset 0x%X, bit %d, image offset 0x%X\n", flip.DataOffset, flip.Bit, *flip.ImageOffset)
+			} else {
+				fmt.Printf("\tdata offset 0x%X, bit %d (not from the image)\n", flip.DataOffset, flip.Bit)
+			}
+		}
+		if len(match.NodeNames) > 0 {
+			fmt.Printf("\tnodes: %s\n", strings.Join(match.NodeNames, ", "))
+		}
+	}
+	if len(r.SkippedMeasurements) > 0 {
+		fmt.Printf("skipped large measurements (no candidate regions): %v\n", r.SkippedMeasurements)
+	}
+	fmt.Printf("checked combinations: %d\n\n", r.Combinations)
+
+	for _, problem := range r.Problems {
+		fmt.Printf("FAIL: %s\n", problem)
+	}
+	if len(r.Matches) > 0 {
+		fmt.Println("the PCR value is explained by bit flips, which points to a corruption rather than to a tampering")
+		return
+	}
+	fmt.Printf("the PCR value is not explained by up to %d flipped bits in the searched data\n", maxDistance)
+}
@@ -0,0 +1,45 @@
+package forensics
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	pkgbytes "github.com/linuxboot/fiano/pkg/bytes"
+)
+
+var _ flag.Value = (*flagRanges)(nil)
+
+// flagRanges is a list of byte ranges in format "offset:length", the values
+// are parsed as Go integer literals (so "0x" prefix is supported).
+type flagRanges pkgbytes.Ranges
+
+// String implements flag.Value.
+func (f flagRanges) String() string {
+	var result []string
+	for _, r := range f {
+		result = append(result, fmt.Sprintf("0x%X:0x%X", r.Offset, r.Length))
+	}
+	return strings.Join(result, ",")
+}
+
+// Set implements flag.Value.
+func (f *flagRanges) Set(in string) error {
+	for _, item := range strings.Split(in, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ":")
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid range '%s', expected format 'offset:length'", item)
+		}
+		offset, err := strconv.ParseUint(parts[0], 0, 64)
+		if err != nil {
+			return fmt.Errorf("invalid offset '%s': %w", parts[0], err)
+		}
+		length, err := strconv.ParseUint(parts[1], 0, 64)
+		if err != nil {
+			return fmt.Errorf("invalid length '%s': %w", parts[1], err)
+		}
+		*f = append(*f, pkgbytes.Range{Offset: offset, Length: length})
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+)
+
+// PCRFlags is a set of option flags which selects the PCR to calculate
+// and provides the input data which could not be extracted from the firmware
+// image (required for PCR1 and PCR7).
+type PCRFlags struct {
+	pcrIndex                  *uint
+	efiVariables              *string
+	smbiosTables              *string
+	cpuSignature              *uint
+	skipUndefinedMeasurements *bool
+}
+
+// SetupFlagSet adds the option flags to the flag set.
+func (f *PCRFlags) SetupFlagSet(flag *flag.FlagSet) {
+	f.pcrIndex = flag.Uint("pcr-index", 0, "which PCR to calculate; values: 0, 1, 7")
+	f.SetupMeasureFlagSet(flag)
+}
+
+// SetupMeasureFlagSet adds only the option flags which provide the input
+// data (without option "pcr-index"). It is used by commands which calculate
+// multiple PCRs.
+func (f *PCRFlags) SetupMeasureFlagSet(flag *flag.FlagSet) {
+	f.efiVariables = flag.String("efi-variables", "",
+		"[optional] file with an UEFI variable store to be used instead of the variable store of the firmware image (affects PCR1 and PCR7)")
+	f.smbiosTables = flag.String("smbios-tables", "",
+		"[optional] file with SMBIOS tables, for example /sys/firmware/dmi/tables/DMI (affects PCR1)")
+	f.cpuSignature = flag.Uint("cpu-signature", 0,
+		"[optional] CPU signature (CPUID leaf 1, EAX) to select the measured microcode update (affects PCR1)")
+	f.skipUndefinedMeasurements = flag.Bool("skip-undefined-measurements", false,
+		"skip (with a warning) the measurements whose input is not defined by options 'smbios-tables' and 'cpu-signature' instead of failing; the PCR value will not match the real one (affects PCR1)")
+}
+
+// PCRID returns the selected PCR index.
+func (f *PCRFlags) PCRID() (pcr.ID, error) {
+	if f.pcrIndex == nil {
+		return 0, fmt.Errorf("option 'pcr-index' is not supported")
+	}
+	switch *f.pcrIndex {
+	case 0, 1, 7:
+		return pcr.ID(*f.pcrIndex), nil
+	}
+	return 0, fmt.Errorf("PCR index %d is not supported", *f.pcrIndex)
+}
+
+// MeasureOptions returns the measure options defined by the flags.
+func (f *PCRFlags) MeasureOptions() ([]pcr.MeasureOption, error) {
+	var opts []pcr.MeasureOption
+	if *f.efiVariables != "" {
+		b, err := ioutil.ReadFile(*f.efiVariables)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read file '%s': %w", *f.efiVariables, err)
+		}
+		variables, err := uefi.FindEFIVariables(b)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse UEFI variables from file '%s': %w", *f.efiVariables, err)
+		}
+		opts = append(opts, pcr.SetEFIVariables(variables))
+	}
+	if *f.smbiosTables != "" {
+		b, err := ioutil.ReadFile(*f.smbiosTables)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read file '%s': %w", *f.smbiosTables, err)
+		}
+		opts = append(opts, pcr.SetSMBIOSTables(b))
+	}
+	if *f.cpuSignature != 0 {
+		opts = append(opts, pcr.SetCPUSignature(uint32(*f.cpuSignature)))
+	}
+	if *f.skipUndefinedMeasurements {
+		opts = append(opts, pcr.SetSkipUndefinedMeasurements(true))
+	}
+	return opts, nil
+}
@@ -1,15 +1,11 @@
 package sum
 
 import (
-	"crypto/sha1"
-	"crypto/sha256"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"hash"
 	"log"
 	"os"
-	"strings"
 
 	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands"
 	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/dumpregisters/helpers"
@@ -19,7 +15,6 @@ import (
 	"github.com/9elements/converged-security-suite/v2/pkg/tpmdetection"
 	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
 	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
-	"github.com/google/go-tpm/tpm2"
 )
 
 func usageAndExit() {
@@ -41,6 +36,10 @@ type Command struct {
 	registers           helpers.FlagRegisters
 	tpmDevice           *string
 	compareWithEventLog *string
+	pcrFlags            commands.PCRFlags
+	format              *string
+	reportIncludeData   *bool
+	printJSONSchema     *bool
 
 	printMeasurementLengthLimit *uint
 
@@ -55,7 +54,7 @@ func (cmd Command) Usage() string {
 
 // Description explains what this verb commands to do
 func (cmd Command) Description() string {
-	return "calculate the expected value of PCR0 for a specified firmware image"
+	return "calculate the expected value of PCR0 (or another PCR, see -pcr-index) for a specified firmware image"
 }
 
 // SetupFlagSet is called to allow the command implementation
@@ -63,12 +62,16 @@ func (cmd Command) Description() string {
 func (cmd *Command) SetupFlagSet(flag *flag.FlagSet) {
 	cmd.isQuiet = flag.Bool("quiet", false, `display only the result`)
 	cmd.flow = flag.String("flow", pcr.FlowAuto.String(), "values: "+commands.FlowCommandLineValues())
-	cmd.hashFunc = flag.String("hash-func", "sha1", `which hash function use to hash measurements and to extend the PCR0; values: "sha1", "sha256"`)
+	cmd.hashFunc = flag.String("hash-func", "sha1", "which hash function use to hash measurements and to extend the PCR0 (comma-separated list to calculate multiple PCR banks at once); values: "+commands.HashAlgoCommandLineValues())
 	flag.Var(&cmd.registers, "registers", "[optional] file that contains registers as a json array (use value '/dev' to use registers of the local machine)")
 	cmd.tpmDevice = flag.String("tpm-device", "", "[optional] tpm device used for measurements, values: "+commands.TPMTypeCommandLineValues())
 	cmd.compareWithEventLog = flag.String("compare-with-eventlog", "", "[optional] compare expected measurements with a TPM EventLog")
 	cmd.printMeasurementLengthLimit = flag.Uint("print-measurement-length-limit", 20, "length limit of measured data to be printed")
 	cmd.decrementACMPolicyStatus = flag.Uint("decrement-acm-policy-status", 0, "[advanced] decrement Intel ACM Policy Status value")
+	cmd.format = flag.String("format", "text", `output format; values: "text", "json" (see -print-json-schema)`)
+	cmd.reportIncludeData = flag.Bool("report-include-data", false, "[json] include the data of firmware ranges into the report, so that the PCR value could be reproduced without the firmware image")
+	cmd.printJSONSchema = flag.Bool("print-json-schema", false, "print the JSON schema of the report printed with '-format json' and exit")
+	cmd.pcrFlags.SetupFlagSet(flag)
 }
 
 // Execute is the main function here. It is responsible to
@@ -76,6 +79,10 @@ func (cmd *Command) SetupFlagSet(flag *flag.FlagSet) {
 //
 // `args` are the arguments left unused by verb itself and options.
 func (cmd Command) Execute(args []string) {
+	if *cmd.printJSONSchema {
+		fmt.Print(pcr.MeasurementsReportJSONSchema)
+		return
+	}
 	if len(args) < 1 {
 		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: no path to the firmare was specified\n")
 		usageAndExit()
@@ -107,24 +114,43 @@ func (cmd Command) Execute(args []string) {
 		}
 	}
 
-	var measureOpts []pcr.MeasureOption
-	measureOpts = append(measureOpts, pcr.SetFlow(flow))
-	measureOpts = append(measureOpts, pcr.SetRegisters(cmd.registers))
+	hashAlgos, err := commands.ParseHashAlgos(*cmd.hashFunc)
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: invalid value of option 'hash-func': %v\n", err)
+		usageAndExit()
+	}
+	hashAlgo := hashAlgos[0]
 
-	var hashFunc hash.Hash
-	hashFuncString := strings.ToLower(*cmd.hashFunc)
-	switch hashFuncString {
-	case "sha1", "":
-		hashFunc = sha1.New()
-		measureOpts = append(measureOpts, pcr.SetIBBHashDigest(tpm2.AlgSHA1))
-	case "sha256":
-		hashFunc = sha256.New()
-		measureOpts = append(measureOpts, pcr.SetIBBHashDigest(tpm2.AlgSHA256))
+	switch *cmd.format {
+	case "text":
+	case "json":
+		if len(hashAlgos) > 1 {
+			_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: multiple PCR banks are not supported with '-format json'\n")
+			usageAndExit()
+		}
+		if *cmd.compareWithEventLog != "" {
+			_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: option 'compare-with-eventlog' is not supported with '-format json'\n")
+			usageAndExit()
+		}
 	default:
-		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: invalid value of option 'hash-func': '%s'\n", hashFuncString)
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: invalid value of option 'format': '%s'\n", *cmd.format)
+		usageAndExit()
+	}
+
+	pcrID, err := cmd.pcrFlags.PCRID()
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: %v\n", err)
 		usageAndExit()
 	}
 
+	var measureOpts []pcr.MeasureOption
+	measureOpts = append(measureOpts, pcr.SetFlow(flow))
+	measureOpts = append(measureOpts, pcr.SetRegisters(cmd.registers))
+
+	pcrMeasureOpts, err := cmd.pcrFlags.MeasureOptions()
+	assertNoError(err)
+	measureOpts = append(measureOpts, pcrMeasureOpts...)
+
 	if len(*cmd.tpmDevice) > 0 {
 		tpmDevice, err := tpmdetection.FromString(*cmd.tpmDevice)
 		if err != nil {
@@ -136,7 +162,22 @@ func (cmd Command) Execute(args []string) {
 	firmware, err := uefi.ParseUEFIFirmwareFile(imagePath)
 	assertNoError(err)
 
-	measurements, flow, debugInfo, err := pcr.GetMeasurements(firmware, 0, measureOpts...)
+	measurements, flow, debugInfo, err := pcr.GetMeasurements(firmware, pcrID, append(measureOpts, pcr.SetIBBHashDigest(hashAlgo))...)
+	if *cmd.format == "json" {
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "GetPCRMeasurements error: %v\n", err)
+		}
+		if measurements == nil {
+			os.Exit(1)
+		}
+		report, err := pcr.NewMeasurementsReport(firmware.Buf(), pcrID, flow, hashAlgo, registers.Registers(cmd.registers), measurements, *cmd.reportIncludeData)
+		assertNoError(err)
+		b, err := json.MarshalIndent(report, "", "  ")
+		assertNoError(err)
+		fmt.Println(string(b))
+		return
+	}
+
 	var pcrLogger pcr.Printfer
 	if !*cmd.isQuiet {
 		debugInfoBytes, err := json.MarshalIndent(debugInfo, "", "  ")
@@ -155,19 +196,44 @@ func (cmd Command) Execute(args []string) {
 		os.Exit(1)
 	}
 	pcr.LoggingDataLimit = *cmd.printMeasurementLengthLimit
-	result := measurements.Calculate(firmware.Buf(), flow.TPMLocality(), hashFunc, pcrLogger)
+	if len(hashAlgos) == 1 {
+		hashFunc, err := tpmeventlog.NewHasher(hashAlgo)
+		assertNoError(err)
+		result := measurements.Calculate(firmware.Buf(), flow.InitialValue(pcrID), hashFunc, pcrLogger)
 
-	if !*cmd.isQuiet {
-		fmt.Printf("Resulting PCR0: ")
+		if !*cmd.isQuiet {
+			fmt.Printf("Resulting %s: ", pcrID)
+		}
+		fmt.Printf("%X\n", result)
+	} else {
+		// Some measurements depend on the PCR bank, so the measurements
+		// printed above are the measurements of the first bank only.
+		values, _, err := pcr.CalculateExpectedBanks(firmware, pcrID, hashAlgos, measureOpts...)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "CalculateExpectedBanks error: %v\n", err)
+		}
+		for _, value := range values {
+			if value.Value == nil {
+				continue
+			}
+			if !*cmd.isQuiet {
+				fmt.Printf("Resulting %s (%s): ", pcrID, tpmeventlog.HashAlgorithmName(value.HashAlgo))
+			} else {
+				fmt.Printf("%s:", tpmeventlog.HashAlgorithmName(value.HashAlgo))
+			}
+			fmt.Printf("%X\n", value.Value)
+		}
 	}
-	fmt.Printf("%X\n", result)
 
 	if *cmd.compareWithEventLog != "" {
 		fmt.Println()
 
-		if *cmd.hashFunc != "sha1" {
+		if len(hashAlgos) != 1 || hashAlgo != tpmeventlog.TPMAlgorithmSHA1 {
 			panic("comparing with TPM EventLog is currently supported only for SHA1 digests")
 		}
+		if pcrID != 0 {
+			panic("comparing with TPM EventLog is currently supported only for PCR0")
+		}
 		f, err := os.Open(*cmd.compareWithEventLog)
 		assertNoError(err)
 		tpmEventLog, err := tpmeventlog.Parse(f)
@@ -0,0 +1,189 @@
+package synthesizeeventlog
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands"
+	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/dumpregisters/helpers"
+	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+)
+
+func usageAndExit() {
+	flag.Usage()
+	os.Exit(2)
+}
+
+func assertNoError(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Command is the implementation of `commands.Command`.
+type Command struct {
+	flow                *string
+	registers           helpers.FlagRegisters
+	hashAlgos           *string
+	format              *string
+	output              *string
+	compareWithEventLog *string
+	pcrFlags            commands.PCRFlags
+}
+
+// Usage prints the syntax of arguments for this command
+func (cmd Command) Usage() string {
+	return "<firmware>"
+}
+
+// Description explains what this verb commands to do
+func (cmd Command) Description() string {
+	return "construct the TPM Event Log expected to be produced by a specified firmware image"
+}
+
+// SetupFlagSet is called to allow the command implementation
+// to setup which option flags it has.
+func (cmd *Command) SetupFlagSet(flag *flag.FlagSet) {
+	cmd.flow = flag.String("flow", pcr.FlowAuto.String(), "values: "+commands.FlowCommandLineValues())
+	flag.Var(&cmd.registers, "registers", "[optional] file that contains registers as a json array (use value '/dev' to use registers of the local machine)")
+	cmd.hashAlgos = flag.String("hash-algos", "sha1,sha256", "comma-separated list of PCR banks; values: "+commands.HashAlgoCommandLineValues())
+	cmd.format = flag.String("format", tpmeventlog.FormatTCG.String(), "the format of the output EventLog, values: "+formatCommandLineValues())
+	cmd.output = flag.String("output", "", "[optional] path to the file to write the EventLog to (default: stdout)")
+	cmd.compareWithEventLog = flag.String("compare-with-eventlog", "", "[optional] path to a binary EventLog (for example captured from a host) to be compared with the expected one event by event; the expected EventLog is not printed in this case")
+	cmd.pcrFlags.SetupFlagSet(flag)
+}
+
+func formatCommandLineValues() string {
+	var result []string
+	for format := tpmeventlog.FormatUndefined + 1; format < tpmeventlog.EndOfFormat; format++ {
+		result = append(result, format.String())
+	}
+	return strings.Join(result, ", ")
+}
+
+// Execute is the main function here. It is responsible to
+// start the execution of the command.
+//
+// `args` are the arguments left unused by verb itself and options.
+//
+// With -compare-with-eventlog the exit code is 1 if the EventLogs differ.
+func (cmd Command) Execute(args []string) {
+	if len(args) < 1 {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: no path to the firmare was specified\n")
+		usageAndExit()
+	}
+	if len(args) > 1 {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: too many parameters\n")
+		usageAndExit()
+	}
+
+	flow, err := pcr.FlowFromString(*cmd.flow)
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "unknown attestation flow: '%s'\n", *cmd.flow)
+		usageAndExit()
+	}
+	hashAlgos, err := commands.ParseHashAlgos(*cmd.hashAlgos)
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: invalid value of option 'hash-algos': %v\n", err)
+		usageAndExit()
+	}
+	format, err := tpmeventlog.FormatFromString(*cmd.format)
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: invalid value of option 'format': %v\n", err)
+		usageAndExit()
+	}
+	pcrID, err := cmd.pcrFlags.PCRID()
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: %v\n", err)
+		usageAndExit()
+	}
+
+	measureOpts := []pcr.MeasureOption{
+		pcr.SetFlow(flow),
+		pcr.SetRegisters(cmd.registers),
+	}
+	pcrMeasureOpts, err := cmd.pcrFlags.MeasureOptions()
+	assertNoError(err)
+	measureOpts = append(measureOpts, pcrMeasureOpts...)
+
+	firmware, err := uefi.ParseUEFIFirmwareFile(args[0])
+	assertNoError(err)
+
+	eventLog, flow, err := pcr.ExpectedEventLog(firmware, pcrID, hashAlgos, measureOpts...)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ExpectedEventLog error: %v\n", err)
+	}
+	if eventLog == nil {
+		os.Exit(1)
+	}
+
+	if *cmd.compareWithEventLog != "" {
+		f, err := os.Open(*cmd.compareWithEventLog)
+		assertNoError(err)
+		defer f.Close()
+		actualEventLog, err := tpmeventlog.Parse(f)
+		assertNoError(err)
+
+		fmt.Printf("flow: %s\n", flow)
+		if !compareEventLogs(eventLog, actualEventLog, pcrID, hashAlgos) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	output := os.Stdout
+	if *cmd.output != "" {
+		output, err = os.Create(*cmd.output)
+		assertNoError(err)
+		defer output.Close()
+	}
+	assertNoError(eventLog.Encode(output, format))
+}
+
+// compareEventLogs prints the differences between the events of
+// the expected and the actual EventLogs and returns true if there are none.
+func compareEventLogs(expected, actual *tpmeventlog.TPMEventLog, pcrID pcr.ID, hashAlgos []tpmeventlog.TPMAlgorithm) bool {
+	match := true
+	for _, hashAlgo := range hashAlgos {
+		expectedEvents, err := expected.FilterEvents(pcrID, hashAlgo)
+		assertNoError(err)
+		actualEvents, err := actual.FilterEvents(pcrID, hashAlgo)
+		assertNoError(err)
+
+		fmt.Printf("\n%s bank of %s:\n", tpmeventlog.HashAlgorithmName(hashAlgo), pcrID)
+		for idx := 0; idx < len(expectedEvents) || idx < len(actualEvents); idx++ {
+			switch {
+			case idx >= len(actualEvents):
+				match = false
+				fmt.Printf("#%d\tMISSING\texpected: %s\n", idx, formatEvent(expectedEvents[idx]))
+			case idx >= len(expectedEvents):
+				match = false
+				fmt.Printf("#%d\tEXTRA\tactual:   %s\n", idx, formatEvent(actualEvents[idx]))
+			case expectedEvents[idx].Type != actualEvents[idx].Type ||
+				!bytes.Equal(expectedEvents[idx].Digest.Digest, actualEvents[idx].Digest.Digest):
+				match = false
+				fmt.Printf("#%d\tDIFF\texpected: %s\n", idx, formatEvent(expectedEvents[idx]))
+				fmt.Printf("\t\tactual:   %s\n", formatEvent(actualEvents[idx]))
+			default:
+				fmt.Printf("#%d\tOK\t%s\n", idx, formatEvent(expectedEvents[idx]))
+			}
+		}
+	}
+	fmt.Println()
+	if match {
+		fmt.Println("the EventLogs match")
+	} else {
+		fmt.Println("the EventLogs differ")
+	}
+	return match
+}
+
+func formatEvent(event *tpmeventlog.Event) string {
+	return fmt.Sprintf("type 0x%08X digest %X", uint32(event.Type), event.Digest.Digest)
+}
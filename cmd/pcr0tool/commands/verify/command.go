@@ -0,0 +1,284 @@
+package verify
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands"
+	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/dumpregisters/helpers"
+	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
+	"github.com/9elements/converged-security-suite/v2/pkg/pcrbruteforcer"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+	pkgbytes "github.com/linuxboot/fiano/pkg/bytes"
+)
+
+func usageAndExit() {
+	flag.Usage()
+	os.Exit(2)
+}
+
+func assertNoError(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Command is the implementation of `commands.Command`.
+type Command struct {
+	eventLog  *string
+	flow      *string
+	hashFunc  *string
+	registers helpers.FlagRegisters
+	quotedPCR *string
+	format    *string
+	pcrFlags  commands.PCRFlags
+}
+
+// Usage prints the syntax of arguments for this command
+func (cmd Command) Usage() string {
+	return "<firmware>"
+}
+
+// Description explains what this verb commands to do
+func (cmd Command) Description() string {
+	return "verify a TPM EventLog (and optionally a quoted PCR value) against a firmware image, event by event"
+}
+
+// SetupFlagSet is called to allow the command implementation
+// to setup which option flags it has.
+func (cmd *Command) SetupFlagSet(flag *flag.FlagSet) {
+	cmd.eventLog = flag.String("event-log", "/sys/kernel/security/tpm0/binary_bios_measurements", "path to the binary EventLog")
+	cmd.flow = flag.String("flow", pcr.FlowAuto.String(), "values: "+commands.FlowCommandLineValues())
+	cmd.hashFunc = flag.String("hash-func", "sha1", "which hash function to verify; values: "+commands.HashAlgoCommandLineValues())
+	flag.Var(&cmd.registers, "registers", "[optional] file that contains registers as a json array (use value '/dev' to use registers of the local machine)")
+	cmd.quotedPCR = flag.String("quoted-pcr", "", "[optional] the PCR value (in hex) reported by TPM, to be compared with the EventLog and the firmware")
+	cmd.format = flag.String("format", "text", `output format; values: "text", "json"`)
+	cmd.pcrFlags.SetupFlagSet(flag)
+}
+
+type eventVerdict struct {
+	pcrbruteforcer.EventVerdict
+	Ranges    pkgbytes.Ranges `json:",omitempty"`
+	NodeNames []string        `json:",omitempty"`
+}
+
+type result struct {
+	Flow             string
+	PCRIndex         pcr.ID
+	HashAlgorithm    string
+	Verdicts         []eventVerdict
+	ExpectedPCRValue pcr.HexBytes
+	EventLogPCRValue pcr.HexBytes `json:",omitempty"`
+	QuotedPCRValue   pcr.HexBytes `json:",omitempty"`
+	Problems         []string     `json:",omitempty"`
+}
+
+// Execute is the main function here. It is responsible to
+// start the execution of the command.
+//
+// `args` are the arguments left unused by verb itself and options.
+//
+// Exit code is 1 if the verification failed.
+func (cmd Command) Execute(args []string) {
+	if len(args) < 1 {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: no path to the firmare was specified\n")
+		usageAndExit()
+	}
+	if len(args) > 1 {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: too many parameters\n")
+		usageAndExit()
+	}
+	imagePath := args[0]
+
+	flow, err := pcr.FlowFromString(*cmd.flow)
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "unknown attestation flow: '%s'\n", *cmd.flow)
+		usageAndExit()
+	}
+
+	hashAlgo, err := commands.ParseHashAlgo(*cmd.hashFunc)
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: invalid value of option 'hash-func': %v\n", err)
+		usageAndExit()
+	}
+
+	switch *cmd.format {
+	case "text", "json":
+	default:
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: invalid value of option 'format': '%s'\n", *cmd.format)
+		usageAndExit()
+	}
+
+	var quotedPCR []byte
+	if *cmd.quotedPCR != "" {
+		quotedPCR, err = hex.DecodeString(strings.TrimPrefix(*cmd.quotedPCR, "0x"))
+		if err != nil {
+			_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: invalid value of option 'quoted-pcr': %v\n", err)
+			usageAndExit()
+		}
+	}
+
+	pcrID, err := cmd.pcrFlags.PCRID()
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: %v\n", err)
+		usageAndExit()
+	}
+
+	measureOpts := []pcr.MeasureOption{
+		pcr.SetFlow(flow),
+		pcr.SetRegisters(cmd.registers),
+		pcr.SetIBBHashDigest(hashAlgo),
+	}
+	pcrMeasureOpts, err := cmd.pcrFlags.MeasureOptions()
+	assertNoError(err)
+	measureOpts = append(measureOpts, pcrMeasureOpts...)
+
+	eventLogFile, err := os.Open(*cmd.eventLog)
+	assertNoError(err)
+	eventLog, err := tpmeventlog.Parse(eventLogFile)
+	_ = eventLogFile.Close()
+	assertNoError(err)
+
+	firmware, err := uefi.ParseUEFIFirmwareFile(imagePath)
+	assertNoError(err)
+
+	measurements, flow, _, err := pcr.GetMeasurements(firmware, pcrID, measureOpts...)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "GetPCRMeasurements error: %v\n", err)
+	}
+	if measurements == nil {
+		os.Exit(1)
+	}
+
+	verdicts, err := pcrbruteforcer.VerifyEventLog(eventLog, pcrID, hashAlgo, flow, measurements, firmware.Buf())
+	assertNoError(err)
+
+	hasher, err := tpmeventlog.NewHasher(hashAlgo)
+	assertNoError(err)
+
+	r := result{
+		Flow:             flow.String(),
+		PCRIndex:         pcrID,
+		HashAlgorithm:    strings.ToUpper(tpmeventlog.HashAlgorithmName(hashAlgo)),
+		ExpectedPCRValue: measurements.Calculate(firmware.Buf(), flow.InitialValue(pcrID), hasher, nil),
+		QuotedPCRValue:   quotedPCR,
+	}
+	for _, verdict := range verdicts {
+		v := eventVerdict{EventVerdict: verdict}
+		if verdict.Measurement != nil {
+			v.Ranges = verdict.Measurement.Ranges()
+			v.NodeNames = nodeNames(firmware, v.Ranges)
+		}
+		r.Verdicts = append(r.Verdicts, v)
+	}
+	if !verdicts.IsMatch() {
+		r.Problems = append(r.Problems, "the EventLog does not match the firmware")
+	}
+
+	eventLogPCR, err := pcr.Replay(eventLog, pcrID, hashAlgo, nil)
+	if err != nil {
+		r.Problems = append(r.Problems, fmt.Sprintf("unable to replay the EventLog: %v", err))
+	} else {
+		r.EventLogPCRValue = eventLogPCR
+	}
+	if quotedPCR != nil {
+		if !bytes.Equal(quotedPCR, r.EventLogPCRValue) {
+			r.Problems = append(r.Problems, "the quoted PCR value does not match the EventLog")
+		}
+		if !bytes.Equal(quotedPCR, r.ExpectedPCRValue) {
+			r.Problems = append(r.Problems, "the quoted PCR value does not match the firmware")
+		}
+	}
+
+	if pcrID == 0 && hashAlgo == tpmeventlog.TPMAlgorithmSHA1 && !verdicts.IsMatch() {
+		// Try to explain the mismatch with a corrupted ACM_POLICY_STATUS.
+		match, updatedACMPolicyStatus, _ := pcrbruteforcer.ReproduceEventLog(eventLog, measurements, firmware.Buf())
+		if match && updatedACMPolicyStatus != nil {
+			r.Problems = append(r.Problems, fmt.Sprintf("the EventLog matches the firmware if ACM_POLICY_STATUS is 0x%X", updatedACMPolicyStatus.Raw()))
+		}
+	}
+
+	switch *cmd.format {
+	case "json":
+		b, err := json.MarshalIndent(r, "", "  ")
+		assertNoError(err)
+		fmt.Println(string(b))
+	default:
+		printText(r)
+	}
+
+	if len(r.Problems) > 0 {
+		os.Exit(1)
+	}
+}
+
+func nodeNames(firmware *uefi.UEFI, ranges pkgbytes.Ranges) []string {
+	m := map[string]struct{}{}
+	for _, r := range ranges {
+		for _, name := range firmware.GetNamesByRange(r) {
+			m[name] = struct{}{}
+		}
+	}
+	result := make([]string, 0, len(m))
+	for name := range m {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func printText(r result) {
+	fmt.Printf("flow: %s, %s, hash algorithm: %s\n\n", r.Flow, r.PCRIndex, r.HashAlgorithm)
+	for _, v := range r.Verdicts {
+		eventIdx, eventType := "-", "-"
+		if v.Event != nil {
+			eventIdx = fmt.Sprint(v.EventIndex)
+			eventType = fmt.Sprint(v.Event.Type)
+		}
+		measurementID := "-"
+		switch {
+		case v.Measurement != nil:
+			measurementID = v.Measurement.ID.String()
+		case len(v.CandidateMeasurementIDs) > 0:
+			measurementID = fmt.Sprintf("(could be %v)", v.CandidateMeasurementIDs)
+		}
+		fmt.Printf("%-3s %-11s type:%-11s %s\n", eventIdx, v.Status, eventType, measurementID)
+		if v.Description != "" {
+			fmt.Printf("\t%s\n", v.Description)
+		}
+		if v.Status == pcrbruteforcer.EventVerdictStatusMatch {
+			continue
+		}
+		for _, byteRange := range v.Ranges {
+			fmt.Printf("\trange: 0x%X-0x%X\n", byteRange.Offset, byteRange.End())
+		}
+		if len(v.NodeNames) > 0 {
+			fmt.Printf("\tnodes: %s\n", strings.Join(v.NodeNames, ", "))
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("expected %s:  %X\n", r.PCRIndex, []byte(r.ExpectedPCRValue))
+	if r.EventLogPCRValue != nil {
+		fmt.Printf("EventLog %s:  %X\n", r.PCRIndex, []byte(r.EventLogPCRValue))
+	}
+	if r.QuotedPCRValue != nil {
+		fmt.Printf("quoted %s:    %X\n", r.PCRIndex, []byte(r.QuotedPCRValue))
+	}
+	fmt.Println()
+	if len(r.Problems) == 0 {
+		fmt.Println("OK")
+		return
+	}
+	for _, problem := range r.Problems {
+		fmt.Printf("FAIL: %s\n", problem)
+	}
+}
@@ -0,0 +1,295 @@
+package verifyquote
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands"
+	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/dumpregisters/helpers"
+	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
+	"github.com/9elements/converged-security-suite/v2/pkg/pcrbruteforcer"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmquote"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+	"github.com/linuxboot/contest/pkg/xcontext"
+)
+
+func usageAndExit() {
+	flag.Usage()
+	os.Exit(2)
+}
+
+func assertNoError(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Command is the implementation of `commands.Command`.
+type Command struct {
+	quote       *string
+	signature   *string
+	akPublic    *string
+	nonce       *string
+	golden      *string
+	flow        *string
+	registers   helpers.FlagRegisters
+	claimedPCR0 *string
+	hypotheses  *bool
+	pcrFlags    commands.PCRFlags
+}
+
+// Usage prints the syntax of arguments for this command
+func (cmd Command) Usage() string {
+	return "[firmware]"
+}
+
+// Description explains what this verb commands to do
+func (cmd Command) Description() string {
+	return "verify a TPM2 quote against PCR values predicted from a firmware image (or golden values)"
+}
+
+// SetupFlagSet is called to allow the command implementation
+// to setup which option flags it has.
+func (cmd *Command) SetupFlagSet(flag *flag.FlagSet) {
+	cmd.quote = flag.String("quote", "", "path to the quoted data (TPMS_ATTEST)")
+	cmd.signature = flag.String("signature", "", "path to the quote signature (TPMT_SIGNATURE)")
+	cmd.akPublic = flag.String("ak-public", "", "path to the public key of the attestation key (TPMT_PUBLIC, TPM2B_PUBLIC, PEM or DER)")
+	cmd.nonce = flag.String("nonce", "", "the nonce (qualifying data) the quote was requested with, in hex")
+	cmd.golden = flag.String("golden", "", "[optional] comma-separated list of files with golden values (reports of 'sum -format json') to be used instead of the firmware")
+	cmd.flow = flag.String("flow", pcr.FlowAuto.String(), "values: "+commands.FlowCommandLineValues())
+	flag.Var(&cmd.registers, "registers", "[optional] file that contains registers as a json array (use value '/dev' to use registers of the local machine)")
+	cmd.claimedPCR0 = flag.String("claimed-pcr0", "", "[optional] the PCR0 value reported by the attested machine, in hex; used to explain a PCR0 mismatch")
+	cmd.hypotheses = flag.Bool("hypotheses", false, "[optional] rank the known reasons of a PCR0 mismatch (locality, IBB hash algorithm, BPM copy, PCD firmware vendor version, microcode) by whether they reproduce the claimed PCR0 value")
+	cmd.pcrFlags.SetupMeasureFlagSet(flag)
+}
+
+func readFile(path, option string) []byte {
+	if path == "" {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: option '%s' is required\n", option)
+		usageAndExit()
+	}
+	b, err := ioutil.ReadFile(path)
+	assertNoError(err)
+	return b
+}
+
+func parseHex(s, option string) []byte {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: invalid value of option '%s': %v\n", option, err)
+		usageAndExit()
+	}
+	return b
+}
+
+// Execute is the main function here. It is responsible to
+// start the execution of the command.
+//
+// `args` are the arguments left unused by verb itself and options.
+//
+// Exit code is 1 if the verification failed.
+func (cmd Command) Execute(args []string) {
+	if len(args) > 1 {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "error: too many parameters\n")
+		usageAndExit()
+	}
+
+	flow, err := pcr.FlowFromString(*cmd.flow)
+	if err != nil {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "unknown attestation flow: '%s'\n", *cmd.flow)
+		usageAndExit()
+	}
+
+	quote := tpmquote.Quote{
+		Attest:    readFile(*cmd.quote, "quote"),
+		Signature: readFile(*cmd.signature, "signature"),
+	}
+	if len(quote.Attest) > 2 && int(binary.BigEndian.Uint16(quote.Attest)) == len(quote.Attest)-2 {
+		// TPM2B_ATTEST
+		quote.Attest = quote.Attest[2:]
+	}
+	akPublicKey, err := tpmquote.ParseAKPublicKey(readFile(*cmd.akPublic, "ak-public"))
+	assertNoError(err)
+	nonce := parseHex(*cmd.nonce, "nonce")
+	var claimedPCR0 []byte
+	if *cmd.claimedPCR0 != "" {
+		claimedPCR0 = parseHex(*cmd.claimedPCR0, "claimed-pcr0")
+	}
+
+	quoteInfo, err := quote.Verify(akPublicKey, nonce)
+	if err != nil {
+		fmt.Printf("FAIL: %v\n", err)
+		os.Exit(1)
+	}
+	digestAlgo, err := quote.DigestAlgo()
+	assertNoError(err)
+	bank := quoteInfo.PCRSelection.Hash
+	bankName := strings.ToUpper(tpmeventlog.HashAlgorithmName(bank))
+	fmt.Printf("the quote signature is valid; PCR bank: %s, PCRs: %v\n", bankName, quoteInfo.PCRSelection.PCRs)
+
+	predicted := tpmquote.PCRValues{}
+	if *cmd.golden != "" {
+		for _, path := range strings.Split(*cmd.golden, ",") {
+			var report pcr.MeasurementsReport
+			assertNoError(json.Unmarshal(readFile(path, "golden"), &report))
+			if report.HashAlgorithm != bankName {
+				log.Fatalf("golden value from '%s' is of bank %s, but the quote is of bank %s", path, report.HashAlgorithm, bankName)
+			}
+			predicted[report.PCRIndex] = report.FinalValue
+		}
+	}
+
+	var (
+		firmware         *uefi.UEFI
+		measureOpts      []pcr.MeasureOption
+		pcr0Flow         pcr.Flow
+		pcr0Measurements pcr.Measurements
+	)
+	if len(args) > 0 {
+		firmware, err = uefi.ParseUEFIFirmwareFile(args[0])
+		assertNoError(err)
+
+		measureOpts = []pcr.MeasureOption{
+			pcr.SetFlow(flow),
+			pcr.SetRegisters(cmd.registers),
+			pcr.SetIBBHashDigest(bank),
+		}
+		pcrMeasureOpts, err := cmd.pcrFlags.MeasureOptions()
+		assertNoError(err)
+		measureOpts = append(measureOpts, pcrMeasureOpts...)
+
+		hasher, err := tpmeventlog.NewHasher(bank)
+		assertNoError(err)
+		for _, idx := range quoteInfo.PCRSelection.PCRs {
+			pcrID := pcr.ID(idx)
+			if _, ok := predicted[pcrID]; ok {
+				continue
+			}
+			switch pcrID {
+			case 0, 1, 7:
+			default:
+				continue
+			}
+			measurements, measuredFlow, _, err := pcr.GetMeasurements(firmware, pcrID, measureOpts...)
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "GetPCRMeasurements(%s) error: %v\n", pcrID, err)
+			}
+			if measurements == nil {
+				continue
+			}
+			predicted[pcrID] = measurements.Calculate(firmware.Buf(), measuredFlow.InitialValue(pcrID), hasher, nil)
+			if pcrID == 0 {
+				pcr0Flow, pcr0Measurements = measuredFlow, measurements
+			}
+		}
+	}
+
+	pcrIDs := make([]int, 0, len(predicted))
+	for pcrID := range predicted {
+		pcrIDs = append(pcrIDs, int(pcrID))
+	}
+	sort.Ints(pcrIDs)
+	for _, idx := range pcrIDs {
+		fmt.Printf("predicted %s: %X\n", pcr.ID(idx), predicted[pcr.ID(idx)])
+	}
+
+	err = tpmquote.VerifyPCRs(quoteInfo, predicted, digestAlgo)
+	if err == nil {
+		fmt.Println("OK")
+		return
+	}
+	fmt.Printf("FAIL: %v\n", err)
+
+	if claimedPCR0 == nil || pcr0Measurements == nil {
+		os.Exit(1)
+	}
+
+	// The predicted values do not match the quote. Let's check if the
+	// quote confirms the claimed PCR0 value, and if so try to explain the
+	// difference between the claimed and predicted values.
+	withClaimedPCR0 := tpmquote.PCRValues{}
+	for pcrID, value := range predicted {
+		withClaimedPCR0[pcrID] = value
+	}
+	withClaimedPCR0[0] = claimedPCR0
+	if err := tpmquote.VerifyPCRs(quoteInfo, withClaimedPCR0, digestAlgo); err != nil {
+		fmt.Printf("the claimed PCR0 value is not confirmed by the quote: %v\n", err)
+		os.Exit(1)
+	}
+	if bytes.Equal(claimedPCR0, predicted[0]) {
+		os.Exit(1)
+	}
+	fmt.Printf("the quote confirms the claimed PCR0 value %X, trying to reproduce it...\n", claimedPCR0)
+	isSuccess, locality, updatedACMPolicyStatus, err := pcrbruteforcer.ReproduceExpectedPCR0InBank(
+		xcontext.Background(),
+		claimedPCR0,
+		bank,
+		pcr0Flow,
+		pcr0Measurements,
+		firmware.Buf(),
+	)
+	fmt.Printf("reproduced: %v\n", isSuccess)
+	if isSuccess {
+		fmt.Printf("\tlocality: %d\n", locality)
+		if updatedACMPolicyStatus != nil {
+			fmt.Printf("\tACM_POLICY_STATUS: 0x%X\n", updatedACMPolicyStatus.Raw())
+		}
+	}
+	if err != nil {
+		fmt.Printf("\terr: %v\n", err)
+	}
+
+	if *cmd.hypotheses {
+		report, err := pcrbruteforcer.RankHypotheses(
+			xcontext.Background(),
+			claimedPCR0,
+			pcrbruteforcer.HypothesisInput{
+				Firmware:       firmware,
+				Flow:           pcr0Flow,
+				Measurements:   pcr0Measurements,
+				MeasureOptions: measureOpts,
+			},
+		)
+		if report == nil {
+			assertNoError(err)
+		}
+		if err != nil {
+			fmt.Printf("hypotheses warnings: %v\n", err)
+		}
+		printHypothesesReport(report)
+	}
+	os.Exit(1)
+}
+
+func printHypothesesReport(report *pcrbruteforcer.HypothesesReport) {
+	fmt.Printf("hypotheses (most likely first):\n")
+	for _, result := range report.Results {
+		if !result.Reproduced {
+			continue
+		}
+		amendments := "none"
+		if len(result.Amendments) > 0 {
+			amendments = strings.Join(result.Amendments, "; ")
+		}
+		fmt.Printf("\t[score %d] %s: %s\n", result.Score, result.Strategy, amendments)
+		if result.UpdatedACMPolicyStatus != nil {
+			fmt.Printf("\t\tACM_POLICY_STATUS: 0x%X\n", result.UpdatedACMPolicyStatus.Raw())
+		}
+		for _, issue := range result.Issues {
+			fmt.Printf("\t\t%v\n", issue)
+		}
+	}
+	if report.Best() == nil {
+		fmt.Printf("\tnone of %d hypotheses reproduces the claimed PCR0 value\n", len(report.Results))
+	}
+}
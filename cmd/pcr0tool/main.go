@@ -6,26 +6,38 @@ import (
 	"os"
 
 	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands"
+	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/converteventlog"
 	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/diff"
 	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/displayeventlog"
 	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/displayfwinfo"
 	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/dumpfit"
 	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/dumpregisters"
+	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/dumptxtheap"
+	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/forensics"
 	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/printnodes"
 	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/sum"
+	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/synthesizeeventlog"
+	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/verify"
+	"github.com/9elements/converged-security-suite/v2/cmd/pcr0tool/commands/verifyquote"
 	"github.com/9elements/converged-security-suite/v2/pkg/log"
 	"github.com/linuxboot/fiano/pkg/intel/metadata/manifest"
 	fianoLog "github.com/linuxboot/fiano/pkg/log"
 )
 
 var knownCommands = map[string]commands.Command{
-	"diff":             &diff.Command{},
-	"display_eventlog": &displayeventlog.Command{},
-	"display_fwinfo":   &displayfwinfo.Command{},
-	"dump_fit":         &dumpfit.Command{},
-	"dump_registers":   &dumpregisters.Command{},
-	"printnodes":       &printnodes.Command{},
-	"sum":              &sum.Command{},
+	"convert_eventlog":    &converteventlog.Command{},
+	"diff":                &diff.Command{},
+	"display_eventlog":    &displayeventlog.Command{},
+	"display_fwinfo":      &displayfwinfo.Command{},
+	"dump_fit":            &dumpfit.Command{},
+	"dump_registers":      &dumpregisters.Command{},
+	"dump_txt_heap":       &dumptxtheap.Command{},
+	"forensics":           &forensics.Command{},
+	"printnodes":          &printnodes.Command{},
+	"sum":                 &sum.Command{},
+	"synthesize_eventlog": &synthesizeeventlog.Command{},
+	"verify":              &verify.Command{},
+	"verify_quote":        &verifyquote.Command{},
 }
 
 func usageAndExit() {
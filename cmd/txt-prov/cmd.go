@@ -14,6 +14,7 @@ import (
 // We need a TPM device in most commands.
 type context struct {
 	debug bool
+	tpm   string
 }
 
 type versionCmd struct {
@@ -43,8 +44,9 @@ type showCmd struct {
 }
 
 var cli struct {
-	Debug                    bool `help:"Enable debug mode"`
-	ManifestStrictOrderCheck bool `help:"Enable checking of manifest elements order"`
+	Debug                    bool   `help:"Enable debug mode"`
+	ManifestStrictOrderCheck bool   `help:"Enable checking of manifest elements order"`
+	TPM                      string `name:"tpm" help:"TPM to use: empty for the TPM of the local machine, 'sim' or 'sim:<statefile>' for a software TPM 2.0 simulator (with the state persisted in the statefile)"`
 
 	Version      versionCmd   `cmd help:"Prints the version of the program"`
 	AuxDelete    auxDeleteCmd `cmd help:"Delete AUX index if exists in TPM NVRAM"`
@@ -54,6 +56,7 @@ var cli struct {
 	PsUpdate     psUpdateCmd  `cmd help:"Update PS index content in TPM NVRAM"`
 	PlatformProv platProvCmd  `cmd help:"Provision PS & AUX index with LCP config"`
 	Show         showCmd      `cmd help:"Show current provisioned PS & AUX index in NVRAM on stdout"`
+	LCP          lcpCmd       `cmd name:"lcp" help:"Build, sign and verify LCP policy data files"`
 }
 
 func (v *versionCmd) Run(ctx *context) error {
@@ -63,7 +66,7 @@ func (v *versionCmd) Run(ctx *context) error {
 
 func (a *auxDeleteCmd) Run(ctx *context) error {
 	// Set Aux Delete bit in LCP Policy and writes it to PS index in TPM NVRAM
-	tpm, err := hwapi.NewTPM()
+	tpm, err := openTPM(ctx.tpm)
 	if err != nil {
 		return err
 	}
@@ -97,10 +100,11 @@ func (a *auxDeleteCmd) Run(ctx *context) error {
 
 func (a *auxDefineCmd) Run(ctx *context) error {
 	// Define AUX index in TPM NVRAM
-	tpm, err := hwapi.NewTPM()
+	tpm, err := openTPM(ctx.tpm)
 	if err != nil {
 		return err
 	}
+	defer tpm.Close()
 	switch tpm.Version {
 	case hwapi.TPMVersion12:
 		return fmt.Errorf("TPM 1.2 not supported yet")
@@ -122,10 +126,11 @@ func (a *auxDefineCmd) Run(ctx *context) error {
 }
 func (p *psDeleteCmd) Run(ctx *context) error {
 	// Delete PS index in TPM NVRAM
-	tpm, err := hwapi.NewTPM()
+	tpm, err := openTPM(ctx.tpm)
 	if err != nil {
 		return err
 	}
+	defer tpm.Close()
 	switch tpm.Version {
 	case hwapi.TPMVersion12:
 		return fmt.Errorf("TPM 1.2 not supported yet")
@@ -144,10 +149,11 @@ func (p *psDeleteCmd) Run(ctx *context) error {
 }
 func (p *psDefineCmd) Run(ctx *context) error {
 	// Define PS index in TPM NVRAM
-	tpm, err := hwapi.NewTPM()
+	tpm, err := openTPM(ctx.tpm)
 	if err != nil {
 		return err
 	}
+	defer tpm.Close()
 	switch tpm.Version {
 	case hwapi.TPMVersion12:
 		return fmt.Errorf("TPM 1.2 not supported yet")
@@ -173,10 +179,11 @@ func (p *psDefineCmd) Run(ctx *context) error {
 }
 func (p *psUpdateCmd) Run(ctx *context) error {
 	// Writes new LCP Policy to PS index in TPM NVRAM
-	tpm, err := hwapi.NewTPM()
+	tpm, err := openTPM(ctx.tpm)
 	if err != nil {
 		return err
 	}
+	defer tpm.Close()
 	switch tpm.Version {
 	case hwapi.TPMVersion12:
 		return fmt.Errorf("TPM 1.2 not supported yet")
@@ -204,10 +211,11 @@ func (p *psUpdateCmd) Run(ctx *context) error {
 }
 func (p *platProvCmd) Run(ctx *context) error {
 	// Provision PS & AUX index in TPM NVRAM with LCP Policy
-	tpm, err := hwapi.NewTPM()
+	tpm, err := openTPM(ctx.tpm)
 	if err != nil {
 		return err
 	}
+	defer tpm.Close()
 	switch tpm.Version {
 	case hwapi.TPMVersion12:
 		return fmt.Errorf("TPM 1.2 not supported yet")
@@ -238,10 +246,11 @@ func (p *platProvCmd) Run(ctx *context) error {
 }
 func (s *showCmd) Run(ctx *context) error {
 	// Show PS & AUX index content from TPM NVRAM
-	tpm, err := hwapi.NewTPM()
+	tpm, err := openTPM(ctx.tpm)
 	if err != nil {
 		return err
 	}
+	defer tpm.Close()
 	switch tpm.Version {
 	case hwapi.TPMVersion12:
 		return fmt.Errorf("TPM 1.2 not supported yet")
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"crypto"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/tjfoc/gmsm/x509"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/provisioning/cbnt"
+	"github.com/9elements/converged-security-suite/v2/pkg/provisioning/txt"
+	"github.com/9elements/converged-security-suite/v2/pkg/tools"
+)
+
+type lcpCmd struct {
+	Build  lcpBuildCmd  `cmd help:"Build a LCP policy data file with signed policy lists out of a YAML/JSON description"`
+	Sign   lcpSignCmd   `cmd help:"Sign a policy list of a LCP policy data file"`
+	Verify lcpVerifyCmd `cmd help:"Verify signatures of a LCP policy data file and (optionally) that a PO/PS policy enforces it"`
+	Hash   lcpHashCmd   `cmd help:"Print the policy hash of a LCP policy data file (to put into the PO/PS index)"`
+}
+
+type lcpBuildCmd struct {
+	Description string `arg required name:"description" help:"Filename of the policy data description in YAML or JSON format" type:"path"`
+	Out         string `arg required name:"out" help:"Filename to write the LCP policy data file into" type:"path"`
+	Config      string `flag optional name:"config" help:"Filename of LCP config file in JSON format, to build the PO/PS policy enforcing the policy data file" type:"path"`
+	PolicyOut   string `flag optional name:"policy-out" help:"Filename to write binary PO/PS index LCP Policy into (requires --config)" type:"path"`
+}
+
+type lcpSignCmd struct {
+	File              string `arg required name:"file" help:"Filename of the LCP policy data file" type:"path"`
+	Key               string `arg required name:"key" help:"Filename of the private key (RSA, ECDSA or SM2) in PEM format" type:"path"`
+	Password          string `flag optional name:"password" help:"Password of the encrypted private key"`
+	List              uint   `flag optional name:"list" help:"Index of the policy list to sign"`
+	RevocationCounter uint16 `flag optional name:"revocation-counter" help:"Revocation counter of the signature"`
+	HashAlg           string `flag optional name:"hash" default:"SHA256" help:"Hash algorithm of RSA signatures: SHA1, SHA256, SHA384 or SHA512"`
+	Out               string `flag optional name:"out" help:"Filename to write the signed policy data file into, the input file is overwritten if not set" type:"path"`
+}
+
+type lcpVerifyCmd struct {
+	File   string `arg required name:"file" help:"Filename of the LCP policy data file" type:"path"`
+	Policy string `flag optional name:"policy" help:"Filename of binary PO/PS index LCP Policy to verify against" type:"path"`
+}
+
+type lcpHashCmd struct {
+	File    string `arg required name:"file" help:"Filename of the LCP policy data file" type:"path"`
+	HashAlg string `flag optional name:"hash" default:"SHA256" help:"Hash algorithm of the policy hash: SHA1, SHA256, SHA384 or SHA512"`
+}
+
+func (l *lcpBuildCmd) Run(ctx *context) error {
+	data, err := ioutil.ReadFile(l.Description)
+	if err != nil {
+		return fmt.Errorf("couldn't read description file: %v", err)
+	}
+	desc, err := txt.ParseLCPPolicyDataDescription(data)
+	if err != nil {
+		return fmt.Errorf("couldn't parse description file: %v", err)
+	}
+	pd, err := desc.Build(loadLCPSigningKey)
+	if err != nil {
+		return fmt.Errorf("couldn't build policy data file: %v", err)
+	}
+	if err = writeLCPPolicyData(pd, l.Out); err != nil {
+		return err
+	}
+
+	if len(l.Config) == 0 {
+		if len(l.PolicyOut) > 0 {
+			return fmt.Errorf("--policy-out requires --config")
+		}
+		return nil
+	}
+	lcp, err := loadConfig(l.Config)
+	if err != nil {
+		return fmt.Errorf("couldn't parse LCP config file: %v", err)
+	}
+	if err = txt.SetLCPPolicyData(lcp, pd); err != nil {
+		return fmt.Errorf("couldn't set policy hash: %v", err)
+	}
+	if len(l.PolicyOut) > 0 {
+		if err = writePSPolicy2file(lcp, l.PolicyOut); err != nil {
+			return fmt.Errorf("couldn't write PS Policy2 into file: %v", err)
+		}
+	}
+	fmt.Printf("Policy hash: %x\n", lcp.PolicyHash)
+	return nil
+}
+
+func (l *lcpSignCmd) Run(ctx *context) error {
+	pd, err := readLCPPolicyData(l.File)
+	if err != nil {
+		return err
+	}
+	if l.List >= uint(len(pd.PolicyLists)) {
+		return fmt.Errorf("there is no list %d, the file contains %d lists", l.List, len(pd.PolicyLists))
+	}
+	list := &pd.PolicyLists[l.List]
+	if !list.IsTPM20() {
+		return fmt.Errorf("only LCP_POLICY_LIST2 could be signed")
+	}
+	h, ok := txt.HashMapping[l.HashAlg]
+	if !ok {
+		return fmt.Errorf("unknown hash algorithm: %s", l.HashAlg)
+	}
+	privKey, err := loadLCPSigningKey(l.Key, l.Password)
+	if err != nil {
+		return fmt.Errorf("couldn't load private key: %v", err)
+	}
+	if err = list.TPM20PolicyList.Sign(privKey, l.RevocationCounter, h); err != nil {
+		return fmt.Errorf("couldn't sign list %d: %v", l.List, err)
+	}
+
+	out := l.Out
+	if len(out) == 0 {
+		out = l.File
+	}
+	return writeLCPPolicyData(pd, out)
+}
+
+func (l *lcpVerifyCmd) Run(ctx *context) error {
+	pd, err := readLCPPolicyData(l.File)
+	if err != nil {
+		return err
+	}
+	var pol *tools.LCPPolicy2
+	if len(l.Policy) > 0 {
+		data, err := ioutil.ReadFile(l.Policy)
+		if err != nil {
+			return fmt.Errorf("couldn't read policy file: %v", err)
+		}
+		if _, pol, err = tools.ParsePolicy(data); err != nil {
+			return fmt.Errorf("couldn't parse policy file: %v", err)
+		}
+		if pol == nil {
+			return fmt.Errorf("only LCP_POLICY2 is supported")
+		}
+	}
+	if err = txt.VerifyLCPPolicyData(pd, pol); err != nil {
+		return fmt.Errorf("verification failed: %v", err)
+	}
+	for idx := range pd.PolicyLists {
+		if sig := pd.PolicyLists[idx].TPM20PolicyList.Signature; sig != nil {
+			fmt.Printf("List %d: signature OK (revocation counter %d)\n", idx, sig.RevocationCounter)
+		} else {
+			fmt.Printf("List %d: not signed\n", idx)
+		}
+	}
+	if pol != nil {
+		fmt.Printf("Policy hash matches: %x\n", pol.PolicyHash)
+	}
+	return nil
+}
+
+func (l *lcpHashCmd) Run(ctx *context) error {
+	pd, err := readLCPPolicyData(l.File)
+	if err != nil {
+		return err
+	}
+	h, ok := txt.HashMapping[l.HashAlg]
+	if !ok {
+		return fmt.Errorf("unknown hash algorithm: %s", l.HashAlg)
+	}
+	policyHash, err := pd.PolicyHash(h)
+	if err != nil {
+		return fmt.Errorf("couldn't calculate policy hash: %v", err)
+	}
+	fmt.Printf("%x\n", policyHash)
+	return nil
+}
+
+func readLCPPolicyData(filename string) (*tools.LCPPolicyData, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read policy data file: %v", err)
+	}
+	pd, err := tools.ParsePolicyData(data)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse policy data file: %v", err)
+	}
+	return pd, nil
+}
+
+func writeLCPPolicyData(pd *tools.LCPPolicyData, filename string) error {
+	data, err := pd.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("couldn't marshal policy data file: %v", err)
+	}
+	if err = ioutil.WriteFile(filename, data, 0600); err != nil {
+		return fmt.Errorf("couldn't write policy data file: %v", err)
+	}
+	return nil
+}
+
+// loadLCPSigningKey loads a RSA/ECDSA private key (optionally encrypted by
+// cbnt-prov) or a SM2 private key in PKCS#8 PEM format.
+func loadLCPSigningKey(path, password string) (crypto.Signer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := cbnt.DecryptPrivKey(data, password)
+	if err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported key type: %T", key)
+		}
+		return signer, nil
+	}
+	var pwd []byte
+	if len(password) > 0 {
+		pwd = []byte(password)
+	}
+	sm2Key, sm2Err := x509.ReadPrivateKeyFromPem(data, pwd)
+	if sm2Err != nil {
+		return nil, fmt.Errorf("%v (as SM2 key: %v)", err, sm2Err)
+	}
+	return sm2Key, nil
+}
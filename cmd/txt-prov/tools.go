@@ -19,6 +19,19 @@ var (
 	tpm2LockedResult = "error code 0x22"
 )
 
+// openTPM opens the TPM selected by the "--tpm" option: the TPM of the
+// local machine if the option is empty, or the TPM 2.0 simulator if it is
+// "sim" or "sim:<statefile>".
+func openTPM(selector string) (*hwapi.TPM, error) {
+	if selector == "" {
+		return hwapi.NewTPM()
+	}
+	if selector != "sim" && !strings.HasPrefix(selector, "sim:") {
+		return nil, fmt.Errorf("unknown TPM '%s', expected 'sim' or 'sim:<statefile>'", selector)
+	}
+	return openTPMSimulator(strings.TrimPrefix(strings.TrimPrefix(selector, "sim"), ":"))
+}
+
 func readPassphraseHashTPM20() ([]byte, error) {
 	fmt.Printf("Now, please type in the password (mandatory): ")
 	password, err := terminal.ReadPassword(0)
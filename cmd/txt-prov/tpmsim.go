@@ -0,0 +1,22 @@
+//go:build tpmsim
+// +build tpmsim
+
+package main
+
+import (
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmsim"
+	"github.com/9elements/go-linux-lowlevel-hw/pkg/hwapi"
+)
+
+// openTPMSimulator opens the TPM 2.0 simulator with the state persisted
+// in the stateFile (or a throwaway state if it is empty).
+func openTPMSimulator(stateFile string) (*hwapi.TPM, error) {
+	sim, err := tpmsim.Open(stateFile)
+	if err != nil {
+		return nil, err
+	}
+	return &hwapi.TPM{
+		Version: hwapi.TPMVersion20,
+		RWC:     sim,
+	}, nil
+}
@@ -0,0 +1,16 @@
+//go:build !tpmsim
+// +build !tpmsim
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/9elements/go-linux-lowlevel-hw/pkg/hwapi"
+)
+
+// openTPMSimulator always fails: txt-prov is built without the TPM 2.0
+// simulator, which requires cgo and OpenSSL (build with "-tags tpmsim").
+func openTPMSimulator(stateFile string) (*hwapi.TPM, error) {
+	return nil, fmt.Errorf("txt-prov is built without the TPM simulator, rebuild it with '-tags tpmsim'")
+}
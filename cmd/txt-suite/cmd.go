@@ -2,13 +2,14 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
-	"sort"
+	"strings"
+	"time"
 
+	hwInternal "github.com/9elements/converged-security-suite/v2/pkg/hwapi"
 	"github.com/9elements/converged-security-suite/v2/pkg/test"
+	"github.com/9elements/converged-security-suite/v2/pkg/test/report"
 	"github.com/9elements/converged-security-suite/v2/pkg/tools"
 	"github.com/google/go-tpm/tpm2"
 
@@ -33,10 +34,26 @@ type versionCmd struct {
 }
 
 type execTestsCmd struct {
-	Set         string `required default:"all" help:"Select subset of tests. Options: all, uefi, txtready, tboot, cbnt, legacy"`
-	Interactive bool   `optional short:"i" help:"Interactive mode. Errors will stop the testing."`
-	Config      string `optional short:"c" help:"Path/Filename to config file."`
-	Log         string `optional help:"Give a path/filename for test result output inJSON format. e.g.: /path/to/filename.json"`
+	Set         string   `required default:"all" help:"Select subset of tests. Options: all, uefi, txtready, tboot, cbnt, legacy"`
+	Tests       []string `optional help:"Run only the tests with the given names or tags (e.g. cpu, tpm, fit, memory, acpi, cbnt, required), see the list command"`
+	Workers     int      `optional default:"1" help:"Number of tests run concurrently, 0 runs one test per CPU at a time"`
+	Interactive bool     `optional short:"i" help:"Interactive mode. Errors will stop the testing."`
+	Config      string   `optional short:"c" help:"Path/Filename to config file."`
+	Log         string   `optional help:"Give a path/filename for the test report. e.g.: /path/to/filename.json" default:"test_log.json"`
+	Format      string   `optional help:"Format of the test report. Options: json, junit, sarif" default:"json"`
+	Firmware    string   `optional help:"Path/Filename of a full SPI flash image to be used by CBnT tests instead of the memory mapped BIOS region (required to check the ME region)."`
+	Snapshot    string   `optional help:"Path/Filename of a snapshot created by the capture command. The tests are run against the snapshot instead of the local hardware."`
+	CPUDatabase string   `optional name:"cpu-db" help:"Path/Filename of a CPU database in YAML or JSON format. Its entries take precedence over the embedded database."`
+}
+
+type captureCmd struct {
+	Set         string   `required default:"all" help:"Select subset of tests. Options: all, uefi, txtready, tboot, cbnt, legacy"`
+	Tests       []string `optional help:"Run only the tests with the given names or tags (e.g. cpu, tpm, fit, memory, acpi, cbnt, required), see the list command"`
+	Workers     int      `optional default:"1" help:"Number of tests run concurrently, 0 runs one test per CPU at a time"`
+	Config      string   `optional short:"c" help:"Path/Filename to config file."`
+	Firmware    string   `optional help:"Path/Filename of a full SPI flash image to be used by CBnT tests instead of the memory mapped BIOS region (required to check the ME region)."`
+	CPUDatabase string   `optional name:"cpu-db" help:"Path/Filename of a CPU database in YAML or JSON format. Its entries take precedence over the embedded database."`
+	Output      string   `arg required name:"snapshot" help:"Path/Filename to store the snapshot at. e.g.: /path/to/snapshot.json.gz" type:"path"`
 }
 
 var cli struct {
@@ -45,19 +62,100 @@ var cli struct {
 	TpmDev string `short:"t" help:"Select TPM-Path. e.g.:--tpmdev=/dev/tpmX, with X as number of the TPM module"`
 
 	ExecTests execTestsCmd `cmd help:"Executes tests given be TestNo or TestSet"`
+	Capture   captureCmd   `cmd help:"Runs tests given by TestSet and stores everything read from the hardware into a snapshot"`
 	List      listCmd      `cmd help:"Lists all tests"`
 	Markdown  markdownCmd  `cmd help:"Output test implementation state as Markdown"`
 	Version   versionCmd   `cmd help:"Prints the version of the program"`
 }
 
 func (e *execTestsCmd) Run(ctx *context) error {
-	ret := false
+	config, err := getConfig(e.Config)
+	if err != nil {
+		return err
+	}
+	config.FirmwareImage = e.Firmware
+	config.CPUDatabase = e.CPUDatabase
+
+	hwAPI := hwapi.GetAPI()
+	if e.Snapshot != "" {
+		snapshot, err := hwInternal.LoadSnapshot(e.Snapshot)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Using snapshot of %s captured at %v\n", snapshot.Hostname, snapshot.Time)
+		hwAPI = hwInternal.NewReplayer(snapshot)
+	}
+
+	writer, err := report.GetWriter(e.Format)
+	if err != nil {
+		return err
+	}
+
+	if e.Set == "all" || e.Set == "txtready" {
+		fmt.Println("For more information about the documents and chapters, run: txt-suite -m")
+	}
+	testGroup, tests, err := getTestSet(e.Set)
+	if err != nil {
+		return err
+	}
+	r, err := run(testGroup, tests, config, runOptions{
+		interactive: e.Interactive,
+		workers:     e.Workers,
+		selectors:   e.Tests,
+	}, hwAPI)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(e.Log)
+	if err != nil {
+		return fmt.Errorf("unable to create test report: %w", err)
+	}
+	defer f.Close()
+	if err := writer.Write(f, r); err != nil {
+		return fmt.Errorf("unable to write test report: %w", err)
+	}
+
+	if code := r.ExitCode(); code != report.ExitCodePass {
+		return exitCodeError{code: code}
+	}
+	return nil
+}
+
+func (c *captureCmd) Run(ctx *context) error {
+	config, err := getConfig(c.Config)
+	if err != nil {
+		return err
+	}
+	config.FirmwareImage = c.Firmware
+	config.CPUDatabase = c.CPUDatabase
+
+	testGroup, tests, err := getTestSet(c.Set)
+	if err != nil {
+		return err
+	}
+	recorder := hwInternal.NewRecorder(hwapi.GetAPI())
+	_, err = run(testGroup, tests, config, runOptions{
+		workers:   c.Workers,
+		selectors: c.Tests,
+	}, recorder)
+	if err != nil {
+		return err
+	}
+
+	if err := recorder.Snapshot().Save(c.Output); err != nil {
+		return fmt.Errorf("unable to store snapshot: %w", err)
+	}
+	fmt.Printf("Snapshot stored at %s\n", c.Output)
+	return nil
+}
+
+func getConfig(path string) (tools.Configuration, error) {
 	var config tools.Configuration
-	if e.Config != "" {
-		var err error
-		configuration, err := tools.ParseConfig(e.Config)
+	if path != "" {
+		configuration, err := tools.ParseConfig(path)
 		if err != nil {
-			os.Exit(1)
+			return config, err
 		}
 		config = *configuration
 	} else {
@@ -66,35 +164,31 @@ func (e *execTestsCmd) Run(ctx *context) error {
 		config.TPM = hwapi.TPMVersion20
 		config.TXTMode = tools.AutoPromotion
 	}
+	return config, nil
+}
 
-	switch e.Set {
+func getTestSet(set string) (string, []*test.Test, error) {
+	switch set {
 	case "all":
-		fmt.Println("For more information about the documents and chapters, run: txt-suite -m")
-		ret = run("All", getTests(), config, e.Interactive)
+		return "All", getTestsAll(), nil
 	case "uefi":
-		ret = run("UEFI", test.TestsUEFI, config, e.Interactive)
+		return "UEFI", test.TestsUEFI, nil
 	case "txtready":
-		fmt.Println("For more information about the documents and chapters, run: txt-suite -m")
-		ret = run("TXT Ready", test.TestsTXTReady, config, e.Interactive)
+		return "TXT Ready", test.TestsTXTReady, nil
 	case "tboot":
-		ret = run("Tboot", test.TestsTBoot, config, e.Interactive)
+		return "Tboot", test.TestsTBoot, nil
 	case "cbnt":
-		return fmt.Errorf("CBnT support not implemented yet")
+		return "CBnT", test.TestsCBnT, nil
 	case "legacy":
-		ret = run("Legacy TXT", test.TestsLegacy, config, e.Interactive)
-	default:
-		return fmt.Errorf("no valid test set given")
-	}
-	if !ret {
-		return fmt.Errorf("tests ran with errors")
+		return "Legacy TXT", test.TestsLegacy, nil
 	}
-	return nil
+	return "", nil, fmt.Errorf("no valid test set given")
 }
 
 func (l *listCmd) Run(ctx *context) error {
 	tests := getTests()
 	for i := range tests {
-		fmt.Printf("Test No: %v, %v\n", i, tests[i].Name)
+		fmt.Printf("Test No: %v, %v, Tags: %s\n", i, tests[i].Name, strings.Join(tests[i].Tags(), ","))
 	}
 	return nil
 }
@@ -127,31 +221,86 @@ func (v *versionCmd) Run(ctx *context) error {
 	return nil
 }
 
+// getTests returns all the tests of the suite, their index is the test ID
 func getTests() []*test.Test {
-	var tests []*test.Test
-	for i := range test.TestsCPU {
-		tests = append(tests, test.TestsCPU[i])
+	return collectTests(true)
+}
+
+// getTestIDs returns the IDs of the tests, see getTests
+func getTestIDs() map[*test.Test]int {
+	ids := map[*test.Test]int{}
+	for id, t := range getTests() {
+		ids[t] = id
 	}
-	for i := range test.TestsTPM {
-		tests = append(tests, test.TestsTPM[i])
+	return ids
+}
+
+// getTestsAll returns the tests of the "all" set. The CBnT specific tests
+// are not part of it, because they are required and would fail on every
+// non-CBnT platform; they are run by the "cbnt" set.
+func getTestsAll() []*test.Test {
+	return collectTests(false)
+}
+
+// collectTests returns the tests of the groups in the order of the test IDs:
+// the tests of the groups followed by test.TestsAppended.
+func collectTests(withCBnT bool) []*test.Test {
+	groups := [][]*test.Test{
+		test.TestsCPU[:],
+		test.TestsTPM[:],
+		test.TestsFIT[:],
+		test.TestsMemory[:],
+		test.TestsACPI[:],
+	}
+	if withCBnT {
+		groups = append(groups, test.TestsCBnTSpecific[:])
 	}
-	for i := range test.TestsFIT {
-		tests = append(tests, test.TestsFIT[i])
+
+	appended := map[*test.Test]bool{}
+	for _, t := range test.TestsAppended {
+		appended[t] = true
 	}
-	for i := range test.TestsMemory {
-		tests = append(tests, test.TestsMemory[i])
+	selected := map[*test.Test]bool{}
+	var tests []*test.Test
+	for _, group := range groups {
+		for _, t := range group {
+			selected[t] = true
+			if !appended[t] {
+				tests = append(tests, t)
+			}
+		}
 	}
-	for i := range test.TestsACPI {
-		tests = append(tests, test.TestsACPI[i])
+	for _, t := range test.TestsAppended {
+		if selected[t] {
+			tests = append(tests, t)
+		}
 	}
 	return tests
 }
 
-func run(testGroup string, tests []*test.Test, config tools.Configuration, interactive bool) bool {
-	var result = false
+// runOptions contains the options of a test run
+type runOptions struct {
+	interactive bool
+	workers     int
+	selectors   []string
+}
+
+func run(testGroup string, tests []*test.Test, config tools.Configuration, opts runOptions, hwAPI hwapi.LowLevelHardwareInterfaces) (*report.Report, error) {
 	f := bufio.NewWriter(os.Stdout)
 
-	hwAPI := hwapi.GetAPI()
+	if len(opts.selectors) > 0 {
+		var err error
+		tests, err = test.SelectTests(tests, opts.selectors...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	scheduler, err := test.NewScheduler(tests)
+	if err != nil {
+		return nil, err
+	}
+	scheduler.Workers = opts.workers
+	scheduler.StopOnFailure = opts.interactive
 
 	fmt.Printf("\n%s tests\n", a.Bold(a.Gray(20-1, testGroup).BgGray(4-1)))
 	var i int
@@ -159,57 +308,36 @@ func run(testGroup string, tests []*test.Test, config tools.Configuration, inter
 		fmt.Print("_")
 	}
 	fmt.Println()
-	for idx := range tests {
-		if len(testnos) > 0 {
-			// SearchInt returns an index where to "insert" idx
-			i := sort.SearchInts(testnos, idx)
-			if i >= len(testnos) {
-				continue
-			}
-			// still here? i must be within testnos.
-			if testnos[i] != idx {
-				continue
-			}
-		}
-
-		if !tests[idx].Run(hwAPI, &config) && tests[idx].Required && interactive {
-			result = true
-			break
-		}
 
-	}
+	started := time.Now()
+	results := scheduler.Run(hwAPI, &config)
 
-	if !interactive {
-		var t []temptest
-		for index := range tests {
-			if tests[index].Status != test.NotImplemented {
-				ttemp := temptest{index, tests[index].Name, tests[index].Result.String(), tests[index].ErrorText, tests[index].Status.String()}
-				t = append(t, ttemp)
-			}
-		}
-		data, _ := json.MarshalIndent(t, "", "")
-		ioutil.WriteFile(logfile, data, 0664)
-	}
+	testIDs := getTestIDs()
+	r := report.New(testGroup, results, testIDs)
+	r.Tool = programName
+	r.Version = gittag
+	r.Started = started
+	r.Duration = time.Since(started)
 
-	for index := range tests {
-		if tests[index].Status == test.NotImplemented {
+	for _, result := range results.All() {
+		if result.Test.Status == test.NotImplemented {
 			continue
 		}
-		if tests[index].Result == test.ResultNotRun {
+		if result.Result == test.ResultNotRun {
 			continue
 		}
-		fmt.Printf("%02d - ", index)
-		fmt.Printf("%-40s: ", a.Bold(tests[index].Name))
+		fmt.Printf("%02d - ", testIDs[result.Test])
+		fmt.Printf("%-40s: ", a.Bold(result.Test.Name))
 		f.Flush()
 
-		if tests[index].Result == test.ResultPass {
-			fmt.Printf("%-20s", a.Bold(a.Green(tests[index].Result)))
+		if result.Result == test.ResultPass {
+			fmt.Printf("%-20s", a.Bold(a.Green(result.Result)))
 		} else {
-			fmt.Printf("%-20s", a.Bold(a.Red(tests[index].Result)))
+			fmt.Printf("%-20s", a.Bold(a.Red(result.Result)))
 		}
-		if tests[index].ErrorText != "" {
-			fmt.Printf(" (%s)", tests[index].ErrorText)
-		} else if len(tests[index].ErrorText) == 0 && tests[index].Result == test.ResultFail {
+		if result.ErrorText != "" {
+			fmt.Printf(" (%s)", result.ErrorText)
+		} else if result.Result == test.ResultFail {
 			fmt.Print(" (No error text given)")
 		}
 		fmt.Printf("\n")
@@ -217,5 +345,5 @@ func run(testGroup string, tests []*test.Test, config tools.Configuration, inter
 		f.Flush()
 	}
 
-	return result
+	return r, nil
 }
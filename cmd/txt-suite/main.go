@@ -1,7 +1,12 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"os"
+
 	"github.com/9elements/converged-security-suite/v2/pkg/log"
+	"github.com/9elements/converged-security-suite/v2/pkg/test/report"
 	"github.com/alecthomas/kong"
 	"github.com/linuxboot/fiano/pkg/intel/metadata/manifest"
 	fianoLog "github.com/linuxboot/fiano/pkg/log"
@@ -10,22 +15,30 @@ import (
 const (
 	programName = "txt-suite"
 	programDesc = "Intel TXT Test Suite"
+
+	// exitCodeUsage is returned if the tests could not be run at all
+	exitCodeUsage = 3
 )
 
 var (
-	testnos   []int
 	testerg   bool
-	logfile   = "test_log.json"
 	gitcommit string
 	gittag    string
 )
 
-type temptest struct {
-	Testnumber int
-	Testname   string
-	Result     string
-	Error      string
-	Status     string
+// exitCodeError makes txt-suite exit with the given code
+type exitCodeError struct {
+	code int
+}
+
+func (e exitCodeError) Error() string {
+	switch e.code {
+	case report.ExitCodeFail:
+		return "required tests failed"
+	case report.ExitCodeInternalError:
+		return "required tests could not be completed due to internal errors"
+	}
+	return fmt.Sprintf("exit code %d", e.code)
 }
 
 func main() {
@@ -40,5 +53,13 @@ func main() {
 	manifest.StrictOrderCheck = cli.ManifestStrictOrderCheck
 	fianoLog.DefaultLogger = log.DummyLogger{}
 	err := ctx.Run(&context{})
-	ctx.FatalIfErrorf(err)
+	var exitErr exitCodeError
+	if errors.As(err, &exitErr) {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", programName, err)
+		os.Exit(exitErr.code)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: error: %v\n", programName, err)
+		os.Exit(exitCodeUsage)
+	}
 }
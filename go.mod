@@ -12,8 +12,9 @@ require (
 	github.com/edsrzf/mmap-go v1.0.0
 	github.com/fearful-symmetry/gomsr v0.0.1
 	github.com/golang-collections/go-datastructures v0.0.0-20150211160725-59788d5eb259
-	github.com/google/go-attestation v0.4.0
+	github.com/google/go-attestation v0.4.0 // indirect
 	github.com/google/go-tpm v0.3.3-0.20210120190357-1ff48daca32f
+	github.com/google/go-tpm-tools v0.3.1
 	github.com/google/uuid v1.3.0
 	github.com/klauspost/cpuid/v2 v2.0.9
 	github.com/kr/pretty v0.2.1 // indirect
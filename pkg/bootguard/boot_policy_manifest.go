@@ -0,0 +1,330 @@
+package bootguard
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	pkgbytes "github.com/linuxboot/fiano/pkg/bytes"
+	"github.com/linuxboot/fiano/pkg/intel/metadata/manifest"
+)
+
+// BPMH is the header of a Boot Guard 1.0 Boot Policy Manifest.
+type BPMH struct {
+	StructInfo
+	HdrStructVersion uint8
+	PMBPMVersion     uint8
+	BPMSVN           uint8
+	ACMSVNAuth       uint8
+	Reserved0        uint8
+	NEMDataStack     uint16
+}
+
+// bpmhSize is the size of BPMH in the binary form.
+const bpmhSize = structInfoSize + 7
+
+// IBBSegment is a range of the firmware image included into the IBB.
+type IBBSegment struct {
+	Reserved uint16
+	Flags    uint16
+	Base     uint32
+	Size     uint32
+}
+
+// ibbElementHeader is the fixed-size part of IBBElement between StructInfo
+// and PostIBBHash.
+type ibbElementHeader struct {
+	Reserved0 [3]byte
+	Flags     uint32
+	IBBMCHBAR uint64
+	VTdBAR    uint64
+	PMRLBase  uint32
+	PMRLLimit uint32
+	Reserved1 [16]byte
+}
+
+// IBBElement ("__IBBS__") describes the Initial Boot Block: which parts of
+// the firmware are measured/verified by the ACM and their digest.
+type IBBElement struct {
+	StructInfo
+	Reserved0   [3]byte
+	Flags       uint32
+	IBBMCHBAR   uint64
+	VTdBAR      uint64
+	PMRLBase    uint32
+	PMRLLimit   uint32
+	Reserved1   [16]byte
+	PostIBBHash manifest.HashStructure
+	EntryPoint  uint32
+	Digest      manifest.HashStructure
+	IBBSegments []IBBSegment
+}
+
+// DigestOffset returns the offset of field Digest from the beginning
+// of the element.
+func (se *IBBElement) DigestOffset() uint64 {
+	return structInfoSize + uint64(binary.Size(ibbElementHeader{})) + se.PostIBBHash.TotalSize() + 4
+}
+
+// TotalSize returns the size of the element in the binary form.
+func (se *IBBElement) TotalSize() uint64 {
+	return se.DigestOffset() + se.Digest.TotalSize() + 1 + uint64(len(se.IBBSegments)*binary.Size(IBBSegment{}))
+}
+
+// PlatformManufacturerElement ("__PMDA__") contains an OEM-specific data.
+type PlatformManufacturerElement struct {
+	StructInfo
+	Data []byte
+}
+
+// TotalSize returns the size of the element in the binary form.
+func (pm *PlatformManufacturerElement) TotalSize() uint64 {
+	return structInfoSize + 2 + uint64(len(pm.Data))
+}
+
+// SignatureElement ("__PMSG__") contains the signature of the BPM.
+type SignatureElement struct {
+	StructInfo
+	KeySignature manifest.KeySignature
+}
+
+// BootPolicyManifest is a Boot Guard 1.0 Boot Policy Manifest (BPM).
+//
+// The elements are expected in the order they are defined here (which
+// is the order used by Intel tooling).
+type BootPolicyManifest struct {
+	BPMH BPMH
+	SE   IBBElement
+	PM   *PlatformManufacturerElement
+	PMSE SignatureElement
+}
+
+// ParseBootPolicyManifest parses a Boot Guard 1.0 Boot Policy Manifest.
+func ParseBootPolicyManifest(b []byte) (*BootPolicyManifest, error) {
+	r := bytes.NewReader(b)
+	bpm := &BootPolicyManifest{}
+
+	if err := binary.Read(r, binary.LittleEndian, &bpm.BPMH); err != nil {
+		return nil, fmt.Errorf("unable to read BPMH: %w", err)
+	}
+	if err := bpm.BPMH.StructInfo.validate(StructureIDBootPolicyManifestHeader); err != nil {
+		return nil, err
+	}
+
+	if err := bpm.SE.readFrom(r); err != nil {
+		return nil, fmt.Errorf("unable to read the IBB element: %w", err)
+	}
+
+	if id, _ := peekStructureID(b[len(b)-r.Len():]); id == StructureIDPlatformManufacturerElement {
+		bpm.PM = &PlatformManufacturerElement{}
+		if err := bpm.PM.readFrom(r); err != nil {
+			return nil, fmt.Errorf("unable to read the platform manufacturer element: %w", err)
+		}
+	}
+
+	if err := bpm.PMSE.StructInfo.readFrom(r, StructureIDSignatureElement); err != nil {
+		return nil, fmt.Errorf("unable to read the signature element: %w", err)
+	}
+	if _, err := bpm.PMSE.KeySignature.ReadFrom(r); err != nil {
+		return nil, fmt.Errorf("unable to read the BPM signature: %w", err)
+	}
+
+	return bpm, nil
+}
+
+func (se *IBBElement) readFrom(r io.Reader) error {
+	if err := se.StructInfo.readFrom(r, StructureIDIBBElement); err != nil {
+		return err
+	}
+	var hdr ibbElementHeader
+	if err := binary.Read(r, binary.LittleEndian, &hdr); err != nil {
+		return err
+	}
+	se.Reserved0 = hdr.Reserved0
+	se.Flags = hdr.Flags
+	se.IBBMCHBAR = hdr.IBBMCHBAR
+	se.VTdBAR = hdr.VTdBAR
+	se.PMRLBase = hdr.PMRLBase
+	se.PMRLLimit = hdr.PMRLLimit
+	se.Reserved1 = hdr.Reserved1
+	if _, err := se.PostIBBHash.ReadFrom(r); err != nil {
+		return fmt.Errorf("unable to read PostIBBHash: %w", err)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &se.EntryPoint); err != nil {
+		return fmt.Errorf("unable to read EntryPoint: %w", err)
+	}
+	if _, err := se.Digest.ReadFrom(r); err != nil {
+		return fmt.Errorf("unable to read the IBB digest: %w", err)
+	}
+	var segmentCount uint8
+	if err := binary.Read(r, binary.LittleEndian, &segmentCount); err != nil {
+		return fmt.Errorf("unable to read the amount of IBB segments: %w", err)
+	}
+	se.IBBSegments = make([]IBBSegment, segmentCount)
+	if err := binary.Read(r, binary.LittleEndian, se.IBBSegments); err != nil {
+		return fmt.Errorf("unable to read IBB segments: %w", err)
+	}
+	return nil
+}
+
+func (pm *PlatformManufacturerElement) readFrom(r io.Reader) error {
+	if err := pm.StructInfo.readFrom(r, StructureIDPlatformManufacturerElement); err != nil {
+		return err
+	}
+	var size uint16
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return err
+	}
+	pm.Data = make([]byte, size)
+	if _, err := io.ReadFull(r, pm.Data); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SEOffset returns the offset of the IBB element from the beginning
+// of the manifest.
+func (bpm *BootPolicyManifest) SEOffset() uint64 {
+	return bpmhSize
+}
+
+// PMSEOffset returns the offset of the signature element from the beginning
+// of the manifest.
+func (bpm *BootPolicyManifest) PMSEOffset() uint64 {
+	offset := bpm.SEOffset() + bpm.SE.TotalSize()
+	if bpm.PM != nil {
+		offset += bpm.PM.TotalSize()
+	}
+	return offset
+}
+
+// KeySignatureOffset returns the offset of the KeySignature of the signature
+// element from the beginning of the manifest. Everything before this offset
+// is the signed data.
+func (bpm *BootPolicyManifest) KeySignatureOffset() uint64 {
+	return bpm.PMSEOffset() + structInfoSize
+}
+
+// SignatureDataOffset returns the offset of the signature itself (without
+// headers) from the beginning of the manifest.
+func (bpm *BootPolicyManifest) SignatureDataOffset() uint64 {
+	return bpm.KeySignatureOffset() + bpm.PMSE.KeySignature.SignatureOffset() + bpm.PMSE.KeySignature.Signature.DataOffset()
+}
+
+// IBBDigestOffset returns the offset of the IBB digest value (without
+// headers) from the beginning of the manifest.
+func (bpm *BootPolicyManifest) IBBDigestOffset() uint64 {
+	// Note: +2 - skip the size field of the hash buffer.
+	return bpm.SEOffset() + bpm.SE.DigestOffset() + bpm.SE.Digest.HashBufferOffset() + 2
+}
+
+// Verify verifies the signature of the manifest, `manifestBytes` is the
+// binary form of the manifest as it is stored in the firmware.
+func (bpm *BootPolicyManifest) Verify(manifestBytes []byte) error {
+	signedDataSize := bpm.KeySignatureOffset()
+	if uint64(len(manifestBytes)) < signedDataSize {
+		return fmt.Errorf("the manifest is too short: %d < %d", len(manifestBytes), signedDataSize)
+	}
+	return bpm.PMSE.KeySignature.Verify(manifestBytes[:signedDataSize])
+}
+
+// SetSignature signs the manifest with `privKey` and sets the signature
+// element accordingly. Boot Guard 1.0 supports only
+// RSASSA-PKCS1-v1_5 with SHA256.
+func (bpm *BootPolicyManifest) SetSignature(privKey crypto.Signer) error {
+	bpm.PMSE.StructInfo = StructInfo{ID: StructureIDSignatureElement, Version: StructureVersion}
+	b, err := bpm.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("unable to marshal the manifest: %w", err)
+	}
+	return bpm.PMSE.KeySignature.SetSignature(manifest.AlgRSASSA, manifest.AlgSHA256, privKey, b[:bpm.KeySignatureOffset()])
+}
+
+// IBBDataRanges returns data ranges of IBB.
+func (bpm *BootPolicyManifest) IBBDataRanges(firmwareSize uint64) pkgbytes.Ranges {
+	var result pkgbytes.Ranges
+	for _, seg := range bpm.SE.IBBSegments {
+		if seg.Flags&1 == 1 {
+			// the segment is not hashed
+			continue
+		}
+		startIdx := uint64(seg.Base) - (1<<32 - firmwareSize)
+		result = append(result, pkgbytes.Range{Offset: startIdx, Length: uint64(seg.Size)})
+	}
+	return result
+}
+
+// ValidateIBB checks if the IBB digest in the manifest matches the
+// firmware image.
+func (bpm *BootPolicyManifest) ValidateIBB(firmwareImage []byte) error {
+	h, err := bpm.SE.Digest.HashAlg.Hash()
+	if err != nil {
+		return fmt.Errorf("invalid hash function: %v", bpm.SE.Digest.HashAlg)
+	}
+
+	for _, _range := range bpm.IBBDataRanges(uint64(len(firmwareImage))) {
+		if _range.End() > uint64(len(firmwareImage)) {
+			return fmt.Errorf("IBB segment %s is out of the image (size: %d)", _range, len(firmwareImage))
+		}
+		h.Write(firmwareImage[_range.Offset:_range.End()])
+	}
+	hashValue := h.Sum(nil)
+
+	if !bytes.Equal(hashValue, bpm.SE.Digest.HashBuffer) {
+		return fmt.Errorf("IBB %s hash mismatch: %X != %X", bpm.SE.Digest.HashAlg, hashValue, bpm.SE.Digest.HashBuffer)
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (bpm *BootPolicyManifest) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	write := func(value interface{}) {
+		// bytes.Buffer never returns an error on write.
+		_ = binary.Write(&buf, binary.LittleEndian, value)
+	}
+
+	write(bpm.BPMH)
+
+	se := &bpm.SE
+	if len(se.IBBSegments) > 0xff {
+		return nil, fmt.Errorf("too many IBB segments: %d", len(se.IBBSegments))
+	}
+	write(se.StructInfo)
+	write(ibbElementHeader{
+		Reserved0: se.Reserved0,
+		Flags:     se.Flags,
+		IBBMCHBAR: se.IBBMCHBAR,
+		VTdBAR:    se.VTdBAR,
+		PMRLBase:  se.PMRLBase,
+		PMRLLimit: se.PMRLLimit,
+		Reserved1: se.Reserved1,
+	})
+	if _, err := se.PostIBBHash.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("unable to write PostIBBHash: %w", err)
+	}
+	write(se.EntryPoint)
+	if _, err := se.Digest.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("unable to write the IBB digest: %w", err)
+	}
+	write(uint8(len(se.IBBSegments)))
+	write(se.IBBSegments)
+
+	if bpm.PM != nil {
+		if len(bpm.PM.Data) > 0xffff {
+			return nil, fmt.Errorf("platform manufacturer data is too large: %d", len(bpm.PM.Data))
+		}
+		write(bpm.PM.StructInfo)
+		write(uint16(len(bpm.PM.Data)))
+		buf.Write(bpm.PM.Data)
+	}
+
+	write(bpm.PMSE.StructInfo)
+	if _, err := bpm.PMSE.KeySignature.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("unable to write the BPM signature: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,128 @@
+package bootguard
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/linuxboot/fiano/pkg/intel/metadata/manifest"
+)
+
+// KeyManifest is a Boot Guard 1.0 Key Manifest (KM). It is signed by
+// the OEM key (whose hash is fused into the PCH) and contains the hash of
+// the key used to sign the Boot Policy Manifest.
+type KeyManifest struct {
+	StructInfo
+	KMVersion       uint8
+	KMSVN           uint8
+	KMID            uint8
+	BPKey           manifest.HashStructure
+	KeyAndSignature manifest.KeySignature
+}
+
+// IsKeyManifest returns true if `b` looks like a Boot Guard 1.0 Key Manifest
+// (contrary to a CBnT one, which has a different structure version).
+func IsKeyManifest(b []byte) bool {
+	return isStructure(b, StructureIDKeyManifest)
+}
+
+// ParseKeyManifest parses a Boot Guard 1.0 Key Manifest.
+func ParseKeyManifest(b []byte) (*KeyManifest, error) {
+	r := bytes.NewReader(b)
+	km := &KeyManifest{}
+	if err := km.StructInfo.readFrom(r, StructureIDKeyManifest); err != nil {
+		return nil, err
+	}
+	var hdr [3]uint8
+	if err := binary.Read(r, binary.LittleEndian, &hdr); err != nil {
+		return nil, fmt.Errorf("unable to read the KM header: %w", err)
+	}
+	km.KMVersion, km.KMSVN, km.KMID = hdr[0], hdr[1], hdr[2]
+	if _, err := km.BPKey.ReadFrom(r); err != nil {
+		return nil, fmt.Errorf("unable to read the BPM key hash: %w", err)
+	}
+	if _, err := km.KeyAndSignature.ReadFrom(r); err != nil {
+		return nil, fmt.Errorf("unable to read the KM signature: %w", err)
+	}
+	return km, nil
+}
+
+// KeyAndSignatureOffset returns the offset of field KeyAndSignature
+// from the beginning of the manifest. Everything before this offset
+// is the signed data.
+func (km *KeyManifest) KeyAndSignatureOffset() uint64 {
+	return structInfoSize + 3 + km.BPKey.TotalSize()
+}
+
+// SignatureDataOffset returns the offset of the signature itself (without
+// headers) from the beginning of the manifest.
+func (km *KeyManifest) SignatureDataOffset() uint64 {
+	return km.KeyAndSignatureOffset() + km.KeyAndSignature.SignatureOffset() + km.KeyAndSignature.Signature.DataOffset()
+}
+
+// Verify verifies the signature of the manifest, `manifestBytes` is the
+// binary form of the manifest as it is stored in the firmware.
+func (km *KeyManifest) Verify(manifestBytes []byte) error {
+	signedDataSize := km.KeyAndSignatureOffset()
+	if uint64(len(manifestBytes)) < signedDataSize {
+		return fmt.Errorf("the manifest is too short: %d < %d", len(manifestBytes), signedDataSize)
+	}
+	return km.KeyAndSignature.Verify(manifestBytes[:signedDataSize])
+}
+
+// SetSignature signs the manifest with `privKey` and sets field
+// KeyAndSignature accordingly. Boot Guard 1.0 supports only
+// RSASSA-PKCS1-v1_5 with SHA256.
+func (km *KeyManifest) SetSignature(privKey crypto.Signer) error {
+	b, err := km.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("unable to marshal the manifest: %w", err)
+	}
+	return km.KeyAndSignature.SetSignature(manifest.AlgRSASSA, manifest.AlgSHA256, privKey, b[:km.KeyAndSignatureOffset()])
+}
+
+// ValidateBPMKey checks if the key used to sign the BPM is the one
+// authorized by this KM.
+func (km *KeyManifest) ValidateBPMKey(bpmKS manifest.KeySignature) error {
+	h, err := km.BPKey.HashAlg.Hash()
+	if err != nil {
+		return fmt.Errorf("invalid hash algo %v: %w", km.BPKey.HashAlg, err)
+	}
+	if len(km.BPKey.HashBuffer) != h.Size() {
+		return fmt.Errorf("invalid hash length: actual:%d expected:%d", len(km.BPKey.HashBuffer), h.Size())
+	}
+
+	switch bpmKS.Key.KeyAlg {
+	case manifest.AlgRSA:
+		if len(bpmKS.Key.Data) < 4 {
+			return fmt.Errorf("invalid RSA key data length: %d", len(bpmKS.Key.Data))
+		}
+		// The exponent is not hashed, only the modulus.
+		h.Write(bpmKS.Key.Data[4:])
+	default:
+		return fmt.Errorf("unsupported key algorithm: %v", bpmKS.Key.KeyAlg)
+	}
+	digest := h.Sum(nil)
+
+	if !bytes.Equal(km.BPKey.HashBuffer, digest) {
+		return fmt.Errorf("BPM key hash does not match the one in KM: actual:%X != in-KM:%X (hash algo: %v)", digest, km.BPKey.HashBuffer, km.BPKey.HashAlg)
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (km *KeyManifest) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, km.StructInfo); err != nil {
+		return nil, err
+	}
+	buf.Write([]byte{km.KMVersion, km.KMSVN, km.KMID})
+	if _, err := km.BPKey.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("unable to write the BPM key hash: %w", err)
+	}
+	if _, err := km.KeyAndSignature.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("unable to write the KM signature: %w", err)
+	}
+	return buf.Bytes(), nil
+}
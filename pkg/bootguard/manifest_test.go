@@ -0,0 +1,135 @@
+package bootguard
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/linuxboot/fiano/pkg/intel/metadata/manifest"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManifests(t *testing.T, image []byte) (*KeyManifest, *BootPolicyManifest) {
+	kmKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	bpmKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	imageSize := uint64(len(image))
+	ibbSegments := []IBBSegment{
+		{Base: uint32(1<<32 - imageSize + 0x100), Size: 0x200},
+		{Base: uint32(1<<32 - imageSize + 0x400), Size: 0x100, Flags: 1},
+		{Base: uint32(1<<32 - imageSize + 0x800), Size: 0x80},
+	}
+	h := sha256.New()
+	h.Write(image[0x100:0x300])
+	h.Write(image[0x800:0x880])
+
+	bpm := &BootPolicyManifest{
+		BPMH: BPMH{
+			StructInfo:       StructInfo{ID: StructureIDBootPolicyManifestHeader, Version: StructureVersion},
+			HdrStructVersion: 1,
+			BPMSVN:           2,
+			ACMSVNAuth:       3,
+			NEMDataStack:     0x40,
+		},
+		SE: IBBElement{
+			StructInfo:  StructInfo{ID: StructureIDIBBElement, Version: StructureVersion},
+			Flags:       3,
+			PostIBBHash: manifest.HashStructure{HashAlg: manifest.AlgNull, HashBuffer: []byte{}},
+			EntryPoint:  0xfffffff0,
+			Digest:      manifest.HashStructure{HashAlg: manifest.AlgSHA256, HashBuffer: h.Sum(nil)},
+			IBBSegments: ibbSegments,
+		},
+		PM: &PlatformManufacturerElement{
+			StructInfo: StructInfo{ID: StructureIDPlatformManufacturerElement, Version: StructureVersion},
+			Data:       []byte("some OEM data"),
+		},
+	}
+	require.NoError(t, bpm.SetSignature(bpmKey))
+
+	bpKeyHash := sha256.Sum256(bpm.PMSE.KeySignature.Key.Data[4:])
+	km := &KeyManifest{
+		StructInfo: StructInfo{ID: StructureIDKeyManifest, Version: StructureVersion},
+		KMVersion:  1,
+		KMSVN:      2,
+		KMID:       3,
+		BPKey:      manifest.HashStructure{HashAlg: manifest.AlgSHA256, HashBuffer: bpKeyHash[:]},
+	}
+	require.NoError(t, km.SetSignature(kmKey))
+
+	return km, bpm
+}
+
+func TestManifests(t *testing.T) {
+	image := make([]byte, 0x1000)
+	_, err := rand.Read(image)
+	require.NoError(t, err)
+
+	km, bpm := newTestManifests(t, image)
+
+	t.Run("KeyManifest", func(t *testing.T) {
+		b, err := km.MarshalBinary()
+		require.NoError(t, err)
+		require.True(t, IsKeyManifest(b))
+
+		parsed, err := ParseKeyManifest(b)
+		require.NoError(t, err)
+		require.Equal(t, km, parsed)
+		require.NoError(t, parsed.Verify(b))
+		require.Equal(t, km.KeyAndSignature.Signature.Data, b[km.SignatureDataOffset():km.SignatureDataOffset()+uint64(len(km.KeyAndSignature.Signature.Data))])
+
+		b[km.KeyAndSignatureOffset()-1] ^= 1
+		require.Error(t, parsed.Verify(b))
+	})
+
+	t.Run("BootPolicyManifest", func(t *testing.T) {
+		for _, withPM := range []bool{true, false} {
+			bpm := *bpm
+			if !withPM {
+				bpm.PM = nil
+			}
+			b, err := bpm.MarshalBinary()
+			require.NoError(t, err)
+			require.False(t, IsKeyManifest(b))
+
+			parsed, err := ParseBootPolicyManifest(b)
+			require.NoError(t, err)
+			require.Equal(t, &bpm, parsed)
+			require.Equal(t, bpm.SE.Digest.HashBuffer, b[bpm.IBBDigestOffset():bpm.IBBDigestOffset()+sha256.Size])
+			require.Equal(t, bpm.PMSE.KeySignature.Signature.Data, b[bpm.SignatureDataOffset():bpm.SignatureDataOffset()+uint64(len(bpm.PMSE.KeySignature.Signature.Data))])
+			if withPM {
+				require.NoError(t, parsed.Verify(b))
+			} else {
+				// the PM element is covered by the signature
+				require.Error(t, parsed.Verify(b))
+			}
+		}
+	})
+
+	t.Run("ValidateBPMKey", func(t *testing.T) {
+		require.NoError(t, km.ValidateBPMKey(bpm.PMSE.KeySignature))
+		require.Error(t, km.ValidateBPMKey(km.KeyAndSignature))
+	})
+
+	t.Run("ValidateIBB", func(t *testing.T) {
+		require.NoError(t, bpm.ValidateIBB(image))
+
+		// not hashed segment
+		image[0x400] ^= 1
+		require.NoError(t, bpm.ValidateIBB(image))
+
+		image[0x800] ^= 1
+		require.Error(t, bpm.ValidateIBB(image))
+	})
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := ParseKeyManifest([]byte("__KEYM__\x21"))
+	require.Error(t, err)
+	require.False(t, IsKeyManifest([]byte("__KEYM__\x21")))
+
+	_, err = ParseBootPolicyManifest([]byte("__ACBP__\x10"))
+	require.Error(t, err)
+}
@@ -0,0 +1,78 @@
+// Package bootguard implements parsing and validation of the manifests of
+// Intel Boot Guard 1.0 (the pre-CBnT version of Boot Guard): the Key
+// Manifest (KM) and the Boot Policy Manifest (BPM).
+//
+// The key, signature and hash structures of Boot Guard 1.0 have the same
+// binary layout as in CBnT, so they are reused from fiano's "manifest"
+// package.
+package bootguard
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// StructureVersion is the value of field "Version" of StructInfo of all
+// Boot Guard 1.0 structures.
+const StructureVersion = 0x10
+
+// StructureID is the identifier ("tag") of a structure.
+type StructureID [8]byte
+
+// String implements fmt.Stringer.
+func (id StructureID) String() string {
+	return string(id[:])
+}
+
+// Known structure IDs.
+var (
+	StructureIDKeyManifest                 = StructureID{'_', '_', 'K', 'E', 'Y', 'M', '_', '_'}
+	StructureIDBootPolicyManifestHeader    = StructureID{'_', '_', 'A', 'C', 'B', 'P', '_', '_'}
+	StructureIDIBBElement                  = StructureID{'_', '_', 'I', 'B', 'B', 'S', '_', '_'}
+	StructureIDPlatformManufacturerElement = StructureID{'_', '_', 'P', 'M', 'D', 'A', '_', '_'}
+	StructureIDSignatureElement            = StructureID{'_', '_', 'P', 'M', 'S', 'G', '_', '_'}
+)
+
+// StructInfo is the common header of the manifests and of the elements
+// of a BPM.
+type StructInfo struct {
+	ID      StructureID
+	Version uint8
+}
+
+// structInfoSize is the size of StructInfo in the binary form.
+const structInfoSize = 9
+
+func (s *StructInfo) readFrom(r io.Reader, expectedID StructureID) error {
+	if err := binary.Read(r, binary.LittleEndian, s); err != nil {
+		return fmt.Errorf("unable to read the structure header: %w", err)
+	}
+	return s.validate(expectedID)
+}
+
+func (s *StructInfo) validate(expectedID StructureID) error {
+	if s.ID != expectedID {
+		return fmt.Errorf("invalid structure ID: expected '%s', received '%s'", expectedID, s.ID)
+	}
+	if s.Version != StructureVersion {
+		return fmt.Errorf("structure '%s' has unsupported version 0x%02X (expected 0x%02X)", s.ID, s.Version, StructureVersion)
+	}
+	return nil
+}
+
+func peekStructureID(b []byte) (StructureID, bool) {
+	var id StructureID
+	if len(b) < len(id) {
+		return id, false
+	}
+	copy(id[:], b)
+	return id, true
+}
+
+// isStructure returns true if `b` starts with a header of a Boot Guard 1.0
+// structure with ID `id`.
+func isStructure(b []byte, id StructureID) bool {
+	return len(b) >= structInfoSize && bytes.Equal(b[:len(id)], id[:]) && b[len(id)] == StructureVersion
+}
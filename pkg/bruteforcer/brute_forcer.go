@@ -1,6 +1,7 @@
 package bruteforcer
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"math/big"
@@ -12,6 +13,10 @@ import (
 
 const (
 	minIterationsPerCPU = 10000
+
+	// cancelCheckPeriod is how many combinations are tried between checks
+	// if the context is cancelled.
+	cancelCheckPeriod = 1024
 )
 
 // CheckFunc is the function used if the sought value is found. Return true
@@ -36,7 +41,13 @@ type bruteForcer struct {
 // On success it returns the combination of bits which combination are required
 // to be changed. To apply these changes use method ApplyBitFlips.
 func BruteForceBytes(initialData []byte, maxDistance uint64, initFunc InitFunc, checkFunc CheckFunc, maxConcurrency uint) (UniqueUnorderedCombination, error) {
-	return newBruteForcer(initialData, initFunc, checkFunc).run(maxDistance, maxConcurrency)
+	return BruteForceBytesContext(context.Background(), initialData, maxDistance, initFunc, checkFunc, maxConcurrency)
+}
+
+// BruteForceBytesContext is the same as BruteForceBytes, but it stops
+// and returns the error of the context if the context is cancelled.
+func BruteForceBytesContext(ctx context.Context, initialData []byte, maxDistance uint64, initFunc InitFunc, checkFunc CheckFunc, maxConcurrency uint) (UniqueUnorderedCombination, error) {
+	return newBruteForcer(initialData, initFunc, checkFunc).run(ctx, maxDistance, maxConcurrency)
 }
 
 func newBruteForcer(initialData []byte, initFunc InitFunc, checkFunc CheckFunc) *bruteForcer {
@@ -50,7 +61,7 @@ func newBruteForcer(initialData []byte, initFunc InitFunc, checkFunc CheckFunc)
 	}
 }
 
-func (b *bruteForcer) run(maxDistance uint64, maxConcurrency uint) (UniqueUnorderedCombination, error) {
+func (b *bruteForcer) run(cancelCtx context.Context, maxDistance uint64, maxConcurrency uint) (UniqueUnorderedCombination, error) {
 	ctx, err := b.initFunc()
 	if err != nil {
 		return nil, err
@@ -66,6 +77,9 @@ func (b *bruteForcer) run(maxDistance uint64, maxConcurrency uint) (UniqueUnorde
 	}
 
 	for distance := uint64(1); distance <= maxDistance; distance++ {
+		if err := cancelCtx.Err(); err != nil {
+			return nil, err
+		}
 		if totalBitLength < distance {
 			// no combinations possible
 			break
@@ -135,6 +149,9 @@ func (b *bruteForcer) run(maxDistance uint64, maxConcurrency uint) (UniqueUnorde
 						// few false negatives will not affect anything much.
 						return
 					}
+					if i%cancelCheckPeriod == 0 && cancelCtx.Err() != nil {
+						return
+					}
 
 					if b.try(bfctx, dataCopy, iterator) {
 						locker.Lock()
@@ -164,6 +181,12 @@ func (b *bruteForcer) run(maxDistance uint64, maxConcurrency uint) (UniqueUnorde
 			return nil, fmt.Errorf("workers had errors: %v", errors)
 		}
 
+		if resultData == nil {
+			if err := cancelCtx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
 		if resultData != nil {
 			return resultData, nil
 		}
@@ -1,6 +1,7 @@
 package bruteforcer
 
 import (
+	"context"
 	"crypto/sha1"
 	"encoding/binary"
 	"fmt"
@@ -34,6 +35,24 @@ func TestBruteForce(t *testing.T) {
 	require.Len(t, m, int(amountOfCombinations), fmt.Sprintf("%d != %d", len(m), amountOfCombinations))
 }
 
+func TestBruteForceCancel(t *testing.T) {
+	ctx, cancelFn := context.WithCancel(context.Background())
+	var tried uint64
+	var l sync.Mutex
+	result, err := BruteForceBytesContext(ctx, make([]byte, 64), 4, nil, func(_ interface{}, data []byte) bool {
+		l.Lock()
+		defer l.Unlock()
+		tried++
+		if tried == 10 {
+			cancelFn()
+		}
+		return false
+	}, 1)
+	require.Nil(t, result)
+	require.Equal(t, context.Canceled, err)
+	require.Less(t, tried, uint64(10+cancelCheckPeriod+1))
+}
+
 func BenchmarkBruteForce(b *testing.B) {
 	for _, checkFuncName := range []string{"noop", "sha1.Sum"} {
 		var checkFunc CheckFunc
@@ -0,0 +1,363 @@
+package bruteforcer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultShardSize is the default maximal amount of combinations
+	// in a shard.
+	DefaultShardSize = 1 << 24
+
+	// DefaultLeaseTimeout is the default time after which a shard acquired
+	// by a worker which stopped sending checkpoints could be acquired by
+	// another worker.
+	DefaultLeaseTimeout = time.Minute
+)
+
+var (
+	// ErrJobFinished means the sought value was already found (by any
+	// worker), so there is no need to continue.
+	ErrJobFinished = errors.New("the job is already finished")
+
+	// ErrLeaseLost means the shard is not acquired by the worker anymore
+	// (for example, the lease expired and the shard was acquired by
+	// another worker).
+	ErrLeaseLost = errors.New("the shard is not acquired by the worker")
+)
+
+// JobSpec defines a brute-force job. Unlike BruteForceBytes a job is
+// split into shards by combination ID, which could be processed by
+// multiple workers (including workers in different processes or on
+// different hosts), and the progress of each shard is checkpointed, so
+// the job could be resumed after a crash.
+//
+// See also Coordinator and RunJobWorker.
+type JobSpec struct {
+	// InitialData is the value to flip bits of.
+	InitialData []byte
+
+	// MaxDistance is the maximal hamming distance (relatively to
+	// InitialData) to try.
+	MaxDistance uint64
+
+	// ShardSize is the maximal amount of combinations in a shard. If zero,
+	// then DefaultShardSize is used.
+	ShardSize uint64
+}
+
+// Equal returns true if the specs define the same job.
+func (spec JobSpec) Equal(cmp JobSpec) bool {
+	return bytes.Equal(spec.InitialData, cmp.InitialData) &&
+		spec.MaxDistance == cmp.MaxDistance &&
+		spec.ShardSize == cmp.ShardSize
+}
+
+// Shard is a range of combinations with the same hamming distance.
+type Shard struct {
+	// ID is the index of the shard in the job.
+	ID int
+
+	// Distance is the amount of flipped bits.
+	Distance uint64
+
+	// StartCombinationID is the first combination ID of the shard.
+	StartCombinationID uint64
+
+	// EndCombinationID is the combination ID next to the last one.
+	EndCombinationID uint64
+}
+
+// Shards splits the job into shards. The shards are ordered by
+// the hamming distance.
+func (spec JobSpec) Shards() ([]Shard, error) {
+	shardSize := spec.ShardSize
+	if shardSize == 0 {
+		shardSize = DefaultShardSize
+	}
+
+	totalBitLength := uint64(len(spec.InitialData)) * 8
+	maxDistance := spec.MaxDistance
+	if maxDistance > totalBitLength {
+		maxDistance = totalBitLength
+	}
+
+	// Distance zero is just the initial data as is.
+	shards := []Shard{{ID: 0, Distance: 0, StartCombinationID: 0, EndCombinationID: 1}}
+	for distance := uint64(1); distance <= maxDistance; distance++ {
+		amountOfCombinationsBigInt := big.NewInt(1).Binomial(int64(totalBitLength), int64(distance))
+		// See the comment in bruteForcer.run
+		if amountOfCombinationsBigInt.Cmp(big.NewInt(math.MaxInt64)) >= 0 {
+			return nil, fmt.Errorf("distance is too high (amount of combinations causes uint64 overflow)")
+		}
+		amountOfCombinations := amountOfCombinationsBigInt.Uint64()
+		for start := uint64(0); start < amountOfCombinations; start += shardSize {
+			end := start + shardSize
+			if end > amountOfCombinations {
+				end = amountOfCombinations
+			}
+			shards = append(shards, Shard{
+				ID:                 len(shards),
+				Distance:           distance,
+				StartCombinationID: start,
+				EndCombinationID:   end,
+			})
+		}
+	}
+	return shards, nil
+}
+
+// ShardProgress is a checkpoint of a shard.
+type ShardProgress struct {
+	Shard
+
+	// NextCombinationID is the first combination ID which is not checked yet.
+	NextCombinationID uint64
+
+	// Done is true if all the combinations of the shard are checked.
+	Done bool
+}
+
+// JobStatus is the overall progress of a job.
+type JobStatus struct {
+	ShardsTotal int
+	ShardsDone  int
+
+	// CombinationsTotal is the total amount of combinations of the job.
+	CombinationsTotal uint64
+
+	// CombinationsChecked is the amount of checked combinations according
+	// to the latest checkpoints.
+	CombinationsChecked uint64
+
+	// Found is true if the sought value was found.
+	Found bool
+
+	// Combination is the combination of bits to be flipped to get the
+	// sought value (see Found).
+	Combination UniqueUnorderedCombination `json:",omitempty"`
+}
+
+// IsFinished returns true if the sought value was found or all the
+// combinations were checked.
+func (status JobStatus) IsFinished() bool {
+	return status.Found || status.ShardsDone == status.ShardsTotal
+}
+
+// Coordinator distributes shards of a job between workers and stores
+// the progress of the job.
+type Coordinator interface {
+	// Spec returns the specification of the job.
+	Spec() (JobSpec, error)
+
+	// AcquireShard returns a shard to be processed by the worker. If the
+	// worker already has an unfinished shard (for example, the worker was
+	// restarted), then this shard is returned. It returns nil if there
+	// are no shards left to be acquired.
+	AcquireShard(workerID string) (*ShardProgress, error)
+
+	// Checkpoint stores the progress of a shard acquired by the worker
+	// and extends the lease of the shard. It returns ErrJobFinished if
+	// the sought value was found by any worker and ErrLeaseLost if the
+	// shard is not acquired by the worker anymore.
+	Checkpoint(workerID string, progress ShardProgress) error
+
+	// FinishShard marks the shard acquired by the worker as done. If found
+	// is not nil, then the job is finished with this combination.
+	FinishShard(workerID string, progress ShardProgress, found UniqueUnorderedCombination) error
+
+	// Status returns the overall progress of the job.
+	Status() (*JobStatus, error)
+}
+
+// jobState is the complete state of a job, it is the implementation of
+// the logic of Coordinator without concurrency control and persistence.
+type jobState struct {
+	Spec        JobSpec
+	Shards      []shardState
+	Found       bool
+	Combination UniqueUnorderedCombination `json:",omitempty"`
+}
+
+type shardState struct {
+	ShardProgress
+	WorkerID       string    `json:",omitempty"`
+	LeaseExpiresAt time.Time `json:",omitempty"`
+}
+
+func newJobState(spec JobSpec) (*jobState, error) {
+	shards, err := spec.Shards()
+	if err != nil {
+		return nil, err
+	}
+	state := &jobState{
+		Spec:   spec,
+		Shards: make([]shardState, 0, len(shards)),
+	}
+	for _, shard := range shards {
+		state.Shards = append(state.Shards, shardState{
+			ShardProgress: ShardProgress{
+				Shard:             shard,
+				NextCombinationID: shard.StartCombinationID,
+			},
+		})
+	}
+	return state, nil
+}
+
+func (state *jobState) acquireShard(workerID string, now time.Time, leaseTimeout time.Duration) *ShardProgress {
+	if state.Found {
+		return nil
+	}
+
+	var candidate *shardState
+	for idx := range state.Shards {
+		shard := &state.Shards[idx]
+		if shard.Done {
+			continue
+		}
+		if shard.WorkerID == workerID {
+			// Resuming the shard of a restarted worker.
+			candidate = shard
+			break
+		}
+		if candidate == nil && (shard.WorkerID == "" || now.After(shard.LeaseExpiresAt)) {
+			candidate = shard
+		}
+	}
+	if candidate == nil {
+		return nil
+	}
+
+	candidate.WorkerID = workerID
+	candidate.LeaseExpiresAt = now.Add(leaseTimeout)
+	progress := candidate.ShardProgress
+	return &progress
+}
+
+func (state *jobState) getLeasedShard(workerID string, progress ShardProgress) (*shardState, error) {
+	if state.Found {
+		return nil, ErrJobFinished
+	}
+	if progress.ID < 0 || progress.ID >= len(state.Shards) {
+		return nil, fmt.Errorf("invalid shard ID: %d", progress.ID)
+	}
+	shard := &state.Shards[progress.ID]
+	if shard.WorkerID != workerID || shard.Done {
+		return nil, ErrLeaseLost
+	}
+	if progress.NextCombinationID < shard.StartCombinationID || progress.NextCombinationID > shard.EndCombinationID {
+		return nil, fmt.Errorf("combination ID %d is out of the shard %d range [%d, %d)",
+			progress.NextCombinationID, shard.ID, shard.StartCombinationID, shard.EndCombinationID)
+	}
+	return shard, nil
+}
+
+func (state *jobState) checkpoint(workerID string, progress ShardProgress, now time.Time, leaseTimeout time.Duration) error {
+	shard, err := state.getLeasedShard(workerID, progress)
+	if err != nil {
+		return err
+	}
+	shard.NextCombinationID = progress.NextCombinationID
+	shard.LeaseExpiresAt = now.Add(leaseTimeout)
+	return nil
+}
+
+func (state *jobState) finishShard(workerID string, progress ShardProgress, found UniqueUnorderedCombination) error {
+	shard, err := state.getLeasedShard(workerID, progress)
+	if err != nil {
+		return err
+	}
+	shard.NextCombinationID = shard.EndCombinationID
+	shard.Done = true
+	shard.WorkerID = ""
+	shard.LeaseExpiresAt = time.Time{}
+	if found != nil {
+		state.Found = true
+		state.Combination = found.Copy()
+	}
+	return nil
+}
+
+func (state *jobState) status() *JobStatus {
+	status := &JobStatus{
+		ShardsTotal: len(state.Shards),
+		Found:       state.Found,
+		Combination: state.Combination.Copy(),
+	}
+	for _, shard := range state.Shards {
+		status.CombinationsTotal += shard.EndCombinationID - shard.StartCombinationID
+		status.CombinationsChecked += shard.NextCombinationID - shard.StartCombinationID
+		if shard.Done {
+			status.ShardsDone++
+		}
+	}
+	return status
+}
+
+// MemoryCoordinator is an in-memory implementation of Coordinator. It
+// does not survive a restart of the process, but it could be served to
+// other processes with NewHTTPCoordinatorHandler.
+type MemoryCoordinator struct {
+	// LeaseTimeout is the time after which a shard of a silent worker
+	// could be acquired by another worker.
+	LeaseTimeout time.Duration
+
+	locker sync.Mutex
+	state  *jobState
+}
+
+var _ Coordinator = (*MemoryCoordinator)(nil)
+
+// NewMemoryCoordinator returns a new instance of MemoryCoordinator.
+func NewMemoryCoordinator(spec JobSpec) (*MemoryCoordinator, error) {
+	state, err := newJobState(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &MemoryCoordinator{
+		LeaseTimeout: DefaultLeaseTimeout,
+		state:        state,
+	}, nil
+}
+
+// Spec implements Coordinator.
+func (c *MemoryCoordinator) Spec() (JobSpec, error) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	return c.state.Spec, nil
+}
+
+// AcquireShard implements Coordinator.
+func (c *MemoryCoordinator) AcquireShard(workerID string) (*ShardProgress, error) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	return c.state.acquireShard(workerID, time.Now(), c.LeaseTimeout), nil
+}
+
+// Checkpoint implements Coordinator.
+func (c *MemoryCoordinator) Checkpoint(workerID string, progress ShardProgress) error {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	return c.state.checkpoint(workerID, progress, time.Now(), c.LeaseTimeout)
+}
+
+// FinishShard implements Coordinator.
+func (c *MemoryCoordinator) FinishShard(workerID string, progress ShardProgress, found UniqueUnorderedCombination) error {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	return c.state.finishShard(workerID, progress, found)
+}
+
+// Status implements Coordinator.
+func (c *MemoryCoordinator) Status() (*JobStatus, error) {
+	c.locker.Lock()
+	defer c.locker.Unlock()
+	return c.state.status(), nil
+}
@@ -0,0 +1,226 @@
+package bruteforcer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	dirCoordinatorStateFile = "state.json"
+	dirCoordinatorLockFile  = "state.lock"
+
+	// dirCoordinatorStaleLockTimeout is the time after which a lock file
+	// is considered to be left by a crashed process. The lock is held
+	// only for a read-modify-write of the state file, so it is expected
+	// to be released much sooner.
+	dirCoordinatorStaleLockTimeout = 30 * time.Second
+
+	dirCoordinatorLockRetryInterval = 10 * time.Millisecond
+)
+
+// DirCoordinator is an implementation of Coordinator, which stores the
+// state of the job in a local directory. Multiple processes could use
+// the same directory to share the job, and the job could be resumed
+// after a restart of all the processes.
+type DirCoordinator struct {
+	// LeaseTimeout is the time after which a shard of a silent worker
+	// could be acquired by another worker.
+	LeaseTimeout time.Duration
+
+	dir string
+}
+
+var _ Coordinator = (*DirCoordinator)(nil)
+
+// NewDirCoordinator returns a DirCoordinator for the job in directory dir.
+// If the directory does not contain a job yet, then it is initialized with
+// spec. If it contains a job with a different spec, then an error is
+// returned.
+func NewDirCoordinator(dir string, spec JobSpec) (*DirCoordinator, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("unable to create directory '%s': %w", dir, err)
+	}
+	c := &DirCoordinator{
+		LeaseTimeout: DefaultLeaseTimeout,
+		dir:          dir,
+	}
+	err := c.withLock(func() error {
+		state, err := c.loadState()
+		switch {
+		case err == nil:
+			if !state.Spec.Equal(spec) {
+				return fmt.Errorf("directory '%s' already contains a different job", dir)
+			}
+			return nil
+		case errors.Is(err, os.ErrNotExist):
+			state, err := newJobState(spec)
+			if err != nil {
+				return err
+			}
+			return c.storeState(state)
+		default:
+			return err
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// OpenDirCoordinator returns a DirCoordinator for an already existing job
+// in directory dir.
+func OpenDirCoordinator(dir string) (*DirCoordinator, error) {
+	c := &DirCoordinator{
+		LeaseTimeout: DefaultLeaseTimeout,
+		dir:          dir,
+	}
+	if _, err := c.loadState(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Spec implements Coordinator.
+func (c *DirCoordinator) Spec() (JobSpec, error) {
+	state, err := c.loadState()
+	if err != nil {
+		return JobSpec{}, err
+	}
+	return state.Spec, nil
+}
+
+// AcquireShard implements Coordinator.
+func (c *DirCoordinator) AcquireShard(workerID string) (*ShardProgress, error) {
+	var result *ShardProgress
+	err := c.modifyState(func(state *jobState) error {
+		result = state.acquireShard(workerID, time.Now(), c.LeaseTimeout)
+		return nil
+	})
+	return result, err
+}
+
+// Checkpoint implements Coordinator.
+func (c *DirCoordinator) Checkpoint(workerID string, progress ShardProgress) error {
+	return c.modifyState(func(state *jobState) error {
+		return state.checkpoint(workerID, progress, time.Now(), c.LeaseTimeout)
+	})
+}
+
+// FinishShard implements Coordinator.
+func (c *DirCoordinator) FinishShard(workerID string, progress ShardProgress, found UniqueUnorderedCombination) error {
+	return c.modifyState(func(state *jobState) error {
+		return state.finishShard(workerID, progress, found)
+	})
+}
+
+// Status implements Coordinator.
+func (c *DirCoordinator) Status() (*JobStatus, error) {
+	state, err := c.loadState()
+	if err != nil {
+		return nil, err
+	}
+	return state.status(), nil
+}
+
+func (c *DirCoordinator) modifyState(fn func(state *jobState) error) error {
+	return c.withLock(func() error {
+		state, err := c.loadState()
+		if err != nil {
+			return err
+		}
+		if err := fn(state); err != nil {
+			return err
+		}
+		return c.storeState(state)
+	})
+}
+
+func (c *DirCoordinator) loadState() (*jobState, error) {
+	b, err := ioutil.ReadFile(filepath.Join(c.dir, dirCoordinatorStateFile))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the job state: %w", err)
+	}
+	var state jobState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("unable to parse the job state: %w", err)
+	}
+	return &state, nil
+}
+
+// storeState writes the state atomically, so a crash could not leave
+// a partially written state.
+func (c *DirCoordinator) storeState(state *jobState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("unable to serialize the job state: %w", err)
+	}
+	tmpFile, err := ioutil.TempFile(c.dir, dirCoordinatorStateFile+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("unable to create a temporary file: %w", err)
+	}
+	_, err = tmpFile.Write(b)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), filepath.Join(c.dir, dirCoordinatorStateFile))
+	}
+	if err != nil {
+		_ = os.Remove(tmpFile.Name())
+		return fmt.Errorf("unable to write the job state: %w", err)
+	}
+	return nil
+}
+
+// withLock executes fn while holding a lock file, which is shared by all
+// the processes using the directory.
+func (c *DirCoordinator) withLock(fn func() error) error {
+	lockPath := filepath.Join(c.dir, dirCoordinatorLockFile)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_ = f.Close()
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("unable to create lock file '%s': %w", lockPath, err)
+		}
+		if stat, err := os.Stat(lockPath); err == nil && time.Since(stat.ModTime()) > dirCoordinatorStaleLockTimeout {
+			removeStaleLock(lockPath, stat)
+			continue
+		}
+		time.Sleep(dirCoordinatorLockRetryInterval)
+	}
+	defer func() {
+		_ = os.Remove(lockPath)
+	}()
+	return fn()
+}
+
+// removeStaleLock removes the lock file if it is still the file described
+// by staleStat. Another process could have removed the stale lock and taken
+// a new one in the meantime, so the lock file is atomically renamed first and
+// it is removed only if it is the stale one; otherwise it is put back.
+func removeStaleLock(lockPath string, staleStat os.FileInfo) {
+	stalePath := fmt.Sprintf("%s.stale.%d.%d", lockPath, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(lockPath, stalePath); err != nil {
+		// somebody else has removed the lock already
+		return
+	}
+	stat, err := os.Stat(stalePath)
+	if err != nil || !os.SameFile(stat, staleStat) || !stat.ModTime().Equal(staleStat.ModTime()) {
+		// this is a fresh lock of another process, restoring it (Link does
+		// not overwrite a lock which was taken in the meantime).
+		_ = os.Link(stalePath, lockPath)
+	}
+	_ = os.Remove(stalePath)
+}
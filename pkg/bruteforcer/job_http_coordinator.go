@@ -0,0 +1,191 @@
+package bruteforcer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+const (
+	httpCoordinatorPathSpec         = "/spec"
+	httpCoordinatorPathAcquireShard = "/acquire"
+	httpCoordinatorPathCheckpoint   = "/checkpoint"
+	httpCoordinatorPathFinishShard  = "/finish"
+	httpCoordinatorPathStatus       = "/status"
+)
+
+type httpCoordinatorRequest struct {
+	WorkerID string
+	Progress ShardProgress
+	Found    UniqueUnorderedCombination `json:",omitempty"`
+}
+
+type httpCoordinatorHandler struct {
+	coordinator Coordinator
+}
+
+// NewHTTPCoordinatorHandler returns a http.Handler which serves
+// the coordinator to HTTPCoordinator-s. It allows to share a job between
+// hosts without a shared filesystem.
+func NewHTTPCoordinatorHandler(coordinator Coordinator) http.Handler {
+	return &httpCoordinatorHandler{
+		coordinator: coordinator,
+	}
+}
+
+func (h *httpCoordinatorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req httpCoordinatorRequest
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("unable to parse the request: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var (
+		result interface{}
+		err    error
+	)
+	switch r.URL.Path {
+	case httpCoordinatorPathSpec:
+		result, err = h.coordinator.Spec()
+	case httpCoordinatorPathAcquireShard:
+		result, err = h.coordinator.AcquireShard(req.WorkerID)
+	case httpCoordinatorPathCheckpoint:
+		err = h.coordinator.Checkpoint(req.WorkerID, req.Progress)
+	case httpCoordinatorPathFinishShard:
+		err = h.coordinator.FinishShard(req.WorkerID, req.Progress, req.Found)
+	case httpCoordinatorPathStatus:
+		result, err = h.coordinator.Status()
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	switch err {
+	case nil:
+	case ErrJobFinished:
+		http.Error(w, err.Error(), http.StatusGone)
+		return
+	case ErrLeaseLost:
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// HTTPCoordinator is an implementation of Coordinator, which is a client
+// to a coordinator served by NewHTTPCoordinatorHandler.
+type HTTPCoordinator struct {
+	// Client is the HTTP client to be used. If nil, then http.DefaultClient
+	// is used.
+	Client *http.Client
+
+	baseURL string
+}
+
+var _ Coordinator = (*HTTPCoordinator)(nil)
+
+// NewHTTPCoordinator returns a new instance of HTTPCoordinator, which
+// sends requests to baseURL (for example "http://10.0.0.1:8080").
+func NewHTTPCoordinator(baseURL string) *HTTPCoordinator {
+	return &HTTPCoordinator{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Spec implements Coordinator.
+func (c *HTTPCoordinator) Spec() (JobSpec, error) {
+	var spec JobSpec
+	err := c.call(httpCoordinatorPathSpec, nil, &spec)
+	return spec, err
+}
+
+// AcquireShard implements Coordinator.
+func (c *HTTPCoordinator) AcquireShard(workerID string) (*ShardProgress, error) {
+	var progress *ShardProgress
+	err := c.call(httpCoordinatorPathAcquireShard, &httpCoordinatorRequest{WorkerID: workerID}, &progress)
+	return progress, err
+}
+
+// Checkpoint implements Coordinator.
+func (c *HTTPCoordinator) Checkpoint(workerID string, progress ShardProgress) error {
+	return c.call(httpCoordinatorPathCheckpoint, &httpCoordinatorRequest{
+		WorkerID: workerID,
+		Progress: progress,
+	}, nil)
+}
+
+// FinishShard implements Coordinator.
+func (c *HTTPCoordinator) FinishShard(workerID string, progress ShardProgress, found UniqueUnorderedCombination) error {
+	return c.call(httpCoordinatorPathFinishShard, &httpCoordinatorRequest{
+		WorkerID: workerID,
+		Progress: progress,
+		Found:    found,
+	}, nil)
+}
+
+// Status implements Coordinator.
+func (c *HTTPCoordinator) Status() (*JobStatus, error) {
+	var status JobStatus
+	if err := c.call(httpCoordinatorPathStatus, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (c *HTTPCoordinator) call(path string, req *httpCoordinatorRequest, result interface{}) error {
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var (
+		resp *http.Response
+		err  error
+	)
+	if req == nil {
+		resp, err = client.Get(c.baseURL + path)
+	} else {
+		var body []byte
+		body, err = json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("unable to serialize the request: %w", err)
+		}
+		resp, err = client.Post(c.baseURL+path, "application/json", bytes.NewReader(body))
+	}
+	if err != nil {
+		return fmt.Errorf("unable to send request to '%s': %w", c.baseURL+path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("unable to read the response from '%s': %w", c.baseURL+path, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusGone:
+		return ErrJobFinished
+	case http.StatusConflict:
+		return ErrLeaseLost
+	default:
+		return fmt.Errorf("coordinator '%s' returned status %d: %s", c.baseURL, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return fmt.Errorf("unable to parse the response from '%s': %w", c.baseURL+path, err)
+	}
+	return nil
+}
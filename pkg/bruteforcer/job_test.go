@@ -0,0 +1,243 @@
+package bruteforcer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobSpecShards(t *testing.T) {
+	shards, err := JobSpec{
+		InitialData: make([]byte, 2),
+		MaxDistance: 2,
+		ShardSize:   50,
+	}.Shards()
+	require.NoError(t, err)
+	require.Equal(t, []Shard{
+		{ID: 0, Distance: 0, StartCombinationID: 0, EndCombinationID: 1},
+		{ID: 1, Distance: 1, StartCombinationID: 0, EndCombinationID: 16},
+		{ID: 2, Distance: 2, StartCombinationID: 0, EndCombinationID: 50},
+		{ID: 3, Distance: 2, StartCombinationID: 50, EndCombinationID: 100},
+		{ID: 4, Distance: 2, StartCombinationID: 100, EndCombinationID: 120},
+	}, shards)
+}
+
+func TestJobStateLease(t *testing.T) {
+	state, err := newJobState(JobSpec{InitialData: make([]byte, 1), MaxDistance: 1})
+	require.NoError(t, err)
+
+	now := time.Now()
+	a := state.acquireShard("a", now, time.Minute)
+	require.NotNil(t, a)
+	require.Equal(t, 0, a.ID)
+
+	b := state.acquireShard("b", now, time.Minute)
+	require.NotNil(t, b)
+	require.Equal(t, 1, b.ID)
+
+	// all shards are leased
+	require.Nil(t, state.acquireShard("c", now, time.Minute))
+
+	// the worker gets its own shard back after a restart
+	b = state.acquireShard("b", now, time.Minute)
+	require.NotNil(t, b)
+	require.Equal(t, 1, b.ID)
+
+	b.NextCombinationID = 3
+	require.NoError(t, state.checkpoint("b", *b, now, time.Minute))
+
+	// the lease of "b" expired (while "a" extended its lease), the shard is resumed by "c"
+	require.NoError(t, state.checkpoint("a", *a, now.Add(90*time.Second), time.Minute))
+	c := state.acquireShard("c", now.Add(2*time.Minute), time.Minute)
+	require.NotNil(t, c)
+	require.Equal(t, 1, c.ID)
+	require.Equal(t, uint64(3), c.NextCombinationID)
+	require.Equal(t, ErrLeaseLost, state.checkpoint("b", *b, now, time.Minute))
+
+	require.NoError(t, state.finishShard("a", *a, nil))
+	require.NoError(t, state.finishShard("c", *c, UniqueUnorderedCombination{5}))
+	require.Equal(t, ErrJobFinished, state.checkpoint("c", *c, now, time.Minute))
+
+	status := state.status()
+	require.True(t, status.IsFinished())
+	require.True(t, status.Found)
+	require.Equal(t, UniqueUnorderedCombination{5}, status.Combination)
+	require.Equal(t, uint64(9), status.CombinationsChecked)
+}
+
+func newTestJob() (JobSpec, []byte, CheckFunc) {
+	spec := JobSpec{
+		InitialData: []byte{1, 2, 3, 4, 5, 6, 7, 8},
+		MaxDistance: 3,
+		ShardSize:   5000,
+	}
+	expected := make([]byte, len(spec.InitialData))
+	copy(expected, spec.InitialData)
+	expected[1] ^= 0x10
+	expected[4] ^= 0x01
+	expected[7] ^= 0x80
+	return spec, expected, func(_ interface{}, data []byte) bool {
+		return bytes.Equal(data, expected)
+	}
+}
+
+func checkFoundCombination(t *testing.T, spec JobSpec, expected []byte, combination UniqueUnorderedCombination) {
+	require.NotNil(t, combination)
+	data := make([]byte, len(spec.InitialData))
+	copy(data, spec.InitialData)
+	combination.ApplyBitFlips(data)
+	require.Equal(t, expected, data)
+}
+
+func TestRunJobWorkerDirCoordinator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bruteforcer-job-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	spec, expected, checkFunc := newTestJob()
+
+	var wg sync.WaitGroup
+	results := make([]UniqueUnorderedCombination, 3)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			coordinator, err := NewDirCoordinator(dir, spec)
+			require.NoError(t, err)
+			results[i], err = RunJobWorker(context.Background(), coordinator, nil, checkFunc, JobWorkerSettings{
+				WorkerID:       fmt.Sprintf("worker%d", i),
+				MaxConcurrency: 2,
+			})
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		checkFoundCombination(t, spec, expected, result)
+	}
+
+	_, err = NewDirCoordinator(dir, JobSpec{InitialData: []byte{1}})
+	require.Error(t, err)
+}
+
+func TestRunJobWorkerResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bruteforcer-job-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	spec, _, _ := newTestJob()
+	spec.ShardSize = 0
+
+	var checks uint64
+	ctx, cancelFn := context.WithCancel(context.Background())
+	defer cancelFn()
+	checkFunc := func(_ interface{}, data []byte) bool {
+		if atomic.AddUint64(&checks, 1) == 20000 {
+			cancelFn()
+		}
+		return false
+	}
+	settings := JobWorkerSettings{
+		WorkerID:           "worker",
+		MaxConcurrency:     1,
+		CheckpointInterval: time.Nanosecond,
+	}
+
+	coordinator, err := NewDirCoordinator(dir, spec)
+	require.NoError(t, err)
+	_, err = RunJobWorker(ctx, coordinator, nil, checkFunc, settings)
+	require.ErrorIs(t, err, context.Canceled)
+
+	status, err := coordinator.Status()
+	require.NoError(t, err)
+	require.False(t, status.IsFinished())
+	require.NotZero(t, status.CombinationsChecked)
+
+	// "restarting" the worker
+	coordinator, err = OpenDirCoordinator(dir)
+	require.NoError(t, err)
+	result, err := RunJobWorker(context.Background(), coordinator, nil, checkFunc, settings)
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	status, err = coordinator.Status()
+	require.NoError(t, err)
+	require.True(t, status.IsFinished())
+	require.False(t, status.Found)
+	require.Equal(t, status.CombinationsTotal, status.CombinationsChecked)
+	require.Less(t, checks, status.CombinationsTotal+jobWorkerTimeCheckPeriod)
+}
+
+func TestDirCoordinatorStaleLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bruteforcer-job-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	spec, _, _ := newTestJob()
+	coordinator, err := NewDirCoordinator(dir, spec)
+	require.NoError(t, err)
+
+	lockPath := filepath.Join(dir, dirCoordinatorLockFile)
+	require.NoError(t, ioutil.WriteFile(lockPath, nil, 0644))
+	staleTime := time.Now().Add(-2 * dirCoordinatorStaleLockTimeout)
+	require.NoError(t, os.Chtimes(lockPath, staleTime, staleTime))
+	staleStat, err := os.Stat(lockPath)
+	require.NoError(t, err)
+
+	// the stale lock was already replaced by a fresh lock of another process
+	require.NoError(t, os.Remove(lockPath))
+	require.NoError(t, ioutil.WriteFile(lockPath, nil, 0644))
+	removeStaleLock(lockPath, staleStat)
+	_, err = os.Stat(lockPath)
+	require.NoError(t, err)
+
+	require.NoError(t, os.Chtimes(lockPath, staleTime, staleTime))
+	_, err = coordinator.AcquireShard("worker")
+	require.NoError(t, err)
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1, files)
+}
+
+func TestRunJobWorkerHTTPCoordinator(t *testing.T) {
+	spec, expected, checkFunc := newTestJob()
+
+	memoryCoordinator, err := NewMemoryCoordinator(spec)
+	require.NoError(t, err)
+	server := httptest.NewServer(NewHTTPCoordinatorHandler(memoryCoordinator))
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	results := make([]UniqueUnorderedCombination, 2)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			results[i], err = RunJobWorker(context.Background(), NewHTTPCoordinator(server.URL), nil, checkFunc, JobWorkerSettings{
+				WorkerID: fmt.Sprintf("worker%d", i),
+			})
+			require.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		checkFoundCombination(t, spec, expected, result)
+	}
+
+	status, err := NewHTTPCoordinator(server.URL).Status()
+	require.NoError(t, err)
+	require.True(t, status.Found)
+}
@@ -0,0 +1,190 @@
+package bruteforcer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	pkgerrors "github.com/9elements/converged-security-suite/v2/pkg/errors"
+)
+
+const (
+	// DefaultCheckpointInterval is the default interval between
+	// checkpoints of a worker. It should be considerably smaller than
+	// the lease timeout of the coordinator.
+	DefaultCheckpointInterval = 10 * time.Second
+
+	// jobWorkerTimeCheckPeriod is how many combinations are checked
+	// between checks of the time and of the context.
+	jobWorkerTimeCheckPeriod = 1 << 12
+)
+
+// JobWorkerSettings are the settings of RunJobWorker.
+type JobWorkerSettings struct {
+	// WorkerID is the unique identifier of the worker. If the worker is
+	// restarted with the same ID it continues the shards it processed
+	// before the restart immediately (without waiting for the lease to
+	// expire).
+	WorkerID string
+
+	// CheckpointInterval is the interval between checkpoints. If zero,
+	// then DefaultCheckpointInterval is used.
+	CheckpointInterval time.Duration
+
+	// MaxConcurrency is the amount of shards processed concurrently. If
+	// zero, then it is equal to GOMAXPROCS.
+	MaxConcurrency uint
+}
+
+// RunJobWorker acquires shards of the job from the coordinator and brute
+// forces them until checkFunc will return true (in this or any other worker)
+// or there will be no shards left. See also JobSpec and BruteForceBytes.
+//
+// It returns the combination if the sought value is found (by any worker).
+// If the context is cancelled the progress is checkpointed and the
+// context error is returned.
+func RunJobWorker(ctx context.Context, coordinator Coordinator, initFunc InitFunc, checkFunc CheckFunc, settings JobWorkerSettings) (UniqueUnorderedCombination, error) {
+	if settings.CheckpointInterval == 0 {
+		settings.CheckpointInterval = DefaultCheckpointInterval
+	}
+	concurrencyFactor := uint(runtime.GOMAXPROCS(0))
+	if settings.MaxConcurrency > 0 && concurrencyFactor > settings.MaxConcurrency {
+		concurrencyFactor = settings.MaxConcurrency
+	}
+	if initFunc == nil {
+		initFunc = func() (interface{}, error) { return nil, nil }
+	}
+
+	spec, err := coordinator.Spec()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the job spec: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	errChan := make(chan error, concurrencyFactor)
+	for i := uint(0); i < concurrencyFactor; i++ {
+		w := &jobWorker{
+			id:                 fmt.Sprintf("%s/%d", settings.WorkerID, i),
+			coordinator:        coordinator,
+			spec:               spec,
+			checkFunc:          checkFunc,
+			checkpointInterval: settings.CheckpointInterval,
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := w.run(ctx, initFunc); err != nil {
+				errChan <- fmt.Errorf("worker '%s': %w", w.id, err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errChan)
+
+	var mErr pkgerrors.MultiError
+	for err := range errChan {
+		_ = mErr.Add(err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if mErr.Count() > 0 {
+		return nil, mErr.ReturnValue()
+	}
+
+	status, err := coordinator.Status()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the job status: %w", err)
+	}
+	if !status.Found {
+		return nil, nil
+	}
+	return status.Combination, nil
+}
+
+type jobWorker struct {
+	id                 string
+	coordinator        Coordinator
+	spec               JobSpec
+	checkFunc          CheckFunc
+	checkpointInterval time.Duration
+}
+
+func (w *jobWorker) run(ctx context.Context, initFunc InitFunc) error {
+	bfctx, err := initFunc()
+	if err != nil {
+		return fmt.Errorf("initFunc error: %w", err)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil
+		}
+		progress, err := w.coordinator.AcquireShard(w.id)
+		if err != nil {
+			return fmt.Errorf("unable to acquire a shard: %w", err)
+		}
+		if progress == nil {
+			return nil
+		}
+
+		err = w.processShard(ctx, bfctx, *progress)
+		switch {
+		case errors.Is(err, ErrJobFinished):
+			return nil
+		case errors.Is(err, ErrLeaseLost):
+			continue
+		case err != nil:
+			return fmt.Errorf("unable to process shard %d: %w", progress.ID, err)
+		}
+	}
+}
+
+func (w *jobWorker) processShard(ctx context.Context, bfctx interface{}, progress ShardProgress) error {
+	data := make([]byte, len(w.spec.InitialData))
+	copy(data, w.spec.InitialData)
+
+	if progress.Distance == 0 {
+		var found UniqueUnorderedCombination
+		if w.checkFunc(bfctx, data) {
+			found = NewUniqueUnorderedCombination(0)
+		}
+		return w.coordinator.FinishShard(w.id, progress, found)
+	}
+
+	totalBitLength := uint64(len(data)) * 8
+	iterator := NewUniqueUnorderedCombinationIterator(progress.Distance, int64(totalBitLength)-1)
+	if progress.NextCombinationID < progress.EndCombinationID {
+		iterator.SetCombinationID(progress.NextCombinationID)
+	}
+
+	lastCheckpointAt := time.Now()
+	for ; progress.NextCombinationID < progress.EndCombinationID; progress.NextCombinationID++ {
+		if progress.NextCombinationID != progress.StartCombinationID && progress.NextCombinationID%jobWorkerTimeCheckPeriod == 0 {
+			if ctx.Err() != nil {
+				// Saving the progress before exit.
+				return w.coordinator.Checkpoint(w.id, progress)
+			}
+			if time.Since(lastCheckpointAt) >= w.checkpointInterval {
+				if err := w.coordinator.Checkpoint(w.id, progress); err != nil {
+					return err
+				}
+				lastCheckpointAt = time.Now()
+			}
+		}
+
+		iterator.ApplyBitFlips(data)
+		if w.checkFunc(bfctx, data) {
+			return w.coordinator.FinishShard(w.id, progress, iterator.GetCombination())
+		}
+		iterator.ApplyBitFlips(data)
+		if progress.NextCombinationID+1 < progress.EndCombinationID {
+			iterator.Next()
+		}
+	}
+
+	return w.coordinator.FinishShard(w.id, progress, nil)
+}
@@ -201,29 +201,12 @@ func Analyze(
 		entryGoodData := goodData[diffRange.Offset:entryEndOffset]
 		entryBadData := badData[diffRange.Offset:entryEndOffset]
 
-		var relatedMeasurements []RelatedMeasurement
-		for _, m := range measurements {
-			var relatedDataChunks pcr.DataChunks
-			for _, data := range m.Data {
-				if data.Range.Intersect(diffRange) {
-					relatedDataChunks = append(relatedDataChunks, *data.Copy())
-				}
-			}
-			if len(relatedDataChunks) == 0 {
-				continue
-			}
-			relatedMeasurements = append(relatedMeasurements, RelatedMeasurement{
-				RelatedDataChunks: relatedDataChunks,
-				Measurement:       *m.Copy(),
-			})
-		}
-
 		// Filling some analysisEntry fields
 		analysisEntry := AnalysisReportEntry{
 			DiffRange:                diffRange,
 			HammingDistance:          hammingDistance(entryGoodData, entryBadData, nil, nil),
 			HammingDistanceNon00orFF: hammingDistance(entryGoodData, entryBadData, nil, []byte{0x00, 0xff}),
-			RelatedMeasurements:      relatedMeasurements,
+			RelatedMeasurements:      relatedMeasurements(measurements, pkgbytes.Ranges{diffRange}),
 		}
 
 		// Filling analysisEntry.Nodes
@@ -266,6 +249,31 @@ func Analyze(
 	return
 }
 
+// relatedMeasurements returns the measurements with data chunks which
+// overlap with any of the ranges.
+func relatedMeasurements(measurements pcr.Measurements, ranges pkgbytes.Ranges) []RelatedMeasurement {
+	var result []RelatedMeasurement
+	for _, m := range measurements {
+		var relatedDataChunks pcr.DataChunks
+		for _, data := range m.Data {
+			for _, r := range ranges {
+				if data.Range.Intersect(r) {
+					relatedDataChunks = append(relatedDataChunks, *data.Copy())
+					break
+				}
+			}
+		}
+		if len(relatedDataChunks) == 0 {
+			continue
+		}
+		result = append(result, RelatedMeasurement{
+			RelatedDataChunks: relatedDataChunks,
+			Measurement:       *m.Copy(),
+		})
+	}
+	return result
+}
+
 type intervalTree struct {
 	augmentedtree.Tree
 }
@@ -0,0 +1,367 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+
+	pkgbytes "github.com/linuxboot/fiano/pkg/bytes"
+	"github.com/linuxboot/fiano/pkg/intel/metadata/fit"
+	fianoUEFI "github.com/linuxboot/fiano/pkg/uefi"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
+)
+
+// ModuleKind is the kind of a Module
+type ModuleKind string
+
+const (
+	// ModuleKindVolume is an FFS firmware volume. Only the header of the
+	// volume is compared, the files are modules of their own.
+	ModuleKindVolume = ModuleKind("volume")
+
+	// ModuleKindFile is an FFS file.
+	ModuleKindFile = ModuleKind("file")
+
+	// ModuleKindFITEntry is a FIT entry. The data segment is compared if
+	// the entry references one, otherwise the headers are compared.
+	ModuleKindFITEntry = ModuleKind("fit_entry")
+)
+
+// Module is a part of a firmware image, which could be found in another
+// image by its identity (see Module.Key) even if it was moved.
+type Module struct {
+	Kind ModuleKind
+
+	// ID is the GUID of FFS nodes and the type of FIT entries.
+	ID string
+
+	// Name is the module name ("BASE_NAME") of FFS files, if any.
+	Name string `json:",omitempty"`
+
+	// Index enumerates the modules with the same kind, ID and name
+	// in the order of their appearance.
+	Index uint
+
+	// Range is the location of the module in the image. It is empty
+	// for FIT entries without a data segment.
+	Range pkgbytes.Range
+
+	// Data is the compared data of the module.
+	Data []byte `json:"-"`
+}
+
+// ModuleKey is the identity of a Module.
+type ModuleKey struct {
+	Kind  ModuleKind
+	ID    string
+	Name  string
+	Index uint
+}
+
+// Key returns the identity of the module, which is used to find the
+// same module in another image.
+func (m Module) Key() ModuleKey {
+	return ModuleKey{
+		Kind:  m.Kind,
+		ID:    m.ID,
+		Name:  m.Name,
+		Index: m.Index,
+	}
+}
+
+func (m Module) String() string {
+	var result = string(m.Kind) + ":" + m.ID
+	if m.Name != "" {
+		result += ":" + m.Name
+	}
+	if m.Index > 0 {
+		result += fmt.Sprintf("#%d", m.Index)
+	}
+	return result
+}
+
+// ChangeType is the type of a StructuralChange.
+type ChangeType string
+
+const (
+	// ChangeTypeAdded means the module exists only in the new image.
+	ChangeTypeAdded = ChangeType("added")
+
+	// ChangeTypeRemoved means the module exists only in the old image.
+	ChangeTypeRemoved = ChangeType("removed")
+
+	// ChangeTypeMoved means the module has the same data, but it is
+	// placed at another offset.
+	ChangeTypeMoved = ChangeType("moved")
+
+	// ChangeTypeModified means the data of the module was changed.
+	ChangeTypeModified = ChangeType("modified")
+
+	// ChangeTypeMovedAndModified means the data of the module was
+	// changed and it is placed at another offset.
+	ChangeTypeMovedAndModified = ChangeType("moved_and_modified")
+)
+
+// StructuralChange is a change of a single module.
+type StructuralChange struct {
+	Type ChangeType
+
+	// Old is the module in the old image, it is nil for added modules.
+	Old *Module `json:",omitempty"`
+
+	// New is the module in the new image, it is nil for removed modules.
+	New *Module `json:",omitempty"`
+
+	// ByteDiffs contains the ranges with different data relative to the
+	// beginning of the module data. If the size of the module was changed,
+	// then the tail of the larger module is reported as different.
+	ByteDiffs pkgbytes.Ranges `json:",omitempty"`
+
+	// RelatedMeasurements contains the measurements of the old image
+	// which are affected by the change.
+	RelatedMeasurements []RelatedMeasurement `json:",omitempty"`
+}
+
+// Module returns the new module, or the old one if it was removed.
+func (change StructuralChange) Module() *Module {
+	if change.New != nil {
+		return change.New
+	}
+	return change.Old
+}
+
+// StructuralReport is the result of StructuralDiff.
+type StructuralReport struct {
+	Changes []StructuralChange
+}
+
+// StructuralDiff compares two images by their structure instead of
+// comparing the bytes at the same offsets. FFS volumes and files are
+// aligned by their GUID and module name, FIT entries by their type. This way
+// a moved or inserted module does not result into differences in every
+// module which follows it.
+//
+// measurements are the measurements of the old image, each change is linked
+// with the measurements whose data chunks overlap with the changed bytes
+// of the old image.
+//
+// ignoreByteSet is a set of bytes, each of which is just skipped while looking
+// for differences in the data of modules.
+func StructuralDiff(
+	oldFirmware, newFirmware Firmware,
+	measurements pcr.Measurements,
+	ignoreByteSet []byte,
+) (report StructuralReport, err error) {
+	oldModules, err := Modules(oldFirmware)
+	if err != nil {
+		return report, fmt.Errorf("unable to get modules of the old image: %w", err)
+	}
+	newModules, err := Modules(newFirmware)
+	if err != nil {
+		return report, fmt.Errorf("unable to get modules of the new image: %w", err)
+	}
+
+	report.Changes = CompareModules(oldModules, newModules, ignoreByteSet)
+	for idx := range report.Changes {
+		change := &report.Changes[idx]
+		change.RelatedMeasurements = relatedMeasurements(measurements, change.oldAffectedRanges())
+	}
+	return report, nil
+}
+
+// oldAffectedRanges returns the ranges of the old image affected by the change
+func (change StructuralChange) oldAffectedRanges() pkgbytes.Ranges {
+	if change.Old == nil || change.Old.Range.Length == 0 {
+		return nil
+	}
+	switch change.Type {
+	case ChangeTypeModified:
+		var result pkgbytes.Ranges
+		for _, r := range change.ByteDiffs {
+			if r.Offset >= change.Old.Range.Length {
+				continue
+			}
+			if r.Offset+r.Length > change.Old.Range.Length {
+				r.Length = change.Old.Range.Length - r.Offset
+			}
+			r.Offset += change.Old.Range.Offset
+			result = append(result, r)
+		}
+		return result
+	}
+	return pkgbytes.Ranges{change.Old.Range}
+}
+
+// CompareModules aligns the modules of two images by their keys and
+// returns the changes ordered by their offset.
+func CompareModules(oldModules, newModules []Module, ignoreByteSet []byte) []StructuralChange {
+	newModulesMap := map[ModuleKey]*Module{}
+	for idx := range newModules {
+		newModulesMap[newModules[idx].Key()] = &newModules[idx]
+	}
+
+	var changes []StructuralChange
+	found := map[ModuleKey]bool{}
+	for idx := range oldModules {
+		oldModule := &oldModules[idx]
+		key := oldModule.Key()
+		newModule := newModulesMap[key]
+		if newModule == nil {
+			changes = append(changes, StructuralChange{
+				Type: ChangeTypeRemoved,
+				Old:  oldModule,
+			})
+			continue
+		}
+		found[key] = true
+
+		moved := oldModule.Range.Offset != newModule.Range.Offset
+		byteDiffs := moduleByteDiffs(oldModule.Data, newModule.Data, ignoreByteSet)
+		var changeType ChangeType
+		switch {
+		case moved && len(byteDiffs) > 0:
+			changeType = ChangeTypeMovedAndModified
+		case moved:
+			changeType = ChangeTypeMoved
+		case len(byteDiffs) > 0:
+			changeType = ChangeTypeModified
+		default:
+			continue
+		}
+		changes = append(changes, StructuralChange{
+			Type:      changeType,
+			Old:       oldModule,
+			New:       newModule,
+			ByteDiffs: byteDiffs,
+		})
+	}
+	for idx := range newModules {
+		newModule := &newModules[idx]
+		if found[newModule.Key()] {
+			continue
+		}
+		changes = append(changes, StructuralChange{
+			Type: ChangeTypeAdded,
+			New:  newModule,
+		})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changeSortOffset(changes[i]) < changeSortOffset(changes[j])
+	})
+	return changes
+}
+
+func changeSortOffset(change StructuralChange) uint64 {
+	if change.Old != nil {
+		return change.Old.Range.Offset
+	}
+	return change.New.Range.Offset
+}
+
+func moduleByteDiffs(oldData, newData []byte, ignoreByteSet []byte) pkgbytes.Ranges {
+	commonLength := len(oldData)
+	if len(newData) < commonLength {
+		commonLength = len(newData)
+	}
+	var result pkgbytes.Ranges
+	if commonLength > 0 {
+		result = Diff(pkgbytes.Ranges{{Offset: 0, Length: uint64(commonLength)}}, oldData, newData, ignoreByteSet)
+	}
+	if len(oldData) != len(newData) {
+		maxLength := len(oldData)
+		if len(newData) > maxLength {
+			maxLength = len(newData)
+		}
+		result = append(result, pkgbytes.Range{
+			Offset: uint64(commonLength),
+			Length: uint64(maxLength - commonLength),
+		})
+		result = pkgbytes.MergeRanges(result, 0)
+	}
+	return result
+}
+
+// Modules returns the FFS volumes and files and the FIT entries of the
+// image. Nodes with unknown offset (e.g. inside compressed sections)
+// and pad files are skipped.
+func Modules(firmware Firmware) ([]Module, error) {
+	buf := firmware.Buf()
+	nodes, err := firmware.GetByRange(pkgbytes.Range{
+		Offset: 0,
+		Length: uint64(len(buf)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to scan for UEFI nodes: %w", err)
+	}
+
+	var modules []Module
+	count := map[ModuleKey]uint{}
+	add := func(m Module) {
+		key := m.Key()
+		m.Index = count[key]
+		count[key]++
+		modules = append(modules, m)
+	}
+
+	for _, node := range nodes {
+		if node.Offset == math.MaxUint64 {
+			continue
+		}
+		switch f := node.Firmware.(type) {
+		case *fianoUEFI.FirmwareVolume:
+			header := f.Buf()
+			if f.DataOffset < uint64(len(header)) {
+				header = header[:f.DataOffset]
+			}
+			add(Module{
+				Kind:  ModuleKindVolume,
+				ID:    f.FVName.String(),
+				Range: node.Range,
+				Data:  header,
+			})
+		case *fianoUEFI.File:
+			if f.Header.Type == fianoUEFI.FVFileTypePad {
+				continue
+			}
+			m := Module{
+				Kind:  ModuleKindFile,
+				ID:    f.Header.GUID.String(),
+				Range: node.Range,
+				Data:  f.Buf(),
+			}
+			if name := node.ModuleName(); name != nil {
+				m.Name = *name
+			}
+			add(m)
+		}
+	}
+
+	// FIT is optional, images of non-Intel platforms have no FIT at all.
+	fitEntries, _ := fit.GetEntries(buf)
+	for _, entry := range fitEntries {
+		base := entry.GetEntryBase()
+		m := Module{
+			Kind: ModuleKindFITEntry,
+			ID:   base.Headers.Type().String(),
+		}
+		if base.DataSegmentBytes != nil {
+			m.Range = pkgbytes.Range{
+				Offset: base.Headers.Address.Offset(uint64(len(buf))),
+				Length: uint64(len(base.DataSegmentBytes)),
+			}
+			m.Data = base.DataSegmentBytes
+		} else {
+			var headers bytes.Buffer
+			if _, err := base.Headers.WriteTo(&headers); err != nil {
+				return nil, fmt.Errorf("unable to serialize headers of FIT entry %s: %w", m.ID, err)
+			}
+			m.Data = headers.Bytes()
+		}
+		add(m)
+	}
+
+	return modules, nil
+}
@@ -0,0 +1,128 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+	"github.com/9elements/converged-security-suite/v2/testdata/firmware"
+	pkgbytes "github.com/linuxboot/fiano/pkg/bytes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareModules(t *testing.T) {
+	module := func(id string, offset uint64, data string) Module {
+		return Module{
+			Kind:  ModuleKindFile,
+			ID:    id,
+			Range: pkgbytes.Range{Offset: offset, Length: uint64(len(data))},
+			Data:  []byte(data),
+		}
+	}
+
+	oldModules := []Module{
+		module("unchanged", 0, "aaaa"),
+		module("removed", 4, "bbbb"),
+		module("moved", 8, "cccc"),
+		module("modified", 12, "dddd"),
+		module("moved_and_modified", 16, "eeee"),
+	}
+	newModules := []Module{
+		module("unchanged", 0, "aaaa"),
+		module("added", 4, "ffffff"),
+		module("moved", 10, "cccc"),
+		module("modified", 12, "dDdd"),
+		module("moved_and_modified", 18, "eeeeEE"),
+	}
+
+	changes := CompareModules(oldModules, newModules, nil)
+	require.Len(t, changes, 5)
+
+	require.Equal(t, ChangeTypeRemoved, changes[0].Type)
+	require.Equal(t, "removed", changes[0].Old.ID)
+	require.Nil(t, changes[0].New)
+
+	require.Equal(t, ChangeTypeAdded, changes[1].Type)
+	require.Equal(t, "added", changes[1].New.ID)
+	require.Nil(t, changes[1].Old)
+
+	require.Equal(t, ChangeTypeMoved, changes[2].Type)
+	require.Empty(t, changes[2].ByteDiffs)
+
+	require.Equal(t, ChangeTypeModified, changes[3].Type)
+	require.Equal(t, pkgbytes.Ranges{{Offset: 1, Length: 1}}, changes[3].ByteDiffs)
+
+	require.Equal(t, ChangeTypeMovedAndModified, changes[4].Type)
+	require.Equal(t, pkgbytes.Ranges{{Offset: 4, Length: 2}}, changes[4].ByteDiffs)
+}
+
+func TestCompareModulesDuplicates(t *testing.T) {
+	// Modules with the same ID are aligned by the order of appearance
+	oldModules := []Module{
+		{Kind: ModuleKindFile, ID: "a", Index: 0, Data: []byte{1}},
+		{Kind: ModuleKindFile, ID: "a", Index: 1, Data: []byte{2}},
+	}
+	newModules := []Module{
+		{Kind: ModuleKindFile, ID: "a", Index: 0, Data: []byte{1}},
+	}
+	changes := CompareModules(oldModules, newModules, nil)
+	require.Len(t, changes, 1)
+	require.Equal(t, ChangeTypeRemoved, changes[0].Type)
+	require.Equal(t, uint(1), changes[0].Old.Index)
+}
+
+func TestStructuralDiff(t *testing.T) {
+	oldFirmware, err := uefi.ParseUEFIFirmwareBytes(firmware.FakeIntelFirmware)
+	require.NoError(t, err)
+
+	oldModules, err := Modules(oldFirmware)
+	require.NoError(t, err)
+	var file *Module
+	for idx := range oldModules {
+		if oldModules[idx].ID == "658ABE96-545D-4797-BDCC-B9BE16AAD5EE" {
+			file = &oldModules[idx]
+		}
+	}
+	require.NotNil(t, file)
+
+	report, err := StructuralDiff(oldFirmware, oldFirmware, nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, report.Changes)
+
+	// Modifying the last byte of the file
+	newImage := append([]byte{}, firmware.FakeIntelFirmware...)
+	modifiedOffset := file.Range.Offset + file.Range.Length - 1
+	newImage[modifiedOffset]++
+	newFirmware, err := uefi.ParseUEFIFirmwareBytes(newImage)
+	require.NoError(t, err)
+
+	measurements := pcr.Measurements{
+		{
+			ID: pcr.MeasurementIDPCDFirmwareVendorVersionData,
+			Data: pcr.DataChunks{{
+				Range: pkgbytes.Range{Offset: modifiedOffset, Length: 1},
+			}},
+		},
+		{
+			ID: pcr.MeasurementIDBIOSStartupModule,
+			Data: pcr.DataChunks{{
+				Range: pkgbytes.Range{Offset: 0, Length: file.Range.Offset},
+			}},
+		},
+	}
+
+	report, err = StructuralDiff(oldFirmware, newFirmware, measurements, nil)
+	require.NoError(t, err)
+	// The file is also covered by a FIT entry of the startup module
+	require.Len(t, report.Changes, 2)
+	for _, change := range report.Changes {
+		require.Equal(t, ChangeTypeModified, change.Type)
+		require.Len(t, change.RelatedMeasurements, 1)
+		require.Equal(t, pcr.MeasurementIDPCDFirmwareVendorVersionData, change.RelatedMeasurements[0].ID)
+		if change.Module().Kind == ModuleKindFITEntry {
+			continue
+		}
+		require.Equal(t, file.Key(), change.Module().Key())
+		require.Equal(t, pkgbytes.Ranges{{Offset: file.Range.Length - 1, Length: 1}}, change.ByteDiffs)
+	}
+}
@@ -0,0 +1,227 @@
+package hwapi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/9elements/go-linux-lowlevel-hw/pkg/hwapi"
+	"gopkg.in/yaml.v3"
+)
+
+// CPUDatabaseFormatVersion is the version of the CPU database format supported
+const CPUDatabaseFormatVersion = 1
+
+// CPUDatabase is a list of processors and the security technologies they support.
+// The embedded database is returned by DefaultCPUDatabase, it could be extended
+// or overridden by an external file using LoadCPUDatabase.
+type CPUDatabase struct {
+	// Version is the format version, must be CPUDatabaseFormatVersion
+	Version int `yaml:"Version" json:"Version"`
+	// Revision identifies the content of the database (e.g. a date)
+	Revision string `yaml:"Revision" json:"Revision"`
+	// Processors is searched in order, the first matching entry wins
+	Processors []CPUDatabaseEntry `yaml:"Processors" json:"Processors"`
+}
+
+// CPUDatabaseEntry describes processors with the same CPUID family and model
+type CPUDatabaseEntry struct {
+	// Name is the code name of the processors
+	Name string `yaml:"Name" json:"Name"`
+	// Family and Model are the display family and model as calculated
+	// from CPUID.01H:EAX (including the extended family and model)
+	Family uint32 `yaml:"Family" json:"Family"`
+	Model  uint32 `yaml:"Model" json:"Model"`
+	// Steppings limits the entry to the given steppings, all steppings match if empty
+	Steppings []uint32 `yaml:"Steppings,omitempty" json:"Steppings,omitempty"`
+	// PlatformIDs limits the entry to the given processor flags
+	// (IA32_PLATFORM_ID[52:50]), all platforms match if empty
+	PlatformIDs  []uint8         `yaml:"PlatformIDs,omitempty" json:"PlatformIDs,omitempty"`
+	Capabilities CPUCapabilities `yaml:"Capabilities" json:"Capabilities"`
+}
+
+// CPUCapabilities are the security technologies supported by a processor
+// generation. Single SKUs may still have TXT and SMX disabled.
+type CPUCapabilities struct {
+	TXT       bool `yaml:"TXT" json:"TXT"`
+	SMX       bool `yaml:"SMX" json:"SMX"`
+	BootGuard bool `yaml:"BootGuard" json:"BootGuard"`
+	CBnT      bool `yaml:"CBnT" json:"CBnT"`
+}
+
+var (
+	defaultCPUDatabase     *CPUDatabase
+	defaultCPUDatabaseOnce sync.Once
+)
+
+// DefaultCPUDatabase returns the CPU database embedded into the binary
+func DefaultCPUDatabase() *CPUDatabase {
+	defaultCPUDatabaseOnce.Do(func() {
+		db, err := ParseCPUDatabase([]byte(cpuDatabaseYAML))
+		if err != nil {
+			panic(fmt.Sprintf("embedded CPU database is invalid: %v", err))
+		}
+		defaultCPUDatabase = db
+	})
+	return defaultCPUDatabase
+}
+
+// ParseCPUDatabase parses a CPU database in YAML or JSON format
+func ParseCPUDatabase(data []byte) (*CPUDatabase, error) {
+	var db CPUDatabase
+	if err := yaml.Unmarshal(data, &db); err != nil {
+		return nil, err
+	}
+	if db.Version != CPUDatabaseFormatVersion {
+		return nil, fmt.Errorf("unsupported CPU database version %d, expected %d", db.Version, CPUDatabaseFormatVersion)
+	}
+	for idx := range db.Processors {
+		entry := &db.Processors[idx]
+		if len(entry.Name) == 0 {
+			return nil, fmt.Errorf("processor entry %d has no name", idx)
+		}
+		if entry.Family == 0 {
+			return nil, fmt.Errorf("processor entry %d (%s) has no family", idx, entry.Name)
+		}
+		for _, platformID := range entry.PlatformIDs {
+			if platformID > 7 {
+				return nil, fmt.Errorf("processor entry %d (%s) has invalid platform ID %d", idx, entry.Name, platformID)
+			}
+		}
+	}
+	return &db, nil
+}
+
+// LoadCPUDatabase reads a CPU database from a file and returns it merged
+// with the embedded database. The entries of the file take precedence.
+func LoadCPUDatabase(path string) (*CPUDatabase, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	db, err := ParseCPUDatabase(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse CPU database '%s': %w", path, err)
+	}
+	return db.Merge(DefaultCPUDatabase()), nil
+}
+
+// Merge returns a database containing the entries of db followed by the
+// entries of fallback, so entries of db take precedence.
+func (db *CPUDatabase) Merge(fallback *CPUDatabase) *CPUDatabase {
+	result := &CPUDatabase{
+		Version:  db.Version,
+		Revision: db.Revision,
+	}
+	result.Processors = append(result.Processors, db.Processors...)
+	result.Processors = append(result.Processors, fallback.Processors...)
+	return result
+}
+
+// CPUIDFamilyModelStepping decodes the display family, model and stepping of
+// a CPU signature (CPUID.01H:EAX)
+func CPUIDFamilyModelStepping(signature uint32) (uint32, uint32, uint32) {
+	family := (signature >> 8) & 0xf
+	model := (signature >> 4) & 0xf
+	stepping := signature & 0xf
+	if family == 0xf {
+		family += (signature >> 20) & 0xff
+	}
+	if family == 0x6 || family >= 0xf {
+		model |= ((signature >> 16) & 0xf) << 4
+	}
+	return family, model, stepping
+}
+
+// processorFlag returns the processor flag of IA32_PLATFORM_ID
+func processorFlag(platformID uint64) uint8 {
+	return uint8((platformID >> 50) & 0x7)
+}
+
+// Lookup returns the first entry matching the CPU signature (CPUID.01H:EAX)
+// and the value of IA32_PLATFORM_ID, or nil if the processor is unknown
+func (db *CPUDatabase) Lookup(signature uint32, platformID uint64) *CPUDatabaseEntry {
+	family, model, stepping := CPUIDFamilyModelStepping(signature)
+	flag := processorFlag(platformID)
+	for idx := range db.Processors {
+		entry := &db.Processors[idx]
+		if entry.Family != family || entry.Model != model {
+			continue
+		}
+		if len(entry.Steppings) > 0 && !containsUint32(entry.Steppings, stepping) {
+			continue
+		}
+		if len(entry.PlatformIDs) > 0 && !containsUint8(entry.PlatformIDs, flag) {
+			continue
+		}
+		return entry
+	}
+	return nil
+}
+
+// LookupCPU returns the entry matching the CPU of the hardware
+func (db *CPUDatabase) LookupCPU(h hwapi.LowLevelHardwareInterfaces) (*CPUDatabaseEntry, error) {
+	platformID, err := hwapi.IA32PlatformID(h)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read IA32_PLATFORM_ID: %w", err)
+	}
+	signature := h.CPUSignature()
+	entry := db.Lookup(signature, platformID)
+	if entry == nil {
+		return nil, fmt.Errorf("CPU '%s' (signature 0x%x) is not listed in the CPU database", h.ProcessorBrandName(), signature)
+	}
+	return entry, nil
+}
+
+// MatchesACMProcessorID returns true if the processor ID of an ACM
+// (FMS and platform ID with their masks) matches any processor described
+// by the entry
+func (entry *CPUDatabaseEntry) MatchesACMProcessorID(fms, fmsMask uint32, platformID, platformMask uint64) bool {
+	var signature uint32
+	if entry.Family >= 0xf {
+		signature = 0xf<<8 | (entry.Family-0xf)<<20
+	} else {
+		signature = entry.Family << 8
+	}
+	signature |= (entry.Model&0xf)<<4 | (entry.Model>>4)<<16
+
+	steppings := entry.Steppings
+	if len(steppings) == 0 {
+		// any stepping of the entry matches
+		fms &^= 0xf
+		fmsMask &^= 0xf
+		steppings = []uint32{0}
+	}
+	flags := entry.PlatformIDs
+	if len(flags) == 0 {
+		// any platform of the entry matches
+		platformID, platformMask = 0, 0
+		flags = []uint8{0}
+	}
+	for _, stepping := range steppings {
+		for _, flag := range flags {
+			if (signature|stepping)&fmsMask == fms && (uint64(flag)<<50)&platformMask == platformID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsUint32(list []uint32, value uint32) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsUint8(list []uint8, value uint8) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
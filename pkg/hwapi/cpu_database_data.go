@@ -0,0 +1,65 @@
+package hwapi
+
+// cpuDatabaseYAML is the embedded CPU database, see CPUDatabase for the format.
+//
+// TXT and SMX are available on the vPro/Xeon SKUs of a generation only, the
+// other SKUs report no SMX support in CPUID.
+const cpuDatabaseYAML = `
+Version: 1
+Revision: "2026-10-18"
+Processors:
+  # Atom
+  - {Name: "Silvermont (Bay Trail)", Family: 6, Model: 0x37, Capabilities: {}}
+  - {Name: "Silvermont (Avoton/Rangeley)", Family: 6, Model: 0x4d, Capabilities: {}}
+  - {Name: "Airmont (Braswell/Cherry Trail)", Family: 6, Model: 0x4c, Capabilities: {}}
+  - {Name: "Goldmont (Apollo Lake)", Family: 6, Model: 0x5c, Capabilities: {BootGuard: true}}
+  - {Name: "Goldmont (Denverton)", Family: 6, Model: 0x5f, Capabilities: {}}
+  - {Name: "Goldmont Plus (Gemini Lake)", Family: 6, Model: 0x7a, Capabilities: {BootGuard: true}}
+  # Xeon Phi
+  - {Name: "Knights Landing", Family: 6, Model: 0x57, Capabilities: {}}
+  - {Name: "Knights Mill", Family: 6, Model: 0x85, Capabilities: {}}
+  # Core 2
+  - {Name: "Merom/Conroe", Family: 6, Model: 0x0f, Capabilities: {TXT: true, SMX: true}}
+  - {Name: "Penryn/Wolfdale", Family: 6, Model: 0x17, Capabilities: {TXT: true, SMX: true}}
+  # Nehalem/Westmere
+  - {Name: "Nehalem (Bloomfield/Gainestown)", Family: 6, Model: 0x1a, Capabilities: {TXT: true, SMX: true}}
+  - {Name: "Nehalem (Lynnfield/Clarksfield)", Family: 6, Model: 0x1e, Capabilities: {TXT: true, SMX: true}}
+  - {Name: "Nehalem-EX (Beckton)", Family: 6, Model: 0x2e, Capabilities: {TXT: true, SMX: true}}
+  - {Name: "Westmere (Clarkdale/Arrandale)", Family: 6, Model: 0x25, Capabilities: {TXT: true, SMX: true}}
+  - {Name: "Westmere-EP (Gulftown)", Family: 6, Model: 0x2c, Capabilities: {TXT: true, SMX: true}}
+  - {Name: "Westmere-EX", Family: 6, Model: 0x2f, Capabilities: {TXT: true, SMX: true}}
+  # Sandy Bridge/Ivy Bridge
+  - {Name: "Sandy Bridge", Family: 6, Model: 0x2a, Capabilities: {TXT: true, SMX: true}}
+  - {Name: "Sandy Bridge-EP", Family: 6, Model: 0x2d, Capabilities: {TXT: true, SMX: true}}
+  - {Name: "Ivy Bridge", Family: 6, Model: 0x3a, Capabilities: {TXT: true, SMX: true}}
+  - {Name: "Ivy Bridge-EP/EX", Family: 6, Model: 0x3e, Capabilities: {TXT: true, SMX: true}}
+  # Haswell/Broadwell
+  - {Name: "Haswell", Family: 6, Model: 0x3c, Capabilities: {TXT: true, SMX: true, BootGuard: true}}
+  - {Name: "Haswell-ULT", Family: 6, Model: 0x45, Capabilities: {TXT: true, SMX: true, BootGuard: true}}
+  - {Name: "Haswell-GT3e", Family: 6, Model: 0x46, Capabilities: {TXT: true, SMX: true, BootGuard: true}}
+  - {Name: "Haswell-EP/EX", Family: 6, Model: 0x3f, Capabilities: {TXT: true, SMX: true}}
+  - {Name: "Broadwell", Family: 6, Model: 0x3d, Capabilities: {TXT: true, SMX: true, BootGuard: true}}
+  - {Name: "Broadwell-H", Family: 6, Model: 0x47, Capabilities: {TXT: true, SMX: true, BootGuard: true}}
+  - {Name: "Broadwell-EP/EX", Family: 6, Model: 0x4f, Capabilities: {TXT: true, SMX: true}}
+  - {Name: "Broadwell-DE", Family: 6, Model: 0x56, Capabilities: {TXT: true, SMX: true}}
+  # Skylake and derivatives
+  - {Name: "Skylake-U/Y", Family: 6, Model: 0x4e, Capabilities: {TXT: true, SMX: true, BootGuard: true}}
+  - {Name: "Skylake-S/H", Family: 6, Model: 0x5e, Capabilities: {TXT: true, SMX: true, BootGuard: true}}
+  - {Name: "Skylake-SP", Family: 6, Model: 0x55, Steppings: [0, 1, 2, 3, 4], Capabilities: {TXT: true, SMX: true, BootGuard: true}}
+  - {Name: "Cascade Lake-SP", Family: 6, Model: 0x55, Steppings: [5, 6, 7], Capabilities: {TXT: true, SMX: true, BootGuard: true}}
+  - {Name: "Cooper Lake-SP", Family: 6, Model: 0x55, Steppings: [10, 11], Capabilities: {TXT: true, SMX: true, BootGuard: true, CBnT: true}}
+  - {Name: "Kaby Lake/Whiskey Lake/Comet Lake-U", Family: 6, Model: 0x8e, Capabilities: {TXT: true, SMX: true, BootGuard: true}}
+  - {Name: "Kaby Lake/Coffee Lake", Family: 6, Model: 0x9e, Capabilities: {TXT: true, SMX: true, BootGuard: true}}
+  - {Name: "Comet Lake", Family: 6, Model: 0xa5, Capabilities: {TXT: true, SMX: true, BootGuard: true}}
+  - {Name: "Comet Lake-U", Family: 6, Model: 0xa6, Capabilities: {TXT: true, SMX: true, BootGuard: true}}
+  # Sunny Cove and later
+  - {Name: "Ice Lake", Family: 6, Model: 0x7e, Capabilities: {TXT: true, SMX: true, BootGuard: true}}
+  - {Name: "Ice Lake-SP", Family: 6, Model: 0x6a, Capabilities: {TXT: true, SMX: true, BootGuard: true, CBnT: true}}
+  - {Name: "Ice Lake-D", Family: 6, Model: 0x6c, Capabilities: {TXT: true, SMX: true, BootGuard: true, CBnT: true}}
+  - {Name: "Tiger Lake-U", Family: 6, Model: 0x8c, Capabilities: {TXT: true, SMX: true, BootGuard: true, CBnT: true}}
+  - {Name: "Tiger Lake-H", Family: 6, Model: 0x8d, Capabilities: {TXT: true, SMX: true, BootGuard: true, CBnT: true}}
+  - {Name: "Rocket Lake", Family: 6, Model: 0xa7, Capabilities: {TXT: true, SMX: true, BootGuard: true, CBnT: true}}
+  - {Name: "Alder Lake-S", Family: 6, Model: 0x97, Capabilities: {TXT: true, SMX: true, BootGuard: true, CBnT: true}}
+  - {Name: "Alder Lake-P", Family: 6, Model: 0x9a, Capabilities: {TXT: true, SMX: true, BootGuard: true, CBnT: true}}
+  - {Name: "Sapphire Rapids", Family: 6, Model: 0x8f, Capabilities: {TXT: true, SMX: true, BootGuard: true, CBnT: true}}
+`
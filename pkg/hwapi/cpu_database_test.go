@@ -0,0 +1,90 @@
+package hwapi
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCPUIDFamilyModelStepping(t *testing.T) {
+	family, model, stepping := CPUIDFamilyModelStepping(0x906ea)
+	require.Equal(t, []uint32{6, 0x9e, 0xa}, []uint32{family, model, stepping})
+	family, model, stepping = CPUIDFamilyModelStepping(0x00a20f10)
+	require.Equal(t, []uint32{0x19, 0x21, 0}, []uint32{family, model, stepping})
+}
+
+func TestDefaultCPUDatabase(t *testing.T) {
+	db := DefaultCPUDatabase()
+	require.Equal(t, CPUDatabaseFormatVersion, db.Version)
+
+	coffeeLake := db.Lookup(0x906ea, 0)
+	require.NotNil(t, coffeeLake)
+	require.True(t, coffeeLake.Capabilities.TXT)
+	require.True(t, coffeeLake.Capabilities.BootGuard)
+	require.False(t, coffeeLake.Capabilities.CBnT)
+
+	cascadeLake := db.Lookup(0x50657, 0)
+	require.NotNil(t, cascadeLake)
+	require.Equal(t, "Cascade Lake-SP", cascadeLake.Name)
+	cooperLake := db.Lookup(0x5065b, 0)
+	require.NotNil(t, cooperLake)
+	require.True(t, cooperLake.Capabilities.CBnT)
+
+	apolloLake := db.Lookup(0x506c9, 0)
+	require.NotNil(t, apolloLake)
+	require.False(t, apolloLake.Capabilities.TXT)
+
+	require.Nil(t, db.Lookup(0x00a20f10, 0))
+}
+
+func TestLoadCPUDatabase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cpudb")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cpudb.json")
+	override := `{"Version": 1, "Revision": "test", "Processors": [
+		{"Name": "Coffee Lake (no TXT)", "Family": 6, "Model": 158, "PlatformIDs": [2],
+		 "Capabilities": {"BootGuard": true}}]}`
+	require.NoError(t, ioutil.WriteFile(path, []byte(override), 0600))
+
+	db, err := LoadCPUDatabase(path)
+	require.NoError(t, err)
+	require.Equal(t, "test", db.Revision)
+	require.Equal(t, "Coffee Lake (no TXT)", db.Lookup(0x906ea, 2<<50).Name)
+	require.Equal(t, "Kaby Lake/Coffee Lake", db.Lookup(0x906ea, 1<<50).Name)
+	require.NotNil(t, db.Lookup(0x806ec, 0))
+
+	for _, s := range []string{
+		`Version: 2`,
+		`{Version: 1, Processors: [{Family: 6, Model: 1}]}`,
+		`{Version: 1, Processors: [{Name: x, Model: 1}]}`,
+		`{Version: 1, Processors: [{Name: x, Family: 6, Model: 1, PlatformIDs: [8]}]}`,
+		`[`,
+	} {
+		require.NoError(t, ioutil.WriteFile(path, []byte(s), 0600))
+		_, err = LoadCPUDatabase(path)
+		require.Error(t, err, s)
+	}
+	_, err = LoadCPUDatabase(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+}
+
+func TestCPUDatabaseEntryMatchesACMProcessorID(t *testing.T) {
+	db := DefaultCPUDatabase()
+	coffeeLake := db.Lookup(0x906ea, 0)
+	require.True(t, coffeeLake.MatchesACMProcessorID(0x906e0, 0xfff3ff0, 0, 0))
+	require.True(t, coffeeLake.MatchesACMProcessorID(0x906eb, 0xfff3fff, 0, 0))
+	require.False(t, coffeeLake.MatchesACMProcessorID(0x806e0, 0xfff3ff0, 0, 0))
+
+	cooperLake := db.Lookup(0x5065b, 0)
+	require.True(t, cooperLake.MatchesACMProcessorID(0x5065a, 0xfff3fff, 0, 0))
+	require.False(t, cooperLake.MatchesACMProcessorID(0x50657, 0xfff3fff, 0, 0))
+
+	entry := CPUDatabaseEntry{Name: "x", Family: 6, Model: 0x9e, PlatformIDs: []uint8{1}}
+	require.True(t, entry.MatchesACMProcessorID(0x906e0, 0xfff3ff0, 1<<50, 7<<50))
+	require.False(t, entry.MatchesACMProcessorID(0x906e0, 0xfff3ff0, 2<<50, 7<<50))
+}
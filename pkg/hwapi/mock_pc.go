@@ -14,14 +14,6 @@ type pcmock struct {
 	ReadMemoryFunc func(uint64) byte
 }
 
-func (n pcmock) CPUBlacklistTXTSupport() bool {
-	return false
-}
-
-func (n pcmock) CPUWhitelistTXTSupport() bool {
-	return false
-}
-
 func (n pcmock) VersionString() string {
 	return ""
 }
@@ -0,0 +1,206 @@
+package hwapi
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/9elements/go-linux-lowlevel-hw/pkg/hwapi"
+	"github.com/digitalocean/go-smbios/smbios"
+)
+
+// Snapshot contains everything read through hwapi.LowLevelHardwareInterfaces
+// while running tests on a machine. It is created by a Recorder and could be
+// replayed on any other machine using NewReplayer.
+type Snapshot struct {
+	Hostname string
+	Time     time.Time
+
+	CPUID          CPUIDSnapshot
+	MSRs           map[int64][]uint64
+	Memory         []MemorySnapshot
+	MemoryErrors   []MemorySnapshot
+	PCIDevices     *PCIDevicesSnapshot
+	PCIConfigSpace []PCIConfigSnapshot
+	E820           map[string]E820Snapshot
+	IOAddresses    []IOAddressSnapshot
+	ACPITables     map[string]DataSnapshot
+	SMBIOS         map[uint8]SMBIOSSnapshot
+	TPM            *TPMSnapshot
+}
+
+// CPUIDSnapshot contains the CPUID based information of the CPU
+type CPUIDSnapshot struct {
+	VersionString      string
+	HasSMX             bool
+	HasVMX             bool
+	HasMTRR            bool
+	ProcessorBrandName string
+	CPUSignature       uint32
+	CPUSignatureFull   [4]uint32
+	CPULogCount        uint32
+}
+
+// DataSnapshot is the result of a read which returns a buffer
+type DataSnapshot struct {
+	Data  []byte `json:",omitempty"`
+	Error string `json:",omitempty"`
+}
+
+// MemorySnapshot is a captured range of the physical memory. If Error
+// is set, reading Size bytes at Address failed.
+type MemorySnapshot struct {
+	Address uint64
+	Size    uint64 `json:",omitempty"`
+	Data    []byte `json:",omitempty"`
+	Error   string `json:",omitempty"`
+}
+
+// PCIDevicesSnapshot contains the enumerated PCI devices
+type PCIDevicesSnapshot struct {
+	Devices []hwapi.PCIDevice
+	Error   string `json:",omitempty"`
+}
+
+// PCIConfigSnapshot is a captured range of the config space of a PCI device
+type PCIConfigSnapshot struct {
+	Bus      int
+	Device   int
+	Function int
+	Offset   int
+	Size     int
+	DataSnapshot
+}
+
+// E820Range is a single range of the e820 table
+type E820Range struct {
+	Start uint64
+	End   uint64
+}
+
+// E820Snapshot contains the e820 ranges matching a target
+type E820Snapshot struct {
+	Ranges []E820Range
+	Error  string `json:",omitempty"`
+}
+
+// IOAddressSnapshot is the result of a translation of an IO address
+type IOAddressSnapshot struct {
+	Address   uint64
+	Registers hwapi.VTdRegisters
+	Result    []uint64
+	Error     string `json:",omitempty"`
+}
+
+// SMBIOSSnapshot contains all SMBIOS structures of a type
+type SMBIOSSnapshot struct {
+	Structures []*smbios.Structure
+	Error      string `json:",omitempty"`
+}
+
+// TPMSnapshot contains the information read from the TPM
+type TPMSnapshot struct {
+	Version  hwapi.TPMVersion
+	Interf   hwapi.TPMInterface
+	SysPath  string
+	Error    string `json:",omitempty"`
+	NVLocked *NVLockedSnapshot
+	NVPublic map[uint32]DataSnapshot
+	NVValues []NVValueSnapshot
+	PCRs     map[uint32]DataSnapshot
+}
+
+// NVLockedSnapshot is the captured lock state of the TPM NVRAM
+type NVLockedSnapshot struct {
+	Locked bool
+	Error  string `json:",omitempty"`
+}
+
+// NVValueSnapshot is the captured content of a TPM NV index.
+// Passwords are not stored in the snapshot.
+type NVValueSnapshot struct {
+	Index     uint32
+	Size      uint32
+	OffHandle uint32
+	DataSnapshot
+}
+
+// LoadSnapshot reads a snapshot created by Snapshot.Save
+func LoadSnapshot(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress snapshot '%s': %w", path, err)
+	}
+	defer r.Close()
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("unable to parse snapshot '%s': %w", path, err)
+	}
+	return &s, nil
+}
+
+// Save writes the snapshot as gzip compressed JSON to path
+func (s *Snapshot) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := gzip.NewWriter(f)
+	if err := json.NewEncoder(w).Encode(s); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to encode snapshot: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readMemory returns the captured physical memory in range [addr; addr+size)
+func (s *Snapshot) readMemory(addr, size uint64) ([]byte, error) {
+	for _, m := range s.Memory {
+		if addr >= m.Address && addr+size <= m.Address+uint64(len(m.Data)) {
+			return m.Data[addr-m.Address : addr-m.Address+size], nil
+		}
+	}
+	for _, m := range s.MemoryErrors {
+		if addr == m.Address && size == m.Size {
+			return nil, fmt.Errorf("%s", m.Error)
+		}
+	}
+	return nil, fmt.Errorf("physical memory 0x%x-0x%x was not captured", addr, addr+size)
+}
+
+// mergeMemory sorts the captured memory ranges and merges overlapping
+// and adjacent ones
+func mergeMemory(mem []MemorySnapshot) []MemorySnapshot {
+	if len(mem) == 0 {
+		return mem
+	}
+	sort.SliceStable(mem, func(i, j int) bool {
+		return mem[i].Address < mem[j].Address
+	})
+	result := []MemorySnapshot{{Address: mem[0].Address, Data: append([]byte{}, mem[0].Data...)}}
+	for _, m := range mem[1:] {
+		last := &result[len(result)-1]
+		lastEnd := last.Address + uint64(len(last.Data))
+		if m.Address > lastEnd {
+			result = append(result, MemorySnapshot{Address: m.Address, Data: append([]byte{}, m.Data...)})
+			continue
+		}
+		end := m.Address + uint64(len(m.Data))
+		if end > lastEnd {
+			last.Data = append(last.Data, m.Data[lastEnd-m.Address:]...)
+		}
+	}
+	return result
+}
@@ -0,0 +1,327 @@
+package hwapi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/9elements/go-linux-lowlevel-hw/pkg/hwapi"
+	"github.com/digitalocean/go-smbios/smbios"
+)
+
+// Recorder implements hwapi.LowLevelHardwareInterfaces by forwarding all
+// calls to another implementation and recording the results into a Snapshot.
+type Recorder struct {
+	api      hwapi.LowLevelHardwareInterfaces
+	lock     sync.Mutex
+	snapshot Snapshot
+}
+
+// NewRecorder returns a Recorder capturing everything read through api
+func NewRecorder(api hwapi.LowLevelHardwareInterfaces) *Recorder {
+	hostname, _ := os.Hostname()
+	return &Recorder{
+		api: api,
+		snapshot: Snapshot{
+			Hostname:   hostname,
+			Time:       time.Now().UTC(),
+			MSRs:       map[int64][]uint64{},
+			E820:       map[string]E820Snapshot{},
+			ACPITables: map[string]DataSnapshot{},
+			SMBIOS:     map[uint8]SMBIOSSnapshot{},
+		},
+	}
+}
+
+// Snapshot returns everything recorded so far
+func (r *Recorder) Snapshot() *Snapshot {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.snapshot.Memory = mergeMemory(r.snapshot.Memory)
+	s := r.snapshot
+	return &s
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// VersionString implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) VersionString() string {
+	v := r.api.VersionString()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.snapshot.CPUID.VersionString = v
+	return v
+}
+
+// HasSMX implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) HasSMX() bool {
+	v := r.api.HasSMX()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.snapshot.CPUID.HasSMX = v
+	return v
+}
+
+// HasVMX implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) HasVMX() bool {
+	v := r.api.HasVMX()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.snapshot.CPUID.HasVMX = v
+	return v
+}
+
+// HasMTRR implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) HasMTRR() bool {
+	v := r.api.HasMTRR()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.snapshot.CPUID.HasMTRR = v
+	return v
+}
+
+// ProcessorBrandName implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) ProcessorBrandName() string {
+	v := r.api.ProcessorBrandName()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.snapshot.CPUID.ProcessorBrandName = v
+	return v
+}
+
+// CPUSignature implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) CPUSignature() uint32 {
+	v := r.api.CPUSignature()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.snapshot.CPUID.CPUSignature = v
+	return v
+}
+
+// CPUSignatureFull implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) CPUSignatureFull() (uint32, uint32, uint32, uint32) {
+	a, b, c, d := r.api.CPUSignatureFull()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.snapshot.CPUID.CPUSignatureFull = [4]uint32{a, b, c, d}
+	return a, b, c, d
+}
+
+// CPULogCount implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) CPULogCount() uint32 {
+	v := r.api.CPULogCount()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.snapshot.CPUID.CPULogCount = v
+	return v
+}
+
+// IterateOverE820Ranges implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) IterateOverE820Ranges(target string, callback func(start uint64, end uint64) bool) (bool, error) {
+	var e820 E820Snapshot
+	_, err := r.api.IterateOverE820Ranges(target, func(start uint64, end uint64) bool {
+		e820.Ranges = append(e820.Ranges, E820Range{Start: start, End: end})
+		return false
+	})
+	e820.Error = errorString(err)
+	r.lock.Lock()
+	r.snapshot.E820[target] = e820
+	r.lock.Unlock()
+	return e820.iterate(callback)
+}
+
+// LookupIOAddress implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) LookupIOAddress(addr uint64, regs hwapi.VTdRegisters) ([]uint64, error) {
+	v, err := r.api.LookupIOAddress(addr, regs)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.snapshot.IOAddresses = append(r.snapshot.IOAddresses, IOAddressSnapshot{
+		Address:   addr,
+		Registers: regs,
+		Result:    v,
+		Error:     errorString(err),
+	})
+	return v, err
+}
+
+// ReadMSR implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) ReadMSR(msr int64) []uint64 {
+	v := r.api.ReadMSR(msr)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.snapshot.MSRs[msr] = v
+	return v
+}
+
+// PCIEnumerateVisibleDevices implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) PCIEnumerateVisibleDevices(cb func(d hwapi.PCIDevice) (abort bool)) error {
+	var devices PCIDevicesSnapshot
+	err := r.api.PCIEnumerateVisibleDevices(func(d hwapi.PCIDevice) bool {
+		devices.Devices = append(devices.Devices, d)
+		return false
+	})
+	devices.Error = errorString(err)
+	r.lock.Lock()
+	r.snapshot.PCIDevices = &devices
+	r.lock.Unlock()
+	return devices.iterate(cb)
+}
+
+// PCIReadConfigSpace implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) PCIReadConfigSpace(d hwapi.PCIDevice, off int, len int) ([]byte, error) {
+	v, err := r.api.PCIReadConfigSpace(d, off, len)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.snapshot.PCIConfigSpace = append(r.snapshot.PCIConfigSpace, PCIConfigSnapshot{
+		Bus:          d.Bus,
+		Device:       d.Device,
+		Function:     d.Function,
+		Offset:       off,
+		Size:         len,
+		DataSnapshot: DataSnapshot{Data: append([]byte{}, v...), Error: errorString(err)},
+	})
+	return v, err
+}
+
+// PCIWriteConfigSpace implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) PCIWriteConfigSpace(d hwapi.PCIDevice, off int, val interface{}) error {
+	return r.api.PCIWriteConfigSpace(d, off, val)
+}
+
+func (r *Recorder) recordMemory(addr int64, size int, data []byte, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if err != nil {
+		r.snapshot.MemoryErrors = append(r.snapshot.MemoryErrors, MemorySnapshot{
+			Address: uint64(addr),
+			Size:    uint64(size),
+			Error:   err.Error(),
+		})
+		return
+	}
+	r.snapshot.Memory = append(r.snapshot.Memory, MemorySnapshot{
+		Address: uint64(addr),
+		Data:    append([]byte{}, data...),
+	})
+}
+
+// ReadPhys implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) ReadPhys(addr int64, data hwapi.UintN) error {
+	err := r.api.ReadPhys(addr, data)
+	var buf bytes.Buffer
+	if err == nil {
+		err = binary.Write(&buf, binary.LittleEndian, data)
+	}
+	r.recordMemory(addr, int(data.Size()), buf.Bytes(), err)
+	return err
+}
+
+// ReadPhysBuf implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) ReadPhysBuf(addr int64, buf []byte) error {
+	err := r.api.ReadPhysBuf(addr, buf)
+	r.recordMemory(addr, len(buf), buf, err)
+	return err
+}
+
+// WritePhys implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) WritePhys(addr int64, data hwapi.UintN) error {
+	return r.api.WritePhys(addr, data)
+}
+
+// NewTPM implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) NewTPM() (*hwapi.TPM, error) {
+	tpmCon, err := r.api.NewTPM()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	tpm := r.tpmSnapshot()
+	tpm.Error = errorString(err)
+	if err == nil {
+		tpm.Version = tpmCon.Version
+		tpm.Interf = tpmCon.Interf
+		tpm.SysPath = tpmCon.SysPath
+	}
+	return tpmCon, err
+}
+
+// tpmSnapshot returns the TPM part of the snapshot, the lock must be held
+func (r *Recorder) tpmSnapshot() *TPMSnapshot {
+	if r.snapshot.TPM == nil {
+		r.snapshot.TPM = &TPMSnapshot{
+			NVPublic: map[uint32]DataSnapshot{},
+			PCRs:     map[uint32]DataSnapshot{},
+		}
+	}
+	return r.snapshot.TPM
+}
+
+// NVLocked implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) NVLocked(tpmCon *hwapi.TPM) (bool, error) {
+	v, err := r.api.NVLocked(tpmCon)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.tpmSnapshot().NVLocked = &NVLockedSnapshot{Locked: v, Error: errorString(err)}
+	return v, err
+}
+
+// ReadNVPublic implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) ReadNVPublic(tpmCon *hwapi.TPM, index uint32) ([]byte, error) {
+	v, err := r.api.ReadNVPublic(tpmCon, index)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.tpmSnapshot().NVPublic[index] = DataSnapshot{Data: append([]byte{}, v...), Error: errorString(err)}
+	return v, err
+}
+
+// NVReadValue implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) NVReadValue(tpmCon *hwapi.TPM, index uint32, password string, size, offhandle uint32) ([]byte, error) {
+	v, err := r.api.NVReadValue(tpmCon, index, password, size, offhandle)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	tpm := r.tpmSnapshot()
+	tpm.NVValues = append(tpm.NVValues, NVValueSnapshot{
+		Index:        index,
+		Size:         size,
+		OffHandle:    offhandle,
+		DataSnapshot: DataSnapshot{Data: append([]byte{}, v...), Error: errorString(err)},
+	})
+	return v, err
+}
+
+// ReadPCR implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) ReadPCR(tpmCon *hwapi.TPM, pcr uint32) ([]byte, error) {
+	v, err := r.api.ReadPCR(tpmCon, pcr)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.tpmSnapshot().PCRs[pcr] = DataSnapshot{Data: append([]byte{}, v...), Error: errorString(err)}
+	return v, err
+}
+
+// GetACPITable implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) GetACPITable(n string) ([]byte, error) {
+	v, err := r.api.GetACPITable(n)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.snapshot.ACPITables[n] = DataSnapshot{Data: append([]byte{}, v...), Error: errorString(err)}
+	return v, err
+}
+
+// IterateOverSMBIOSTables implements hwapi.LowLevelHardwareInterfaces
+func (r *Recorder) IterateOverSMBIOSTables(n uint8, callback func(s *smbios.Structure) bool) (bool, error) {
+	var tables SMBIOSSnapshot
+	_, err := r.api.IterateOverSMBIOSTables(n, func(s *smbios.Structure) bool {
+		tables.Structures = append(tables.Structures, s)
+		return false
+	})
+	tables.Error = errorString(err)
+	r.lock.Lock()
+	r.snapshot.SMBIOS[n] = tables
+	r.lock.Unlock()
+	return tables.iterate(callback)
+}
@@ -0,0 +1,276 @@
+package hwapi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/9elements/go-linux-lowlevel-hw/pkg/hwapi"
+	"github.com/digitalocean/go-smbios/smbios"
+)
+
+type replayer struct {
+	snapshot *Snapshot
+}
+
+// NewReplayer returns hwapi.LowLevelHardwareInterfaces answering all
+// requests from the snapshot. Reading anything which was not captured
+// returns an error, writes are not supported.
+func NewReplayer(snapshot *Snapshot) hwapi.LowLevelHardwareInterfaces {
+	return replayer{snapshot: snapshot}
+}
+
+// snapshotError recreates an error captured in a snapshot
+func snapshotError(s string) error {
+	if s == "" {
+		return nil
+	}
+	return fmt.Errorf("%s", s)
+}
+
+func (e E820Snapshot) iterate(callback func(start uint64, end uint64) bool) (bool, error) {
+	for _, r := range e.Ranges {
+		if callback(r.Start, r.End) {
+			return true, nil
+		}
+	}
+	return false, snapshotError(e.Error)
+}
+
+func (p PCIDevicesSnapshot) iterate(cb func(d hwapi.PCIDevice) (abort bool)) error {
+	for _, d := range p.Devices {
+		if cb(d) {
+			break
+		}
+	}
+	return snapshotError(p.Error)
+}
+
+func (s SMBIOSSnapshot) iterate(callback func(s *smbios.Structure) bool) (bool, error) {
+	if s.Error != "" {
+		return false, snapshotError(s.Error)
+	}
+	for _, st := range s.Structures {
+		if callback(st) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (d DataSnapshot) result() ([]byte, error) {
+	if d.Error != "" {
+		return nil, snapshotError(d.Error)
+	}
+	return append([]byte{}, d.Data...), nil
+}
+
+func (n replayer) VersionString() string {
+	return n.snapshot.CPUID.VersionString
+}
+
+func (n replayer) HasSMX() bool {
+	return n.snapshot.CPUID.HasSMX
+}
+
+func (n replayer) HasVMX() bool {
+	return n.snapshot.CPUID.HasVMX
+}
+
+func (n replayer) HasMTRR() bool {
+	return n.snapshot.CPUID.HasMTRR
+}
+
+func (n replayer) ProcessorBrandName() string {
+	return n.snapshot.CPUID.ProcessorBrandName
+}
+
+func (n replayer) CPUSignature() uint32 {
+	return n.snapshot.CPUID.CPUSignature
+}
+
+func (n replayer) CPUSignatureFull() (uint32, uint32, uint32, uint32) {
+	s := n.snapshot.CPUID.CPUSignatureFull
+	return s[0], s[1], s[2], s[3]
+}
+
+func (n replayer) CPULogCount() uint32 {
+	return n.snapshot.CPUID.CPULogCount
+}
+
+func (n replayer) IterateOverE820Ranges(target string, callback func(start uint64, end uint64) bool) (bool, error) {
+	e820, ok := n.snapshot.E820[target]
+	if !ok {
+		return false, fmt.Errorf("e820 ranges of '%s' were not captured", target)
+	}
+	return e820.iterate(callback)
+}
+
+func (n replayer) LookupIOAddress(addr uint64, regs hwapi.VTdRegisters) ([]uint64, error) {
+	for _, io := range n.snapshot.IOAddresses {
+		if io.Address == addr && io.Registers == regs {
+			return io.Result, snapshotError(io.Error)
+		}
+	}
+	return nil, fmt.Errorf("IO address 0x%x was not captured", addr)
+}
+
+// ReadMSR returns no values for MSRs which were not captured,
+// as hwapi.HwAPI does for MSRs it could not read.
+func (n replayer) ReadMSR(msr int64) []uint64 {
+	return n.snapshot.MSRs[msr]
+}
+
+func (n replayer) PCIEnumerateVisibleDevices(cb func(d hwapi.PCIDevice) (abort bool)) error {
+	if n.snapshot.PCIDevices == nil {
+		return fmt.Errorf("PCI devices were not captured")
+	}
+	return n.snapshot.PCIDevices.iterate(cb)
+}
+
+func (n replayer) PCIReadConfigSpace(d hwapi.PCIDevice, off int, len int) ([]byte, error) {
+	for _, c := range n.snapshot.PCIConfigSpace {
+		if c.Bus != d.Bus || c.Device != d.Device || c.Function != d.Function {
+			continue
+		}
+		if c.Error != "" {
+			if c.Offset == off && c.Size == len {
+				return nil, snapshotError(c.Error)
+			}
+			continue
+		}
+		if off >= c.Offset && off+len <= c.Offset+c.Size {
+			return append([]byte{}, c.Data[off-c.Offset:off-c.Offset+len]...), nil
+		}
+	}
+	return nil, fmt.Errorf("PCI config space 0x%x-0x%x of %02x:%02x.%x was not captured",
+		off, off+len, d.Bus, d.Device, d.Function)
+}
+
+func (n replayer) PCIWriteConfigSpace(d hwapi.PCIDevice, off int, val interface{}) error {
+	return fmt.Errorf("writing PCI config space is not supported on a snapshot")
+}
+
+func (n replayer) ReadPhys(addr int64, data hwapi.UintN) error {
+	buf, err := n.snapshot.readMemory(uint64(addr), uint64(data.Size()))
+	if err != nil {
+		return err
+	}
+	return binary.Read(bytes.NewReader(buf), binary.LittleEndian, data)
+}
+
+func (n replayer) ReadPhysBuf(addr int64, buf []byte) error {
+	data, err := n.snapshot.readMemory(uint64(addr), uint64(len(buf)))
+	if err != nil {
+		return err
+	}
+	copy(buf, data)
+	return nil
+}
+
+func (n replayer) WritePhys(addr int64, data hwapi.UintN) error {
+	return fmt.Errorf("writing physical memory is not supported on a snapshot")
+}
+
+// replayTPM is used as the connection of TPMs returned by the replayer,
+// TPM commands which are not handled by the hwapi calls can't be replayed.
+type replayTPM struct{}
+
+func (replayTPM) Read([]byte) (int, error) {
+	return 0, fmt.Errorf("TPM commands can't be replayed from a snapshot")
+}
+
+func (replayTPM) Write([]byte) (int, error) {
+	return 0, fmt.Errorf("TPM commands can't be replayed from a snapshot")
+}
+
+func (replayTPM) Close() error {
+	return nil
+}
+
+func (n replayer) tpm() (*TPMSnapshot, error) {
+	if n.snapshot.TPM == nil {
+		return nil, fmt.Errorf("TPM was not captured")
+	}
+	return n.snapshot.TPM, nil
+}
+
+func (n replayer) NewTPM() (*hwapi.TPM, error) {
+	tpm, err := n.tpm()
+	if err != nil {
+		return nil, err
+	}
+	if tpm.Error != "" {
+		return nil, snapshotError(tpm.Error)
+	}
+	return &hwapi.TPM{
+		Version: tpm.Version,
+		Interf:  tpm.Interf,
+		SysPath: tpm.SysPath,
+		RWC:     replayTPM{},
+	}, nil
+}
+
+func (n replayer) NVLocked(tpmCon *hwapi.TPM) (bool, error) {
+	tpm, err := n.tpm()
+	if err != nil {
+		return false, err
+	}
+	if tpm.NVLocked == nil {
+		return false, fmt.Errorf("TPM NVRAM lock state was not captured")
+	}
+	return tpm.NVLocked.Locked, snapshotError(tpm.NVLocked.Error)
+}
+
+func (n replayer) ReadNVPublic(tpmCon *hwapi.TPM, index uint32) ([]byte, error) {
+	tpm, err := n.tpm()
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := tpm.NVPublic[index]
+	if !ok {
+		return nil, fmt.Errorf("public area of NV index 0x%x was not captured", index)
+	}
+	return pub.result()
+}
+
+func (n replayer) NVReadValue(tpmCon *hwapi.TPM, index uint32, password string, size, offhandle uint32) ([]byte, error) {
+	tpm, err := n.tpm()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range tpm.NVValues {
+		if v.Index == index && v.Size == size && v.OffHandle == offhandle {
+			return v.result()
+		}
+	}
+	return nil, fmt.Errorf("content of NV index 0x%x was not captured", index)
+}
+
+func (n replayer) ReadPCR(tpmCon *hwapi.TPM, pcr uint32) ([]byte, error) {
+	tpm, err := n.tpm()
+	if err != nil {
+		return nil, err
+	}
+	v, ok := tpm.PCRs[pcr]
+	if !ok {
+		return nil, fmt.Errorf("PCR %d was not captured", pcr)
+	}
+	return v.result()
+}
+
+func (n replayer) GetACPITable(name string) ([]byte, error) {
+	table, ok := n.snapshot.ACPITables[name]
+	if !ok {
+		return nil, fmt.Errorf("ACPI table '%s' was not captured", name)
+	}
+	return table.result()
+}
+
+func (n replayer) IterateOverSMBIOSTables(t uint8, callback func(s *smbios.Structure) bool) (bool, error) {
+	tables, ok := n.snapshot.SMBIOS[t]
+	if !ok {
+		return false, fmt.Errorf("SMBIOS tables of type %d were not captured", t)
+	}
+	return tables.iterate(callback)
+}
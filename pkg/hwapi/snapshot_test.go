@@ -0,0 +1,75 @@
+package hwapi
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/9elements/go-linux-lowlevel-hw/pkg/hwapi"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotRecordAndReplay(t *testing.T) {
+	recorder := NewRecorder(GetPcMock(MockPCReadMemory))
+
+	txtSpace := make([]byte, 0x100)
+	require.NoError(t, recorder.ReadPhysBuf(0xFED30000, txtSpace))
+	var didVID hwapi.Uint32
+	require.NoError(t, recorder.ReadPhys(0xFED30110, &didVID))
+	_, acpiErr := recorder.GetACPITable("DMAR")
+	require.Error(t, acpiErr)
+	_, tpmErr := recorder.NewTPM()
+	require.Error(t, tpmErr)
+	_, e820Err := recorder.IterateOverE820Ranges("reserved", func(start, end uint64) bool { return false })
+	require.Error(t, e820Err)
+
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snapshot.json.gz")
+	require.NoError(t, recorder.Snapshot().Save(path))
+
+	snapshot, err := LoadSnapshot(path)
+	require.NoError(t, err)
+	replayer := NewReplayer(snapshot)
+
+	replayedTXTSpace := make([]byte, len(txtSpace))
+	require.NoError(t, replayer.ReadPhysBuf(0xFED30000, replayedTXTSpace))
+	require.Equal(t, txtSpace, replayedTXTSpace)
+
+	var replayedDIDVID hwapi.Uint32
+	require.NoError(t, replayer.ReadPhys(0xFED30110, &replayedDIDVID))
+	require.Equal(t, didVID, replayedDIDVID)
+
+	// reads within a captured range are served as well
+	var byte8 hwapi.Uint8
+	require.NoError(t, replayer.ReadPhys(0xFED30008, &byte8))
+	require.Equal(t, hwapi.Uint8(txtSpace[8]), byte8)
+
+	_, err = replayer.GetACPITable("DMAR")
+	require.EqualError(t, err, acpiErr.Error())
+	_, err = replayer.NewTPM()
+	require.EqualError(t, err, tpmErr.Error())
+	_, err = replayer.IterateOverE820Ranges("reserved", func(start, end uint64) bool { return false })
+	require.EqualError(t, err, e820Err.Error())
+
+	// everything not captured fails
+	require.Error(t, replayer.ReadPhysBuf(0xFED20000, make([]byte, 4)))
+	_, err = replayer.GetACPITable("MADT")
+	require.Error(t, err)
+	require.Empty(t, replayer.ReadMSR(0x3a))
+}
+
+func TestMergeMemory(t *testing.T) {
+	merged := mergeMemory([]MemorySnapshot{
+		{Address: 0x14, Data: []byte{5, 6}},
+		{Address: 0x10, Data: []byte{1, 2, 3}},
+		{Address: 0x12, Data: []byte{3, 4}},
+		{Address: 0x20, Data: []byte{9}},
+	})
+	require.Equal(t, []MemorySnapshot{
+		{Address: 0x10, Data: []byte{1, 2, 3, 4, 5, 6}},
+		{Address: 0x20, Data: []byte{9}},
+	}, merged)
+}
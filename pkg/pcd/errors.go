@@ -7,10 +7,12 @@ import (
 
 // ErrTooManyTCGPEIModules means there's more than one TCG PEI module, which is
 // not supported (an unknown case).
-type ErrTooManyTCGPEIModules struct{}
+type ErrTooManyTCGPEIModules struct {
+	Vendor string
+}
 
 func (err *ErrTooManyTCGPEIModules) Error() string {
-	return `[HP signed file] too many TCG PEI modules`
+	return fmt.Sprintf(`[%s] too many TCG PEI modules`, err.Vendor)
 }
 
 // ErrTcgPieUnableToFindPcdFirmwareVendorStart means it was unable to find
@@ -31,18 +33,33 @@ func (err *ErrHPPcdFirmwareVendorEndNotFound) Error() string {
 
 // ErrInvalidTCGPEIModule means it was unable to parse the TCG PEI module volume
 // (it appears to be of unexpected/unknown type).
-type ErrInvalidTCGPEIModule struct{}
+type ErrInvalidTCGPEIModule struct {
+	Vendor string
+}
 
 func (err *ErrInvalidTCGPEIModule) Error() string {
-	return `[HP signed file] invalid TcgPei module`
+	return fmt.Sprintf(`[%s] invalid TcgPei module`, err.Vendor)
 }
 
 // ErrTcgPiePEFileNotFound means it was unable to find the beginning of the
 // PE file (which contains the PCD firmware vendor value).
-type ErrTcgPiePEFileNotFound struct{}
+type ErrTcgPiePEFileNotFound struct {
+	Vendor string
+}
 
 func (err *ErrTcgPiePEFileNotFound) Error() string {
-	return `[HP signed file] PE32 file not found`
+	return fmt.Sprintf(`[%s] PE32 file not found`, err.Vendor)
+}
+
+// ErrTcgPiePcdFirmwareVersionNotFound means the PCD firmware vendor value
+// was found in the TCG PEI module, but no valid PCD firmware version string
+// follows it.
+type ErrTcgPiePcdFirmwareVersionNotFound struct {
+	Vendor string
+}
+
+func (err *ErrTcgPiePcdFirmwareVersionNotFound) Error() string {
+	return fmt.Sprintf(`[%s] pcdFirmwareVersionString not found after pcdFirmwareVendor`, err.Vendor)
 }
 
 // ErrDoesNotMatch means a value was received with multiple ways, but the
@@ -1,6 +1,8 @@
 package pcd
 
 import (
+	"sync"
+
 	"github.com/linuxboot/fiano/pkg/guid"
 
 	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
@@ -71,12 +73,21 @@ type FirmwareImage = *uefi.UEFI
 
 // ParseFirmware extracts PCD values from an UEFI firmware.
 //
+// The parsers registered by RegisterFirmwareParser are tried first (in
+// the order of registration), then the built-in parsers.
+//
 // If "pcd" is not nil and "err" is not nil, then the value was
 // successfully parsed, but there was detected a problem (warning).
 // If "pcd" is nil, but "err" is not nil, then there occurred an
 // error while parsing the value.
 func ParseFirmware(firmwareImage FirmwareImage) (pcd ParsedFirmware, err error) {
-	for _, parserFunc := range firmwareParsers {
+	firmwareParsersLocker.RLock()
+	parsers := make([]FirmwareParser, 0, len(customFirmwareParsers)+len(firmwareParsers))
+	parsers = append(parsers, customFirmwareParsers...)
+	parsers = append(parsers, firmwareParsers...)
+	firmwareParsersLocker.RUnlock()
+
+	for _, parserFunc := range parsers {
 		pcd, err = parserFunc(firmwareImage)
 		if pcd != nil || err != nil {
 			return
@@ -86,24 +97,46 @@ func ParseFirmware(firmwareImage FirmwareImage) (pcd ParsedFirmware, err error)
 	return nil, &ErrUnknownVendorType{}
 }
 
-// firmwareParser is a function to extract PCD values from a firmware image
+// FirmwareParser is a function to extract PCD values from a firmware image
 // And in this sense UEFI images of different vendors are parsed differently.
 // So we have multiple parser-functions, each for each vendor.
 // If the a parser-function does not recognize the UEFI image as the image
 // of it's vendor, then it just returns nil as both values
 // (Interfaces and error).
 //
-// See also "firmwareParsers", "addFirmwareParser()" and
-// "parseFirmware()".
-type firmwareParser func(firmwareImage FirmwareImage) (ParsedFirmware, error)
+// See also "RegisterFirmwareParser" and "ParseFirmware()".
+type FirmwareParser func(firmwareImage FirmwareImage) (ParsedFirmware, error)
 
-// firmwareParsers contains the parsers for known image formats.
-// This slice is filled by init() functions of parse_firmware_*.go.
-var firmwareParsers []firmwareParser
+var (
+	firmwareParsersLocker sync.RWMutex
+
+	// firmwareParsers contains the parsers for known image formats.
+	// This slice is filled by init() functions of parse_firmware_*.go,
+	// so the parsers are tried in the order of the file names.
+	firmwareParsers []FirmwareParser
+
+	// customFirmwareParsers contains the parsers added by
+	// RegisterFirmwareParser.
+	customFirmwareParsers []FirmwareParser
+)
 
 // addFirmwareParser is the function to add
-// an additional firmwareParser for an UEFI image of
+// an additional FirmwareParser for an UEFI image of
 // an additional vendor (see parse_firmware_*.go files).
-func addFirmwareParser(parserFunc firmwareParser) {
+func addFirmwareParser(parserFunc FirmwareParser) {
+	firmwareParsersLocker.Lock()
+	defer firmwareParsersLocker.Unlock()
 	firmwareParsers = append(firmwareParsers, parserFunc)
 }
+
+// RegisterFirmwareParser adds a parser for UEFI images of an additional
+// vendor (for example an in-house firmware). The registered parsers are
+// tried by ParseFirmware before the built-in ones, so they could also
+// override a built-in parser for images they recognize.
+//
+// See also NewTCGPEIModuleParser.
+func RegisterFirmwareParser(parserFunc FirmwareParser) {
+	firmwareParsersLocker.Lock()
+	defer firmwareParsersLocker.Unlock()
+	customFirmwareParsers = append(customFirmwareParsers, parserFunc)
+}
@@ -17,6 +17,14 @@ var (
 	ocpVendorVersion = unhex("1EFB6B540C1D5540A4AD4EF4BF17B83A")
 )
 
+// KnownFirmwareVendorVersions returns the hardcoded firmware vendor version
+// values, which are used if the real value could not be found in an image.
+func KnownFirmwareVendorVersions() [][]byte {
+	v := make([]byte, len(ocpVendorVersion))
+	copy(v, ocpVendorVersion)
+	return [][]byte{v}
+}
+
 func unhex(in string) []byte {
 	out, err := hex.DecodeString(in)
 	if err != nil {
@@ -0,0 +1,246 @@
+package pcd
+
+import (
+	"bytes"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/pcd/helpers"
+	ffsConsts "github.com/9elements/converged-security-suite/v2/pkg/uefi/ffs/consts"
+	pkgbytes "github.com/linuxboot/fiano/pkg/bytes"
+	"github.com/linuxboot/fiano/pkg/guid"
+	"github.com/linuxboot/fiano/pkg/uefi"
+)
+
+func init() {
+	addFirmwareParser(ParseFirmwareAMIAptioV)
+	addFirmwareParser(ParseFirmwareEDK2)
+}
+
+// maxPcdFirmwareVersionPadding is the maximal amount of NUL bytes between
+// the PCD firmware vendor and the PCD firmware version string.
+const maxPcdFirmwareVersionPadding = 0x100
+
+// TCGPEIModuleLayout describes where a vendor stores the PCD values within
+// its TCG PEI module.
+//
+// PcdFirmwareVendor and PcdFirmwareVersionString are expected as
+// NUL-terminated UCS-2 strings in the PE32 image of the module, with the
+// version string following the vendor (optionally padded by NUL characters),
+// as it is laid out by the PCD string table of EDK2 based firmwares. The
+// version string including the terminator is what is measured as the
+// S-CRTM version.
+type TCGPEIModuleLayout struct {
+	// Vendor is the name of the firmware vendor, used in errors.
+	Vendor string
+
+	// ModuleGUIDs are the GUIDs of the TCG PEI modules of the vendor.
+	// If multiple modules are found, then the first GUID wins.
+	ModuleGUIDs []guid.GUID
+
+	// FirmwareVendorPrefixes are the beginnings of the PcdFirmwareVendor
+	// values which identify the vendor. A prefix ending with "\x00" has
+	// to match the whole value.
+	FirmwareVendorPrefixes []string
+}
+
+var (
+	// tcgPEIModuleLayoutAMIAptioV does not include AmiTcgPlatformPeiAfterMem,
+	// because images with this module are handled by ParseFirmwareOCP.
+	tcgPEIModuleLayoutAMIAptioV = TCGPEIModuleLayout{
+		Vendor: "AMI Aptio V",
+		ModuleGUIDs: []guid.GUID{
+			ffsConsts.GUIDAmiTpm20PlatformPei,
+			ffsConsts.GUIDModuleTcg2Pie,
+			ffsConsts.GUIDModuleTcgPie,
+		},
+		FirmwareVendorPrefixes: []string{"American Megatrends"},
+	}
+
+	// tcgPEIModuleLayoutEDK2 covers the firmwares which keep the PCD values
+	// in the EDK2 Tcg2Pei/TcgPei modules; they differ only by the value of
+	// PcdFirmwareVendor (see ParsedFirmwareTCGPEIModule.GetFirmwareVendor).
+	tcgPEIModuleLayoutEDK2 = TCGPEIModuleLayout{
+		Vendor:      "EDK2",
+		ModuleGUIDs: []guid.GUID{ffsConsts.GUIDModuleTcg2Pie, ffsConsts.GUIDModuleTcgPie},
+		FirmwareVendorPrefixes: []string{
+			"Insyde", "INSYDE",
+			"Phoenix",
+			"HP\x00", "Hewlett-Packard",
+		},
+	}
+)
+
+// ParseFirmwareAMIAptioV is a variant of ParseFirmware for AMI Aptio V firmwares
+func ParseFirmwareAMIAptioV(firmwareImage FirmwareImage) (ParsedFirmware, error) {
+	return parseFirmwareTCGPEIModule(firmwareImage, &tcgPEIModuleLayoutAMIAptioV)
+}
+
+// ParseFirmwareEDK2 is a variant of ParseFirmware for EDK2 based firmwares
+// (Insyde H2O, Phoenix and HP)
+func ParseFirmwareEDK2(firmwareImage FirmwareImage) (ParsedFirmware, error) {
+	return parseFirmwareTCGPEIModule(firmwareImage, &tcgPEIModuleLayoutEDK2)
+}
+
+// NewTCGPEIModuleParser returns a FirmwareParser for firmwares of a vendor
+// storing the PCD values the same way as the built-in vendor parsers do
+// (see TCGPEIModuleLayout). It could be registered by RegisterFirmwareParser.
+func NewTCGPEIModuleParser(layout TCGPEIModuleLayout) FirmwareParser {
+	return func(firmwareImage FirmwareImage) (ParsedFirmware, error) {
+		return parseFirmwareTCGPEIModule(firmwareImage, &layout)
+	}
+}
+
+// ParsedFirmwareTCGPEIModule is the result of a parser based on
+// TCGPEIModuleLayout.
+type ParsedFirmwareTCGPEIModule struct {
+	ParsedFirmwareGeneric
+
+	// Vendor is the name of the TCGPEIModuleLayout which recognized the image.
+	Vendor string
+
+	// FirmwareVendorRanges defines where the PCD firmware vendor value
+	// (including the terminator) is stored.
+	FirmwareVendorRanges pkgbytes.Ranges
+}
+
+// GetFirmwareVendor returns the PCD firmware vendor value as an UCS-2 string
+// including the terminator.
+func (pcd *ParsedFirmwareTCGPEIModule) GetFirmwareVendor() []byte {
+	return pcd.FirmwareVendorRanges.Compile(pcd.FirmwareImage.ImageBytes())
+}
+
+func parseFirmwareTCGPEIModule(
+	firmwareImage FirmwareImage,
+	layout *TCGPEIModuleLayout,
+) (ParsedFirmware, error) {
+	for _, moduleGUID := range layout.ModuleGUIDs {
+		nodes, err := firmwareImage.GetByGUID(moduleGUID)
+		if err != nil {
+			return nil, err
+		}
+
+		var result *ParsedFirmwareTCGPEIModule
+		for _, node := range nodes {
+			if _, ok := node.Firmware.(*uefi.File); !ok {
+				continue
+			}
+			moduleBytes := node.Buf()
+			vendorRange, versionRange, err := layout.findPCDValues(moduleBytes)
+			if err != nil {
+				return nil, err
+			}
+			if vendorRange == nil {
+				continue
+			}
+			if result != nil {
+				return nil, &ErrTooManyTCGPEIModules{Vendor: layout.Vendor}
+			}
+			vendorRange.Offset += node.Offset
+			versionRange.Offset += node.Offset
+			result = &ParsedFirmwareTCGPEIModule{
+				ParsedFirmwareGeneric: ParsedFirmwareGeneric{
+					FirmwareImage: firmwareImage,
+					FirmwareVendorVersionCodeRanges: pkgbytes.Ranges{{
+						Offset: node.Offset,
+						Length: uint64(len(moduleBytes)),
+					}},
+					FirmwareVendorVersionRanges:  pkgbytes.Ranges{*versionRange},
+					FirmwareVendorVersionFFSGUID: moduleGUID,
+				},
+				Vendor:               layout.Vendor,
+				FirmwareVendorRanges: pkgbytes.Ranges{*vendorRange},
+			}
+		}
+		if result != nil {
+			return result, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// findPCDValues returns the ranges of the PCD firmware vendor and the PCD
+// firmware version string within the module. It returns nil ranges and no
+// error if the module does not belong to the vendor.
+func (layout *TCGPEIModuleLayout) findPCDValues(moduleBytes []byte) (*pkgbytes.Range, *pkgbytes.Range, error) {
+	vendorStart, vendorEnd := -1, -1
+	for _, prefix := range layout.FirmwareVendorPrefixes {
+		vendorStart, vendorEnd = findUCS2String(moduleBytes, prefix)
+		if vendorStart >= 0 {
+			break
+		}
+	}
+	if vendorStart < 0 {
+		return nil, nil, nil
+	}
+
+	peStart := helpers.FindPEFileStart(moduleBytes)
+	if peStart < 0 || peStart > vendorStart {
+		return nil, nil, &ErrTcgPiePEFileNotFound{Vendor: layout.Vendor}
+	}
+
+	versionStart := vendorEnd
+	for versionStart+1 < len(moduleBytes) && versionStart-vendorEnd < maxPcdFirmwareVersionPadding {
+		if moduleBytes[versionStart] != 0 || moduleBytes[versionStart+1] != 0 {
+			break
+		}
+		versionStart += 2
+	}
+	versionEnd := ucs2StringEnd(moduleBytes, versionStart)
+	if versionEnd < 0 || versionEnd-versionStart < 4 {
+		return nil, nil, &ErrTcgPiePcdFirmwareVersionNotFound{Vendor: layout.Vendor}
+	}
+
+	return &pkgbytes.Range{
+		Offset: uint64(vendorStart),
+		Length: uint64(vendorEnd - vendorStart),
+	}, &pkgbytes.Range{
+		Offset: uint64(versionStart),
+		Length: uint64(versionEnd - versionStart),
+	}, nil
+}
+
+// findUCS2String returns the beginning and the end (after the terminator)
+// of the first UCS-2 string starting with the prefix. The string has to be
+// aligned to 2 bytes and be preceded by a terminator (or the beginning of
+// the data). Returns -1 as both values if it was not found.
+func findUCS2String(data []byte, prefix string) (int, int) {
+	encodedPrefix := make([]byte, 0, len(prefix)*2)
+	for _, c := range []byte(prefix) {
+		encodedPrefix = append(encodedPrefix, c, 0)
+	}
+
+	for offset := 0; offset < len(data); {
+		idx := bytes.Index(data[offset:], encodedPrefix)
+		if idx < 0 {
+			break
+		}
+		start := offset + idx
+		offset = start + 1
+		if start%2 != 0 {
+			continue
+		}
+		if start > 0 && (data[start-2] != 0 || data[start-1] != 0) {
+			continue
+		}
+		if end := ucs2StringEnd(data, start); end >= 0 {
+			return start, end
+		}
+	}
+	return -1, -1
+}
+
+// ucs2StringEnd returns the end (after the terminator) of an UCS-2 string
+// consisting of printable ASCII characters, or -1 if there is no such string
+// at the offset.
+func ucs2StringEnd(data []byte, offset int) int {
+	for idx := offset; idx+1 < len(data); idx += 2 {
+		c, high := data[idx], data[idx+1]
+		if c == 0 && high == 0 {
+			return idx + 2
+		}
+		if high != 0 || c < 0x20 || c > 0x7e {
+			return -1
+		}
+	}
+	return -1
+}
@@ -0,0 +1,167 @@
+package pcd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+	ffsConsts "github.com/9elements/converged-security-suite/v2/pkg/uefi/ffs/consts"
+	"github.com/linuxboot/fiano/pkg/guid"
+	fianoUEFI "github.com/linuxboot/fiano/pkg/uefi"
+	"github.com/stretchr/testify/require"
+)
+
+func ucs2(s string) []byte {
+	var result []byte
+	for _, c := range []byte(s) {
+		result = append(result, c, 0)
+	}
+	return append(result, 0, 0)
+}
+
+// testPEIM returns a PE32 image with the PCD firmware vendor and version
+// strings in its data.
+func testPEIM(vendor, version string) []byte {
+	pe := make([]byte, 0x200)
+	copy(pe, "MZ")
+	pe = append(pe, ucs2("Tcg2Pei")...)
+	pe = append(pe, ucs2(vendor)...)
+	pe = append(pe, make([]byte, 6)...)
+	pe = append(pe, ucs2(version)...)
+	return append(pe, 0xde, 0xad, 0xbe, 0xef)
+}
+
+// testFirmwareImage builds a firmware volume containing a PEIM for each
+// of the modules.
+func testFirmwareImage(t *testing.T, modules map[guid.GUID][]byte) FirmwareImage {
+	const fvSize = 0x10000
+
+	var files []byte
+	for moduleGUID, pe := range modules {
+		for len(files)%8 != 0 {
+			files = append(files, 0xff)
+		}
+		section := make([]byte, 4, 4+len(pe))
+		binary.LittleEndian.PutUint32(section, uint32(4+len(pe)))
+		section[3] = 0x10 // EFI_SECTION_PE32
+		section = append(section, pe...)
+
+		header := make([]byte, 24)
+		copy(header, moduleGUID[:])
+		header[18] = byte(fianoUEFI.FVFileTypePEIM)
+		fileSize := uint32(len(header) + len(section))
+		header[20], header[21], header[22] = byte(fileSize), byte(fileSize>>8), byte(fileSize>>16)
+		header[16] = 0 - fianoUEFI.Checksum8(header)
+		header[17] = fianoUEFI.EmptyBodyChecksum
+		header[23] = 0xf8 // EFI_FILE_DATA_VALID with erase polarity 1
+		files = append(files, header...)
+		files = append(files, section...)
+	}
+
+	fv := make([]byte, 0x48)
+	copy(fv[0x10:], fianoUEFI.FFS2[:])
+	binary.LittleEndian.PutUint64(fv[0x20:], fvSize)
+	copy(fv[0x28:], "_FVH")
+	binary.LittleEndian.PutUint32(fv[0x2c:], 0x0004feff)
+	binary.LittleEndian.PutUint16(fv[0x30:], 0x48)
+	fv[0x37] = 2
+	binary.LittleEndian.PutUint32(fv[0x38:], fvSize/0x1000)
+	binary.LittleEndian.PutUint32(fv[0x3c:], 0x1000)
+	checksum, err := fianoUEFI.Checksum16(fv)
+	require.NoError(t, err)
+	binary.LittleEndian.PutUint16(fv[0x32:], 0-checksum)
+	fv = append(fv, files...)
+	fv = append(fv, bytes.Repeat([]byte{0xff}, fvSize-len(fv))...)
+
+	image, err := uefi.ParseUEFIFirmwareBytes(fv)
+	require.NoError(t, err)
+	return image
+}
+
+func TestParseFirmwareTCGPEIModule(t *testing.T) {
+	for vendor, expected := range map[string]string{
+		"American Megatrends":  "AMI Aptio V",
+		"INSYDE Corp.":         "EDK2",
+		"Phoenix Technologies": "EDK2",
+		"HP":                   "EDK2",
+	} {
+		image := testFirmwareImage(t, map[guid.GUID][]byte{
+			ffsConsts.GUIDModuleTcg2Pie: testPEIM(vendor, "1.2.3"),
+		})
+		parsed, err := ParseFirmware(image)
+		require.NoError(t, err, vendor)
+		require.Equal(t, ucs2("1.2.3"), parsed.GetFirmwareVendorVersion(), vendor)
+		require.Equal(t, ffsConsts.GUIDModuleTcg2Pie, parsed.GetFirmwareVendorVersionFFSGUID())
+		module := parsed.(*ParsedFirmwareTCGPEIModule)
+		require.Equal(t, expected, module.Vendor)
+		require.Equal(t, ucs2(vendor), module.GetFirmwareVendor())
+	}
+
+	t.Run("preferred_module", func(t *testing.T) {
+		image := testFirmwareImage(t, map[guid.GUID][]byte{
+			ffsConsts.GUIDModuleTcgPie:        testPEIM("American Megatrends", "TPM 1.2"),
+			ffsConsts.GUIDAmiTpm20PlatformPei: testPEIM("American Megatrends", "TPM 2.0"),
+		})
+		parsed, err := ParseFirmware(image)
+		require.NoError(t, err)
+		require.Equal(t, ucs2("TPM 2.0"), parsed.GetFirmwareVendorVersion())
+	})
+
+	t.Run("ocp", func(t *testing.T) {
+		image := testFirmwareImage(t, map[guid.GUID][]byte{
+			ffsConsts.GUIDAmiTcgPlatformPeiAfterMem: testPEIM("American Megatrends", "1.0"),
+		})
+		parsed, err := ParseFirmware(image)
+		require.NoError(t, err)
+		require.IsType(t, &ParsedFirmwareOCPGeneric{}, parsed)
+
+		parsed, err = ParseFirmwareAMIAptioV(image)
+		require.NoError(t, err)
+		require.Nil(t, parsed)
+	})
+
+	t.Run("unknown_vendor", func(t *testing.T) {
+		image := testFirmwareImage(t, map[guid.GUID][]byte{
+			ffsConsts.GUIDModuleTcg2Pie: testPEIM("HPE", "1.0"),
+		})
+		_, err := ParseFirmware(image)
+		require.IsType(t, &ErrUnknownVendorType{}, err)
+	})
+
+	t.Run("no_version", func(t *testing.T) {
+		image := testFirmwareImage(t, map[guid.GUID][]byte{
+			ffsConsts.GUIDModuleTcg2Pie: testPEIM("Phoenix", "\x01"),
+		})
+		_, err := ParseFirmware(image)
+		require.IsType(t, &ErrTcgPiePcdFirmwareVersionNotFound{}, err)
+	})
+
+	t.Run("no_pe", func(t *testing.T) {
+		pe := testPEIM("Phoenix", "1.0")
+		pe[0] = 0
+		image := testFirmwareImage(t, map[guid.GUID][]byte{ffsConsts.GUIDModuleTcg2Pie: pe})
+		_, err := ParseFirmware(image)
+		require.IsType(t, &ErrTcgPiePEFileNotFound{}, err)
+	})
+}
+
+func TestRegisterFirmwareParser(t *testing.T) {
+	defer func(parsers []FirmwareParser) {
+		customFirmwareParsers = parsers
+	}(customFirmwareParsers)
+
+	image := testFirmwareImage(t, map[guid.GUID][]byte{
+		ffsConsts.GUIDModuleTcg2Pie: testPEIM("HP", "1.0"),
+		ffsConsts.GUIDSignOn:        testPEIM("Example Corp.", "in-house"),
+	})
+	RegisterFirmwareParser(NewTCGPEIModuleParser(TCGPEIModuleLayout{
+		Vendor:                 "Example",
+		ModuleGUIDs:            []guid.GUID{ffsConsts.GUIDSignOn},
+		FirmwareVendorPrefixes: []string{"Example"},
+	}))
+	parsed, err := ParseFirmware(image)
+	require.NoError(t, err)
+	require.Equal(t, "Example", parsed.(*ParsedFirmwareTCGPEIModule).Vendor)
+	require.Equal(t, ucs2("in-house"), parsed.GetFirmwareVendorVersion())
+}
@@ -0,0 +1,141 @@
+package pcr
+
+import (
+	"fmt"
+	"hash"
+	"reflect"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/errors"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+)
+
+// PCRBankValue is a value of a PCR in a specific PCR bank.
+type PCRBankValue struct {
+	HashAlgo tpmeventlog.TPMAlgorithm
+	Value    []byte
+}
+
+// String implements fmt.Stringer.
+func (v PCRBankValue) String() string {
+	return fmt.Sprintf("%s:%X", tpmeventlog.HashAlgorithmName(v.HashAlgo), v.Value)
+}
+
+// PCRBankValues is a set of values of the same PCR in different PCR banks.
+type PCRBankValues []PCRBankValue
+
+// Find returns the value of the PCR bank of the specified hash algorithm,
+// or nil if there is no such bank.
+func (s PCRBankValues) Find(hashAlgo tpmeventlog.TPMAlgorithm) []byte {
+	for _, v := range s {
+		if v.HashAlgo == hashAlgo {
+			return v.Value
+		}
+	}
+	return nil
+}
+
+// CalculateBanks performs the calculation of the PCR value in PCR banks
+// `hashAlgos` in one pass: the measured data of each measurement is compiled
+// only once and then hashed by the hashers of all the banks.
+func (s Measurements) CalculateBanks(image []byte, initialValue uint8, hashAlgos ...tpmeventlog.TPMAlgorithm) (PCRBankValues, error) {
+	hashers := make([]hash.Hash, 0, len(hashAlgos))
+	result := make(PCRBankValues, 0, len(hashAlgos))
+	for _, hashAlgo := range hashAlgos {
+		hasher, err := tpmeventlog.NewHasher(hashAlgo)
+		if err != nil {
+			return nil, err
+		}
+		hashers = append(hashers, hasher)
+		value := make([]byte, hasher.Size())
+		value[len(value)-1] = initialValue
+		result = append(result, PCRBankValue{HashAlgo: hashAlgo, Value: value})
+	}
+
+	for _, m := range s {
+		if m == nil || m.IsFake() {
+			continue
+		}
+		data := m.CompileMeasurableData(image)
+		for idx, hasher := range hashers {
+			digest := data
+			if !m.NoHash() {
+				hasher.Write(data)
+				digest = hasher.Sum(nil)
+				hasher.Reset()
+			}
+
+			hasher.Write(result[idx].Value)
+			hasher.Write(digest)
+			result[idx].Value = hasher.Sum(nil)
+			hasher.Reset()
+		}
+	}
+
+	return result, nil
+}
+
+// CalculateExpectedBanks returns the expected value of PCR `pcrID` of
+// firmware `firmware` in PCR banks `hashAlgos`.
+//
+// Some measurements depend on the PCR bank (for example the IBB digest
+// in PCR0_DATA of CBnT), so the measurements are collected for each bank,
+// but the banks with the same measurements are calculated in one pass
+// (see Measurements.CalculateBanks). Similar to GetMeasurements it could
+// return (partial) values together with an error.
+func CalculateExpectedBanks(
+	firmware Firmware,
+	pcrID ID,
+	hashAlgos []tpmeventlog.TPMAlgorithm,
+	opts ...MeasureOption,
+) (PCRBankValues, Flow, error) {
+	type bankGroup struct {
+		Measurements Measurements
+		Flow         Flow
+		HashAlgos    []tpmeventlog.TPMAlgorithm
+	}
+
+	var groups []*bankGroup
+	var resultFlow Flow
+	mErr := &errors.MultiError{}
+	for _, hashAlgo := range hashAlgos {
+		bankOpts := append(append([]MeasureOption{}, opts...), SetIBBHashDigest(hashAlgo))
+		measurements, flow, _, err := GetMeasurements(firmware, pcrID, bankOpts...)
+		if err != nil {
+			_ = mErr.Add(fmt.Errorf("unable to get measurements for hash algorithm %s: %w", tpmeventlog.HashAlgorithmName(hashAlgo), err))
+		}
+		if measurements == nil {
+			return nil, flow, mErr.ReturnValue()
+		}
+		resultFlow = flow
+
+		var group *bankGroup
+		for _, g := range groups {
+			if g.Flow == flow && reflect.DeepEqual(g.Measurements, measurements) {
+				group = g
+				break
+			}
+		}
+		if group == nil {
+			group = &bankGroup{Measurements: measurements, Flow: flow}
+			groups = append(groups, group)
+		}
+		group.HashAlgos = append(group.HashAlgos, hashAlgo)
+	}
+
+	values := map[tpmeventlog.TPMAlgorithm][]byte{}
+	for _, group := range groups {
+		groupValues, err := group.Measurements.CalculateBanks(firmware.Buf(), group.Flow.InitialValue(pcrID), group.HashAlgos...)
+		if err != nil {
+			return nil, resultFlow, fmt.Errorf("unable to calculate PCR banks %v: %w", group.HashAlgos, err)
+		}
+		for _, v := range groupValues {
+			values[v.HashAlgo] = v.Value
+		}
+	}
+
+	result := make(PCRBankValues, 0, len(hashAlgos))
+	for _, hashAlgo := range hashAlgos {
+		result = append(result, PCRBankValue{HashAlgo: hashAlgo, Value: values[hashAlgo]})
+	}
+	return result, resultFlow, mErr.ReturnValue()
+}
@@ -0,0 +1,68 @@
+package pcr
+
+import (
+	"testing"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/registers"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+	"github.com/9elements/converged-security-suite/v2/testdata/firmware"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateBanks(t *testing.T) {
+	image := []byte("some firmware image")
+	measurements := Measurements{
+		NewStaticDataMeasurement(MeasurementIDPCR0DATA, []byte("PCR0_DATA")),
+		NewRangeMeasurement(MeasurementIDDXE, 5, 8),
+		NewStaticDataMeasurement(MeasurementIDInit, []byte{3}), // fake, should be skipped
+		NewStaticDataMeasurement(MeasurementIDSeparator, []byte{0, 0, 0, 0}),
+	}
+
+	hashAlgos := tpmeventlog.HashAlgorithms()
+	values, err := measurements.CalculateBanks(image, 3, hashAlgos...)
+	require.NoError(t, err)
+	require.Len(t, values, len(hashAlgos))
+
+	eventLog, err := measurements.EventLog(image, 0, 3, hashAlgos...)
+	require.NoError(t, err)
+	for idx, hashAlgo := range hashAlgos {
+		hasher, err := tpmeventlog.NewHasher(hashAlgo)
+		require.NoError(t, err)
+		require.Equal(t, hashAlgo, values[idx].HashAlgo)
+		require.Equal(t, measurements.Calculate(image, 3, hasher, nil), values[idx].Value)
+		require.Equal(t, values[idx].Value, values.Find(hashAlgo))
+
+		replayed, err := Replay(eventLog, 0, hashAlgo, nil)
+		require.NoError(t, err)
+		require.Equal(t, values[idx].Value, replayed)
+	}
+
+	_, err = measurements.CalculateBanks(image, 3, tpmeventlog.TPMAlgorithm(0x1234))
+	require.Error(t, err)
+}
+
+func TestCalculateExpectedBanks(t *testing.T) {
+	image, err := uefi.ParseUEFIFirmwareBytes(firmware.FakeIntelFirmware)
+	require.NoError(t, err)
+
+	opts := []MeasureOption{
+		SetFlow(FlowIntelCBnT0T),
+		SetRegisters(registers.Registers{
+			registers.ParseACMPolicyStatusRegister(0x0000000200108681),
+		}),
+	}
+	hashAlgos := []tpmeventlog.TPMAlgorithm{tpmeventlog.TPMAlgorithmSHA1, tpmeventlog.TPMAlgorithmSHA256}
+	values, flow, err := CalculateExpectedBanks(image, 0, hashAlgos, opts...)
+	require.NoError(t, err)
+	require.Equal(t, FlowIntelCBnT0T, flow)
+	require.Len(t, values, len(hashAlgos))
+
+	for _, value := range values {
+		measurements, _, _, err := GetMeasurements(image, 0, append(opts, SetIBBHashDigest(value.HashAlgo))...)
+		require.NoError(t, err)
+		hasher, err := tpmeventlog.NewHasher(value.HashAlgo)
+		require.NoError(t, err)
+		require.Equal(t, measurements.Calculate(image.Buf(), flow.InitialValue(0), hasher, nil), value.Value)
+	}
+}
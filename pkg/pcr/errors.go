@@ -60,6 +60,19 @@ func (err ErrPCDVendorVersion) Error() string {
 	return "unable to find the source of firmware vendor version"
 }
 
+// ErrMeasurementSkipped means the measurement could not be constructed,
+// because its input is not defined in the configuration. It is reported as
+// an error, unless MeasurementConfig.SkipUndefinedMeasurements is set (then
+// the measurement is skipped with a warning and the resulting PCR value will
+// not match the real one).
+type ErrMeasurementSkipped struct {
+	Reason string
+}
+
+func (err ErrMeasurementSkipped) Error() string {
+	return fmt.Sprintf("the measurement is skipped: %s", err.Reason)
+}
+
 // ErrNotSupportedIndex means selected PCR index is not supported (yet?)
 type ErrNotSupportedIndex struct {
 	Index       ID
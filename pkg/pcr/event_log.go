@@ -0,0 +1,116 @@
+package pcr
+
+import (
+	"fmt"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/errors"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+)
+
+// EventLog returns a synthetic EventLog with the measurements reported as
+// events of PCR `pcrIndex`. It could be used as a reference EventLog of the
+// firmware `image`. The events are reported for each of `hashAlgos`.
+//
+// If `pcrIndex` is 0 and `locality` is not zero, then the EventLog starts
+// with a "StartupLocality" event (as it is done by firmware), so that the
+// EventLog could be replayed (see Replay).
+func (s Measurements) EventLog(image []byte, pcrIndex ID, locality uint8, hashAlgos ...tpmeventlog.TPMAlgorithm) (*tpmeventlog.TPMEventLog, error) {
+	result := &tpmeventlog.TPMEventLog{}
+	for _, hashAlgo := range hashAlgos {
+		hasher, err := tpmeventlog.NewHasher(hashAlgo)
+		if err != nil {
+			return nil, err
+		}
+
+		if pcrIndex == 0 && locality != 0 {
+			result.Events = append(result.Events, &tpmeventlog.Event{
+				PCRIndex: pcrIndex,
+				Type:     tpmeventlog.EV_NO_ACTION,
+				Data:     append([]byte("StartupLocality\x00"), locality),
+				Digest: &tpmeventlog.Digest{
+					HashAlgo: hashAlgo,
+					Digest:   make([]byte, hasher.Size()),
+				},
+			})
+		}
+
+		for _, m := range s {
+			if m.IsFake() {
+				continue
+			}
+
+			digest, err := m.Calculate(image, hasher)
+			if err != nil {
+				return nil, fmt.Errorf("unable to calculate the digest of measurement '%s': %w", m.ID, err)
+			}
+			if len(digest) != hasher.Size() {
+				return nil, fmt.Errorf("measurement '%s': %w", m.ID,
+					tpmeventlog.ErrInvalidDigestLength{Expected: hasher.Size(), Received: len(digest)})
+			}
+
+			// There is no event type for some of measurements (yet?),
+			// reporting them as a generic firmware blob.
+			eventType := tpmeventlog.EV_EFI_PLATFORM_FIRMWARE_BLOB
+			if eventTypes := m.EventLogEventTypes(); len(eventTypes) > 0 {
+				eventType = *eventTypes[0]
+			}
+
+			// The measured data itself is the event data only for some event
+			// types; for the rest we use a description.
+			var data []byte
+			switch eventType {
+			case tpmeventlog.EV_SEPARATOR, tpmeventlog.EV_S_CRTM_VERSION:
+				data = m.CompileMeasurableData(image)
+			default:
+				data = []byte(m.ID.String())
+			}
+
+			result.Events = append(result.Events, &tpmeventlog.Event{
+				PCRIndex: pcrIndex,
+				Type:     eventType,
+				Data:     data,
+				Digest: &tpmeventlog.Digest{
+					HashAlgo: hashAlgo,
+					Digest:   digest,
+				},
+			})
+		}
+	}
+	return result, nil
+}
+
+// ExpectedEventLog returns the EventLog which is expected to be produced
+// by the firmware for PCR `pcrID` with PCR banks `hashAlgos`.
+//
+// The measurements are collected for each bank separately, because some of
+// them (for example the IBB digest in PCR0_DATA) depend on the hash algorithm
+// of the bank. Similar to GetMeasurements it could return a (partial)
+// EventLog together with an error.
+func ExpectedEventLog(
+	firmware Firmware,
+	pcrID ID,
+	hashAlgos []tpmeventlog.TPMAlgorithm,
+	opts ...MeasureOption,
+) (*tpmeventlog.TPMEventLog, Flow, error) {
+	result := &tpmeventlog.TPMEventLog{}
+	var resultFlow Flow
+	mErr := &errors.MultiError{}
+	for _, hashAlgo := range hashAlgos {
+		bankOpts := append(append([]MeasureOption{}, opts...), SetIBBHashDigest(hashAlgo))
+		measurements, flow, _, err := GetMeasurements(firmware, pcrID, bankOpts...)
+		if err != nil {
+			_ = mErr.Add(fmt.Errorf("unable to get measurements for hash algorithm %s: %w", tpmeventlog.HashAlgorithmName(hashAlgo), err))
+		}
+		if measurements == nil {
+			return nil, flow, mErr.ReturnValue()
+		}
+		resultFlow = flow
+
+		eventLog, err := measurements.EventLog(firmware.Buf(), pcrID, flow.InitialValue(pcrID), hashAlgo)
+		if err != nil {
+			return nil, flow, fmt.Errorf("unable to construct events for hash algorithm %s: %w", tpmeventlog.HashAlgorithmName(hashAlgo), err)
+		}
+		result.Events = append(result.Events, eventLog.Events...)
+	}
+	return result, resultFlow, mErr.ReturnValue()
+}
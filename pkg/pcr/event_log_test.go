@@ -0,0 +1,74 @@
+package pcr
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/registers"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+	"github.com/9elements/converged-security-suite/v2/testdata/firmware"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasurementsEventLog(t *testing.T) {
+	image := []byte("some firmware image")
+	measurements := Measurements{
+		NewStaticDataMeasurement(MeasurementIDPCR0DATA, []byte("PCR0_DATA")),
+		NewRangeMeasurement(MeasurementIDDXE, 5, 8),
+		NewStaticDataMeasurement(MeasurementIDInit, []byte{3}), // fake, should be skipped
+		NewStaticDataMeasurement(MeasurementIDSeparator, []byte{0, 0, 0, 0}),
+	}
+
+	eventLog, err := measurements.EventLog(image, 0, 3, tpmeventlog.TPMAlgorithmSHA1, tpmeventlog.TPMAlgorithmSHA256)
+	require.NoError(t, err)
+	require.Len(t, eventLog.Events, 8)
+
+	// the synthetic EventLog should survive a round-trip through the binary format
+	var buf bytes.Buffer
+	require.NoError(t, eventLog.EncodeTCG(&buf))
+	eventLog, err = tpmeventlog.Parse(&buf)
+	require.NoError(t, err)
+
+	replayed, err := Replay(eventLog, 0, tpmeventlog.TPMAlgorithmSHA1, nil)
+	require.NoError(t, err)
+	require.Equal(t, measurements.Calculate(image, 3, sha1.New(), nil), replayed)
+
+	replayed, err = Replay(eventLog, 0, tpmeventlog.TPMAlgorithmSHA256, nil)
+	require.NoError(t, err)
+	require.Equal(t, measurements.Calculate(image, 3, sha256.New(), nil), replayed)
+}
+
+func TestExpectedEventLog(t *testing.T) {
+	image, err := uefi.ParseUEFIFirmwareBytes(firmware.FakeIntelFirmware)
+	require.NoError(t, err)
+
+	opts := []MeasureOption{
+		SetFlow(FlowIntelCBnT0T),
+		SetRegisters(registers.Registers{
+			registers.ParseACMPolicyStatusRegister(0x0000000200108681),
+		}),
+	}
+	hashAlgos := []tpmeventlog.TPMAlgorithm{tpmeventlog.TPMAlgorithmSHA1, tpmeventlog.TPMAlgorithmSHA256}
+	eventLog, flow, err := ExpectedEventLog(image, 0, hashAlgos, opts...)
+	require.NoError(t, err)
+	require.Equal(t, FlowIntelCBnT0T, flow)
+
+	var buf bytes.Buffer
+	require.NoError(t, eventLog.EncodeTCG(&buf))
+	eventLog, err = tpmeventlog.Parse(&buf)
+	require.NoError(t, err)
+
+	for _, hashAlgo := range hashAlgos {
+		measurements, _, _, err := GetMeasurements(image, 0, append(opts, SetIBBHashDigest(hashAlgo))...)
+		require.NoError(t, err)
+		h, err := hashAlgo.Hash()
+		require.NoError(t, err)
+
+		replayed, err := Replay(eventLog, 0, hashAlgo, nil)
+		require.NoError(t, err)
+		require.Equal(t, measurements.Calculate(image.Buf(), flow.TPMLocality(), h.New(), nil), replayed, hashAlgo)
+	}
+}
@@ -32,6 +32,12 @@ func (f Flow) String() string {
 		return "AMDLocality0"
 	case FlowAMDLocality3:
 		return "AMDLocality3"
+	case FlowIntelBtG3:
+		return "BtG3"
+	case FlowIntelBtG4:
+		return "BtG4"
+	case FlowIntelBtG5:
+		return "BtG5"
 	}
 	panic(fmt.Sprintf("Flow's %d string representation is not supported", f))
 }
@@ -57,6 +63,12 @@ func FlowFromString(s string) (Flow, error) {
 		return FlowAMDLocality0, nil
 	case "amdlocality3":
 		return FlowAMDLocality3, nil
+	case "btg3":
+		return FlowIntelBtG3, nil
+	case "btg4":
+		return FlowIntelBtG4, nil
+	case "btg5":
+		return FlowIntelBtG5, nil
 	}
 	return FlowAuto, fmt.Errorf("'%s' attestation flow is not supported", s)
 }
@@ -72,6 +84,11 @@ const (
 	FlowIntelLegacyTXTEnabled
 
 	// FlowIntelCBnT0T means CBnT flow with profile "0T".
+	//
+	// It is also the flow of profiles "3T", "4T" and "5T": with TXT the ACM
+	// measures PCR0 the same way (in locality 3) for all the Boot Guard
+	// profiles, the profile is reflected only in the ACM_POLICY_STATUS value
+	// within PCR0_DATA, which is taken from the registers.
 	FlowIntelCBnT0T
 
 	// FlowIntelLegacyTXTEnabledTPM12 means a pre-CBnT flow with enabled TXT for TPM 1.2
@@ -88,6 +105,19 @@ const (
 
 	// FlowAMDLocality3 means an AMD flow with TPM locality 0 initialisation of TPM
 	FlowAMDLocality3
+
+	// FlowIntelBtG3 means a pre-CBnT Boot Guard (1.0) flow with profile 3
+	// ("VM": verified and measured boot).
+	FlowIntelBtG3
+
+	// FlowIntelBtG4 means a pre-CBnT Boot Guard (1.0) flow with profile 4
+	// ("FVE": force verified boot with enforcement, without measurements by
+	// the ACM).
+	FlowIntelBtG4
+
+	// FlowIntelBtG5 means a pre-CBnT Boot Guard (1.0) flow with profile 5
+	// ("FVME": verified and measured boot with force anchor boot).
+	FlowIntelBtG5
 )
 
 // Flows contains all supported PCR measurements flows
@@ -101,21 +131,48 @@ var Flows = []Flow{
 	FlowLegacyAMDLocality3,
 	FlowAMDLocality0,
 	FlowAMDLocality3,
+	FlowIntelBtG3,
+	FlowIntelBtG4,
+	FlowIntelBtG5,
 }
 
 // TPMLocality returns TPM initialization locality in this flow.
 func (f Flow) TPMLocality() uint8 {
 	switch f {
-	case FlowIntelCBnT0T, FlowIntelLegacyTXTEnabled, FlowLegacyAMDLocality3, FlowAMDLocality3:
+	case FlowIntelCBnT0T,
+		FlowIntelBtG3, FlowIntelBtG5,
+		FlowIntelLegacyTXTEnabled, FlowLegacyAMDLocality3, FlowAMDLocality3:
 		return 3
 	}
 	return 0
 }
 
+// InitialValue returns the value of the last byte of the initial value
+// of the PCR `pcrID` in this flow (all other bytes are zeros).
+//
+// PCR0 is initialized with the TPM initialization locality, while PCR1
+// and PCR7 are always initialized with zeros.
+func (f Flow) InitialValue(pcrID ID) uint8 {
+	if pcrID != 0 {
+		return 0
+	}
+	return f.TPMLocality()
+}
+
 // MeasurementIDs returns which measurements should be performed for in flow.
+//
+// The list covers all the supported PCRs, use MeasurementIDs.FilterByPCRIndex
+// to get the measurements of a specific PCR. Measurements of PCR1 and PCR7
+// are placed before the separator, because the separator is measured into
+// each of them after the rest of measurements.
 func (f Flow) MeasurementIDs() MeasurementIDs {
 	switch f {
-	case FlowIntelCBnT0T:
+	case FlowIntelCBnT0T,
+		FlowIntelBtG3, FlowIntelBtG5:
+		// With TXT ("T" profiles of CBnT) the ACM extends PCR0_DATA
+		// regardless of the Boot Guard profile, the profile is reflected
+		// only in the ACM_POLICY_STATUS value within PCR0_DATA. Boot Guard 1.0
+		// with measured boot extends PCR0 the same way (see MeasurePCR0Data).
 		/*
 			A sample of the EventLog:
 			fwtool display_eventlog -pcr-index 0 -hash-algo 4
@@ -136,6 +193,15 @@ func (f Flow) MeasurementIDs() MeasurementIDs {
 			MeasurementIDPCDFirmwareVendorVersionData,
 			MeasurementIDPCDFirmwareVendorVersionCode, // is a fake measurement
 			MeasurementIDDXE,
+			MeasurementIDCPUMicrocode,
+			MeasurementIDSMBIOSTables,
+			MeasurementIDEFIVariableBootOrder,
+			MeasurementIDEFIVariablesBoot,
+			MeasurementIDEFIVariableSecureBoot,
+			MeasurementIDEFIVariablePK,
+			MeasurementIDEFIVariableKEK,
+			MeasurementIDEFIVariableDB,
+			MeasurementIDEFIVariableDBX,
 			MeasurementIDSeparator,
 			MeasurementIDFITPointer, // is a fake measurement
 			MeasurementIDFITHeaders, // is a fake measurement
@@ -156,6 +222,15 @@ func (f Flow) MeasurementIDs() MeasurementIDs {
 			MeasurementIDPCDFirmwareVendorVersionData,
 			MeasurementIDPCDFirmwareVendorVersionCode, // is a fake measurement
 			MeasurementIDDXE,
+			MeasurementIDCPUMicrocode,
+			MeasurementIDSMBIOSTables,
+			MeasurementIDEFIVariableBootOrder,
+			MeasurementIDEFIVariablesBoot,
+			MeasurementIDEFIVariableSecureBoot,
+			MeasurementIDEFIVariablePK,
+			MeasurementIDEFIVariableKEK,
+			MeasurementIDEFIVariableDB,
+			MeasurementIDEFIVariableDBX,
 			MeasurementIDSeparator,
 			MeasurementIDFITPointer, // is a fake measurement
 			MeasurementIDFITHeaders, // is a fake measurement
@@ -169,6 +244,39 @@ func (f Flow) MeasurementIDs() MeasurementIDs {
 			MeasurementIDPCDFirmwareVendorVersionData,
 			MeasurementIDPCDFirmwareVendorVersionCode, // is a fake measurement
 			MeasurementIDDXE,
+			MeasurementIDCPUMicrocode,
+			MeasurementIDSMBIOSTables,
+			MeasurementIDEFIVariableBootOrder,
+			MeasurementIDEFIVariablesBoot,
+			MeasurementIDEFIVariableSecureBoot,
+			MeasurementIDEFIVariablePK,
+			MeasurementIDEFIVariableKEK,
+			MeasurementIDEFIVariableDB,
+			MeasurementIDEFIVariableDBX,
+			MeasurementIDSeparator,
+			MeasurementIDFITPointer, // is a fake measurement
+			MeasurementIDFITHeaders, // is a fake measurement
+		}
+	case FlowIntelBtG4:
+		// The ACM verifies the IBB, but does not measure anything, so PCR0
+		// contains only the measurements performed by the firmware itself.
+		return MeasurementIDs{
+			MeasurementIDACM,                // is a fake measurement
+			MeasurementIDKeyManifest,        // is a fake measurement
+			MeasurementIDBootPolicyManifest, // is a fake measurement
+			MeasurementIDIBBFake,            // is a fake measurement
+			MeasurementIDPCDFirmwareVendorVersionData,
+			MeasurementIDPCDFirmwareVendorVersionCode, // is a fake measurement
+			MeasurementIDDXE,
+			MeasurementIDCPUMicrocode,
+			MeasurementIDSMBIOSTables,
+			MeasurementIDEFIVariableBootOrder,
+			MeasurementIDEFIVariablesBoot,
+			MeasurementIDEFIVariableSecureBoot,
+			MeasurementIDEFIVariablePK,
+			MeasurementIDEFIVariableKEK,
+			MeasurementIDEFIVariableDB,
+			MeasurementIDEFIVariableDBX,
 			MeasurementIDSeparator,
 			MeasurementIDFITPointer, // is a fake measurement
 			MeasurementIDFITHeaders, // is a fake measurement
@@ -178,6 +286,15 @@ func (f Flow) MeasurementIDs() MeasurementIDs {
 			MeasurementIDPCDFirmwareVendorVersionData,
 			MeasurementIDPCDFirmwareVendorVersionCode, // is a fake measurement
 			MeasurementIDDXE,
+			MeasurementIDCPUMicrocode,
+			MeasurementIDSMBIOSTables,
+			MeasurementIDEFIVariableBootOrder,
+			MeasurementIDEFIVariablesBoot,
+			MeasurementIDEFIVariableSecureBoot,
+			MeasurementIDEFIVariablePK,
+			MeasurementIDEFIVariableKEK,
+			MeasurementIDEFIVariableDB,
+			MeasurementIDEFIVariableDBX,
 			MeasurementIDSeparator,
 
 			// Also we include as fake measurements the byte ranges which a known
@@ -191,6 +308,14 @@ func (f Flow) MeasurementIDs() MeasurementIDs {
 			MeasurementIDBIOSRTMVolume,
 			MeasurementIDPCDFirmwareVendorVersionData,
 			MeasurementIDDXE,
+			MeasurementIDSMBIOSTables,
+			MeasurementIDEFIVariableBootOrder,
+			MeasurementIDEFIVariablesBoot,
+			MeasurementIDEFIVariableSecureBoot,
+			MeasurementIDEFIVariablePK,
+			MeasurementIDEFIVariableKEK,
+			MeasurementIDEFIVariableDB,
+			MeasurementIDEFIVariableDBX,
 			MeasurementIDSeparator,
 		}
 	case FlowLegacyAMDLocality3:
@@ -199,6 +324,14 @@ func (f Flow) MeasurementIDs() MeasurementIDs {
 			MeasurementIDBIOSRTMVolume,
 			MeasurementIDPCDFirmwareVendorVersionData,
 			MeasurementIDDXE,
+			MeasurementIDSMBIOSTables,
+			MeasurementIDEFIVariableBootOrder,
+			MeasurementIDEFIVariablesBoot,
+			MeasurementIDEFIVariableSecureBoot,
+			MeasurementIDEFIVariablePK,
+			MeasurementIDEFIVariableKEK,
+			MeasurementIDEFIVariableDB,
+			MeasurementIDEFIVariableDBX,
 			MeasurementIDSeparator,
 		}
 	case FlowAMDLocality0:
@@ -217,6 +350,14 @@ func (f Flow) MeasurementIDs() MeasurementIDs {
 			MeasurementIDVideoImageInterpreter,
 			MeasurementIDPCDFirmwareVendorVersionData,
 			MeasurementIDDXE,
+			MeasurementIDSMBIOSTables,
+			MeasurementIDEFIVariableBootOrder,
+			MeasurementIDEFIVariablesBoot,
+			MeasurementIDEFIVariableSecureBoot,
+			MeasurementIDEFIVariablePK,
+			MeasurementIDEFIVariableKEK,
+			MeasurementIDEFIVariableDB,
+			MeasurementIDEFIVariableDBX,
 			MeasurementIDSeparator,
 		}
 	case FlowAMDLocality3:
@@ -243,6 +384,14 @@ func (f Flow) MeasurementIDs() MeasurementIDs {
 			MeasurementIDVideoImageInterpreter,
 			MeasurementIDPCDFirmwareVendorVersionData,
 			MeasurementIDDXE,
+			MeasurementIDSMBIOSTables,
+			MeasurementIDEFIVariableBootOrder,
+			MeasurementIDEFIVariablesBoot,
+			MeasurementIDEFIVariableSecureBoot,
+			MeasurementIDEFIVariablePK,
+			MeasurementIDEFIVariableKEK,
+			MeasurementIDEFIVariableDB,
+			MeasurementIDEFIVariableDBX,
 			MeasurementIDSeparator,
 		}
 	}
@@ -254,10 +403,16 @@ func (f Flow) MeasurementIDs() MeasurementIDs {
 // with valid manifests, otherwise it falls back to TXT-disabled flow.
 func (f Flow) ValidateFlow() ValidateFlow {
 	switch f {
-	case FlowIntelCBnT0T:
+	case FlowIntelCBnT0T,
+		FlowIntelBtG3, FlowIntelBtG4, FlowIntelBtG5:
 		return ValidateFlow{
 			ValidateManifests{},
 		}
+	case FlowLegacyAMDLocality0, FlowLegacyAMDLocality3,
+		FlowAMDLocality0, FlowAMDLocality3:
+		return ValidateFlow{
+			ValidatePSB{},
+		}
 	}
 
 	return nil
@@ -271,7 +426,10 @@ func (f Flow) PlatformSecurityID() platformsecurity.ID {
 		return platformsecurity.IDIntelCBnT
 	case FlowIntelLegacyTXTEnabled,
 		FlowIntelLegacyTXTEnabledTPM12,
-		FlowIntelLegacyTXTDisabled:
+		FlowIntelLegacyTXTDisabled,
+		FlowIntelBtG3,
+		FlowIntelBtG4,
+		FlowIntelBtG5:
 		return platformsecurity.IDIntelTXT
 	case FlowAMDLocality3,
 		FlowAMDLocality0,
@@ -146,9 +146,12 @@ func DetectMainAttestationFlow(firmware Firmware, regs registers.Registers, tpmD
 
 	isCBnT, err := isCBnT(fitEntries)
 	if err == nil && isCBnT {
-		// TODO: check that it is 0T using registers
+		// All the "T" profiles share the flow, see FlowIntelCBnT0T.
 		return FlowIntelCBnT0T, nil
 	}
+	if isBootGuardV1(fitEntries) {
+		return detectBootGuardV1Profile(regs), nil
+	}
 	isTXTEnabledValue, err := isTXTEnabled(fitEntries)
 	if err != nil {
 		return FlowAuto, err
@@ -185,7 +188,9 @@ func DetectAttestationFlow(firmware Firmware, regs registers.Registers, tpmDevic
 	}
 
 	switch flow {
-	case FlowIntelCBnT0T, FlowIntelLegacyTXTEnabled, FlowIntelLegacyTXTEnabledTPM12:
+	case FlowIntelCBnT0T,
+		FlowIntelBtG3, FlowIntelBtG4, FlowIntelBtG5,
+		FlowIntelLegacyTXTEnabled, FlowIntelLegacyTXTEnabledTPM12:
 		err := flow.ValidateFlow().Validate(firmware)
 		if err != nil {
 			return FlowIntelLegacyTXTDisabled, fmt.Errorf("TXT disabled: %w", err)
@@ -195,6 +200,62 @@ func DetectAttestationFlow(firmware Firmware, regs registers.Registers, tpmDevic
 	return flow, nil
 }
 
+// bootGuardPolicy is the Boot Guard policy (defined by the profile fused
+// into the PCH) applied by the ACM.
+type bootGuardPolicy struct {
+	Verified        bool
+	Measured        bool
+	ForceAnchorBoot bool
+}
+
+// findBootGuardPolicy decodes the Boot Guard policy reported by the ACM
+// through MSR BTG_SACM_INFO and/or register ACM_POLICY_STATUS. Returns false
+// if none of the registers is available.
+func findBootGuardPolicy(regs registers.Registers) (bootGuardPolicy, bool) {
+	if btgACMInfo, found := registers.FindBTGSACMInfo(regs); found {
+		return bootGuardPolicy{
+			Verified:        btgACMInfo.Verified(),
+			Measured:        btgACMInfo.Measured(),
+			ForceAnchorBoot: btgACMInfo.ForceAnchorBoot(),
+		}, true
+	}
+	if acmPolicyStatus, found := registers.FindACMPolicyStatus(regs); found {
+		// ACM_POLICY_STATUS does not report the force anchor boot bit.
+		return bootGuardPolicy{
+			Verified: acmPolicyStatus.BootPolicyV(),
+			Measured: acmPolicyStatus.BootPolicyM(),
+		}, true
+	}
+	return bootGuardPolicy{}, false
+}
+
+// detectBootGuardV1Profile returns the flow of the Boot Guard 1.0 profile
+// reported by the ACM. The profile could not be detected from the firmware
+// (it is defined by the fuses), so if the registers are not available then
+// the most common server profile 5 is assumed.
+func detectBootGuardV1Profile(regs registers.Registers) Flow {
+	policy, found := findBootGuardPolicy(regs)
+	if !found {
+		return FlowIntelBtG5
+	}
+	// Profiles: 3 is "VM" (verified and measured), 4 is "FVE" (force
+	// verified with enforcement, not measured) and 5 is "FVME" (force
+	// verified, measured and enforced). Only the measured bit affects PCR0,
+	// so it is preferred over the force anchor boot bit (which is not
+	// reported by ACM_POLICY_STATUS).
+	switch {
+	case policy.Measured && policy.ForceAnchorBoot:
+		return FlowIntelBtG5
+	case policy.Measured:
+		return FlowIntelBtG3
+	case policy.Verified:
+		return FlowIntelBtG4
+	}
+	// Boot Guard is not active: the ACM neither verifies nor measures
+	// anything.
+	return FlowIntelLegacyTXTDisabled
+}
+
 func isTXTEnabled(fitEntries []fit.Entry) (bool, error) {
 	for _, fitEntry := range fitEntries {
 		switch fitEntry := fitEntry.(type) {
@@ -3,6 +3,10 @@ package pcr
 import (
 	"testing"
 
+	"github.com/9elements/converged-security-suite/v2/pkg/registers"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmdetection"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+	"github.com/9elements/converged-security-suite/v2/testdata/firmware"
 	"github.com/klauspost/cpuid/v2"
 	"github.com/stretchr/testify/require"
 )
@@ -15,3 +19,32 @@ func TestFlowCPUVendorID(t *testing.T) {
 		require.NotEqual(t, cpuid.VendorUnknown, flow.CPUVendorID(), flow.String())
 	}
 }
+
+func TestDetectMainAttestationFlowCBnT(t *testing.T) {
+	fw, err := uefi.ParseUEFIFirmwareBytes(firmware.FakeIntelFirmware)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		regs         registers.Registers
+		expectedFlow Flow
+	}{
+		{regs: nil, expectedFlow: FlowIntelCBnT0T},
+		{regs: registers.Registers{registers.ParseACMPolicyStatusRegister(0x0000000200108681)}, expectedFlow: FlowIntelCBnT0T},
+		{regs: registers.Registers{registers.ParseACMPolicyStatusRegister(0x00000002001086a1)}, expectedFlow: FlowIntelCBnT0T},
+		{regs: registers.Registers{registers.ParseACMPolicyStatusRegister(0x00000002001086b1)}, expectedFlow: FlowIntelCBnT0T},
+		{regs: registers.Registers{registers.ParseBTGSACMInfo(0x70)}, expectedFlow: FlowIntelCBnT0T},
+	} {
+		flow, err := DetectMainAttestationFlow(fw, tc.regs, tpmdetection.TypeTPM20)
+		require.NoError(t, err)
+		require.Equal(t, tc.expectedFlow, flow, "%v", tc.regs)
+		require.Equal(t, uint8(3), flow.TPMLocality())
+	}
+}
+
+func TestFlowFromString(t *testing.T) {
+	for _, flow := range Flows {
+		parsed, err := FlowFromString(flow.String())
+		require.NoError(t, err)
+		require.Equal(t, flow, parsed)
+	}
+}
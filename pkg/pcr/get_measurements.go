@@ -1,6 +1,7 @@
 package pcr
 
 import (
+	stderrors "errors"
 	"fmt"
 
 	"github.com/9elements/converged-security-suite/v2/pkg/errors"
@@ -98,6 +99,7 @@ type measurementsCollector struct {
 	fitEntriesResult *[]fit.Entry
 	pcdDataResult    *pcd.ParsedFirmware
 	amdFirmware      *amd.AMDFirmware
+	efiVariables     *uefi.EFIVariables
 	errors           errors.MultiError
 	warnings         errors.MultiError
 }
@@ -151,13 +153,26 @@ func (c *measurementsCollector) PSPFirmware() *amd.PSPFirmware {
 	return c.amdFirmware.PSPFirmware()
 }
 
+func (c *measurementsCollector) EFIVariables() uefi.EFIVariables {
+	if c.efiVariables != nil {
+		return *c.efiVariables
+	}
+
+	variables, err := c.firmware.GetEFIVariables()
+	if err != nil {
+		_ = c.warnings.Add(err)
+	}
+	c.efiVariables = &variables
+	return variables
+}
+
 // CollectMeasurements just returns all the measurements for the specified PCR index.
 func (c *measurementsCollector) CollectMeasurements(
 	pcrIndex ID,
 	config MeasurementConfig,
 ) (result Measurements, warnings error, err error) {
 	switch pcrIndex {
-	case 0, 1:
+	case 0, 1, 7:
 	default:
 		return nil, nil, &ErrUnknownPCRID{pcrIndex}
 	}
@@ -165,7 +180,9 @@ func (c *measurementsCollector) CollectMeasurements(
 	for _, measurementID := range config.Flow.MeasurementIDs().FilterByPCRIndex(pcrIndex) {
 		measurements, err := measurementID.MeasureFunc()(config, c)
 		if err != nil {
-			if measurementID.IsFake() || len(measurements) > 0 {
+			var skipped ErrMeasurementSkipped
+			isSkipped := config.SkipUndefinedMeasurements && stderrors.As(err, &skipped)
+			if measurementID.IsFake() || len(measurements) > 0 || isSkipped {
 				_ = c.warnings.Add(ErrCollect{MeasurementID: measurementID, Err: err})
 			} else {
 				_ = c.errors.Add(ErrCollect{MeasurementID: measurementID, Err: err})
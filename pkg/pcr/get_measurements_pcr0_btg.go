@@ -0,0 +1,110 @@
+package pcr
+
+import (
+	"fmt"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/bootguard"
+	pkgbytes "github.com/linuxboot/fiano/pkg/bytes"
+	"github.com/linuxboot/fiano/pkg/intel/metadata/fit"
+)
+
+// IsBootGuardV1Firmware checks if firmware has Boot Guard 1.0 (pre-CBnT)
+// manifests.
+func IsBootGuardV1Firmware(firmware Firmware) bool {
+	fitEntries, err := fit.GetEntries(firmware.Buf())
+	if err != nil {
+		return false
+	}
+	return isBootGuardV1(fitEntries)
+}
+
+// isBootGuardV1 checks if the Key Manifest is of Boot Guard 1.0. The
+// manifests of Boot Guard 1.0 and CBnT have the same structure ID, but
+// a different structure version.
+func isBootGuardV1(fitEntries []fit.Entry) bool {
+	kmFITEntry, err := findKeyManifestFITEntry(fitEntries)
+	if err != nil {
+		return false
+	}
+	return bootguard.IsKeyManifest(kmFITEntry.DataSegmentBytes)
+}
+
+func findKeyManifestFITEntry(fitEntries []fit.Entry) (*fit.EntryKeyManifestRecord, error) {
+	for _, fitEntry := range fitEntries {
+		switch fitEntry := fitEntry.(type) {
+		case *fit.EntryKeyManifestRecord:
+			return fitEntry, nil
+		}
+	}
+	return nil, fmt.Errorf("key manifest FIT entry is not found")
+}
+
+func findBootPolicyManifestFITEntry(fitEntries []fit.Entry) (*fit.EntryBootPolicyManifestRecord, error) {
+	for _, fitEntry := range fitEntries {
+		switch fitEntry := fitEntry.(type) {
+		case *fit.EntryBootPolicyManifestRecord:
+			return fitEntry, nil
+		}
+	}
+	return nil, fmt.Errorf("boot policy manifest FIT entry is not found")
+}
+
+func getBootGuardV1KeyManifest(fitEntries []fit.Entry) (*bootguard.KeyManifest, *fit.EntryKeyManifestRecord, error) {
+	fitEntry, err := findKeyManifestFITEntry(fitEntries)
+	if err != nil {
+		return nil, nil, err
+	}
+	km, err := bootguard.ParseKeyManifest(fitEntry.DataSegmentBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return km, fitEntry, nil
+}
+
+func getBootGuardV1BootPolicyManifest(fitEntries []fit.Entry) (*bootguard.BootPolicyManifest, *fit.EntryBootPolicyManifestRecord, error) {
+	fitEntry, err := findBootPolicyManifestFITEntry(fitEntries)
+	if err != nil {
+		return nil, nil, err
+	}
+	bpm, err := bootguard.ParseBootPolicyManifest(fitEntry.DataSegmentBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bpm, fitEntry, nil
+}
+
+// setBootGuardV1Manifests sets the fields of PCR0_DATA which are taken
+// from Boot Guard 1.0 manifests.
+//
+// Contrary to CBnT, there is only one IBB digest in the BPM, so
+// it is always used (regardless of PCR0DataIbbDigestHashAlgorithm).
+func (d *pcr0Data) setBootGuardV1Manifests(imageSize uint64, fitEntries []fit.Entry) error {
+	km, kmFITEntry, err := getBootGuardV1KeyManifest(fitEntries)
+	if err != nil {
+		return fmt.Errorf("unable to get key manifest (KM): %w", err)
+	}
+	kmOffset := kmFITEntry.Headers.Address.Offset(imageSize)
+	d.kmSignature = pkgbytes.Range{
+		Offset: kmOffset + km.SignatureDataOffset(),
+		Length: uint64(len(km.KeyAndSignature.Signature.Data)),
+	}
+
+	bpm, bpmFITEntry, err := getBootGuardV1BootPolicyManifest(fitEntries)
+	if err != nil {
+		return fmt.Errorf("unable to get boot policy manifest (BPM): %w", err)
+	}
+	bpmOffset := bpmFITEntry.Headers.Address.Offset(imageSize)
+	d.bpmSignature = pkgbytes.Range{
+		Offset: bpmOffset + bpm.SignatureDataOffset(),
+		Length: uint64(len(bpm.PMSE.KeySignature.Signature.Data)),
+	}
+
+	if len(bpm.SE.Digest.HashBuffer) == 0 {
+		return fmt.Errorf("IBB digest is empty")
+	}
+	d.ibbDigest = pkgbytes.Range{
+		Offset: bpmOffset + bpm.IBBDigestOffset(),
+		Length: uint64(len(bpm.SE.Digest.HashBuffer)),
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package pcr
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/bootguard"
+	"github.com/9elements/converged-security-suite/v2/pkg/registers"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmdetection"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+	"github.com/9elements/converged-security-suite/v2/testdata/firmware"
+	"github.com/linuxboot/fiano/pkg/intel/metadata/fit"
+	"github.com/linuxboot/fiano/pkg/intel/metadata/manifest"
+	"github.com/stretchr/testify/require"
+)
+
+// newBootGuardV1Firmware returns the fake firmware with CBnT manifests
+// replaced with signed Boot Guard 1.0 ones (covering the same IBB).
+func newBootGuardV1Firmware(t *testing.T) (*uefi.UEFI, *bootguard.KeyManifest, *bootguard.BootPolicyManifest) {
+	image := append([]byte{}, firmware.FakeIntelFirmware...)
+	imageSize := uint64(len(image))
+	fitEntries, err := fit.GetEntries(image)
+	require.NoError(t, err)
+
+	cbntBPM, bpmFITEntry, err := getBootPolicyManifest(fitEntries)
+	require.NoError(t, err)
+	kmFITEntry, err := findKeyManifestFITEntry(fitEntries)
+	require.NoError(t, err)
+
+	bpm := &bootguard.BootPolicyManifest{
+		BPMH: bootguard.BPMH{
+			StructInfo: bootguard.StructInfo{ID: bootguard.StructureIDBootPolicyManifestHeader, Version: bootguard.StructureVersion},
+		},
+		SE: bootguard.IBBElement{
+			StructInfo:  bootguard.StructInfo{ID: bootguard.StructureIDIBBElement, Version: bootguard.StructureVersion},
+			PostIBBHash: manifest.HashStructure{HashAlg: manifest.AlgNull, HashBuffer: []byte{}},
+		},
+	}
+	for _, seg := range cbntBPM.SE[0].IBBSegments {
+		bpm.SE.IBBSegments = append(bpm.SE.IBBSegments, bootguard.IBBSegment{
+			Flags: seg.Flags,
+			Base:  seg.Base,
+			Size:  seg.Size,
+		})
+	}
+	h := sha256.New()
+	for _, _range := range bpm.IBBDataRanges(imageSize) {
+		h.Write(image[_range.Offset:_range.End()])
+	}
+	bpm.SE.Digest = manifest.HashStructure{HashAlg: manifest.AlgSHA256, HashBuffer: h.Sum(nil)}
+
+	bpmKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	require.NoError(t, bpm.SetSignature(bpmKey))
+
+	bpKeyHash := sha256.Sum256(bpm.PMSE.KeySignature.Key.Data[4:])
+	km := &bootguard.KeyManifest{
+		StructInfo: bootguard.StructInfo{ID: bootguard.StructureIDKeyManifest, Version: bootguard.StructureVersion},
+		BPKey:      manifest.HashStructure{HashAlg: manifest.AlgSHA256, HashBuffer: bpKeyHash[:]},
+	}
+	kmKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	require.NoError(t, km.SetSignature(kmKey))
+
+	kmBytes, err := km.MarshalBinary()
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(kmBytes), len(kmFITEntry.DataSegmentBytes))
+	copy(image[kmFITEntry.Headers.Address.Offset(imageSize):], kmBytes)
+
+	bpmBytes, err := bpm.MarshalBinary()
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(bpmBytes), len(bpmFITEntry.DataSegmentBytes))
+	copy(image[bpmFITEntry.Headers.Address.Offset(imageSize):], bpmBytes)
+
+	fw, err := uefi.ParseUEFIFirmwareBytes(image)
+	require.NoError(t, err)
+	return fw, km, bpm
+}
+
+func TestBootGuardV1(t *testing.T) {
+	fw, km, bpm := newBootGuardV1Firmware(t)
+	image := fw.Buf()
+
+	require.True(t, IsBootGuardV1Firmware(fw))
+	require.False(t, IsCBnTFirmware(fw))
+
+	t.Run("detect_flow", func(t *testing.T) {
+		for _, tc := range []struct {
+			btgSACMInfo  uint64
+			expectedFlow Flow
+		}{
+			{btgSACMInfo: 0x70, expectedFlow: FlowIntelBtG5},
+			{btgSACMInfo: 0x60, expectedFlow: FlowIntelBtG3},
+			{btgSACMInfo: 0x50, expectedFlow: FlowIntelBtG4},
+			{btgSACMInfo: 0x40, expectedFlow: FlowIntelBtG4},
+			{btgSACMInfo: 0x00, expectedFlow: FlowIntelLegacyTXTDisabled},
+		} {
+			regs := registers.Registers{registers.ParseBTGSACMInfo(tc.btgSACMInfo)}
+			flow, err := DetectAttestationFlow(fw, regs, tpmdetection.TypeTPM20)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedFlow, flow, "BTG_SACM_INFO: 0x%X", tc.btgSACMInfo)
+		}
+
+		flow, err := DetectMainAttestationFlow(fw, nil, tpmdetection.TypeTPM20)
+		require.NoError(t, err)
+		require.Equal(t, FlowIntelBtG5, flow)
+	})
+
+	t.Run("pcr0_data", func(t *testing.T) {
+		measurements, flow, _, err := GetMeasurements(fw, 0,
+			SetFlow(FlowIntelBtG5),
+			SetRegisters(registers.Registers{
+				registers.ParseACMPolicyStatusRegister(0x0000000200108681),
+			}),
+		)
+		require.NoError(t, err)
+		require.Equal(t, FlowIntelBtG5, flow)
+
+		pcr0Data := measurements.Find(MeasurementIDPCR0DATA)
+		require.NotNil(t, pcr0Data)
+		for chunkID, expected := range map[DataChunkID][]byte{
+			DataChunkIDKeyManifestSignature:        km.KeyAndSignature.Signature.Data,
+			DataChunkIDBootPolicyManifestSignature: bpm.PMSE.KeySignature.Signature.Data,
+			DataChunkIDIBBDigest:                   bpm.SE.Digest.HashBuffer,
+		} {
+			chunk := pcr0Data.Data.Find(chunkID)
+			require.NotNil(t, chunk, chunkID.String())
+			require.Equal(t, expected, chunk.CompileMeasurableData(image), chunkID.String())
+		}
+
+		ibb := measurements.Find(MeasurementIDIBBFake)
+		require.NotNil(t, ibb)
+		require.Len(t, ibb.Data, len(bpm.IBBDataRanges(uint64(len(image)))))
+	})
+
+	t.Run("profiles", func(t *testing.T) {
+		for _, tc := range []struct {
+			flow             Flow
+			expectedMeasured bool
+		}{
+			{flow: FlowIntelBtG3, expectedMeasured: true},
+			{flow: FlowIntelBtG4, expectedMeasured: false},
+			{flow: FlowIntelBtG5, expectedMeasured: true},
+		} {
+			t.Run(tc.flow.String(), func(t *testing.T) {
+				measurements, _, _, err := GetMeasurements(fw, 0,
+					SetFlow(tc.flow),
+					SetRegisters(registers.Registers{
+						registers.ParseACMPolicyStatusRegister(0x0000000200108681),
+					}),
+				)
+				require.NoError(t, err)
+				// the fake measurements are the ranges verified by the ACM,
+				// so they are required in every profile
+				require.NotNil(t, measurements.Find(MeasurementIDKeyManifest))
+				require.NotNil(t, measurements.Find(MeasurementIDIBBFake))
+				if tc.expectedMeasured {
+					require.NotNil(t, measurements.Find(MeasurementIDPCR0DATA))
+					require.NotNil(t, measurements.Find(MeasurementIDInit))
+					require.Equal(t, uint8(3), tc.flow.TPMLocality())
+				} else {
+					require.Nil(t, measurements.Find(MeasurementIDPCR0DATA))
+					require.Nil(t, measurements.Find(MeasurementIDInit))
+					require.Equal(t, uint8(0), tc.flow.TPMLocality())
+				}
+			})
+		}
+	})
+
+	t.Run("invalid_ibb", func(t *testing.T) {
+		ibbRange := bpm.IBBDataRanges(uint64(len(image)))[0]
+		corrupted := append([]byte{}, image...)
+		corrupted[ibbRange.Offset] ^= 1
+		corruptedFW, err := uefi.ParseUEFIFirmwareBytes(corrupted)
+		require.NoError(t, err)
+
+		require.Error(t, ValidateManifests{}.Validate(corruptedFW))
+		flow, err := DetectAttestationFlow(corruptedFW, nil, tpmdetection.TypeTPM20)
+		require.Error(t, err)
+		require.Equal(t, FlowIntelLegacyTXTDisabled, flow)
+	})
+}
@@ -71,6 +71,13 @@ func MeasurePCR0Data(config MeasurementConfig, imageSize uint64, fitEntries []fi
 		Length: uint64(len(acmEntry.GetRSASig())),
 	}
 
+	if isBootGuardV1(fitEntries) {
+		if err := data.setBootGuardV1Manifests(imageSize, fitEntries); err != nil {
+			return nil, err
+		}
+		return data.Measurement(), nil
+	}
+
 	keyManifest, keyManifestFITEntry, err := getKeyManifest(fitEntries)
 	if err != nil {
 		return nil, err
@@ -170,9 +177,10 @@ func getBootPolicyManifest(fitEntries []fit.Entry) (*bootpolicy.Manifest, *fit.E
 	return nil, nil, fmt.Errorf("boot policy manifest FIT entry is not found")
 }
 
-// MeasureKeyManifest returns a measurement containing CBnT key manifest.
+// MeasureKeyManifest returns a measurement containing CBnT (or Boot Guard 1.0)
+// key manifest.
 func MeasureKeyManifest(imageSize uint64, fitEntries []fit.Entry) (*Measurement, error) {
-	_, kmFITEntry, err := getKeyManifest(fitEntries)
+	kmFITEntry, err := findKeyManifestFITEntry(fitEntries)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get key manifest (KM): %w", err)
 	}
@@ -188,9 +196,10 @@ func MeasureKeyManifest(imageSize uint64, fitEntries []fit.Entry) (*Measurement,
 	}, nil
 }
 
-// MeasureBootPolicy returns a measurement containing CBnT key manifest.
+// MeasureBootPolicy returns a measurement containing CBnT (or Boot Guard 1.0)
+// boot policy manifest.
 func MeasureBootPolicy(imageSize uint64, fitEntries []fit.Entry) (*Measurement, error) {
-	_, bpmFITEntry, err := getBootPolicyManifest(fitEntries)
+	bpmFITEntry, err := findBootPolicyManifestFITEntry(fitEntries)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get boot policy manifest (BPM): %w", err)
 	}
@@ -208,15 +217,24 @@ func MeasureBootPolicy(imageSize uint64, fitEntries []fit.Entry) (*Measurement,
 
 // MeasureIBB returns a measurement containing IBB according to BPM.
 func MeasureIBB(fitEntries []fit.Entry, firmwareSize uint64) (*Measurement, error) {
-	bpManifest, _, err := getBootPolicyManifest(fitEntries)
-	if err != nil {
-		return nil, fmt.Errorf("unable to get boot policy manifest (BPM): %w", err)
+	var ibbRanges pkgbytes.Ranges
+	if isBootGuardV1(fitEntries) {
+		bpManifest, _, err := getBootGuardV1BootPolicyManifest(fitEntries)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get boot policy manifest (BPM): %w", err)
+		}
+		ibbRanges = bpManifest.IBBDataRanges(firmwareSize)
+	} else {
+		bpManifest, _, err := getBootPolicyManifest(fitEntries)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get boot policy manifest (BPM): %w", err)
+		}
+		ibbRanges = bpManifest.IBBDataRanges(firmwareSize)
 	}
 
 	result := Measurement{
 		ID: MeasurementIDIBBFake,
 	}
-	ibbRanges := bpManifest.IBBDataRanges(firmwareSize)
 	for _, _range := range ibbRanges {
 		result.Data = append(result.Data, DataChunk{
 			Range: _range,
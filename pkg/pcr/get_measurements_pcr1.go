@@ -0,0 +1,208 @@
+package pcr
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi/consts"
+	pkgbytes "github.com/linuxboot/fiano/pkg/bytes"
+	"github.com/linuxboot/fiano/pkg/intel/metadata/fit"
+)
+
+const (
+	// See "Intel 64 and IA-32 Architectures Software Developer's Manual",
+	// Volume 3, Section 9.11.1 "Microcode Update".
+	microcodeHeaderSize            = 48
+	microcodeDefaultDataSize       = 2000
+	microcodeExtendedTableHeadSize = 20
+	microcodeExtendedSignatureSize = 12
+)
+
+type microcodeUpdate struct {
+	Range      pkgbytes.Range
+	Signatures []uint32
+}
+
+func parseMicrocodeUpdate(image []byte, offset uint64) (*microcodeUpdate, error) {
+	if offset+microcodeHeaderSize > uint64(len(image)) {
+		return nil, fmt.Errorf("microcode update header at 0x%X is out of the image", offset)
+	}
+	hdr := image[offset:]
+	if binary.LittleEndian.Uint32(hdr[0:]) != 1 {
+		return nil, fmt.Errorf("unexpected microcode update header version at 0x%X: %d",
+			offset, binary.LittleEndian.Uint32(hdr[0:]))
+	}
+	dataSize := uint64(binary.LittleEndian.Uint32(hdr[28:]))
+	totalSize := uint64(binary.LittleEndian.Uint32(hdr[32:]))
+	if dataSize == 0 {
+		dataSize = microcodeDefaultDataSize
+		totalSize = microcodeDefaultDataSize + microcodeHeaderSize
+	}
+	if offset+totalSize > uint64(len(image)) {
+		return nil, fmt.Errorf("microcode update at 0x%X (size: %d) is out of the image", offset, totalSize)
+	}
+
+	result := &microcodeUpdate{
+		Range: pkgbytes.Range{
+			Offset: offset,
+			Length: totalSize,
+		},
+		Signatures: []uint32{binary.LittleEndian.Uint32(hdr[12:])},
+	}
+
+	extTableOffset := microcodeHeaderSize + dataSize
+	if totalSize >= extTableOffset+microcodeExtendedTableHeadSize {
+		count := uint64(binary.LittleEndian.Uint32(hdr[extTableOffset:]))
+		for idx := uint64(0); idx < count; idx++ {
+			sigOffset := extTableOffset + microcodeExtendedTableHeadSize + idx*microcodeExtendedSignatureSize
+			if sigOffset+microcodeExtendedSignatureSize > totalSize {
+				break
+			}
+			result.Signatures = append(result.Signatures, binary.LittleEndian.Uint32(hdr[sigOffset:]))
+		}
+	}
+
+	return result, nil
+}
+
+func getMicrocodeUpdates(image []byte, fitEntries []fit.Entry) ([]*microcodeUpdate, error) {
+	var updates []*microcodeUpdate
+	for _, fitEntry := range fitEntries {
+		switch fitEntry := fitEntry.(type) {
+		case *fit.EntryMicrocodeUpdateEntry:
+			update, err := parseMicrocodeUpdate(image, fitEntry.Headers.Address.Offset(uint64(len(image))))
+			if err != nil {
+				return nil, err
+			}
+			updates = append(updates, update)
+		}
+	}
+	if len(updates) == 0 {
+		return nil, fmt.Errorf("no microcode updates found in FIT")
+	}
+	return updates, nil
+}
+
+// MicrocodeUpdateRanges returns the ranges of all the CPU microcode updates
+// referenced by FIT.
+func MicrocodeUpdateRanges(image []byte, fitEntries []fit.Entry) (pkgbytes.Ranges, error) {
+	updates, err := getMicrocodeUpdates(image, fitEntries)
+	if err != nil {
+		return nil, err
+	}
+	result := make(pkgbytes.Ranges, 0, len(updates))
+	for _, update := range updates {
+		result = append(result, update.Range)
+	}
+	return result, nil
+}
+
+// MeasureCPUMicrocode returns the measurement of the CPU microcode update
+// (referenced by FIT) which is loaded on a CPU with the signature
+// config.CPUSignature.
+//
+// If the CPU signature is not defined, then the measurement could be
+// constructed only if there is exactly one microcode update, otherwise
+// ErrMeasurementSkipped is returned.
+func MeasureCPUMicrocode(config MeasurementConfig, image []byte, fitEntries []fit.Entry) (*Measurement, error) {
+	updates, err := getMicrocodeUpdates(image, fitEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.CPUSignature == 0 {
+		if len(updates) != 1 {
+			return nil, ErrMeasurementSkipped{Reason: fmt.Sprintf("CPU signature is not defined and there are %d microcode updates to choose from", len(updates))}
+		}
+		return NewRangeMeasurement(MeasurementIDCPUMicrocode, updates[0].Range.Offset, updates[0].Range.Length), nil
+	}
+
+	for _, update := range updates {
+		for _, signature := range update.Signatures {
+			if signature == config.CPUSignature {
+				return NewRangeMeasurement(MeasurementIDCPUMicrocode, update.Range.Offset, update.Range.Length), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no microcode update for CPU signature 0x%X", config.CPUSignature)
+}
+
+// MeasureSMBIOSTables returns the measurement of SMBIOS tables.
+//
+// SMBIOS tables are not part of the firmware image, so if they are not
+// defined, then ErrMeasurementSkipped is returned.
+func MeasureSMBIOSTables(tables []byte) (*Measurement, error) {
+	if len(tables) == 0 {
+		return nil, ErrMeasurementSkipped{Reason: "SMBIOS tables are not defined"}
+	}
+	return NewStaticDataMeasurement(MeasurementIDSMBIOSTables, tables), nil
+}
+
+// MeasureEFIBootOrderVariable returns the measurement of UEFI variable
+// "BootOrder". Returns nil if the variable is not defined (in this case
+// boot variables are not measured).
+//
+// Similar to EDK2 (see "MeasureAllBootVariables" in Tcg2Dxe) the digest of
+// a boot variable is the digest of the variable data only.
+func MeasureEFIBootOrderVariable(variables uefi.EFIVariables, isFromImage bool) (*Measurement, error) {
+	bootOrder := variables.Find(consts.GUIDGlobalVariable, "BootOrder")
+	if bootOrder == nil {
+		return nil, nil
+	}
+	return newEFIVariableDataMeasurement(MeasurementIDEFIVariableBootOrder, bootOrder, isFromImage), nil
+}
+
+// MeasureEFIBootVariables returns the measurements of UEFI variables
+// "Boot####" in the order they are referenced in variable "BootOrder".
+func MeasureEFIBootVariables(variables uefi.EFIVariables, isFromImage bool) (Measurements, error) {
+	bootOrder := variables.Find(consts.GUIDGlobalVariable, "BootOrder")
+	if bootOrder == nil {
+		return nil, nil
+	}
+	if len(bootOrder.Data)%2 != 0 {
+		return nil, fmt.Errorf("invalid length of BootOrder: %d", len(bootOrder.Data))
+	}
+
+	var (
+		result  Measurements
+		missing []string
+	)
+	for idx := 0; idx < len(bootOrder.Data); idx += 2 {
+		name := fmt.Sprintf("Boot%04X", binary.LittleEndian.Uint16(bootOrder.Data[idx:]))
+		variable := variables.Find(consts.GUIDGlobalVariable, name)
+		if variable == nil {
+			missing = append(missing, name)
+			continue
+		}
+		result = append(result, newEFIVariableDataMeasurement(MeasurementIDEFIVariablesBoot, variable, isFromImage))
+	}
+	if len(missing) > 0 {
+		return result, fmt.Errorf("variables referenced by BootOrder are not found: %s", strings.Join(missing, ", "))
+	}
+	return result, nil
+}
+
+// newEFIVariableDataMeasurement returns a measurement of the variable data.
+//
+// If the variable was not found in the firmware image, then the data
+// is added as is (instead of a reference to a byte range of the image).
+func newEFIVariableDataMeasurement(id MeasurementID, variable *uefi.EFIVariable, isFromImage bool) *Measurement {
+	m := &Measurement{ID: id}
+	if isFromImage {
+		m.Data = append(m.Data, *NewRangeDataChunk(DataChunkIDEFIVariableData, variable.DataRange.Offset, variable.DataRange.Length))
+	} else {
+		m.Data = append(m.Data, *NewStaticDataChunk(DataChunkIDEFIVariableData, variable.Data))
+	}
+	return m
+}
+
+// getEFIVariables returns the variables to be measured, and if the
+// variables were taken from the firmware image.
+func getEFIVariables(config MeasurementConfig, provider DataProvider) (uefi.EFIVariables, bool) {
+	if config.EFIVariables != nil {
+		return config.EFIVariables, false
+	}
+	return provider.EFIVariables(), true
+}
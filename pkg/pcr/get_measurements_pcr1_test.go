@@ -0,0 +1,106 @@
+package pcr
+
+import (
+	"encoding/binary"
+	stderrors "errors"
+	"testing"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/errors"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+	"github.com/9elements/converged-security-suite/v2/testdata/firmware"
+	"github.com/linuxboot/fiano/pkg/intel/metadata/fit"
+	"github.com/stretchr/testify/require"
+)
+
+// testMicrocodeImage returns an image with a microcode update for each of
+// the signatures and the FIT entries referencing them.
+func testMicrocodeImage(signatures ...uint32) ([]byte, []fit.Entry) {
+	const updateSize = 0x400
+	image := make([]byte, 0x1000)
+	var fitEntries []fit.Entry
+	for idx, signature := range signatures {
+		offset := uint64(idx * updateSize)
+		hdr := image[offset:]
+		binary.LittleEndian.PutUint32(hdr[0:], 1)
+		binary.LittleEndian.PutUint32(hdr[12:], signature)
+		binary.LittleEndian.PutUint32(hdr[28:], updateSize-microcodeHeaderSize)
+		binary.LittleEndian.PutUint32(hdr[32:], updateSize)
+
+		entry := &fit.EntryMicrocodeUpdateEntry{}
+		entry.Headers.Address.SetOffset(offset, uint64(len(image)))
+		fitEntries = append(fitEntries, entry)
+	}
+	return image, fitEntries
+}
+
+func TestMeasureCPUMicrocode(t *testing.T) {
+	image, fitEntries := testMicrocodeImage(0x50657, 0x50656)
+
+	m, err := MeasureCPUMicrocode(MeasurementConfig{CPUSignature: 0x50656}, image, fitEntries)
+	require.NoError(t, err)
+	require.Equal(t, image[0x400:0x800], m.CompileMeasurableData(image))
+
+	var skipped ErrMeasurementSkipped
+	_, err = MeasureCPUMicrocode(MeasurementConfig{}, image, fitEntries)
+	require.ErrorAs(t, err, &skipped)
+	require.Equal(t, "CPU signature is not defined and there are 2 microcode updates to choose from", skipped.Reason)
+
+	_, err = MeasureCPUMicrocode(MeasurementConfig{CPUSignature: 0x906ea}, image, fitEntries)
+	require.Error(t, err)
+	require.False(t, stderrors.As(err, &skipped))
+
+	image, fitEntries = testMicrocodeImage(0x50657)
+	m, err = MeasureCPUMicrocode(MeasurementConfig{}, image, fitEntries)
+	require.NoError(t, err)
+	require.Equal(t, image[:0x400], m.CompileMeasurableData(image))
+}
+
+func TestMeasureSMBIOSTables(t *testing.T) {
+	m, err := MeasureSMBIOSTables([]byte{1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, m.CompileMeasurableData(nil))
+
+	var skipped ErrMeasurementSkipped
+	_, err = MeasureSMBIOSTables(nil)
+	require.ErrorAs(t, err, &skipped)
+	require.Equal(t, "SMBIOS tables are not defined", skipped.Reason)
+}
+
+// skippedMeasurementIDs returns the IDs of the measurements reported
+// as skipped in the collected errors.
+func skippedMeasurementIDs(t *testing.T, err error) MeasurementIDs {
+	if err == nil {
+		return nil
+	}
+	var mErr errors.MultiError
+	require.ErrorAs(t, err, &mErr)
+
+	var result MeasurementIDs
+	for _, item := range mErr {
+		var collectErr ErrCollect
+		var skipped ErrMeasurementSkipped
+		if stderrors.As(item, &collectErr) && stderrors.As(item, &skipped) {
+			result = append(result, collectErr.MeasurementID)
+		}
+	}
+	return result
+}
+
+func TestCollectMeasurementsSkipUndefined(t *testing.T) {
+	fw, err := uefi.ParseUEFIFirmwareBytes(firmware.FakeIntelFirmware)
+	require.NoError(t, err)
+
+	config := DefaultMeasurementConfig
+	config.Flow = FlowIntelCBnT0T
+
+	// By default a measurement with an undefined input is an error.
+	_, warnings, err := newMeasurementsCollector(fw).CollectMeasurements(1, config)
+	require.Error(t, err)
+	require.Equal(t, MeasurementIDs{MeasurementIDSMBIOSTables}, skippedMeasurementIDs(t, err))
+	require.Empty(t, skippedMeasurementIDs(t, warnings))
+
+	config.SkipUndefinedMeasurements = true
+	_, warnings, err = newMeasurementsCollector(fw).CollectMeasurements(1, config)
+	require.Empty(t, skippedMeasurementIDs(t, err))
+	require.Equal(t, MeasurementIDs{MeasurementIDSMBIOSTables}, skippedMeasurementIDs(t, warnings))
+}
@@ -0,0 +1,85 @@
+package pcr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi/consts"
+	fianoGUID "github.com/linuxboot/fiano/pkg/guid"
+)
+
+// secureBootVariable returns the vendor GUID and the name of the UEFI
+// variable measured by the measurement.
+func (id MeasurementID) secureBootVariable() (fianoGUID.GUID, string, bool) {
+	switch id {
+	case MeasurementIDEFIVariableSecureBoot:
+		return consts.GUIDGlobalVariable, "SecureBoot", true
+	case MeasurementIDEFIVariablePK:
+		return consts.GUIDGlobalVariable, "PK", true
+	case MeasurementIDEFIVariableKEK:
+		return consts.GUIDGlobalVariable, "KEK", true
+	case MeasurementIDEFIVariableDB:
+		return consts.GUIDImageSecurityDatabase, "db", true
+	case MeasurementIDEFIVariableDBX:
+		return consts.GUIDImageSecurityDatabase, "dbx", true
+	}
+	return fianoGUID.GUID{}, "", false
+}
+
+// MeasureSecureBootVariable returns the measurement of a Secure Boot
+// policy variable (EV_EFI_VARIABLE_DRIVER_CONFIG).
+//
+// Similar to EDK2 (see "MeasureAllSecureVariables" in Tcg2Dxe) a variable
+// which is not defined is measured with empty data. Variable "SecureBoot"
+// is volatile, so it is never stored in a variable store. If it is not
+// defined explicitly, it is assumed to be enabled iff "PK" is enrolled.
+func MeasureSecureBootVariable(id MeasurementID, variables uefi.EFIVariables, isFromImage bool) (*Measurement, error) {
+	guid, name, ok := id.secureBootVariable()
+	if !ok {
+		return nil, fmt.Errorf("measurement '%s' is not a Secure Boot variable measurement", id)
+	}
+
+	variable := variables.Find(guid, name)
+	if variable == nil && id == MeasurementIDEFIVariableSecureBoot {
+		secureBoot := []byte{0}
+		if pk := variables.Find(consts.GUIDGlobalVariable, "PK"); pk != nil && len(pk.Data) > 0 {
+			secureBoot[0] = 1
+		}
+		variable = &uefi.EFIVariable{
+			GUID: guid,
+			Name: name,
+			Data: secureBoot,
+		}
+		isFromImage = false
+	}
+	if variable == nil {
+		variable = &uefi.EFIVariable{
+			GUID: guid,
+			Name: name,
+			Data: []byte{},
+		}
+		isFromImage = false
+	}
+
+	return newEFIVariableEventMeasurement(id, variable, isFromImage), nil
+}
+
+// newEFIVariableEventMeasurement returns a measurement of the variable as
+// structure UEFI_VARIABLE_DATA (the digest of EV_EFI_VARIABLE_DRIVER_CONFIG
+// events is calculated over the whole structure).
+func newEFIVariableEventMeasurement(id MeasurementID, variable *uefi.EFIVariable, isFromImage bool) *Measurement {
+	name := utf16.Encode([]rune(variable.Name))
+
+	var header bytes.Buffer
+	header.Write(variable.GUID[:])
+	_ = binary.Write(&header, binary.LittleEndian, uint64(len(name)))
+	_ = binary.Write(&header, binary.LittleEndian, uint64(len(variable.Data)))
+	_ = binary.Write(&header, binary.LittleEndian, name)
+
+	m := newEFIVariableDataMeasurement(id, variable, isFromImage)
+	m.Data = append(DataChunks{*NewStaticDataChunk(DataChunkIDEFIVariableHeader, header.Bytes())}, m.Data...)
+	return m
+}
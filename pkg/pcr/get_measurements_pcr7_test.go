@@ -0,0 +1,68 @@
+package pcr
+
+import (
+	"testing"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi/consts"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasureSecureBootVariable(t *testing.T) {
+	variables := uefi.EFIVariables{
+		{
+			GUID: consts.GUIDGlobalVariable,
+			Name: "PK",
+			Data: []byte{0xaa, 0xbb},
+		},
+	}
+
+	t.Run("PK", func(t *testing.T) {
+		m, err := MeasureSecureBootVariable(MeasurementIDEFIVariablePK, variables, false)
+		require.NoError(t, err)
+
+		expected := append([]byte{}, consts.GUIDGlobalVariable[:]...)
+		expected = append(expected, 2, 0, 0, 0, 0, 0, 0, 0) // UnicodeNameLength
+		expected = append(expected, 2, 0, 0, 0, 0, 0, 0, 0) // VariableDataLength
+		expected = append(expected, 'P', 0, 'K', 0)         // UnicodeName
+		expected = append(expected, 0xaa, 0xbb)             // VariableData
+		require.Equal(t, expected, m.CompileMeasurableData(nil))
+	})
+
+	t.Run("SecureBoot_implicit", func(t *testing.T) {
+		m, err := MeasureSecureBootVariable(MeasurementIDEFIVariableSecureBoot, variables, false)
+		require.NoError(t, err)
+
+		data := m.CompileMeasurableData(nil)
+		require.Equal(t, uint8(10), data[16])
+		require.Equal(t, []byte{1}, data[len(data)-1:])
+	})
+
+	t.Run("db_undefined", func(t *testing.T) {
+		m, err := MeasureSecureBootVariable(MeasurementIDEFIVariableDB, variables, false)
+		require.NoError(t, err)
+
+		expected := append([]byte{}, consts.GUIDImageSecurityDatabase[:]...)
+		expected = append(expected, 2, 0, 0, 0, 0, 0, 0, 0) // UnicodeNameLength
+		expected = append(expected, 0, 0, 0, 0, 0, 0, 0, 0) // VariableDataLength
+		expected = append(expected, 'd', 0, 'b', 0)         // UnicodeName
+		require.Equal(t, expected, m.CompileMeasurableData(nil))
+	})
+}
+
+func TestFlowMeasurementIDsPCR7(t *testing.T) {
+	for _, flow := range Flows {
+		if flow == FlowAuto {
+			continue
+		}
+		ids := flow.MeasurementIDs().FilterByPCRIndex(7)
+		require.Equal(t, MeasurementIDs{
+			MeasurementIDEFIVariableSecureBoot,
+			MeasurementIDEFIVariablePK,
+			MeasurementIDEFIVariableKEK,
+			MeasurementIDEFIVariableDB,
+			MeasurementIDEFIVariableDBX,
+			MeasurementIDSeparator,
+		}, ids, flow.String())
+	}
+}
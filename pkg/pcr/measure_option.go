@@ -5,6 +5,7 @@ import (
 	"github.com/google/go-tpm/tpm2"
 
 	"github.com/9elements/converged-security-suite/v2/pkg/registers"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
 	"github.com/linuxboot/fiano/pkg/intel/metadata/manifest"
 )
 
@@ -62,3 +63,43 @@ func (opt SetTPMDevice) Apply(config *MeasurementConfig) error {
 	config.TPMDevice = tpmdetection.Type(opt)
 	return nil
 }
+
+// SetEFIVariables overrides the UEFI variables used for PCR1 and PCR7
+// measurements.
+type SetEFIVariables uefi.EFIVariables
+
+// Apply implements `MeasureOption`
+func (opt SetEFIVariables) Apply(config *MeasurementConfig) error {
+	config.EFIVariables = uefi.EFIVariables(opt)
+	return nil
+}
+
+// SetSMBIOSTables sets the SMBIOS structure table measured into PCR1.
+type SetSMBIOSTables []byte
+
+// Apply implements `MeasureOption`
+func (opt SetSMBIOSTables) Apply(config *MeasurementConfig) error {
+	config.SMBIOSTables = []byte(opt)
+	return nil
+}
+
+// SetCPUSignature sets the CPU signature (CPUID leaf 1, EAX) used to
+// select the measured microcode update.
+type SetCPUSignature uint32
+
+// Apply implements `MeasureOption`
+func (opt SetCPUSignature) Apply(config *MeasurementConfig) error {
+	config.CPUSignature = uint32(opt)
+	return nil
+}
+
+// SetSkipUndefinedMeasurements defines if the measurements with undefined
+// input (SMBIOS tables, CPU signature) are skipped with a warning instead
+// of failing.
+type SetSkipUndefinedMeasurements bool
+
+// Apply implements `MeasureOption`
+func (opt SetSkipUndefinedMeasurements) Apply(config *MeasurementConfig) error {
+	config.SkipUndefinedMeasurements = bool(opt)
+	return nil
+}
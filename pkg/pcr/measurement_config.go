@@ -3,6 +3,7 @@ package pcr
 import (
 	"github.com/9elements/converged-security-suite/v2/pkg/registers"
 	"github.com/9elements/converged-security-suite/v2/pkg/tpmdetection"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
 	"github.com/linuxboot/fiano/pkg/intel/metadata/manifest"
 )
 
@@ -30,6 +31,26 @@ type MeasurementConfig struct {
 	// TPMDevice defines a TPM device version that performed the measurements.
 	// Value TypeNoTPM means undefined
 	TPMDevice tpmdetection.Type
+
+	// EFIVariables overrides the UEFI variables found in the firmware
+	// image (used for PCR1 and PCR7). If nil, the variables of the
+	// default variable store of the firmware image are used.
+	EFIVariables uefi.EFIVariables
+
+	// SMBIOSTables is the SMBIOS structure table measured into PCR1.
+	// SMBIOS tables are generated at runtime, so there is no way to
+	// extract them from the firmware image.
+	SMBIOSTables []byte
+
+	// CPUSignature is the CPU signature (CPUID leaf 1, EAX) used to select
+	// the microcode update measured into PCR1. Value 0 means undefined.
+	CPUSignature uint32
+
+	// SkipUndefinedMeasurements defines if the measurements which could not
+	// be constructed because their input is not defined (SMBIOSTables or
+	// CPUSignature) are skipped with a warning. By default it is an error,
+	// because the resulting PCR value will not match the real one.
+	SkipUndefinedMeasurements bool
 }
 
 // DefaultMeasurementConfig defines the default values for MeasurementConfig.
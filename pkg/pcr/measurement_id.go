@@ -8,6 +8,7 @@ import (
 
 	"github.com/9elements/converged-security-suite/v2/pkg/pcd"
 	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
 	"github.com/linuxboot/fiano/pkg/intel/metadata/fit"
 )
 
@@ -72,6 +73,15 @@ const (
 	MeasurementIDPMUFirmwareData
 	MeasurementIDMicrocodePatch
 	MeasurementIDVideoImageInterpreter
+	MeasurementIDCPUMicrocode
+	MeasurementIDSMBIOSTables
+	MeasurementIDEFIVariableBootOrder
+	MeasurementIDEFIVariablesBoot
+	MeasurementIDEFIVariableSecureBoot
+	MeasurementIDEFIVariablePK
+	MeasurementIDEFIVariableKEK
+	MeasurementIDEFIVariableDB
+	MeasurementIDEFIVariableDBX
 	EndOfMeasurementID
 )
 
@@ -128,6 +138,8 @@ func (id MeasurementID) IsMultiple() bool {
 		return true
 	case MeasurementIDMicrocodePatch:
 		return true
+	case MeasurementIDEFIVariablesBoot:
+		return true
 	}
 	return false
 }
@@ -220,13 +232,31 @@ func (id MeasurementID) String() string {
 		return "Microcode patch file"
 	case MeasurementIDVideoImageInterpreter:
 		return "Interpreter binary that displays the video image"
+	case MeasurementIDCPUMicrocode:
+		return "CPU_microcode"
+	case MeasurementIDSMBIOSTables:
+		return "SMBIOS_tables"
+	case MeasurementIDEFIVariableBootOrder:
+		return "EFI_variable_BootOrder"
+	case MeasurementIDEFIVariablesBoot:
+		return "EFI_variables_Boot####"
+	case MeasurementIDEFIVariableSecureBoot:
+		return "EFI_variable_SecureBoot"
+	case MeasurementIDEFIVariablePK:
+		return "EFI_variable_PK"
+	case MeasurementIDEFIVariableKEK:
+		return "EFI_variable_KEK"
+	case MeasurementIDEFIVariableDB:
+		return "EFI_variable_db"
+	case MeasurementIDEFIVariableDBX:
+		return "EFI_variable_dbx"
 	}
 	return fmt.Sprintf("unknown_measurement_ID_%d", int(id))
 }
 
 // PCRIDs returns in which PCRs the measurement is supposed to be used.
 //
-// Currently we support only PCR0, so everything returns 0 or nil.
+// Currently we support only PCR0, PCR1 and PCR7.
 func (id MeasurementID) PCRIDs() []ID {
 	switch id {
 	case MeasurementIDInit:
@@ -256,7 +286,7 @@ func (id MeasurementID) PCRIDs() []ID {
 	case MeasurementIDDXE:
 		return []ID{0}
 	case MeasurementIDSeparator:
-		return []ID{0}
+		return []ID{0, 1, 7}
 	case MeasurementIDFITPointer:
 		return []ID{0}
 	case MeasurementIDFITHeaders:
@@ -287,6 +317,24 @@ func (id MeasurementID) PCRIDs() []ID {
 		return []ID{0}
 	case MeasurementIDVideoImageInterpreter:
 		return []ID{0}
+	case MeasurementIDCPUMicrocode:
+		return []ID{1}
+	case MeasurementIDSMBIOSTables:
+		return []ID{1}
+	case MeasurementIDEFIVariableBootOrder:
+		return []ID{1}
+	case MeasurementIDEFIVariablesBoot:
+		return []ID{1}
+	case MeasurementIDEFIVariableSecureBoot:
+		return []ID{7}
+	case MeasurementIDEFIVariablePK:
+		return []ID{7}
+	case MeasurementIDEFIVariableKEK:
+		return []ID{7}
+	case MeasurementIDEFIVariableDB:
+		return []ID{7}
+	case MeasurementIDEFIVariableDBX:
+		return []ID{7}
 	}
 	return nil
 }
@@ -337,6 +385,20 @@ func (id MeasurementID) EventLogEventTypes() []*tpmeventlog.EventType {
 		eventTypes = append(eventTypes, eventTypePtr(tpmeventlog.EV_EFI_PLATFORM_FIRMWARE_BLOB))
 	case MeasurementIDVideoImageInterpreter:
 		eventTypes = append(eventTypes, eventTypePtr(tpmeventlog.EV_EFI_PLATFORM_FIRMWARE_BLOB))
+	case MeasurementIDCPUMicrocode:
+		eventTypes = append(eventTypes, eventTypePtr(tpmeventlog.EV_CPU_MICROCODE))
+	case MeasurementIDSMBIOSTables:
+		eventTypes = append(eventTypes, eventTypePtr(tpmeventlog.EV_EFI_HANDOFF_TABLES))
+		eventTypes = append(eventTypes, eventTypePtr(tpmeventlog.EV_EFI_HANDOFF_TABLES2))
+	case MeasurementIDEFIVariableBootOrder, MeasurementIDEFIVariablesBoot:
+		eventTypes = append(eventTypes, eventTypePtr(tpmeventlog.EV_EFI_VARIABLE_BOOT))
+		eventTypes = append(eventTypes, eventTypePtr(tpmeventlog.EV_EFI_VARIABLE_BOOT2))
+	case MeasurementIDEFIVariableSecureBoot,
+		MeasurementIDEFIVariablePK,
+		MeasurementIDEFIVariableKEK,
+		MeasurementIDEFIVariableDB,
+		MeasurementIDEFIVariableDBX:
+		eventTypes = append(eventTypes, eventTypePtr(tpmeventlog.EV_EFI_VARIABLE_DRIVER_CONFIG))
 	}
 	return eventTypes
 }
@@ -380,6 +442,7 @@ type DataProvider interface {
 	FITEntries() []fit.Entry
 	PCDData() pcd.ParsedFirmware
 	PSPFirmware() *amd.PSPFirmware
+	EFIVariables() uefi.EFIVariables
 }
 
 // MeasureFunc performs a measurement.
@@ -630,6 +693,27 @@ func (id MeasurementID) singleMeasureFunc() singleMeasureFunc {
 			}
 			return result, err
 		}
+	case MeasurementIDCPUMicrocode:
+		return func(config MeasurementConfig, provider DataProvider) (*Measurement, error) {
+			return MeasureCPUMicrocode(config, provider.Firmware().Buf(), provider.FITEntries())
+		}
+	case MeasurementIDSMBIOSTables:
+		return func(config MeasurementConfig, provider DataProvider) (*Measurement, error) {
+			return MeasureSMBIOSTables(config.SMBIOSTables)
+		}
+	case MeasurementIDEFIVariableBootOrder:
+		return func(config MeasurementConfig, provider DataProvider) (*Measurement, error) {
+			return MeasureEFIBootOrderVariable(getEFIVariables(config, provider))
+		}
+	case MeasurementIDEFIVariableSecureBoot,
+		MeasurementIDEFIVariablePK,
+		MeasurementIDEFIVariableKEK,
+		MeasurementIDEFIVariableDB,
+		MeasurementIDEFIVariableDBX:
+		return func(config MeasurementConfig, provider DataProvider) (*Measurement, error) {
+			variables, isFromImage := getEFIVariables(config, provider)
+			return MeasureSecureBootVariable(id, variables, isFromImage)
+		}
 	}
 	return nil
 }
@@ -663,6 +747,10 @@ func (id MeasurementID) multipleMeasureFunc() MeasureFunc {
 			return MeasureEntryFromBIOSDirectory(amd.MicrocodePatchEntry, nil,
 				pspFirmware.BIOSDirectoryLevel1, pspFirmware.BIOSDirectoryLevel2, MeasurementIDMicrocodePatch)
 		}
+	case MeasurementIDEFIVariablesBoot:
+		return func(config MeasurementConfig, provider DataProvider) (Measurements, error) {
+			return MeasureEFIBootVariables(getEFIVariables(config, provider))
+		}
 	}
 	return nil
 }
@@ -736,6 +824,8 @@ const (
 	DataChunkIDKeyManifestSignature
 	DataChunkIDBootPolicyManifestSignature
 	DataChunkIDIBBDigest
+	DataChunkIDEFIVariableHeader
+	DataChunkIDEFIVariableData
 	EndOfDataChunkID
 )
 
@@ -788,6 +878,10 @@ func (id DataChunkID) String() string {
 		return "boot_policy_manifest_signature"
 	case DataChunkIDIBBDigest:
 		return "IBB_digest"
+	case DataChunkIDEFIVariableHeader:
+		return "EFI_variable_header"
+	case DataChunkIDEFIVariableData:
+		return "EFI_variable_data"
 	}
 	return fmt.Sprintf("unknown_data_chunk_ID_%d", int(id))
 }
@@ -0,0 +1,275 @@
+package pcr
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/registers"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+	"github.com/linuxboot/fiano/pkg/bytes"
+)
+
+// MeasurementsReportVersion is the version of the format of MeasurementsReport.
+// It should be incremented on every incompatible change of the format
+// (and of MeasurementsReportJSONSchema).
+const MeasurementsReportVersion = 1
+
+// HexBytes is a slice of bytes which is serialized to JSON as a hex string
+// (instead of base64).
+type HexBytes []byte
+
+// String implements fmt.Stringer.
+func (b HexBytes) String() string {
+	return hex.EncodeToString(b)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (b HexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *HexBytes) UnmarshalJSON(in []byte) error {
+	var s string
+	if err := json.Unmarshal(in, &s); err != nil {
+		return err
+	}
+	r, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return fmt.Errorf("unable to parse hex '%s': %w", s, err)
+	}
+	*b = r
+	return nil
+}
+
+// MeasurementsReport is a self-sufficient description of how a PCR value
+// is calculated: it contains everything required to reproduce the
+// PCR value without parsing the firmware image.
+//
+// The JSON representation is described by MeasurementsReportJSONSchema.
+type MeasurementsReport struct {
+	Version       uint
+	PCRIndex      ID
+	Flow          string
+	TPMLocality   uint8
+	HashAlgorithm string
+
+	// InitialValue is the value of the PCR before the first extend.
+	InitialValue HexBytes
+
+	// Registers are the register values used to construct the measurements.
+	Registers registers.Registers `json:",omitempty"`
+
+	Measurements []MeasurementReport
+
+	// FinalValue is the resulting PCR value.
+	FinalValue HexBytes
+}
+
+// MeasurementReport describes a single measurement of a MeasurementsReport.
+type MeasurementReport struct {
+	ID MeasurementID
+
+	// IsFake is true if the measurement does not extend the PCR (it is
+	// provided for information only).
+	IsFake bool `json:",omitempty"`
+
+	// NoHash is true if the measured data is extended into the PCR as is
+	// (without hashing).
+	NoHash bool `json:",omitempty"`
+
+	Data []DataChunkReport
+
+	// Digest is the value the PCR is extended with. It is empty for
+	// fake measurements.
+	Digest HexBytes `json:",omitempty"`
+
+	// PCRValue is the value of the PCR after the extend. It is empty for
+	// fake measurements.
+	PCRValue HexBytes `json:",omitempty"`
+}
+
+// DataChunkReport describes a single data chunk of a MeasurementReport.
+type DataChunkReport struct {
+	ID DataChunkID `json:",omitempty"`
+
+	// Range is the byte range of the firmware image. It is nil for
+	// static data chunks.
+	Range *bytes.Range `json:",omitempty"`
+
+	// Data is the measured data. It is always set for static data chunks.
+	// For range data chunks it is set only if requested (see
+	// NewMeasurementsReport).
+	Data HexBytes `json:",omitempty"`
+
+	// Digest is the hash of the data chunk alone.
+	Digest HexBytes
+}
+
+// NewMeasurementsReport calculates the PCR value and returns the report
+// describing each step of the calculation.
+//
+// If includeRangeData is true, then the data referenced by range data
+// chunks is also included into the report (which makes the report
+// significantly bigger, but allows to reproduce the PCR value without
+// the firmware image).
+func NewMeasurementsReport(
+	image []byte,
+	pcrID ID,
+	flow Flow,
+	hashAlgo tpmeventlog.TPMAlgorithm,
+	regs registers.Registers,
+	measurements Measurements,
+	includeRangeData bool,
+) (*MeasurementsReport, error) {
+	hasher, err := tpmeventlog.NewHasher(hashAlgo)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MeasurementsReport{
+		Version:       MeasurementsReportVersion,
+		PCRIndex:      pcrID,
+		Flow:          flow.String(),
+		TPMLocality:   flow.TPMLocality(),
+		HashAlgorithm: strings.ToUpper(tpmeventlog.HashAlgorithmName(hashAlgo)),
+		Registers:     regs,
+	}
+
+	pcrValue := make([]byte, hasher.Size())
+	pcrValue[len(pcrValue)-1] = flow.InitialValue(pcrID)
+	report.InitialValue = append(HexBytes{}, pcrValue...)
+
+	for _, m := range measurements {
+		if m == nil {
+			continue
+		}
+		mReport := MeasurementReport{
+			ID:     m.ID,
+			IsFake: m.IsFake(),
+			NoHash: m.NoHash(),
+		}
+
+		for _, chunk := range m.Data {
+			if chunk.ForceData == nil && chunk.Range.End() > uint64(len(image)) {
+				return nil, fmt.Errorf("data chunk %s of measurement %s is out of the image (size: %d)",
+					chunk, m.ID, len(image))
+			}
+			data := chunk.CompileMeasurableData(image)
+			hasher.Reset()
+			_, _ = hasher.Write(data)
+			chunkReport := DataChunkReport{
+				ID:     chunk.ID,
+				Digest: hasher.Sum(nil),
+			}
+			if chunk.ForceData != nil {
+				chunkReport.Data = append(HexBytes{}, data...)
+			} else {
+				r := chunk.Range
+				chunkReport.Range = &r
+				if includeRangeData {
+					chunkReport.Data = append(HexBytes{}, data...)
+				}
+			}
+			mReport.Data = append(mReport.Data, chunkReport)
+		}
+		hasher.Reset()
+
+		digest, err := m.Calculate(image, hasher)
+		if err != nil {
+			return nil, fmt.Errorf("unable to calculate measurement %s: %w", m.ID, err)
+		}
+		if digest != nil {
+			_, _ = hasher.Write(pcrValue)
+			_, _ = hasher.Write(digest)
+			pcrValue = hasher.Sum(nil)
+			hasher.Reset()
+
+			mReport.Digest = digest
+			mReport.PCRValue = append(HexBytes{}, pcrValue...)
+		}
+
+		report.Measurements = append(report.Measurements, mReport)
+	}
+
+	report.FinalValue = pcrValue
+	return report, nil
+}
+
+// MeasurementsReportJSONSchema is the JSON schema of the JSON
+// representation of MeasurementsReport.
+const MeasurementsReportJSONSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "$id": "https://github.com/9elements/converged-security-suite/pkg/pcr/measurements_report.schema.json",
+  "title": "MeasurementsReport",
+  "description": "Description of how a PCR value is calculated from a firmware image",
+  "type": "object",
+  "definitions": {
+    "hex": {
+      "type": "string",
+      "pattern": "^[0-9a-f]*$"
+    },
+    "range": {
+      "type": "object",
+      "properties": {
+        "Offset": {"type": "integer", "minimum": 0},
+        "Length": {"type": "integer", "minimum": 0}
+      },
+      "required": ["Offset", "Length"]
+    },
+    "dataChunk": {
+      "type": "object",
+      "properties": {
+        "ID": {"type": "string", "description": "DataChunkID, absent if the chunk has no special meaning"},
+        "Range": {"$ref": "#/definitions/range", "description": "byte range of the firmware image, absent for static data"},
+        "Data": {"$ref": "#/definitions/hex", "description": "the measured data, always present for static data"},
+        "Digest": {"$ref": "#/definitions/hex", "description": "hash of this data chunk alone"}
+      },
+      "required": ["Digest"]
+    },
+    "measurement": {
+      "type": "object",
+      "properties": {
+        "ID": {"type": "string", "description": "MeasurementID"},
+        "IsFake": {"type": "boolean", "description": "the measurement does not extend the PCR"},
+        "NoHash": {"type": "boolean", "description": "the data is extended into the PCR without hashing"},
+        "Data": {
+          "type": ["array", "null"],
+          "items": {"$ref": "#/definitions/dataChunk"},
+          "description": "data chunks, the measured data is the concatenation of them"
+        },
+        "Digest": {"$ref": "#/definitions/hex", "description": "the value the PCR is extended with"},
+        "PCRValue": {"$ref": "#/definitions/hex", "description": "the PCR value after the extend"}
+      },
+      "required": ["ID", "Data"]
+    }
+  },
+  "properties": {
+    "Version": {"type": "integer", "const": 1},
+    "PCRIndex": {"type": "integer", "minimum": 0},
+    "Flow": {"type": "string"},
+    "TPMLocality": {"type": "integer", "minimum": 0, "maximum": 4},
+    "HashAlgorithm": {"type": "string", "enum": ["SHA1", "SHA256", "SHA384", "SHA512", "SM3_256"]},
+    "InitialValue": {"$ref": "#/definitions/hex"},
+    "Registers": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string"},
+          "value": {"type": "string", "contentEncoding": "base64"}
+        },
+        "required": ["id", "value"]
+      }
+    },
+    "Measurements": {
+      "type": ["array", "null"],
+      "items": {"$ref": "#/definitions/measurement"}
+    },
+    "FinalValue": {"$ref": "#/definitions/hex"}
+  },
+  "required": ["Version", "PCRIndex", "Flow", "TPMLocality", "HashAlgorithm", "InitialValue", "Measurements", "FinalValue"]
+}
+`
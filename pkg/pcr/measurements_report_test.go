@@ -0,0 +1,69 @@
+package pcr
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/registers"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+)
+
+func TestNewMeasurementsReport(t *testing.T) {
+	image := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	measurements := Measurements{
+		NewRangeMeasurement(MeasurementIDPCR0DATA, 2, 4),
+		{
+			ID: MeasurementIDIBBFake,
+			Data: DataChunks{
+				*NewRangeDataChunk(DataChunkIDUndefined, 8, 2),
+			},
+		},
+		NewStaticDataMeasurement(MeasurementIDSeparator, []byte{0, 0, 0, 0}),
+	}
+	regs := registers.Registers{registers.ACMPolicyStatus(0x1234)}
+
+	report, err := NewMeasurementsReport(image, 0, FlowIntelLegacyTXTEnabled, tpmeventlog.TPMAlgorithmSHA1, regs, measurements, false)
+	require.NoError(t, err)
+
+	require.Equal(t, "SHA1", report.HashAlgorithm)
+	require.Equal(t, uint8(3), report.TPMLocality)
+	require.Equal(t, HexBytes(append(make([]byte, 19), 3)), report.InitialValue)
+	require.Len(t, report.Measurements, 3)
+	require.Equal(t, measurements.Calculate(image, 3, sha1.New(), nil), []byte(report.FinalValue))
+
+	require.True(t, report.Measurements[1].IsFake)
+	require.Nil(t, report.Measurements[1].PCRValue)
+	require.Nil(t, report.Measurements[0].Data[0].Data)
+	require.Equal(t, HexBytes{0, 0, 0, 0}, report.Measurements[2].Data[0].Data)
+	require.Equal(t, report.Measurements[2].PCRValue, report.FinalValue)
+
+	d := sha1.Sum(image[2:6])
+	require.Equal(t, HexBytes(d[:]), report.Measurements[0].Data[0].Digest)
+	require.Equal(t, HexBytes(d[:]), report.Measurements[0].Digest)
+
+	b, err := json.Marshal(report)
+	require.NoError(t, err)
+	var reportCopy MeasurementsReport
+	require.NoError(t, json.Unmarshal(b, &reportCopy))
+	require.Equal(t, report.FinalValue, reportCopy.FinalValue)
+	require.Equal(t, report.Measurements, reportCopy.Measurements)
+
+	t.Run("include_range_data", func(t *testing.T) {
+		report, err := NewMeasurementsReport(image, 0, FlowIntelLegacyTXTEnabled, tpmeventlog.TPMAlgorithmSHA1, nil, measurements, true)
+		require.NoError(t, err)
+		require.Equal(t, HexBytes{2, 3, 4, 5}, report.Measurements[0].Data[0].Data)
+	})
+
+	t.Run("out_of_range", func(t *testing.T) {
+		_, err := NewMeasurementsReport(image[:4], 0, FlowIntelLegacyTXTEnabled, tpmeventlog.TPMAlgorithmSHA1, nil, measurements, false)
+		require.Error(t, err)
+	})
+}
+
+func TestMeasurementsReportJSONSchema(t *testing.T) {
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(MeasurementsReportJSONSchema), &schema))
+}
@@ -14,11 +14,10 @@ func Replay(eventLog *tpmeventlog.TPMEventLog, pcrIndex pcr.ID, hashAlgo tpmeven
 	if logOut == nil {
 		logOut = ioutil.Discard
 	}
-	hash, err := hashAlgo.Hash()
+	hasher, err := tpmeventlog.NewHasher(hashAlgo)
 	if err != nil {
-		return nil, tpmeventlog.ErrNotSupportedHashAlgo{TPMAlgo: hashAlgo}
+		return nil, err
 	}
-	hasher := hash.New()
 
 	events, err := eventLog.FilterEvents(pcrIndex, hashAlgo)
 	if err != nil {
@@ -29,14 +28,14 @@ func Replay(eventLog *tpmeventlog.TPMEventLog, pcrIndex pcr.ID, hashAlgo tpmeven
 	//
 	// Different PCR values has different rules how to set the initial value:
 	// * PCR0 is initially filled with zeros, but with the last byte equals to TPM initialization locality.
-	// * PCR1 is initially just filled with zeros.
+	// * PCR1 and PCR7 are initially just filled with zeros.
 	// * Some PCR values are initially filled with 0xFF-s.
 	var result []byte
 	switch pcrIndex {
-	// We currently support only PCR0 and PCR1
+	// We currently support only PCR0, PCR1 and PCR7
 	case 0:
 		// The locality to be determined from EventLog, so do not initialize it, yet.
-	case 1:
+	case 1, 7:
 		// The initial value is always a bunch of zeros.
 		result = make([]byte, hasher.Size())
 		_, _ = fmt.Fprintf(logOut, "set(0x%X)\n", result)
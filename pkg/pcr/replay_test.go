@@ -40,14 +40,17 @@ func TestReplay(t *testing.T) {
 
 	// An EventLog for our unit-tests would be:
 	//
-	// PCR # |  Algo  |         Type         |  Digest  | Data
-	// ------|--------|----------------------|----------|----------------------------------
-	//   0   | SHA1   | EV_NO_ACTION         | 00..0002 | []byte("StartupLocality\x00\x01")
-	//   0   | SHA256 | EV_NO_ACTION         | 00..0003 | nil
-	//   0   | SHA1   | EV_S_CRTM_CONTENTS   | 00..0004 | nil
-	//   0   | SHA256 | EV_S_CRTM_CONTENTS   | 00..0005 | nil
-	//   1   | SHA1   | EV_S_CRTM_CONTENTS   | 00..0006 | nil
-	//   1   | SHA1   | EV_EFI_VARIABLE_BOOT | 00..0007 | nil
+	// PCR # |  Algo  |             Type              |  Digest  | Data
+	// ------|--------|-------------------------------|----------|----------------------------------
+	//   0   | SHA1   | EV_NO_ACTION                  | 00..0002 | []byte("StartupLocality\x00\x01")
+	//   0   | SHA256 | EV_NO_ACTION                  | 00..0003 | nil
+	//   0   | SHA1   | EV_S_CRTM_CONTENTS            | 00..0004 | nil
+	//   0   | SHA256 | EV_S_CRTM_CONTENTS            | 00..0005 | nil
+	//   1   | SHA1   | EV_S_CRTM_CONTENTS            | 00..0006 | nil
+	//   1   | SHA1   | EV_EFI_VARIABLE_BOOT          | 00..0007 | nil
+	//   7   | SHA1   | EV_EFI_VARIABLE_DRIVER_CONFIG | 00..0008 | nil
+	//   7   | SHA1   | EV_SEPARATOR                  | 00..0009 | nil
+
 	eventLog := &tpmeventlog.TPMEventLog{
 		Events: []*tpmeventlog.Event{
 			{
@@ -100,6 +103,22 @@ func TestReplay(t *testing.T) {
 					Digest:   makeMeasurementDigest(tpm2.AlgSHA1, 7),
 				},
 			},
+			{
+				PCRIndex: 7,
+				Type:     tpmeventlog.EV_EFI_VARIABLE_DRIVER_CONFIG,
+				Digest: &tpmeventlog.Digest{
+					HashAlgo: tpm2.AlgSHA1,
+					Digest:   makeMeasurementDigest(tpm2.AlgSHA1, 8),
+				},
+			},
+			{
+				PCRIndex: 7,
+				Type:     tpmeventlog.EV_SEPARATOR,
+				Digest: &tpmeventlog.Digest{
+					HashAlgo: tpm2.AlgSHA1,
+					Digest:   makeMeasurementDigest(tpm2.AlgSHA1, 9),
+				},
+			},
 		},
 	}
 
@@ -120,6 +139,13 @@ func TestReplay(t *testing.T) {
 				// 00..0006 and 00..0007, this: {0, 6, 7}.
 				require.Equal(t, makeFinalDigest(hashAlgo, []uint8{0, 6, 7}), r)
 			})
+			t.Run("pcr7", func(t *testing.T) {
+				r, err := Replay(eventLog, 7, hashAlgo, nil)
+				require.NoError(t, err)
+				// The init value ends with 0, and the measurements are
+				// 00..0008 and 00..0009, this: {0, 8, 9}.
+				require.Equal(t, makeFinalDigest(hashAlgo, []uint8{0, 8, 9}), r)
+			})
 		}
 	})
 	t.Run("negative", func(t *testing.T) {
@@ -127,6 +153,9 @@ func TestReplay(t *testing.T) {
 		// This is to avoid returning wrong data to an user.
 
 		for pcrID := ID(2); ; pcrID++ {
+			if pcrID == 7 {
+				continue
+			}
 			for _, hashAlgo := range []tpm2.Algorithm{tpm2.AlgSHA1} {
 				t.Run("not_supported/pcr%d", func(t *testing.T) {
 					_, err := Replay(eventLog, pcrID, hashAlgo, nil)
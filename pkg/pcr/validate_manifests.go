@@ -17,6 +17,10 @@ func (v ValidateManifests) Validate(firmware Firmware) error {
 		return fmt.Errorf("unable to parse FIT entries: %w", err)
 	}
 
+	if isBootGuardV1(fitEntries) {
+		return v.validateBootGuardV1(firmware, fitEntries)
+	}
+
 	km, kmFIT, err := getKeyManifest(fitEntries)
 	if err != nil {
 		return fmt.Errorf("unable to get Key Manifest: %w", err)
@@ -45,3 +49,33 @@ func (v ValidateManifests) Validate(firmware Firmware) error {
 
 	return nil
 }
+
+func (v ValidateManifests) validateBootGuardV1(firmware Firmware, fitEntries []fit.Entry) error {
+	km, kmFIT, err := getBootGuardV1KeyManifest(fitEntries)
+	if err != nil {
+		return fmt.Errorf("unable to get Key Manifest: %w", err)
+	}
+
+	if err := km.Verify(kmFIT.DataSegmentBytes); err != nil {
+		return fmt.Errorf("unable to confirm KM signature: %w", err)
+	}
+
+	bpm, bpmFIT, err := getBootGuardV1BootPolicyManifest(fitEntries)
+	if err != nil {
+		return fmt.Errorf("unable to get Boot Policy Manifest: %w", err)
+	}
+
+	if err := bpm.Verify(bpmFIT.DataSegmentBytes); err != nil {
+		return fmt.Errorf("unable to confirm BPM signature: %w", err)
+	}
+
+	if err := km.ValidateBPMKey(bpm.PMSE.KeySignature); err != nil {
+		return fmt.Errorf("key chain is invalid: %w", err)
+	}
+
+	if err := bpm.ValidateIBB(firmware.Buf()); err != nil {
+		return fmt.Errorf("IBB signature in BPM is not valid: %w", err)
+	}
+
+	return nil
+}
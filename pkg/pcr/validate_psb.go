@@ -0,0 +1,146 @@
+package pcr
+
+import (
+	"fmt"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/psb"
+	amd "github.com/linuxboot/fiano/pkg/amd/manifest"
+)
+
+const (
+	// biosDirectoryOEMPublicKeyEntry is the BIOS directory entry with
+	// the OEM public key token (signed by the AMD root key).
+	biosDirectoryOEMPublicKeyEntry = amd.BIOSDirectoryTableEntryType(0x05)
+
+	// biosDirectoryRTMSignatureEntry is the BIOS directory entry with
+	// the signature of the BIOS RTM volume (made by the OEM key).
+	biosDirectoryRTMSignatureEntry = amd.BIOSDirectoryTableEntryType(0x07)
+)
+
+// ValidatePSB validates the AMD Platform Secure Boot (PSB) chain of trust:
+// AMD root key -> OEM key -> BIOS RTM volume.
+type ValidatePSB struct{}
+
+// Validate implements Validator.
+func (ValidatePSB) Validate(firmware Firmware) error {
+	_, err := GetPSBChain(firmware)
+	return err
+}
+
+// PSBChain is the validated PSB chain of trust.
+type PSBChain struct {
+	RootKey *psb.Key
+	OEMKey  *psb.Key
+}
+
+// FuseHashCandidates returns the possible values of the OEM key hash
+// fused into the SoC, to be compared with the values reported by the PSP
+// (see registers MP0_C2P_MSG_37 and MP0_C2P_MSG_38).
+func (chain *PSBChain) FuseHashCandidates() []psb.FuseHashCandidate {
+	return chain.OEMKey.FuseHashCandidates()
+}
+
+// GetPSBChain parses and validates the PSB chain of trust of the firmware.
+func GetPSBChain(firmware Firmware) (*PSBChain, error) {
+	amdFirmware, err := amd.NewAMDFirmware(firmware)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse AMD firmware: %w", err)
+	}
+	return getPSBChain(firmware.Buf(), amdFirmware.PSPFirmware())
+}
+
+func getPSBChain(image []byte, pspFirmware *amd.PSPFirmware) (*PSBChain, error) {
+	if err := checkPSPFirmwareFound(pspFirmware); err != nil {
+		return nil, err
+	}
+
+	rootKeyBytes, err := findPSPDirectoryEntryData(image, amd.AMDPublicKeyEntry, pspFirmware.PSPDirectoryLevel1, pspFirmware.PSPDirectoryLevel2)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get AMD root key: %w", err)
+	}
+	rootKey, err := psb.ParseKey(rootKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse AMD root key: %w", err)
+	}
+	if rootKey.KeyUsage != psb.KeyUsageAMDSigning {
+		return nil, fmt.Errorf("AMD root key has invalid usage: %s", rootKey.KeyUsage)
+	}
+	if !rootKey.IsSelfSigned() {
+		return nil, fmt.Errorf("AMD root key %s is certified by another key %s", rootKey.KeyID, rootKey.CertifyingKeyID)
+	}
+
+	oemKeyBytes, err := findBIOSDirectoryEntryData(image, biosDirectoryOEMPublicKeyEntry, pspFirmware.BIOSDirectoryLevel1, pspFirmware.BIOSDirectoryLevel2)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get OEM key: %w", err)
+	}
+	oemKey, err := psb.ParseKey(oemKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse OEM key: %w", err)
+	}
+	if oemKey.KeyUsage != psb.KeyUsageOEMSigning {
+		return nil, fmt.Errorf("OEM key has invalid usage: %s", oemKey.KeyUsage)
+	}
+	if err := oemKey.VerifyCertifiedBy(rootKey, oemKeyBytes); err != nil {
+		return nil, fmt.Errorf("OEM key is not certified by AMD root key: %w", err)
+	}
+
+	rtmVolume, err := findBIOSDirectoryEntryData(image, amd.BIOSRTMVolumeEntry, pspFirmware.BIOSDirectoryLevel1, pspFirmware.BIOSDirectoryLevel2)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get BIOS RTM volume: %w", err)
+	}
+	rtmSignature, err := findBIOSDirectoryEntryData(image, biosDirectoryRTMSignatureEntry, pspFirmware.BIOSDirectoryLevel1, pspFirmware.BIOSDirectoryLevel2)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get BIOS RTM volume signature: %w", err)
+	}
+	if err := oemKey.VerifySignature(rtmVolume, rtmSignature); err != nil {
+		return nil, fmt.Errorf("BIOS RTM volume is not signed by OEM key: %w", err)
+	}
+
+	return &PSBChain{
+		RootKey: rootKey,
+		OEMKey:  oemKey,
+	}, nil
+}
+
+// findPSPDirectoryEntryData returns the data of the first PSP directory entry
+// of type `entryType`, level 2 directory is preferred.
+func findPSPDirectoryEntryData(image []byte, entryType amd.PSPDirectoryTableEntryType,
+	pspDirectoryLevel1, pspDirectoryLevel2 *amd.PSPDirectoryTable,
+) ([]byte, error) {
+	for _, pspDirectory := range []*amd.PSPDirectoryTable{pspDirectoryLevel2, pspDirectoryLevel1} {
+		if pspDirectory == nil {
+			continue
+		}
+		for _, entry := range pspDirectory.Entries {
+			if entry.Type == entryType {
+				return getImageData(image, entry.LocationOrValue, uint64(entry.Size))
+			}
+		}
+	}
+	return nil, fmt.Errorf("PSP directory entry 0x%X is not found", entryType)
+}
+
+// findBIOSDirectoryEntryData returns the data of the first BIOS directory
+// entry of type `entryType`, level 2 directory is preferred.
+func findBIOSDirectoryEntryData(image []byte, entryType amd.BIOSDirectoryTableEntryType,
+	biosDirectoryLevel1, biosDirectoryLevel2 *amd.BIOSDirectoryTable,
+) ([]byte, error) {
+	for _, biosDirectory := range []*amd.BIOSDirectoryTable{biosDirectoryLevel2, biosDirectoryLevel1} {
+		if biosDirectory == nil {
+			continue
+		}
+		for _, entry := range biosDirectory.Entries {
+			if entry.Type == entryType {
+				return getImageData(image, entry.SourceAddress, uint64(entry.Size))
+			}
+		}
+	}
+	return nil, fmt.Errorf("BIOS directory entry 0x%X is not found", entryType)
+}
+
+func getImageData(image []byte, offset, length uint64) ([]byte, error) {
+	if offset+length < offset || offset+length > uint64(len(image)) {
+		return nil, fmt.Errorf("range [0x%X:0x%X] is out of the image (size: 0x%X)", offset, offset+length, len(image))
+	}
+	return image[offset : offset+length], nil
+}
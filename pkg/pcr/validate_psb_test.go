@@ -0,0 +1,93 @@
+package pcr
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/psb"
+	amd "github.com/linuxboot/fiano/pkg/amd/manifest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPSBChain(t *testing.T) {
+	rootPrivKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	oemPrivKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	rootKeyID := psb.KeyID{1}
+	rootKey := psb.NewKey(&rootPrivKey.PublicKey, rootKeyID, rootKeyID, psb.KeyUsageAMDSigning)
+	oemKey := psb.NewKey(&oemPrivKey.PublicKey, psb.KeyID{2}, rootKeyID, psb.KeyUsageOEMSigning)
+	require.NoError(t, oemKey.SetSignature(rootPrivKey))
+
+	rootKeyBytes, err := rootKey.MarshalBinary()
+	require.NoError(t, err)
+	oemKeyBytes, err := oemKey.MarshalBinary()
+	require.NoError(t, err)
+	rtmVolume := make([]byte, 0x1000)
+	_, err = rand.Read(rtmVolume)
+	require.NoError(t, err)
+	rtmSignature, err := psb.Sign(oemPrivKey, rtmVolume)
+	require.NoError(t, err)
+
+	var image []byte
+	appendData := func(data []byte) (uint64, uint32) {
+		offset := uint64(len(image))
+		image = append(image, data...)
+		return offset, uint32(len(data))
+	}
+	rootKeyOffset, rootKeySize := appendData(rootKeyBytes)
+	oemKeyOffset, oemKeySize := appendData(oemKeyBytes)
+	rtmVolumeOffset, rtmVolumeSize := appendData(rtmVolume)
+	rtmSignatureOffset, rtmSignatureSize := appendData(rtmSignature)
+
+	pspFirmware := &amd.PSPFirmware{
+		PSPDirectoryLevel1: &amd.PSPDirectoryTable{
+			Entries: []amd.PSPDirectoryTableEntry{
+				{Type: amd.AMDPublicKeyEntry, LocationOrValue: rootKeyOffset, Size: rootKeySize},
+			},
+		},
+		BIOSDirectoryLevel1: &amd.BIOSDirectoryTable{
+			Entries: []amd.BIOSDirectoryTableEntry{
+				{Type: biosDirectoryOEMPublicKeyEntry, SourceAddress: oemKeyOffset, Size: oemKeySize},
+				{Type: amd.BIOSRTMVolumeEntry, SourceAddress: rtmVolumeOffset, Size: rtmVolumeSize},
+				{Type: biosDirectoryRTMSignatureEntry, SourceAddress: rtmSignatureOffset, Size: rtmSignatureSize},
+			},
+		},
+	}
+
+	chain, err := getPSBChain(image, pspFirmware)
+	require.NoError(t, err)
+	require.Equal(t, rootKey, chain.RootKey)
+	require.Equal(t, oemKey, chain.OEMKey)
+	require.Equal(t, oemKey.FuseHashCandidates(), chain.FuseHashCandidates())
+
+	t.Run("corrupted_rtm_volume", func(t *testing.T) {
+		corrupted := append([]byte{}, image...)
+		corrupted[rtmVolumeOffset] ^= 1
+		_, err := getPSBChain(corrupted, pspFirmware)
+		require.Error(t, err)
+	})
+
+	t.Run("corrupted_oem_key", func(t *testing.T) {
+		corrupted := append([]byte{}, image...)
+		corrupted[oemKeyOffset+uint64(oemKey.SignedDataSize())-1] ^= 1
+		_, err := getPSBChain(corrupted, pspFirmware)
+		require.Error(t, err)
+	})
+
+	t.Run("no_oem_key", func(t *testing.T) {
+		pspFirmware := *pspFirmware
+		pspFirmware.BIOSDirectoryLevel1 = &amd.BIOSDirectoryTable{
+			Entries: pspFirmware.BIOSDirectoryLevel1.Entries[1:],
+		}
+		_, err := getPSBChain(image, &pspFirmware)
+		require.Error(t, err)
+	})
+
+	t.Run("out_of_image", func(t *testing.T) {
+		_, err := getPSBChain(image[:rtmSignatureOffset], pspFirmware)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,519 @@
+package pcrbruteforcer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/errors"
+	"github.com/9elements/converged-security-suite/v2/pkg/pcd"
+	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
+	"github.com/9elements/converged-security-suite/v2/pkg/registers"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	pkgbytes "github.com/linuxboot/fiano/pkg/bytes"
+	"github.com/linuxboot/fiano/pkg/intel/metadata/fit"
+	"github.com/linuxboot/fiano/pkg/intel/metadata/manifest/bootpolicy"
+)
+
+const (
+	// HypothesisStrategyNameBaseline is the name of the hypothesis that the
+	// measurements were performed exactly as expected.
+	HypothesisStrategyNameBaseline = "baseline"
+)
+
+// Hypothesis is an assumption about how the measurements were performed
+// on the platform, which differs from the expected way.
+type Hypothesis struct {
+	// Strategy is the name of the HypothesisStrategy which made
+	// the hypothesis.
+	Strategy string
+
+	// Amendments describes how the hypothesis differs from the expected
+	// way to perform the measurements. It is empty for the baseline.
+	Amendments []string `json:",omitempty"`
+
+	// Locality is the TPM locality the PCR0 is initialized with.
+	Locality uint8
+
+	// Measurements are the measurements to be checked.
+	Measurements pcr.Measurements `json:"-"`
+}
+
+// HypothesisInput is the expected way to perform the measurements,
+// which is the source of alternatives for a HypothesisStrategy.
+type HypothesisInput struct {
+	Firmware       pcr.Firmware
+	Flow           pcr.Flow
+	Measurements   pcr.Measurements
+	MeasureOptions []pcr.MeasureOption
+}
+
+// baseline returns the hypothesis that the measurements were performed
+// exactly as expected.
+func (input HypothesisInput) baseline() Hypothesis {
+	return Hypothesis{
+		Strategy:     HypothesisStrategyNameBaseline,
+		Locality:     input.Flow.TPMLocality(),
+		Measurements: input.Measurements,
+	}
+}
+
+// HypothesisStrategy enumerates alternative measurement sets, each of
+// them corresponds to a single known reason of a PCR0 mismatch.
+type HypothesisStrategy interface {
+	// Name returns a short unique name of the strategy.
+	Name() string
+
+	// Hypotheses returns the alternatives to the expected measurements.
+	// It returns no hypotheses if the strategy is not applicable.
+	Hypotheses(input HypothesisInput) ([]Hypothesis, error)
+}
+
+// DefaultHypothesisStrategies returns all the strategies implemented
+// in this package.
+func DefaultHypothesisStrategies() []HypothesisStrategy {
+	return []HypothesisStrategy{
+		LocalityStrategy{},
+		IBBHashAlgorithmStrategy{},
+		BPMCopyStrategy{},
+		PCDFirmwareVendorVersionStrategy{},
+		MicrocodeStrategy{},
+	}
+}
+
+// HypothesisResult is the outcome of checking a single hypothesis.
+type HypothesisResult struct {
+	Hypothesis
+
+	// Reproduced is true if the expected PCR0 value was reproduced.
+	Reproduced bool
+
+	// UpdatedACMPolicyStatus is the ACM_POLICY_STATUS value which was
+	// used to reproduce the PCR0 value (if applicable).
+	UpdatedACMPolicyStatus *registers.ACMPolicyStatus `json:",omitempty"`
+
+	// Issues are the amendments which were required to reproduce the
+	// PCR0 value in addition to the hypothesis (like disabled
+	// measurements or a modified ACM_POLICY_STATUS).
+	Issues errors.MultiError `json:",omitempty"`
+
+	// Score is the amount of amendments (of the hypothesis and the
+	// issues). The less is the score the more likely is the hypothesis.
+	Score uint
+}
+
+// HypothesesReport contains the results of all the checked hypotheses,
+// the reproducing hypotheses go first ordered by their score.
+type HypothesesReport struct {
+	Results []HypothesisResult
+}
+
+// Best returns the most likely hypothesis which reproduces the expected
+// PCR0 value, or nil if there is no such hypothesis.
+func (report HypothesesReport) Best() *HypothesisResult {
+	if len(report.Results) == 0 || !report.Results[0].Reproduced {
+		return nil
+	}
+	return &report.Results[0]
+}
+
+// RankHypotheses checks the hypotheses of the strategies (and the baseline)
+// and ranks them by how likely they explain the expected PCR0 value.
+//
+// Each hypothesis is checked the same way as ReproduceExpectedPCR0 does:
+// with disabling measurements and bruteforcing ACM_POLICY_STATUS, but
+// with the locality fixed by the hypothesis.
+//
+// If the report is not nil and the error is not nil, then the error
+// contains the problems of the strategies which were skipped and of the
+// hypotheses which could not be checked (they are reported as not
+// reproduced and are logged as well).
+//
+// If no strategies are passed, then DefaultHypothesisStrategies are used.
+func RankHypotheses(
+	_ctx context.Context,
+	expectedPCR0 []byte,
+	input HypothesisInput,
+	strategies ...HypothesisStrategy,
+) (*HypothesesReport, error) {
+	ctx, ok := _ctx.(xcontext.Context)
+	if !ok {
+		ctx = xcontext.Extend(_ctx)
+	}
+	defer ctx.Tracer().StartSpan("RankHypotheses").Finish()
+
+	if len(strategies) == 0 {
+		strategies = DefaultHypothesisStrategies()
+	}
+
+	mErr := errors.MultiError{}
+	hypotheses := []Hypothesis{input.baseline()}
+	for _, strategy := range strategies {
+		strategyHypotheses, err := strategy.Hypotheses(input)
+		if err != nil {
+			_ = mErr.Add(fmt.Errorf("strategy '%s' failed: %w", strategy.Name(), err))
+			continue
+		}
+		hypotheses = append(hypotheses, strategyHypotheses...)
+	}
+
+	report := &HypothesesReport{}
+	for _, hypothesis := range hypotheses {
+		if ctx.IsSignaledWith() {
+			return nil, fmt.Errorf("interrupted")
+		}
+		ctx.Logger().Debugf("checking hypothesis %s %v (locality: %d)", hypothesis.Strategy, hypothesis.Amendments, hypothesis.Locality)
+		result, err := checkHypothesis(ctx, expectedPCR0, input, hypothesis)
+		if err != nil {
+			err = fmt.Errorf("unable to check hypothesis %s %v: %w", hypothesis.Strategy, hypothesis.Amendments, err)
+			ctx.Logger().Warnf("%v", err)
+			_ = mErr.Add(err)
+		}
+		// a hypothesis which could not be checked is kept as not reproduced
+		report.Results = append(report.Results, result)
+	}
+
+	sort.SliceStable(report.Results, func(i, j int) bool {
+		a, b := report.Results[i], report.Results[j]
+		if a.Reproduced != b.Reproduced {
+			return a.Reproduced
+		}
+		return a.Score < b.Score
+	})
+
+	return report, mErr.ReturnValue()
+}
+
+func checkHypothesis(
+	ctx xcontext.Context,
+	expectedPCR0 []byte,
+	input HypothesisInput,
+	hypothesis Hypothesis,
+) (HypothesisResult, error) {
+	result := HypothesisResult{
+		Hypothesis: hypothesis,
+	}
+
+	hashFuncFactory, err := hashFactoryForDigest(expectedPCR0)
+	if err != nil {
+		return result, err
+	}
+	handler, err := newReproduceExpectedPCR0Handler(
+		expectedPCR0,
+		hashFuncFactory,
+		input.Flow,
+		realMeasurements(hypothesis.Measurements),
+		input.Firmware.Buf(),
+	)
+	if err != nil {
+		return result, fmt.Errorf("unable to initialize a handler: %w", err)
+	}
+
+	isSuccess, updatedACMPolicyStatus, err := handler.newJob(hypothesis.Locality).Execute(ctx)
+	if !isSuccess {
+		if err != nil {
+			return result, fmt.Errorf("unable to reproduce PCR0: %w", err)
+		}
+		return result, nil
+	}
+	result.Reproduced = true
+	result.UpdatedACMPolicyStatus = updatedACMPolicyStatus
+	_ = result.Issues.Add(err)
+	result.Score = uint(len(hypothesis.Amendments)) + result.Issues.Count()
+	return result, nil
+}
+
+//-----------------------------------------------------------------------------
+// Hypothesis strategies
+//-----------------------------------------------------------------------------
+
+// LocalityStrategy assumes the PCR0 was initialized with another locality.
+type LocalityStrategy struct{}
+
+// Name implements HypothesisStrategy.
+func (LocalityStrategy) Name() string {
+	return "locality"
+}
+
+// Hypotheses implements HypothesisStrategy.
+func (s LocalityStrategy) Hypotheses(input HypothesisInput) ([]Hypothesis, error) {
+	var result []Hypothesis
+	for locality := uint8(0); locality < 5; locality++ {
+		if locality == input.Flow.TPMLocality() {
+			continue
+		}
+		result = append(result, Hypothesis{
+			Strategy:     s.Name(),
+			Amendments:   []string{fmt.Sprintf("locality %d instead of %d", locality, input.Flow.TPMLocality())},
+			Locality:     locality,
+			Measurements: input.Measurements,
+		})
+	}
+	return result, nil
+}
+
+// IBBHashAlgorithmStrategy assumes the IBB digest of PCR0_DATA was taken
+// with another hash algorithm (out of the ones listed in the BPM).
+type IBBHashAlgorithmStrategy struct{}
+
+// Name implements HypothesisStrategy.
+func (IBBHashAlgorithmStrategy) Name() string {
+	return "ibb_hash_algorithm"
+}
+
+// Hypotheses implements HypothesisStrategy.
+func (s IBBHashAlgorithmStrategy) Hypotheses(input HypothesisInput) ([]Hypothesis, error) {
+	pcr0Data := input.Measurements.Find(pcr.MeasurementIDPCR0DATA)
+	if pcr0Data == nil {
+		return nil, nil
+	}
+	ibbDigest := pcr0Data.Data.Find(pcr.DataChunkIDIBBDigest)
+	if ibbDigest == nil {
+		return nil, fmt.Errorf("no IBB digest in PCR0_DATA")
+	}
+	if pcr.IsBootGuardV1Firmware(input.Firmware) {
+		// Boot Guard 1.0 BPM has only one IBB digest.
+		return nil, nil
+	}
+
+	bpm, _, err := getBootPolicyManifest(input.Firmware.Buf())
+	if err != nil {
+		return nil, err
+	}
+	if len(bpm.SE) == 0 {
+		return nil, fmt.Errorf("no IBB segments in BPM")
+	}
+
+	var result []Hypothesis
+	for _, digest := range bpm.SE[0].DigestList.List {
+		opts := append(append([]pcr.MeasureOption{}, input.MeasureOptions...),
+			pcr.SetFlow(input.Flow),
+			pcr.SetIBBHashDigest(tpm2.Algorithm(digest.HashAlg)),
+		)
+		measurements, _, _, err := pcr.GetMeasurements(input.Firmware, 0, opts...)
+		if measurements == nil {
+			return nil, fmt.Errorf("unable to get measurements with IBB digest %s: %w", digest.HashAlg, err)
+		}
+		altPCR0Data := measurements.Find(pcr.MeasurementIDPCR0DATA)
+		if altPCR0Data == nil {
+			continue
+		}
+		altIBBDigest := altPCR0Data.Data.Find(pcr.DataChunkIDIBBDigest)
+		if altIBBDigest == nil || altIBBDigest.Range == ibbDigest.Range {
+			continue
+		}
+
+		hypothesisMeasurements := input.Measurements.Copy()
+		*hypothesisMeasurements.Find(pcr.MeasurementIDPCR0DATA).Data.Find(pcr.DataChunkIDIBBDigest) = *altIBBDigest
+		result = append(result, Hypothesis{
+			Strategy:     s.Name(),
+			Amendments:   []string{fmt.Sprintf("IBB digest %s", digest.HashAlg)},
+			Locality:     input.Flow.TPMLocality(),
+			Measurements: hypothesisMeasurements,
+		})
+	}
+	return result, nil
+}
+
+// BPMCopyStrategy assumes the BPM was taken from another copy of the BIOS
+// region (for example, from the top swap copy), which is placed elsewhere
+// and differs from the BPM referenced by FIT.
+type BPMCopyStrategy struct{}
+
+// Name implements HypothesisStrategy.
+func (BPMCopyStrategy) Name() string {
+	return "bpm_copy"
+}
+
+// Hypotheses implements HypothesisStrategy.
+func (s BPMCopyStrategy) Hypotheses(input HypothesisInput) ([]Hypothesis, error) {
+	image := input.Firmware.Buf()
+	_, bpmRange, err := getBootPolicyManifestFITEntry(image)
+	if err != nil {
+		// No BPM means the strategy is not applicable
+		return nil, nil
+	}
+	if bpmRange.End() > uint64(len(image)) {
+		return nil, fmt.Errorf("BPM %v is out of the image", bpmRange)
+	}
+	bpmBytes := image[bpmRange.Offset:bpmRange.End()]
+
+	var result []Hypothesis
+	structureID := []byte(bootpolicy.StructureIDBPMH)
+	for offset := 0; ; offset++ {
+		idx := bytes.Index(image[offset:], structureID)
+		if idx < 0 {
+			break
+		}
+		offset += idx
+		copyRange := pkgbytes.Range{Offset: uint64(offset), Length: bpmRange.Length}
+		if copyRange.Offset == bpmRange.Offset || copyRange.End() > uint64(len(image)) {
+			continue
+		}
+		if bytes.Equal(image[copyRange.Offset:copyRange.End()], bpmBytes) {
+			// An identical copy gives the same measurements
+			continue
+		}
+
+		hypothesisMeasurements := input.Measurements.Copy()
+		var moved bool
+		for _, m := range hypothesisMeasurements {
+			for idx := range m.Data {
+				chunk := &m.Data[idx]
+				if chunk.ForceData != nil || chunk.Range.Offset < bpmRange.Offset || chunk.Range.End() > bpmRange.End() {
+					continue
+				}
+				chunk.Range.Offset = chunk.Range.Offset - bpmRange.Offset + copyRange.Offset
+				moved = true
+			}
+		}
+		if !moved {
+			continue
+		}
+		result = append(result, Hypothesis{
+			Strategy:     s.Name(),
+			Amendments:   []string{fmt.Sprintf("BPM copy at 0x%X instead of 0x%X", copyRange.Offset, bpmRange.Offset)},
+			Locality:     input.Flow.TPMLocality(),
+			Measurements: hypothesisMeasurements,
+		})
+	}
+	return result, nil
+}
+
+// PCDFirmwareVendorVersionStrategy assumes another PCD firmware vendor
+// version value was measured: one of the Candidates or the found value
+// with a different amount of trailing zeros.
+type PCDFirmwareVendorVersionStrategy struct {
+	// Candidates are the additional values to try. If nil, then
+	// pcd.KnownFirmwareVendorVersions are used.
+	Candidates [][]byte
+}
+
+// Name implements HypothesisStrategy.
+func (PCDFirmwareVendorVersionStrategy) Name() string {
+	return "pcd_firmware_vendor_version"
+}
+
+// Hypotheses implements HypothesisStrategy.
+func (s PCDFirmwareVendorVersionStrategy) Hypotheses(input HypothesisInput) ([]Hypothesis, error) {
+	measurement := input.Measurements.Find(pcr.MeasurementIDPCDFirmwareVendorVersionData)
+	if measurement == nil {
+		return nil, nil
+	}
+	value := measurement.CompileMeasurableData(input.Firmware.Buf())
+
+	candidates := s.Candidates
+	if candidates == nil {
+		candidates = pcd.KnownFirmwareVendorVersions()
+	}
+	trimmed := bytes.TrimRight(value, "\x00")
+	candidates = append(append([][]byte{}, candidates...),
+		trimmed,
+		append(append([]byte{}, trimmed...), 0, 0),
+	)
+
+	var result []Hypothesis
+	tried := [][]byte{value}
+	for _, candidate := range candidates {
+		isTried := false
+		for _, v := range tried {
+			if bytes.Equal(v, candidate) {
+				isTried = true
+				break
+			}
+		}
+		if isTried {
+			continue
+		}
+		tried = append(tried, candidate)
+
+		hypothesisMeasurements := input.Measurements.Copy()
+		*hypothesisMeasurements.Find(pcr.MeasurementIDPCDFirmwareVendorVersionData) =
+			*pcr.NewStaticDataMeasurement(pcr.MeasurementIDPCDFirmwareVendorVersionData, candidate)
+		result = append(result, Hypothesis{
+			Strategy:     s.Name(),
+			Amendments:   []string{fmt.Sprintf("PCD firmware vendor version %X instead of %X", candidate, value)},
+			Locality:     input.Flow.TPMLocality(),
+			Measurements: hypothesisMeasurements,
+		})
+	}
+	return result, nil
+}
+
+// MicrocodeStrategy assumes another (for example, a stale) CPU microcode
+// update referenced by FIT was measured.
+type MicrocodeStrategy struct{}
+
+// Name implements HypothesisStrategy.
+func (MicrocodeStrategy) Name() string {
+	return "cpu_microcode"
+}
+
+// Hypotheses implements HypothesisStrategy.
+func (s MicrocodeStrategy) Hypotheses(input HypothesisInput) ([]Hypothesis, error) {
+	measurement := input.Measurements.Find(pcr.MeasurementIDCPUMicrocode)
+	if measurement == nil {
+		return nil, nil
+	}
+	measuredRanges := measurement.Ranges()
+
+	image := input.Firmware.Buf()
+	fitEntries, err := fit.GetEntries(image)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get FIT entries: %w", err)
+	}
+	updateRanges, err := pcr.MicrocodeUpdateRanges(image, fitEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Hypothesis
+	for _, updateRange := range updateRanges {
+		if len(measuredRanges) == 1 && measuredRanges[0] == updateRange {
+			continue
+		}
+		hypothesisMeasurements := input.Measurements.Copy()
+		*hypothesisMeasurements.Find(pcr.MeasurementIDCPUMicrocode) =
+			*pcr.NewRangeMeasurement(pcr.MeasurementIDCPUMicrocode, updateRange.Offset, updateRange.Length)
+		result = append(result, Hypothesis{
+			Strategy:     s.Name(),
+			Amendments:   []string{fmt.Sprintf("microcode update at 0x%X", updateRange.Offset)},
+			Locality:     input.Flow.TPMLocality(),
+			Measurements: hypothesisMeasurements,
+		})
+	}
+	return result, nil
+}
+
+func getBootPolicyManifest(image []byte) (*bootpolicy.Manifest, pkgbytes.Range, error) {
+	fitEntry, bpmRange, err := getBootPolicyManifestFITEntry(image)
+	if err != nil {
+		return nil, pkgbytes.Range{}, err
+	}
+	bpm, err := fitEntry.ParseData()
+	if err != nil {
+		return nil, pkgbytes.Range{}, fmt.Errorf("unable to parse BPM: %w", err)
+	}
+	return bpm, bpmRange, nil
+}
+
+// getBootPolicyManifestFITEntry returns the FIT entry of the BPM without
+// parsing the BPM, so it works for both CBnT and Boot Guard 1.0.
+func getBootPolicyManifestFITEntry(image []byte) (*fit.EntryBootPolicyManifestRecord, pkgbytes.Range, error) {
+	fitEntries, err := fit.GetEntries(image)
+	if err != nil {
+		return nil, pkgbytes.Range{}, fmt.Errorf("unable to get FIT entries: %w", err)
+	}
+	for _, fitEntry := range fitEntries {
+		switch fitEntry := fitEntry.(type) {
+		case *fit.EntryBootPolicyManifestRecord:
+			return fitEntry, pkgbytes.Range{
+				Offset: fitEntry.Headers.Address.Offset(uint64(len(image))),
+				Length: uint64(len(fitEntry.DataSegmentBytes)),
+			}, nil
+		}
+	}
+	return nil, pkgbytes.Range{}, fmt.Errorf("boot policy manifest FIT entry is not found")
+}
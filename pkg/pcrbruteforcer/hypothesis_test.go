@@ -0,0 +1,109 @@
+package pcrbruteforcer
+
+import (
+	"crypto/sha1"
+	"testing"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/pcd"
+	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
+	"github.com/9elements/converged-security-suite/v2/pkg/registers"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRankHypotheses(t *testing.T) {
+	firmware := getFirmware(t)
+
+	measureOptions := []pcr.MeasureOption{
+		pcr.SetFlow(pcr.FlowIntelCBnT0T),
+		pcr.SetRegisters(registers.Registers{
+			registers.ParseACMPolicyStatusRegister(0x0000000200108681),
+		}),
+	}
+	getMeasurements := func(t *testing.T, opts ...pcr.MeasureOption) pcr.Measurements {
+		measurements, _, debugInfo, err := pcr.GetMeasurements(firmware, 0, append(measureOptions, opts...)...)
+		require.NoError(t, err, debugInfo)
+		return measurements
+	}
+	measurements := getMeasurements(t, pcr.SetIBBHashDigest(tpm2.AlgSHA1))
+	input := HypothesisInput{
+		Firmware:       firmware,
+		Flow:           pcr.FlowIntelCBnT0T,
+		Measurements:   measurements,
+		MeasureOptions: measureOptions,
+	}
+
+	checkBest := func(t *testing.T, expectedPCR0 []byte, expectedStrategy string, expectedScore uint) {
+		report, err := RankHypotheses(xcontext.Background(), expectedPCR0, input)
+		require.NoError(t, err)
+		best := report.Best()
+		require.NotNil(t, best, report)
+		require.Equal(t, expectedStrategy, best.Strategy, best.Amendments)
+		require.Equal(t, expectedScore, best.Score, best.Issues)
+		require.Len(t, best.Issues, 0)
+	}
+
+	t.Run("baseline", func(t *testing.T) {
+		pcr0 := measurements.Calculate(firmware.Buf(), pcr.FlowIntelCBnT0T.TPMLocality(), sha1.New(), nil)
+		checkBest(t, pcr0, HypothesisStrategyNameBaseline, 0)
+	})
+
+	t.Run("locality", func(t *testing.T) {
+		pcr0 := measurements.Calculate(firmware.Buf(), 0, sha1.New(), nil)
+		checkBest(t, pcr0, LocalityStrategy{}.Name(), 1)
+	})
+
+	t.Run("ibb_hash_algorithm", func(t *testing.T) {
+		pcr0 := getMeasurements(t, pcr.SetIBBHashDigest(tpm2.AlgSHA256)).
+			Calculate(firmware.Buf(), pcr.FlowIntelCBnT0T.TPMLocality(), sha1.New(), nil)
+		checkBest(t, pcr0, IBBHashAlgorithmStrategy{}.Name(), 1)
+	})
+
+	t.Run("pcd_firmware_vendor_version", func(t *testing.T) {
+		altMeasurements := measurements.Copy()
+		*altMeasurements.Find(pcr.MeasurementIDPCDFirmwareVendorVersionData) = *pcr.NewStaticDataMeasurement(
+			pcr.MeasurementIDPCDFirmwareVendorVersionData,
+			pcd.KnownFirmwareVendorVersions()[0],
+		)
+		pcr0 := altMeasurements.Calculate(firmware.Buf(), pcr.FlowIntelCBnT0T.TPMLocality(), sha1.New(), nil)
+		checkBest(t, pcr0, PCDFirmwareVendorVersionStrategy{}.Name(), 1)
+	})
+
+	t.Run("not_reproduced", func(t *testing.T) {
+		report, err := RankHypotheses(xcontext.Background(), unhex(t, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"), input, LocalityStrategy{})
+		require.NoError(t, err)
+		require.Nil(t, report.Best())
+		require.Len(t, report.Results, 5)
+	})
+
+	t.Run("check_error", func(t *testing.T) {
+		report, err := RankHypotheses(xcontext.Background(), []byte{1, 2, 3}, input, LocalityStrategy{})
+		require.Error(t, err)
+		require.Nil(t, report.Best())
+		require.Len(t, report.Results, 5)
+	})
+}
+
+func TestBPMCopyStrategy(t *testing.T) {
+	firmware := getFirmware(t)
+
+	measurements, _, debugInfo, err := pcr.GetMeasurements(firmware, 0,
+		pcr.SetFlow(pcr.FlowIntelCBnT0T),
+		pcr.SetRegisters(registers.Registers{
+			registers.ParseACMPolicyStatusRegister(0x0000000200108681),
+		}),
+	)
+	require.NoError(t, err, debugInfo)
+
+	input := HypothesisInput{
+		Firmware:     firmware,
+		Flow:         pcr.FlowIntelCBnT0T,
+		Measurements: measurements,
+	}
+
+	// The image contains the only BPM
+	hypotheses, err := BPMCopyStrategy{}.Hypotheses(input)
+	require.NoError(t, err)
+	require.Empty(t, hypotheses)
+}
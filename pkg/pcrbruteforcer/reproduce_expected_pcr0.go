@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/binary"
 	"fmt"
 	"hash"
@@ -17,6 +18,7 @@ import (
 	"github.com/9elements/converged-security-suite/v2/pkg/errors"
 	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
 	"github.com/9elements/converged-security-suite/v2/pkg/registers"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
 	"github.com/linuxboot/contest/pkg/xcontext"
 )
 
@@ -39,7 +41,9 @@ const (
 )
 
 // ReproduceExpectedPCR0 brute-forces measurements to achieve the expected PCR0
-// SHA1 or SHA256 value.
+// value. The PCR bank is detected by the size of the value: SHA1, SHA256,
+// SHA384 or SHA512 (use ReproduceExpectedPCR0InBank for SM3_256 or to
+// specify the bank explicitly).
 //
 // If succeeded to reproduce, then `isSuccess` is true.
 //
@@ -56,18 +60,43 @@ func ReproduceExpectedPCR0(
 	measurements pcr.Measurements,
 	imageBytes []byte,
 ) (isSuccess bool, locality uint8, updatedACMPolicyStatus *registers.ACMPolicyStatus, returnErr error) {
-	var realMeasurements pcr.Measurements
-	for _, ms := range measurements {
-		if ms.IsFake() {
-			continue
-		}
-		realMeasurements = append(realMeasurements, ms)
+	hashFuncFactory, err := hashFactoryForDigest(expectedPCR0)
+	if err != nil {
+		return false, 0, nil, err
+	}
+	return reproduceExpectedPCR0(ctx, expectedPCR0, hashFuncFactory, flow, measurements, imageBytes)
+}
+
+// ReproduceExpectedPCR0InBank is the same as ReproduceExpectedPCR0, but
+// the PCR bank is defined explicitly by `hashAlgo`.
+func ReproduceExpectedPCR0InBank(
+	ctx xcontext.Context,
+	expectedPCR0 []byte,
+	hashAlgo tpmeventlog.TPMAlgorithm,
+	flow pcr.Flow,
+	measurements pcr.Measurements,
+	imageBytes []byte,
+) (isSuccess bool, locality uint8, updatedACMPolicyStatus *registers.ACMPolicyStatus, returnErr error) {
+	hashFuncFactory, err := hashFactoryForAlgorithm(hashAlgo, expectedPCR0)
+	if err != nil {
+		return false, 0, nil, err
 	}
+	return reproduceExpectedPCR0(ctx, expectedPCR0, hashFuncFactory, flow, measurements, imageBytes)
+}
 
+func reproduceExpectedPCR0(
+	ctx xcontext.Context,
+	expectedPCR0 []byte,
+	hashFuncFactory hashFactory,
+	flow pcr.Flow,
+	measurements pcr.Measurements,
+	imageBytes []byte,
+) (isSuccess bool, locality uint8, updatedACMPolicyStatus *registers.ACMPolicyStatus, returnErr error) {
 	handler, err := newReproduceExpectedPCR0Handler(
 		expectedPCR0,
+		hashFuncFactory,
 		flow,
-		realMeasurements,
+		realMeasurements(measurements),
 		imageBytes,
 	)
 	if err != nil {
@@ -76,6 +105,53 @@ func ReproduceExpectedPCR0(
 	return handler.Execute(ctx)
 }
 
+// hashFactoryForDigest returns the hash function of the PCR bank of
+// the PCR value.
+//
+// SM3_256 could not be distinguished from SHA256 by the size, so SHA256
+// is assumed for 32-byte values.
+func hashFactoryForDigest(pcrValue []byte) (hashFactory, error) {
+	switch len(pcrValue) {
+	case sha1.Size:
+		return sha1.New, nil
+	case sha256.Size:
+		return sha256.New, nil
+	case sha512.Size384:
+		return sha512.New384, nil
+	case sha512.Size:
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("invalid len for expectedPCR0: %d", len(pcrValue))
+}
+
+// hashFactoryForAlgorithm returns the hash function of the PCR bank
+// `hashAlgo` and verifies the PCR value has the appropriate size.
+func hashFactoryForAlgorithm(hashAlgo tpmeventlog.TPMAlgorithm, pcrValue []byte) (hashFactory, error) {
+	hasher, err := tpmeventlog.NewHasher(hashAlgo)
+	if err != nil {
+		return nil, err
+	}
+	if len(pcrValue) != hasher.Size() {
+		return nil, tpmeventlog.ErrInvalidDigestLength{Expected: hasher.Size(), Received: len(pcrValue)}
+	}
+	return func() hash.Hash {
+		hasher, _ := tpmeventlog.NewHasher(hashAlgo)
+		return hasher
+	}, nil
+}
+
+// realMeasurements returns the measurements without fake ones.
+func realMeasurements(measurements pcr.Measurements) pcr.Measurements {
+	var result pcr.Measurements
+	for _, ms := range measurements {
+		if ms.IsFake() {
+			continue
+		}
+		result = append(result, ms)
+	}
+	return result
+}
+
 type reproduceExpectedPCR0Handler struct {
 	expectedPCR0              []byte
 	flow                      pcr.Flow
@@ -86,20 +162,11 @@ type reproduceExpectedPCR0Handler struct {
 
 func newReproduceExpectedPCR0Handler(
 	expectedPCR0 []byte,
+	hashFuncFactory hashFactory,
 	flow pcr.Flow,
 	measurements pcr.Measurements,
 	imageBytes []byte,
 ) (*reproduceExpectedPCR0Handler, error) {
-	var hashFuncFactory func() hash.Hash
-	switch len(expectedPCR0) {
-	case sha1.Size:
-		hashFuncFactory = sha1.New
-	case sha256.Size:
-		hashFuncFactory = sha256.New
-	default:
-		return nil, fmt.Errorf("invalid len for expectedPCR0: %d", len(expectedPCR0))
-	}
-
 	precalculatedMeasurements, err := cacheMeasurements(measurements, imageBytes, hashFuncFactory)
 	if err != nil {
 		return nil, fmt.Errorf("invalid measurements: %w", err)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
 	"github.com/9elements/converged-security-suite/v2/pkg/registers"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
 	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
 	"github.com/9elements/converged-security-suite/v2/testdata/firmware"
 	"github.com/linuxboot/contest/pkg/xcontext/bundles/logrusctx"
@@ -86,6 +87,55 @@ func TestReproduceExpectedPCR0(t *testing.T) {
 	t.Run("test_invalid_PCR0", func(t *testing.T) { testACM(t, pcr0Invalid, correctACMRegValue) })
 }
 
+func TestReproduceExpectedPCR0InBank(t *testing.T) {
+	firmware := getFirmware(t)
+
+	const correctACMRegValue = 0x0000000200108681
+	measureOptions := []pcr.MeasureOption{
+		pcr.SetFlow(pcr.FlowIntelCBnT0T),
+		pcr.SetIBBHashDigest(tpm2.AlgSHA1),
+		pcr.SetRegisters(registers.Registers{
+			registers.ParseACMPolicyStatusRegister(correctACMRegValue),
+		}),
+	}
+	measurements, _, debugInfo, err := pcr.GetMeasurements(firmware, 0, measureOptions...)
+	require.NoError(t, err, fmt.Sprintf("debugInfo: '%v'", debugInfo))
+	pcr0s, err := measurements.CalculateBanks(firmware.Buf(), pcr.FlowIntelCBnT0T.TPMLocality(), tpmeventlog.HashAlgorithms()...)
+	require.NoError(t, err)
+
+	corruptedMeasureOptions := append(measureOptions[:2:2], pcr.SetRegisters(registers.Registers{
+		registers.ParseACMPolicyStatusRegister(correctACMRegValue + 0x1c),
+	}))
+	corruptedMeasurements, _, debugInfo, err := pcr.GetMeasurements(firmware, 0, corruptedMeasureOptions...)
+	require.NoError(t, err, fmt.Sprintf("debugInfo: '%v'", debugInfo))
+
+	for _, pcr0 := range pcr0s {
+		pcr0 := pcr0
+		t.Run(tpmeventlog.HashAlgorithmName(pcr0.HashAlgo), func(t *testing.T) {
+			succeeded, _, acmPolicyStatus, err := ReproduceExpectedPCR0InBank(
+				logrusctx.NewContext(logger.LevelDebug),
+				pcr0.Value,
+				pcr0.HashAlgo,
+				pcr.FlowIntelCBnT0T,
+				corruptedMeasurements,
+				firmware.Buf(),
+			)
+			require.True(t, succeeded, "%v", err)
+			require.Equal(t, uint64(correctACMRegValue), acmPolicyStatus.Raw())
+		})
+	}
+
+	_, _, _, err = ReproduceExpectedPCR0InBank(
+		xcontext.Background(),
+		pcr0s.Find(tpmeventlog.TPMAlgorithmSHA1),
+		tpmeventlog.TPMAlgorithmSHA256,
+		pcr.FlowIntelCBnT0T,
+		measurements,
+		firmware.Buf(),
+	)
+	require.Error(t, err)
+}
+
 func BenchmarkReproduceExpectedPCR0(b *testing.B) {
 	firmware := getFirmware(b)
 
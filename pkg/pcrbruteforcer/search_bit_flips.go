@@ -0,0 +1,456 @@
+package pcrbruteforcer
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"fmt"
+	"hash"
+	"math"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/bruteforcer"
+	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	pkgbytes "github.com/linuxboot/fiano/pkg/bytes"
+	fianoUEFI "github.com/linuxboot/fiano/pkg/uefi"
+)
+
+// BitFlipSearchSettings defines how SearchBitFlips looks for bit flips.
+type BitFlipSearchSettings struct {
+	// MaxDistance is the maximal amount of flipped bits in a measurement.
+	MaxDistance uint64
+
+	// ExhaustiveSizeLimit is the maximal size of the measured data of
+	// a measurement to be searched exhaustively. In larger measurements
+	// only the static data chunks (like registers values) and
+	// CandidateRegions are searched.
+	ExhaustiveSizeLimit uint64
+
+	// CandidateRegions are the ranges of the image to be searched in
+	// large measurements. See also FFSHeaderRegions.
+	CandidateRegions pkgbytes.Ranges
+
+	// MaxConcurrency limits the amount of goroutines used to search
+	// a single region. Zero means no limit.
+	MaxConcurrency uint
+
+	// ProgressInterval is how often the progress is reported to the logger
+	// of the context. Zero disables the progress reporting.
+	ProgressInterval time.Duration
+
+	// HashAlgo is the hash algorithm of the PCR bank. Zero means to detect
+	// it by the size of the expected PCR value (see ReproduceExpectedPCR0).
+	HashAlgo tpmeventlog.TPMAlgorithm
+}
+
+// DefaultBitFlipSearchSettings returns the default BitFlipSearchSettings.
+func DefaultBitFlipSearchSettings() BitFlipSearchSettings {
+	return BitFlipSearchSettings{
+		MaxDistance:         2,
+		ExhaustiveSizeLimit: 64,
+		ProgressInterval:    10 * time.Second,
+	}
+}
+
+// BitFlip is a single flipped bit of a measurement.
+type BitFlip struct {
+	// DataOffset is the offset of the byte in the measured data.
+	DataOffset uint64
+
+	// Bit is the index of the bit in the byte.
+	Bit uint8
+
+	// ImageOffset is the offset of the byte in the image. It is nil if
+	// the byte is not taken from the image (like a register value).
+	ImageOffset *uint64 `json:",omitempty"`
+}
+
+// BitFlipMatch is a combination of bit flips, which reproduces the expected
+// PCR value.
+type BitFlipMatch struct {
+	MeasurementID pcr.MeasurementID
+	Flips         []BitFlip
+}
+
+// ImageRanges returns the ranges of the image bytes with flipped bits.
+func (match BitFlipMatch) ImageRanges() pkgbytes.Ranges {
+	var result pkgbytes.Ranges
+	for _, flip := range match.Flips {
+		if flip.ImageOffset == nil {
+			continue
+		}
+		result = append(result, pkgbytes.Range{Offset: *flip.ImageOffset, Length: 1})
+	}
+	return pkgbytes.MergeRanges(result, 0)
+}
+
+// BitFlipSearchReport is the result of SearchBitFlips.
+type BitFlipSearchReport struct {
+	// Matches are all the found combinations of bit flips.
+	Matches []BitFlipMatch
+
+	// SkippedMeasurements are the large measurements which have no
+	// static data chunks and do not overlap with candidate regions.
+	SkippedMeasurements []pcr.MeasurementID `json:",omitempty"`
+
+	// Combinations is the amount of checked combinations.
+	Combinations uint64
+}
+
+// ErrNoBitFlipsRequired means the expected PCR value is reproduced without
+// any bit flips.
+type ErrNoBitFlipsRequired struct{}
+
+func (err ErrNoBitFlipsRequired) Error() string {
+	return "the expected PCR value is reproduced without any bit flips"
+}
+
+// SearchBitFlips checks if the expected PCR value could be reproduced
+// by flipping up to settings.MaxDistance bits in the measured data of
+// a single measurement. It is used to distinguish a corruption of the
+// flash chip (or of a register) from a tampering.
+//
+// Only one measurement is modified at a time. Small measurements are
+// searched exhaustively, and in large ones only the static data chunks and
+// settings.CandidateRegions are searched, since it is too expensive to
+// rehash the whole measurement for each combination of bits.
+//
+// The search could be cancelled through the context, in this case
+// the found matches are returned with an error.
+func SearchBitFlips(
+	_ctx context.Context,
+	expectedPCR []byte,
+	initialValue uint8,
+	measurements pcr.Measurements,
+	image []byte,
+	settings BitFlipSearchSettings,
+) (*BitFlipSearchReport, error) {
+	ctx, ok := _ctx.(xcontext.Context)
+	if !ok {
+		ctx = xcontext.Extend(_ctx)
+	}
+	defer ctx.Tracer().StartSpan("SearchBitFlips").Finish()
+
+	var hashFuncFactory hashFactory
+	var err error
+	if settings.HashAlgo == 0 {
+		hashFuncFactory, err = hashFactoryForDigest(expectedPCR)
+	} else {
+		hashFuncFactory, err = hashFactoryForAlgorithm(settings.HashAlgo, expectedPCR)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	measurements = realMeasurements(measurements)
+	measureEvents := make([]pcr.MeasureEvent, 0, len(measurements))
+	for _, m := range measurements {
+		measureEvents = append(measureEvents, m)
+	}
+	pcrValue, err := pcr.CalculatePCR(image, initialValue, measureEvents, hashFuncFactory(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to calculate the PCR value: %w", err)
+	}
+	if bytes.Equal(pcrValue, expectedPCR) {
+		return nil, ErrNoBitFlipsRequired{}
+	}
+
+	report := &BitFlipSearchReport{}
+	var combinations uint64
+	defer func() {
+		report.Combinations = atomic.LoadUint64(&combinations)
+	}()
+
+	for idx, m := range measurements {
+		data := m.CompileMeasurableData(image)
+		if len(data) == 0 {
+			continue
+		}
+		prefixPCR, err := pcr.CalculatePCR(image, initialValue, measureEvents[:idx], hashFuncFactory(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("unable to calculate the PCR value before measurement %s: %w", m.ID, err)
+		}
+		var suffixDigests [][]byte
+		for _, suffixMeasurement := range measurements[idx+1:] {
+			digest, err := suffixMeasurement.Calculate(image, hashFuncFactory())
+			if err != nil {
+				return nil, fmt.Errorf("unable to calculate the digest of measurement %s: %w", suffixMeasurement.ID, err)
+			}
+			if digest == nil {
+				continue
+			}
+			suffixDigests = append(suffixDigests, digest)
+		}
+
+		search := &bitFlipSearch{
+			ctx:             ctx,
+			expectedPCR:     expectedPCR,
+			hashFuncFactory: hashFuncFactory,
+			measurement:     m,
+			data:            data,
+			prefixPCR:       prefixPCR,
+			suffixDigests:   suffixDigests,
+			combinations:    &combinations,
+		}
+
+		windows := search.windows(settings)
+		if len(windows) == 0 {
+			report.SkippedMeasurements = append(report.SkippedMeasurements, m.ID)
+			continue
+		}
+		for _, window := range windows {
+			match, err := search.run(window, settings)
+			if err != nil {
+				return report, err
+			}
+			if match != nil {
+				report.Matches = append(report.Matches, *match)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+type bitFlipSearch struct {
+	ctx             xcontext.Context
+	expectedPCR     []byte
+	hashFuncFactory func() hash.Hash
+	measurement     *pcr.Measurement
+	data            []byte
+	prefixPCR       []byte
+	suffixDigests   [][]byte
+	combinations    *uint64
+}
+
+// windows returns the ranges of the measured data to be searched.
+func (s *bitFlipSearch) windows(settings BitFlipSearchSettings) pkgbytes.Ranges {
+	if uint64(len(s.data)) <= settings.ExhaustiveSizeLimit {
+		return pkgbytes.Ranges{{Offset: 0, Length: uint64(len(s.data))}}
+	}
+
+	var result pkgbytes.Ranges
+	dataOffset := uint64(0)
+	for _, chunk := range s.measurement.Data {
+		length := uint64(len(chunk.ForceData))
+		if chunk.ForceData == nil {
+			length = chunk.Range.Length
+		}
+		switch {
+		case chunk.ForceData != nil:
+			result = append(result, pkgbytes.Range{Offset: dataOffset, Length: length})
+		default:
+			for _, region := range settings.CandidateRegions {
+				start, end := region.Offset, region.End()
+				if start < chunk.Range.Offset {
+					start = chunk.Range.Offset
+				}
+				if end > chunk.Range.End() {
+					end = chunk.Range.End()
+				}
+				if start >= end {
+					continue
+				}
+				result = append(result, pkgbytes.Range{
+					Offset: dataOffset + start - chunk.Range.Offset,
+					Length: end - start,
+				})
+			}
+		}
+		dataOffset += length
+	}
+	result.Sort()
+	return pkgbytes.MergeRanges(result, 0)
+}
+
+// imageOffset returns the offset in the image of the byte of the measured
+// data, or nil if the byte is not from the image.
+func (s *bitFlipSearch) imageOffset(dataOffset uint64) *uint64 {
+	chunkDataOffset := uint64(0)
+	for _, chunk := range s.measurement.Data {
+		length := uint64(len(chunk.ForceData))
+		if chunk.ForceData == nil {
+			length = chunk.Range.Length
+		}
+		if dataOffset < chunkDataOffset+length {
+			if chunk.ForceData != nil {
+				return nil
+			}
+			offset := chunk.Range.Offset + dataOffset - chunkDataOffset
+			return &offset
+		}
+		chunkDataOffset += length
+	}
+	return nil
+}
+
+type bitFlipSearchContext struct {
+	hasher    hash.Hash
+	pcrBuffer []byte
+	data      []byte
+}
+
+func (s *bitFlipSearch) run(window pkgbytes.Range, settings BitFlipSearchSettings) (*BitFlipMatch, error) {
+	ctx := s.ctx
+	head := s.data[:window.Offset]
+	tail := s.data[window.End():]
+
+	// Hashing the data before the window only once
+	var midState []byte
+	if !s.measurement.NoHash() {
+		hasher := s.hashFuncFactory()
+		if marshaler, ok := hasher.(encoding.BinaryMarshaler); ok {
+			_, _ = hasher.Write(head)
+			var err error
+			midState, err = marshaler.MarshalBinary()
+			if err != nil {
+				return nil, fmt.Errorf("unable to get the intermediate hash state: %w", err)
+			}
+		}
+	}
+
+	total := amountOfBitFlipCombinations(window.Length*8, settings.MaxDistance)
+	var tried uint64
+	if settings.ProgressInterval > 0 {
+		stopProgress := make(chan struct{})
+		defer close(stopProgress)
+		go func() {
+			ticker := time.NewTicker(settings.ProgressInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stopProgress:
+					return
+				case <-ticker.C:
+					ctx.Logger().Infof("bit-flip search: measurement %s, data range 0x%X-0x%X: %d of %d combinations checked",
+						s.measurement.ID, window.Offset, window.End(), atomic.LoadUint64(&tried), total)
+				}
+			}
+		}()
+	}
+
+	initFunc := func() (interface{}, error) {
+		return &bitFlipSearchContext{
+			hasher:    s.hashFuncFactory(),
+			pcrBuffer: make([]byte, 0, len(s.expectedPCR)),
+		}, nil
+	}
+	checkFunc := func(_bfCtx interface{}, windowData []byte) bool {
+		if ctx.IsSignaledWith() {
+			return false
+		}
+		atomic.AddUint64(&tried, 1)
+		bfCtx := _bfCtx.(*bitFlipSearchContext)
+		h := bfCtx.hasher
+
+		var digest []byte
+		switch {
+		case s.measurement.NoHash():
+			bfCtx.data = append(append(append(bfCtx.data[:0], head...), windowData...), tail...)
+			digest = bfCtx.data
+		case midState != nil:
+			if err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary(midState); err != nil {
+				return false
+			}
+			_, _ = h.Write(windowData)
+			_, _ = h.Write(tail)
+			digest = h.Sum(nil)
+		default:
+			h.Reset()
+			_, _ = h.Write(head)
+			_, _ = h.Write(windowData)
+			_, _ = h.Write(tail)
+			digest = h.Sum(nil)
+		}
+
+		pcrValue := append(bfCtx.pcrBuffer[:0], s.prefixPCR...)
+		for _, d := range append([][]byte{digest}, s.suffixDigests...) {
+			h.Reset()
+			_, _ = h.Write(pcrValue)
+			_, _ = h.Write(d)
+			pcrValue = h.Sum(pcrValue[:0])
+		}
+		return bytes.Equal(pcrValue, s.expectedPCR)
+	}
+
+	windowData := make([]byte, window.Length)
+	copy(windowData, s.data[window.Offset:window.End()])
+	combination, err := bruteforcer.BruteForceBytesContext(ctx, windowData, settings.MaxDistance, initFunc, checkFunc, settings.MaxConcurrency)
+	atomic.AddUint64(s.combinations, atomic.LoadUint64(&tried))
+	if ctx.IsSignaledWith() {
+		return nil, fmt.Errorf("the search was interrupted: %w", ctx.Err())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to search bit flips in measurement %s: %w", s.measurement.ID, err)
+	}
+	if combination == nil {
+		return nil, nil
+	}
+
+	match := &BitFlipMatch{
+		MeasurementID: s.measurement.ID,
+	}
+	for _, bitIdx := range combination {
+		dataOffset := window.Offset + uint64(bitIdx>>3)
+		match.Flips = append(match.Flips, BitFlip{
+			DataOffset:  dataOffset,
+			Bit:         uint8(bitIdx & 0x7),
+			ImageOffset: s.imageOffset(dataOffset),
+		})
+	}
+	return match, nil
+}
+
+func amountOfBitFlipCombinations(bits, maxDistance uint64) uint64 {
+	if maxDistance > bits {
+		maxDistance = bits
+	}
+	total := big.NewInt(0)
+	for distance := uint64(0); distance <= maxDistance; distance++ {
+		total.Add(total, big.NewInt(0).Binomial(int64(bits), int64(distance)))
+	}
+	if !total.IsUint64() {
+		return 0
+	}
+	return total.Uint64()
+}
+
+// FFSHeaderRegions returns the ranges of headers of FFS volumes and files
+// of the image. These are the candidate regions for SearchBitFlips: even
+// a single flipped bit in a header breaks the firmware, so such corruptions
+// are found soon.
+func FFSHeaderRegions(firmware pcr.Firmware) (pkgbytes.Ranges, error) {
+	nodes, err := firmware.GetByRange(pkgbytes.Range{
+		Offset: 0,
+		Length: uint64(len(firmware.Buf())),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to scan for UEFI nodes: %w", err)
+	}
+
+	var result pkgbytes.Ranges
+	for _, node := range nodes {
+		if node.Offset == math.MaxUint64 {
+			continue
+		}
+		var headerLength uint64
+		switch f := node.Firmware.(type) {
+		case *fianoUEFI.FirmwareVolume:
+			headerLength = f.DataOffset
+		case *fianoUEFI.File:
+			headerLength = f.DataOffset
+		default:
+			continue
+		}
+		if headerLength > node.Length {
+			headerLength = node.Length
+		}
+		result = append(result, pkgbytes.Range{Offset: node.Offset, Length: headerLength})
+	}
+	result.Sort()
+	return pkgbytes.MergeRanges(result, 0), nil
+}
@@ -0,0 +1,97 @@
+package pcrbruteforcer
+
+import (
+	"crypto/sha1"
+	"testing"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
+	"github.com/9elements/converged-security-suite/v2/pkg/registers"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/linuxboot/contest/pkg/xcontext"
+	pkgbytes "github.com/linuxboot/fiano/pkg/bytes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchBitFlips(t *testing.T) {
+	firmware := getFirmware(t)
+	image := firmware.Buf()
+
+	const acmPolicyStatus = 0x0000000200108681
+	getMeasurements := func(t *testing.T, acmPolicyStatus uint64) pcr.Measurements {
+		measurements, _, debugInfo, err := pcr.GetMeasurements(firmware, 0,
+			pcr.SetFlow(pcr.FlowIntelCBnT0T),
+			pcr.SetIBBHashDigest(tpm2.AlgSHA1),
+			pcr.SetRegisters(registers.Registers{
+				registers.ParseACMPolicyStatusRegister(acmPolicyStatus),
+			}),
+		)
+		require.NoError(t, err, debugInfo)
+		return measurements
+	}
+	measurements := getMeasurements(t, acmPolicyStatus)
+	locality := pcr.FlowIntelCBnT0T.TPMLocality()
+
+	settings := DefaultBitFlipSearchSettings()
+	settings.ProgressInterval = 0
+
+	t.Run("no_flips", func(t *testing.T) {
+		pcr0 := measurements.Calculate(image, locality, sha1.New(), nil)
+		_, err := SearchBitFlips(xcontext.Background(), pcr0, locality, measurements, image, settings)
+		require.ErrorAs(t, err, &ErrNoBitFlipsRequired{})
+	})
+
+	t.Run("register", func(t *testing.T) {
+		pcr0 := getMeasurements(t, acmPolicyStatus^(1<<17|1<<40)).Calculate(image, locality, sha1.New(), nil)
+		report, err := SearchBitFlips(xcontext.Background(), pcr0, locality, measurements, image, settings)
+		require.NoError(t, err)
+		require.Len(t, report.Matches, 1)
+		match := report.Matches[0]
+		require.Equal(t, pcr.MeasurementIDPCR0DATA, match.MeasurementID)
+		require.Equal(t, []BitFlip{
+			{DataOffset: 2, Bit: 1},
+			{DataOffset: 5, Bit: 0},
+		}, match.Flips)
+		require.Empty(t, match.ImageRanges())
+	})
+
+	t.Run("image", func(t *testing.T) {
+		dxe := measurements.Find(pcr.MeasurementIDDXE)
+		require.NotNil(t, dxe)
+		flipOffset := dxe.Data[0].Range.Offset + 0x10
+
+		badImage := append([]byte{}, image...)
+		badImage[flipOffset] ^= 1 << 3
+		pcr0 := measurements.Calculate(badImage, locality, sha1.New(), nil)
+
+		// The DXE measurement is large, so it is skipped without
+		// candidate regions.
+		report, err := SearchBitFlips(xcontext.Background(), pcr0, locality, measurements, image, settings)
+		require.NoError(t, err)
+		require.Empty(t, report.Matches)
+		require.Contains(t, report.SkippedMeasurements, pcr.MeasurementIDDXE)
+
+		settings := settings
+		settings.CandidateRegions = pkgbytes.Ranges{{Offset: flipOffset - 4, Length: 8}}
+		report, err = SearchBitFlips(xcontext.Background(), pcr0, locality, measurements, image, settings)
+		require.NoError(t, err)
+		require.Len(t, report.Matches, 1)
+		require.Equal(t, pcr.MeasurementIDDXE, report.Matches[0].MeasurementID)
+		require.Equal(t, pkgbytes.Ranges{{Offset: flipOffset, Length: 1}}, report.Matches[0].ImageRanges())
+		require.Equal(t, uint8(3), report.Matches[0].Flips[0].Bit)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancelFn := xcontext.WithCancel(xcontext.Background())
+		cancelFn()
+		pcr0 := unhex(t, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA")
+		_, err := SearchBitFlips(ctx, pcr0, locality, measurements, image, settings)
+		require.Error(t, err)
+	})
+}
+
+func TestFFSHeaderRegions(t *testing.T) {
+	firmware := getFirmware(t)
+	regions, err := FFSHeaderRegions(firmware)
+	require.NoError(t, err)
+	require.NotEmpty(t, regions)
+}
@@ -0,0 +1,252 @@
+package pcrbruteforcer
+
+import (
+	"bytes"
+	"fmt"
+	"hash"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+)
+
+// EventVerdictStatus is the result of verification of a single EventLog
+// entry (or of a single expected measurement).
+type EventVerdictStatus int
+
+const (
+	// EventVerdictStatusUndefined is an invalid value of EventVerdictStatus.
+	EventVerdictStatusUndefined = EventVerdictStatus(iota)
+
+	// EventVerdictStatusMatch means the EventLog entry matches the expected
+	// measurement.
+	EventVerdictStatusMatch
+
+	// EventVerdictStatusMismatch means the EventLog entry corresponds to the
+	// expected measurement, but the digest (or the locality) differs.
+	EventVerdictStatusMismatch
+
+	// EventVerdictStatusUnexpected means the EventLog entry does not
+	// correspond to any expected measurement.
+	EventVerdictStatusUnexpected
+
+	// EventVerdictStatusMissing means the expected measurement has no
+	// corresponding EventLog entry.
+	EventVerdictStatusMissing
+)
+
+// String implements fmt.Stringer.
+func (s EventVerdictStatus) String() string {
+	switch s {
+	case EventVerdictStatusUndefined:
+		return "undefined"
+	case EventVerdictStatusMatch:
+		return "match"
+	case EventVerdictStatusMismatch:
+		return "mismatch"
+	case EventVerdictStatusUnexpected:
+		return "unexpected"
+	case EventVerdictStatusMissing:
+		return "missing"
+	}
+	return fmt.Sprintf("unknown_status_%d", int(s))
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s EventVerdictStatus) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + s.String() + `"`), nil
+}
+
+// EventVerdict is the result of verification of a single EventLog entry
+// against the expected measurement.
+type EventVerdict struct {
+	Status EventVerdictStatus
+
+	// EventIndex is the index of the event within the events of the
+	// verified PCR and hash algorithm. It is -1 if Status is
+	// EventVerdictStatusMissing.
+	EventIndex int
+
+	// Event is the EventLog entry. It is nil if Status is
+	// EventVerdictStatusMissing.
+	Event *tpmeventlog.Event `json:",omitempty"`
+
+	// Measurement is the expected measurement. It is nil if Status is
+	// EventVerdictStatusUnexpected.
+	Measurement *pcr.Measurement `json:",omitempty"`
+
+	// CandidateMeasurementIDs are the measurements which could produce
+	// an EventLog entry of such type (see pcr.TPMEventTypeToMeasurementIDs).
+	// It is set only if Status is EventVerdictStatusUnexpected.
+	CandidateMeasurementIDs pcr.MeasurementIDs `json:",omitempty"`
+
+	// ExpectedDigest is the digest calculated from the firmware image.
+	ExpectedDigest []byte `json:",omitempty"`
+
+	// Description explains the status (if it is not a match).
+	Description string `json:",omitempty"`
+}
+
+// EventVerdicts is a list of EventVerdict-s in the order of the EventLog
+// entries (missing measurements are placed where they were expected).
+type EventVerdicts []EventVerdict
+
+// IsMatch returns true if all EventLog entries match the expected
+// measurements.
+func (s EventVerdicts) IsMatch() bool {
+	for _, v := range s {
+		if v.Status != EventVerdictStatusMatch {
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
+// VerifyEventLog maps each EventLog entry of PCR `pcrIndex` with digest
+// algorithm `hashAlgo` to the expected measurement and reports a verdict
+// for every entry and every expected measurement.
+//
+// The mapping is performed in order: for each expected measurement it is
+// used the nearest following EventLog entry of an appropriate type; all
+// entries skipped on the way are reported as unexpected. If there is no
+// such entry, then the measurement is reported as missing.
+func VerifyEventLog(
+	eventLog *tpmeventlog.TPMEventLog,
+	pcrIndex pcr.ID,
+	hashAlgo tpmeventlog.TPMAlgorithm,
+	flow pcr.Flow,
+	measurements pcr.Measurements,
+	imageBytes []byte,
+) (EventVerdicts, error) {
+	if eventLog == nil {
+		return nil, fmt.Errorf("TPM EventLog is not provided")
+	}
+	hasher, err := tpmeventlog.NewHasher(hashAlgo)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := eventLog.FilterEvents(pcrIndex, hashAlgo)
+	if err != nil {
+		return nil, fmt.Errorf("unable to filter events: %w", err)
+	}
+
+	var expected pcr.Measurements
+	for _, m := range measurements.FilterByPCRIndex(pcrIndex) {
+		if m.IsFake() && m.ID != pcr.MeasurementIDInit {
+			continue
+		}
+		if len(m.EventLogEventTypes()) == 0 {
+			continue
+		}
+		expected = append(expected, m)
+	}
+
+	unexpected := func(evIdx int) EventVerdict {
+		ev := events[evIdx]
+		return EventVerdict{
+			Status:                  EventVerdictStatusUnexpected,
+			EventIndex:              evIdx,
+			Event:                   ev,
+			CandidateMeasurementIDs: pcr.TPMEventTypeToMeasurementIDs(pcrIndex, ev.Type),
+			Description:             fmt.Sprintf("no expected measurement for event type %d", ev.Type),
+		}
+	}
+
+	var result EventVerdicts
+	evIdx := 0
+	for _, m := range expected {
+		matchIdx := -1
+		for idx := evIdx; idx < len(events); idx++ {
+			if isEventTypeOf(m, events[idx].Type) {
+				matchIdx = idx
+				break
+			}
+		}
+		if matchIdx < 0 {
+			result = append(result, EventVerdict{
+				Status:      EventVerdictStatusMissing,
+				EventIndex:  -1,
+				Measurement: m,
+				Description: fmt.Sprintf("expected an event of type %v", eventTypesOf(m)),
+			})
+			continue
+		}
+		for ; evIdx < matchIdx; evIdx++ {
+			result = append(result, unexpected(evIdx))
+		}
+
+		verdict, err := verifyEvent(events[matchIdx], m, flow, imageBytes, hasher)
+		if err != nil {
+			return nil, fmt.Errorf("unable to verify measurement '%s': %w", m.ID, err)
+		}
+		verdict.EventIndex = matchIdx
+		result = append(result, *verdict)
+		evIdx = matchIdx + 1
+	}
+	for ; evIdx < len(events); evIdx++ {
+		result = append(result, unexpected(evIdx))
+	}
+
+	return result, nil
+}
+
+func eventTypesOf(m *pcr.Measurement) []tpmeventlog.EventType {
+	var result []tpmeventlog.EventType
+	for _, eventType := range m.EventLogEventTypes() {
+		result = append(result, *eventType)
+	}
+	return result
+}
+
+func isEventTypeOf(m *pcr.Measurement, eventType tpmeventlog.EventType) bool {
+	for _, cmp := range m.EventLogEventTypes() {
+		if *cmp == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func verifyEvent(
+	ev *tpmeventlog.Event,
+	m *pcr.Measurement,
+	flow pcr.Flow,
+	imageBytes []byte,
+	hasher hash.Hash,
+) (*EventVerdict, error) {
+	verdict := &EventVerdict{
+		Status:      EventVerdictStatusMatch,
+		Event:       ev,
+		Measurement: m,
+	}
+
+	if m.ID == pcr.MeasurementIDInit {
+		locality, err := tpmeventlog.ParseLocality(ev.Data)
+		if err != nil {
+			verdict.Status = EventVerdictStatusMismatch
+			verdict.Description = fmt.Sprintf("unable to parse the locality: %v", err)
+			return verdict, nil
+		}
+		if locality != flow.TPMLocality() {
+			verdict.Status = EventVerdictStatusMismatch
+			verdict.Description = fmt.Sprintf("locality %d != %d (expected by flow %s)", locality, flow.TPMLocality(), flow)
+		}
+		return verdict, nil
+	}
+
+	for _, r := range m.Ranges() {
+		if r.End() > uint64(len(imageBytes)) {
+			return nil, fmt.Errorf("range %s is out of the image (size: %d)", r, len(imageBytes))
+		}
+	}
+	digest, err := m.Calculate(imageBytes, hasher)
+	if err != nil {
+		return nil, err
+	}
+	verdict.ExpectedDigest = digest
+	if !bytes.Equal(digest, ev.Digest.Digest) {
+		verdict.Status = EventVerdictStatusMismatch
+		verdict.Description = fmt.Sprintf("digest %X != %X", ev.Digest.Digest, digest)
+	}
+	return verdict, nil
+}
@@ -0,0 +1,98 @@
+package pcrbruteforcer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
+	"github.com/9elements/converged-security-suite/v2/pkg/registers"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+	"github.com/9elements/converged-security-suite/v2/testdata/firmware"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyEventLog(t *testing.T) {
+	firmwareImage := firmware.FakeIntelFirmware
+
+	firmware, err := uefi.ParseUEFIFirmwareBytes(firmwareImage)
+	require.NoError(t, err)
+
+	getMeasurements := func(t *testing.T, acmPolicyStatus uint64) pcr.Measurements {
+		measurements, _, debugInfo, err := pcr.GetMeasurements(firmware, 0,
+			pcr.SetFlow(pcr.FlowIntelCBnT0T),
+			pcr.SetIBBHashDigest(tpm2.AlgSHA1),
+			pcr.SetRegisters(registers.Registers{
+				registers.ParseACMPolicyStatusRegister(acmPolicyStatus),
+			}),
+		)
+		require.NoError(t, err, fmt.Sprintf("debugInfo: '%v'", debugInfo))
+		return measurements
+	}
+
+	newEvent := func(eventType tpmeventlog.EventType, data []byte, digest string) *tpmeventlog.Event {
+		return &tpmeventlog.Event{
+			PCRIndex: 0,
+			Type:     eventType,
+			Data:     data,
+			Digest: &tpmeventlog.Digest{
+				HashAlgo: tpmeventlog.TPMAlgorithmSHA1,
+				Digest:   unhex(t, digest),
+			},
+		}
+	}
+	events := []*tpmeventlog.Event{
+		newEvent(tpmeventlog.EV_NO_ACTION, []byte("StartupLocality\000\003"), "0000000000000000000000000000000000000000"),
+		newEvent(tpmeventlog.EV_S_CRTM_CONTENTS, nil, "527C9A38B2F45FBF89C382547E0A0812722A47D3"),
+		newEvent(tpmeventlog.EV_S_CRTM_VERSION, nil, "C14F556E35C9BB45F189B03F383A6A3E31256681"),
+		newEvent(tpmeventlog.EV_POST_CODE, nil, "4C9836F73CC42ADBECE7D565B783E618B4A75C22"),
+		newEvent(tpmeventlog.EV_SEPARATOR, nil, "9069CA78E7450A285173431B3E52C5C25299E473"),
+	}
+
+	statuses := func(verdicts EventVerdicts) []EventVerdictStatus {
+		var result []EventVerdictStatus
+		for _, v := range verdicts {
+			result = append(result, v.Status)
+		}
+		return result
+	}
+
+	t.Run("match", func(t *testing.T) {
+		verdicts, err := VerifyEventLog(&tpmeventlog.TPMEventLog{Events: events}, 0, tpmeventlog.TPMAlgorithmSHA1, pcr.FlowIntelCBnT0T, getMeasurements(t, 0x0000000200108681), firmwareImage)
+		require.NoError(t, err)
+		require.True(t, verdicts.IsMatch(), fmt.Sprintf("%v", statuses(verdicts)))
+		require.Len(t, verdicts, len(events))
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		verdicts, err := VerifyEventLog(&tpmeventlog.TPMEventLog{Events: events}, 0, tpmeventlog.TPMAlgorithmSHA1, pcr.FlowIntelCBnT0T, getMeasurements(t, 0x0000000200108682), firmwareImage)
+		require.NoError(t, err)
+		require.False(t, verdicts.IsMatch())
+		require.Equal(t, EventVerdictStatusMismatch, verdicts[1].Status)
+		require.Equal(t, pcr.MeasurementIDPCR0DATA, verdicts[1].Measurement.ID)
+		require.Equal(t, EventVerdictStatusMatch, verdicts[2].Status)
+	})
+
+	t.Run("missing_and_unexpected", func(t *testing.T) {
+		modifiedEvents := []*tpmeventlog.Event{
+			events[0],
+			events[1],
+			events[3],
+			newEvent(tpmeventlog.EV_EFI_PLATFORM_FIRMWARE_BLOB, nil, "0000000000000000000000000000000000000000"),
+			events[4],
+		}
+		verdicts, err := VerifyEventLog(&tpmeventlog.TPMEventLog{Events: modifiedEvents}, 0, tpmeventlog.TPMAlgorithmSHA1, pcr.FlowIntelCBnT0T, getMeasurements(t, 0x0000000200108681), firmwareImage)
+		require.NoError(t, err)
+		require.Equal(t, []EventVerdictStatus{
+			EventVerdictStatusMatch,
+			EventVerdictStatusMatch,
+			EventVerdictStatusMissing,
+			EventVerdictStatusMatch,
+			EventVerdictStatusUnexpected,
+			EventVerdictStatusMatch,
+		}, statuses(verdicts))
+		require.Equal(t, -1, verdicts[2].EventIndex)
+		require.Equal(t, 3, verdicts[4].EventIndex)
+	})
+}
@@ -79,6 +79,7 @@ func PrintCBnTStructures(image []byte) error {
 	}
 	if acm != nil {
 		acm.PrettyPrint()
+		tools.PrintACMSignature(acmEntry.DataSegmentBytes)
 		chipsets.PrettyPrint()
 		processors.PrettyPrint()
 		tpms.PrettyPrint()
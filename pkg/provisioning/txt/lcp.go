@@ -0,0 +1,327 @@
+package txt
+
+import (
+	"crypto"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/tools"
+	"github.com/google/go-tpm/tpm2"
+	"gopkg.in/yaml.v3"
+)
+
+// LCPPolicyDataDescription is the human-readable description of an LCP
+// policy data file (LCP_POLICY_DATA), it could be written in YAML or JSON.
+// Digests are hex-encoded.
+type LCPPolicyDataDescription struct {
+	Lists []LCPPolicyListDescription `yaml:"Lists" json:"Lists"`
+}
+
+// LCPPolicyListDescription describes a policy list (LCP_POLICY_LIST2).
+type LCPPolicyListDescription struct {
+	// SigningKey is the path to the private key to sign the list with,
+	// the list is not signed if it is empty.
+	SigningKey string `yaml:"SigningKey,omitempty" json:"SigningKey,omitempty"`
+	// SigningKeyPassword is the password of the encrypted private key.
+	SigningKeyPassword string `yaml:"SigningKeyPassword,omitempty" json:"SigningKeyPassword,omitempty"`
+	// SignatureHashAlg is the hash algorithm of RSA signatures: SHA1, SHA256 (default), SHA384 or SHA512.
+	SignatureHashAlg  string                        `yaml:"SignatureHashAlg,omitempty" json:"SignatureHashAlg,omitempty"`
+	RevocationCounter uint16                        `yaml:"RevocationCounter,omitempty" json:"RevocationCounter,omitempty"`
+	Elements          []LCPPolicyElementDescription `yaml:"Elements" json:"Elements"`
+}
+
+// LCPPolicyElementDescription describes a policy element. The set of used
+// fields depends on Type:
+//   - MLE: SINITMinVersion, HashAlg, Hashes;
+//   - PCONF: HashAlg, PCRInfos;
+//   - SBIOS: HashAlg, FallbackHash, Hashes;
+//   - STM: HashAlg, Hashes.
+type LCPPolicyElementDescription struct {
+	Type            string                  `yaml:"Type" json:"Type"`
+	Control         uint32                  `yaml:"Control,omitempty" json:"Control,omitempty"`
+	HashAlg         string                  `yaml:"HashAlg" json:"HashAlg"`
+	SINITMinVersion uint8                   `yaml:"SINITMinVersion,omitempty" json:"SINITMinVersion,omitempty"`
+	Hashes          []string                `yaml:"Hashes,omitempty" json:"Hashes,omitempty"`
+	FallbackHash    string                  `yaml:"FallbackHash,omitempty" json:"FallbackHash,omitempty"`
+	PCRInfos        []LCPPCRInfoDescription `yaml:"PCRInfos,omitempty" json:"PCRInfos,omitempty"`
+}
+
+// LCPPCRInfoDescription describes an expected PCR composite of a PCONF
+// element. Either Digest (the hash of the concatenated PCR values) or
+// PCRValues (to calculate the digest from) should be set.
+type LCPPCRInfoDescription struct {
+	PCRs      []int          `yaml:"PCRs,omitempty" json:"PCRs,omitempty"`
+	Digest    string         `yaml:"Digest,omitempty" json:"Digest,omitempty"`
+	PCRValues map[int]string `yaml:"PCRValues,omitempty" json:"PCRValues,omitempty"`
+}
+
+// LCPKeyLoader loads a private key to sign a policy list with.
+type LCPKeyLoader func(path, password string) (crypto.Signer, error)
+
+// lcpHashAlgs maps hash algorithm names used in descriptions to TPM
+// algorithm IDs.
+var lcpHashAlgs = map[string]tpm2.Algorithm{
+	"SHA1":   tpm2.AlgSHA1,
+	"SHA256": tpm2.AlgSHA256,
+	"SHA384": tpm2.AlgSHA384,
+	"SHA512": tpm2.AlgSHA512,
+}
+
+// ParseLCPPolicyDataDescription parses a YAML or JSON description of
+// an LCP policy data file.
+func ParseLCPPolicyDataDescription(b []byte) (*LCPPolicyDataDescription, error) {
+	var desc LCPPolicyDataDescription
+	if err := yaml.Unmarshal(b, &desc); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the description: %w", err)
+	}
+	return &desc, nil
+}
+
+// Build constructs the policy data file and signs the lists. `loadKey` is
+// used only for lists with field SigningKey set.
+func (desc *LCPPolicyDataDescription) Build(loadKey LCPKeyLoader) (*tools.LCPPolicyData, error) {
+	if len(desc.Lists) == 0 || len(desc.Lists) > int(tools.LCPMaxLists) {
+		return nil, fmt.Errorf("invalid amount of lists: %d (expected 1..%d)", len(desc.Lists), tools.LCPMaxLists)
+	}
+	pd := &tools.LCPPolicyData{
+		NumLists: uint8(len(desc.Lists)),
+	}
+	copy(pd.FileSignature[:], tools.LCPDataFileSignature)
+	for idx := range desc.Lists {
+		list, err := desc.Lists[idx].build(loadKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to build list %d: %w", idx, err)
+		}
+		pd.PolicyLists = append(pd.PolicyLists, tools.LCPList{TPM20PolicyList: *list})
+	}
+	return pd, nil
+}
+
+func (desc *LCPPolicyListDescription) build(loadKey LCPKeyLoader) (*tools.LCPPolicyList2, error) {
+	list := &tools.LCPPolicyList2{
+		Version:     tools.LCPPolicyList2Version,
+		SignaturAlg: uint16(tpm2.AlgNull),
+	}
+	for idx := range desc.Elements {
+		element, err := desc.Elements[idx].build()
+		if err != nil {
+			return nil, fmt.Errorf("unable to build element %d: %w", idx, err)
+		}
+		list.PolicyElements = append(list.PolicyElements, *element)
+	}
+	if desc.SigningKey == "" {
+		return list, nil
+	}
+
+	if loadKey == nil {
+		return nil, fmt.Errorf("no key loader is provided")
+	}
+	privKey, err := loadKey(desc.SigningKey, desc.SigningKeyPassword)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load key '%s': %w", desc.SigningKey, err)
+	}
+	hashAlg := crypto.SHA256
+	if desc.SignatureHashAlg != "" {
+		alg, err := parseLCPHashAlg(desc.SignatureHashAlg)
+		if err != nil {
+			return nil, err
+		}
+		if hashAlg, err = alg.Hash(); err != nil {
+			return nil, err
+		}
+	}
+	if err := list.Sign(privKey, desc.RevocationCounter, hashAlg); err != nil {
+		return nil, fmt.Errorf("unable to sign the list: %w", err)
+	}
+	return list, nil
+}
+
+func (desc *LCPPolicyElementDescription) build() (*tools.LCPPolicyElement, error) {
+	hashAlg, err := parseLCPHashAlg(desc.HashAlg)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := parseLCPDigests(hashAlg, desc.Hashes...)
+	if err != nil {
+		return nil, err
+	}
+
+	element := &tools.LCPPolicyElement{PolicyEltControl: desc.Control}
+	switch strings.ToUpper(desc.Type) {
+	case "MLE":
+		element.Type = tools.LCPPolicyElementMLE2
+		element.MLE2 = &tools.LCPPolicyMLE2{
+			SINITMinVersion: desc.SINITMinVersion,
+			HashAlg:         hashAlg,
+			Hashes:          hashes,
+		}
+	case "SBIOS":
+		fallbackHash, err := parseLCPDigests(hashAlg, desc.FallbackHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fallback hash: %w", err)
+		}
+		element.Type = tools.LCPPolicyElementSBIOS2
+		element.SBIOS2 = &tools.LCPPolicySBIOS2{
+			HashAlg:      hashAlg,
+			FallbackHash: fallbackHash[0],
+			Hashes:       hashes,
+		}
+	case "STM":
+		element.Type = tools.LCPPolicyElementSTM2
+		element.STM2 = &tools.LCPPolicySTM2{
+			HashAlg: hashAlg,
+			Hashes:  hashes,
+		}
+	case "PCONF":
+		element.Type = tools.LCPPolicyElementPCONF2
+		element.PCONF2 = &tools.LCPPolicyPCONF2{
+			HashAlg: hashAlg,
+		}
+		for idx := range desc.PCRInfos {
+			info, err := desc.PCRInfos[idx].build(hashAlg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid PCR info %d: %w", idx, err)
+			}
+			element.PCONF2.PCRInfos = append(element.PCONF2.PCRInfos, *info)
+		}
+	default:
+		return nil, fmt.Errorf("unknown element type '%s' (expected: MLE, PCONF, SBIOS or STM)", desc.Type)
+	}
+	return element, nil
+}
+
+func (desc *LCPPCRInfoDescription) build(hashAlg tpm2.Algorithm) (*tools.TPMSQuoteInfo, error) {
+	if desc.Digest != "" {
+		digest, err := parseLCPDigests(hashAlg, desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		return &tools.TPMSQuoteInfo{
+			PCRSelections: []tpm2.PCRSelection{{Hash: hashAlg, PCRs: desc.PCRs}},
+			PCRDigest:     digest[0],
+		}, nil
+	}
+
+	if len(desc.PCRValues) == 0 {
+		return nil, fmt.Errorf("neither Digest nor PCRValues is set")
+	}
+	var pcrs []int
+	for pcr := range desc.PCRValues {
+		pcrs = append(pcrs, pcr)
+	}
+	sort.Ints(pcrs)
+	h, err := hashAlg.Hash()
+	if err != nil {
+		return nil, err
+	}
+	hasher := h.New()
+	for _, pcr := range pcrs {
+		value, err := parseLCPDigests(hashAlg, desc.PCRValues[pcr])
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of PCR %d: %w", pcr, err)
+		}
+		hasher.Write(value[0])
+	}
+	return &tools.TPMSQuoteInfo{
+		PCRSelections: []tpm2.PCRSelection{{Hash: hashAlg, PCRs: pcrs}},
+		PCRDigest:     hasher.Sum(nil),
+	}, nil
+}
+
+func parseLCPHashAlg(name string) (tpm2.Algorithm, error) {
+	alg, ok := lcpHashAlgs[strings.ToUpper(name)]
+	if !ok {
+		return tpm2.AlgUnknown, fmt.Errorf("unknown hash algorithm '%s'", name)
+	}
+	return alg, nil
+}
+
+func parseLCPDigests(hashAlg tpm2.Algorithm, hexDigests ...string) ([][]byte, error) {
+	h, err := hashAlg.Hash()
+	if err != nil {
+		return nil, err
+	}
+	var result [][]byte
+	for _, hexDigest := range hexDigests {
+		digest, err := hex.DecodeString(hexDigest)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode digest '%s': %w", hexDigest, err)
+		}
+		if len(digest) != h.Size() {
+			return nil, fmt.Errorf("invalid %s digest size: %d", hashAlg, len(digest))
+		}
+		result = append(result, digest)
+	}
+	return result, nil
+}
+
+// SetLCPPolicyData updates LCP_POLICY2 (the content of the PO/PS index) to
+// enforce the policy data: sets the policy type to List, the data revocation
+// counters and the policy hash.
+func SetLCPPolicyData(pol *tools.LCPPolicy2, pd *tools.LCPPolicyData) error {
+	h, err := pol.HashAlg.Hash()
+	if err != nil {
+		return fmt.Errorf("invalid policy hash algorithm: %w", err)
+	}
+	if h.Size() > len(pol.PolicyHash) {
+		return fmt.Errorf("policy hash algorithm %s is not supported", pol.HashAlg)
+	}
+	policyHash, err := pd.PolicyHash(h)
+	if err != nil {
+		return err
+	}
+
+	pol.PolicyType = tools.LCPPolicyTypeList
+	pol.DataRevocationCounters = [tools.LCPMaxLists]uint16{}
+	for idx, list := range pd.PolicyLists {
+		if list.TPM20PolicyList.Signature != nil {
+			pol.DataRevocationCounters[idx] = list.TPM20PolicyList.Signature.RevocationCounter
+		}
+	}
+	pol.PolicyHash = [32]byte{}
+	copy(pol.PolicyHash[:], policyHash)
+	return nil
+}
+
+// VerifyLCPPolicyData verifies the signatures of all signed lists of
+// the policy data file and (if `pol` is not nil) that the policy enforces
+// exactly this policy data.
+func VerifyLCPPolicyData(pd *tools.LCPPolicyData, pol *tools.LCPPolicy2) error {
+	for idx := range pd.PolicyLists {
+		list := &pd.PolicyLists[idx]
+		if !list.IsTPM20() {
+			return fmt.Errorf("list %d: LCP_POLICY_LIST is not supported", idx)
+		}
+		if list.TPM20PolicyList.Signature == nil {
+			continue
+		}
+		if err := list.TPM20PolicyList.Verify(); err != nil {
+			return fmt.Errorf("list %d: %w", idx, err)
+		}
+		if pol != nil && list.TPM20PolicyList.Signature.RevocationCounter < pol.DataRevocationCounters[idx] {
+			return fmt.Errorf("list %d is revoked: revocation counter %d < %d", idx,
+				list.TPM20PolicyList.Signature.RevocationCounter, pol.DataRevocationCounters[idx])
+		}
+	}
+	if pol == nil {
+		return nil
+	}
+
+	if pol.PolicyType != tools.LCPPolicyTypeList {
+		return fmt.Errorf("policy type is %s, the policy data file is not used", pol.PolicyType)
+	}
+	h, err := pol.HashAlg.Hash()
+	if err != nil {
+		return fmt.Errorf("invalid policy hash algorithm: %w", err)
+	}
+	policyHash, err := pd.PolicyHash(h)
+	if err != nil {
+		return err
+	}
+	if h.Size() > len(pol.PolicyHash) || string(pol.PolicyHash[:h.Size()]) != string(policyHash) {
+		return fmt.Errorf("policy hash mismatch: %X != %X", pol.PolicyHash, policyHash)
+	}
+	return nil
+}
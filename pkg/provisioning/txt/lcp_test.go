@@ -0,0 +1,131 @@
+package txt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/tools"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/stretchr/testify/require"
+)
+
+const testLCPPolicyDataDescription = `
+Lists:
+  - SigningKey: rsa.pem
+    SignatureHashAlg: SHA384
+    RevocationCounter: 3
+    Elements:
+      - Type: MLE
+        HashAlg: SHA256
+        SINITMinVersion: 5
+        Hashes: [%[1]s]
+      - Type: PCONF
+        HashAlg: SHA256
+        PCRInfos:
+          - PCRValues: {0: "%[1]s", 17: "%[2]s"}
+          - PCRs: [1]
+            Digest: "%[2]s"
+  - Elements:
+      - Type: SBIOS
+        HashAlg: SHA256
+        FallbackHash: "%[1]s"
+        Hashes: ["%[2]s"]
+      - Type: STM
+        HashAlg: SHA256
+        Hashes: ["%[1]s"]
+  - SigningKey: ecdsa.pem
+    Elements:
+      - {Type: MLE, HashAlg: SHA256, Hashes: ["%[2]s"]}
+`
+
+func testLCPPolicy2() *tools.LCPPolicy2 {
+	return &tools.LCPPolicy2{
+		Version:            tools.LCPPolicyVersion3,
+		HashAlg:            tpm2.AlgSHA256,
+		PolicyType:         tools.LCPPolicyTypeAny,
+		MaxSINITMinVersion: 0xff,
+		LcpHashAlgMask:     tools.LCPPol2HashMaskSHA256,
+		LcpSignAlgMask:     tools.RSA2048SHA256,
+	}
+}
+
+func TestLCPPolicyDataDescription(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	loadKey := func(path, password string) (crypto.Signer, error) {
+		switch path {
+		case "rsa.pem":
+			return rsaKey, nil
+		case "ecdsa.pem":
+			return ecdsaKey, nil
+		}
+		return nil, fmt.Errorf("no key '%s'", path)
+	}
+
+	hash0 := sha256.Sum256([]byte("0"))
+	hash1 := sha256.Sum256([]byte("1"))
+	desc, err := ParseLCPPolicyDataDescription([]byte(fmt.Sprintf(testLCPPolicyDataDescription,
+		fmt.Sprintf("%x", hash0), fmt.Sprintf("%x", hash1))))
+	require.NoError(t, err)
+
+	pd, err := desc.Build(loadKey)
+	require.NoError(t, err)
+	require.Len(t, pd.PolicyLists, 3)
+
+	list0 := &pd.PolicyLists[0].TPM20PolicyList
+	require.NoError(t, list0.Verify())
+	require.Equal(t, uint8(5), list0.PolicyElements[0].MLE2.SINITMinVersion)
+	pcrInfos := list0.PolicyElements[1].PCONF2.PCRInfos
+	require.Len(t, pcrInfos, 2)
+	require.Equal(t, []int{0, 17}, pcrInfos[0].PCRSelections[0].PCRs)
+	composite := sha256.Sum256(append(hash0[:], hash1[:]...))
+	require.Equal(t, composite[:], pcrInfos[0].PCRDigest)
+	require.Equal(t, hash1[:], pcrInfos[1].PCRDigest)
+	require.Nil(t, pd.PolicyLists[1].TPM20PolicyList.Signature)
+	require.Equal(t, hash0[:], pd.PolicyLists[1].TPM20PolicyList.PolicyElements[0].SBIOS2.FallbackHash)
+
+	pol := testLCPPolicy2()
+	require.Error(t, VerifyLCPPolicyData(pd, pol))
+	require.NoError(t, SetLCPPolicyData(pol, pd))
+	require.Equal(t, tools.LCPPolicyTypeList, pol.PolicyType)
+	require.Equal(t, uint16(3), pol.DataRevocationCounters[0])
+	require.NoError(t, VerifyLCPPolicyData(pd, pol))
+
+	b, err := pd.MarshalBinary()
+	require.NoError(t, err)
+	parsed, err := tools.ParsePolicyData(b)
+	require.NoError(t, err)
+	require.NoError(t, VerifyLCPPolicyData(parsed, pol))
+
+	t.Run("revoked", func(t *testing.T) {
+		pol := *pol
+		pol.DataRevocationCounters[0] = 4
+		require.Error(t, VerifyLCPPolicyData(pd, &pol))
+	})
+
+	t.Run("invalid_description", func(t *testing.T) {
+		for _, s := range []string{
+			`Lists: []`,
+			`Lists: [{Elements: [{Type: MLE, HashAlg: SHA256, Hashes: ["00"]}]}]`,
+			`Lists: [{Elements: [{Type: PTT, HashAlg: SHA256}]}]`,
+			`Lists: [{Elements: [{Type: STM, HashAlg: MD5}]}]`,
+			`Lists: [{SigningKey: none.pem, Elements: []}]`,
+		} {
+			desc, err := ParseLCPPolicyDataDescription([]byte(s))
+			require.NoError(t, err, s)
+			_, err = desc.Build(loadKey)
+			require.Error(t, err, s)
+		}
+		_, err := ParseLCPPolicyDataDescription([]byte(strings.Repeat("[", 3)))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,121 @@
+//go:build tpmsim
+// +build tpmsim
+
+package txt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/tools"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmsim"
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+	"github.com/stretchr/testify/require"
+)
+
+// policyOr calculates the policy digest after TPM2_PolicyOR, see
+// TPM 2.0 Part 3, 23.6.
+func policyOr(digests ...[]byte) []byte {
+	h := sha256.New()
+	h.Write(make([]byte, sha256.Size))
+	_ = binary.Write(h, binary.BigEndian, uint32(tpm2.CmdPolicyOr))
+	for _, digest := range digests {
+		h.Write(digest)
+	}
+	return h.Sum(nil)
+}
+
+// policyCommandCode calculates the policy digest after TPM2_PolicyCommandCode,
+// see TPM 2.0 Part 3, 23.11.
+func policyCommandCode(prev []byte, cc tpmutil.Command) []byte {
+	h := sha256.New()
+	h.Write(prev)
+	_ = binary.Write(h, binary.BigEndian, uint32(tpm2.CmdPolicyCommandCode))
+	_ = binary.Write(h, binary.BigEndian, uint32(cc))
+	return h.Sum(nil)
+}
+
+func expectedPSPolicyHash(passHash []byte) []byte {
+	zeroHash := make([]byte, len(passHash))
+	delBranch := policyCommandCode(policyOr(passHash, zeroHash), tpm2.CmdNVUndefineSpaceSpecial)
+	writeBranch := policyOr(passHash, zeroHash)
+	return policyOr(delBranch, writeBranch)
+}
+
+func policyBytes(t *testing.T, pol *tools.LCPPolicy2) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, *pol))
+	return buf.Bytes()
+}
+
+func TestGetPSPolicyHash(t *testing.T) {
+	sim, err := tpmsim.Open("")
+	require.NoError(t, err)
+	defer sim.Close()
+
+	passHash := sha256.Sum256([]byte("password"))
+	psPolicyHash, err := getPSPolicyHash(sim, passHash[:])
+	require.NoError(t, err)
+	require.Equal(t, expectedPSPolicyHash(passHash[:]), psPolicyHash)
+}
+
+func TestProvisioningTPM20(t *testing.T) {
+	sim, err := tpmsim.Open("")
+	require.NoError(t, err)
+	defer sim.Close()
+
+	passHash := sha256.Sum256([]byte("password"))
+
+	// PS index
+	require.NoError(t, DefinePSIndexTPM20(sim, passHash[:]))
+	require.Error(t, DefinePSIndexTPM20(sim, passHash[:]))
+
+	psIndex, err := tpm2.NVReadPublic(sim, tpm2PSIndexDef.NVIndex)
+	require.NoError(t, err)
+	require.Equal(t, tpm2PSIndexDef.Attributes, psIndex.Attributes)
+	require.Equal(t, uint16(tpm2PSIndexSize), psIndex.DataSize)
+	require.Equal(t, tpm2.AlgSHA256, psIndex.NameAlg)
+	require.Equal(t, expectedPSPolicyHash(passHash[:]), []byte(psIndex.AuthPolicy))
+
+	pol := testLCPPolicy2()
+	require.NoError(t, WritePSIndexTPM20(sim, pol, passHash[:]))
+	psIndex, err = tpm2.NVReadPublic(sim, tpm2PSIndexDef.NVIndex)
+	require.NoError(t, err)
+	require.Equal(t, tpm2PSIndexDef.Attributes|tpm2.AttrWritten, psIndex.Attributes)
+	data, err := tpm2.NVRead(sim, tpm2PSIndexDef.NVIndex)
+	require.NoError(t, err)
+	require.Equal(t, policyBytes(t, pol), data)
+
+	wrongPassHash := sha256.Sum256([]byte("wrong password"))
+	require.Error(t, WritePSIndexTPM20(sim, pol, wrongPassHash[:]))
+
+	// AUX index
+	require.NoError(t, DefineAUXIndexTPM20(sim))
+	require.Error(t, DefineAUXIndexTPM20(sim))
+
+	auxIndex, err := tpm2.NVReadPublic(sim, tpm20AUXIndexDef.NVIndex)
+	require.NoError(t, err)
+	require.Equal(t, tpm20AUXIndexDef.Attributes, auxIndex.Attributes)
+	require.Equal(t, uint16(tpm2AUXIndexSize), auxIndex.DataSize)
+	require.Equal(t, []byte(tpm20AUXIndexHashData), []byte(auxIndex.AuthPolicy))
+
+	// AUX index deletion is requested through the PS index
+	require.Error(t, DeleteAUXindexTPM20(sim, pol, passHash[:]))
+	pol.PolicyControl |= tools.LCPPolicyControlAuxDelete
+	require.NoError(t, DeleteAUXindexTPM20(sim, pol, passHash[:]))
+	data, err = tpm2.NVRead(sim, tpm2PSIndexDef.NVIndex)
+	require.NoError(t, err)
+	require.Equal(t, policyBytes(t, pol), data)
+	_, written, err := tools.ParsePolicy(data)
+	require.NoError(t, err)
+	require.True(t, written.ParsePolicyControl2().AuxDelete)
+
+	// PS index deletion
+	require.Error(t, DeletePSIndexTPM20(sim, wrongPassHash[:]))
+	require.NoError(t, DeletePSIndexTPM20(sim, passHash[:]))
+	_, err = tpm2.NVReadPublic(sim, tpm2PSIndexDef.NVIndex)
+	require.Error(t, err)
+}
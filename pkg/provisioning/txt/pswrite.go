@@ -26,6 +26,9 @@ func WritePSIndexTPM20(rw io.ReadWriter, lcppol *tools.LCPPolicy2, passHash []by
 	if err != nil {
 		return fmt.Errorf("StartAuthSession in writePSPolicy failed: %v", err)
 	}
+	defer func() {
+		_ = tpm2.FlushContext(rw, sess)
+	}()
 
 	a := tpm2.TPMLDigest{Digests: []tpmutil.U16Bytes{passHash, zeroHash}}
 	b := tpm2.TPMLDigest{Digests: []tpmutil.U16Bytes{delPol, writePol}}
@@ -0,0 +1,51 @@
+package psb
+
+import (
+	"crypto"
+	"fmt"
+)
+
+// FuseHashCandidate is a possible value of the OEM key hash fused into
+// the SoC when PSB is enabled.
+type FuseHashCandidate struct {
+	// Description describes what data was hashed and how.
+	Description string
+	HashAlgo    crypto.Hash
+	Digest      []byte
+}
+
+// String implements fmt.Stringer.
+func (c FuseHashCandidate) String() string {
+	return fmt.Sprintf("%s(%s): %X", c.HashAlgo, c.Description, c.Digest)
+}
+
+// FuseHashCandidates returns the possible values of the hash of the key
+// which are fused into the SoC. The exact hashed data depends on the
+// SoC generation, so all known variants are returned.
+func (key *Key) FuseHashCandidates() []FuseHashCandidate {
+	token, _ := key.MarshalBinary()
+	token = token[:key.SignedDataSize()]
+
+	dataVariants := []struct {
+		description string
+		data        []byte
+	}{
+		{description: "token", data: token},
+		{description: "exponent|modulus", data: token[keyHeaderSize:]},
+		{description: "modulus", data: key.Modulus},
+	}
+
+	var result []FuseHashCandidate
+	for _, hashAlgo := range []crypto.Hash{crypto.SHA256, crypto.SHA384} {
+		for _, variant := range dataVariants {
+			h := hashAlgo.New()
+			h.Write(variant.data)
+			result = append(result, FuseHashCandidate{
+				Description: variant.description,
+				HashAlgo:    hashAlgo,
+				Digest:      h.Sum(nil),
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,271 @@
+// Package psb implements parsing and verification of the structures of AMD
+// Platform Secure Boot (PSB): the public key tokens (the AMD root key
+// and the OEM key) and the signature of the BIOS RTM volume.
+//
+// The chain of trust is:
+//
+//	AMD root key (PSP directory entry 0x00, self-signed)
+//	  -> OEM key (BIOS directory entry 0x05, signed by the AMD root key)
+//	    -> BIOS RTM volume (BIOS directory entry 0x62, signature is
+//	       stored in BIOS directory entry 0x07)
+//
+// The hash of the OEM key is fused into the SoC when PSB is enabled.
+package psb
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	// register hash functions used in signatures
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// KeyID is the identifier of a key.
+type KeyID [16]byte
+
+// String implements fmt.Stringer.
+func (id KeyID) String() string {
+	return fmt.Sprintf("%X", id[:])
+}
+
+// KeyUsage defines what a key could be used for.
+type KeyUsage uint32
+
+const (
+	// KeyUsageAMDSigning is the usage of the AMD root key: it signs
+	// AMD firmware and other keys.
+	KeyUsageAMDSigning = KeyUsage(0)
+
+	// KeyUsageOEMSigning is the usage of the OEM key: it signs the BIOS.
+	KeyUsageOEMSigning = KeyUsage(8)
+)
+
+// String implements fmt.Stringer.
+func (u KeyUsage) String() string {
+	switch u {
+	case KeyUsageAMDSigning:
+		return "AMDSigning"
+	case KeyUsageOEMSigning:
+		return "OEMSigning"
+	}
+	return fmt.Sprintf("unknown_key_usage_0x%X", uint32(u))
+}
+
+// KeyHeader is the fixed-size part of a public key token.
+type KeyHeader struct {
+	Version         uint32
+	KeyID           KeyID
+	CertifyingKeyID KeyID
+	KeyUsage        KeyUsage
+	Reserved        [16]byte
+	// ExponentSize is the size of the public exponent in bits.
+	ExponentSize uint32
+	// ModulusSize is the size of the modulus in bits.
+	ModulusSize uint32
+}
+
+// keyHeaderSize is the size of KeyHeader in the binary form.
+const keyHeaderSize = 64
+
+// Key is a public key token.
+//
+// Exponent, Modulus and Signature are stored in the little-endian
+// byte order (as they are stored in the firmware).
+type Key struct {
+	KeyHeader
+	Exponent []byte
+	Modulus  []byte
+	// Signature is the signature of the token made by the certifying key.
+	// It is empty for self-signed keys.
+	Signature []byte
+}
+
+// ParseKey parses a public key token. The signature is parsed only if
+// the key is not self-signed and there are bytes left after the modulus;
+// it is expected to fill the rest of `b`.
+func ParseKey(b []byte) (*Key, error) {
+	key := &Key{}
+	if err := binary.Read(bytes.NewReader(b), binary.LittleEndian, &key.KeyHeader); err != nil {
+		return nil, fmt.Errorf("unable to read the key header: %w", err)
+	}
+	if key.ExponentSize%8 != 0 || key.ModulusSize%8 != 0 || key.ModulusSize == 0 {
+		return nil, fmt.Errorf("invalid key sizes: exponent: %d bits, modulus: %d bits", key.ExponentSize, key.ModulusSize)
+	}
+	b = b[keyHeaderSize:]
+
+	exponentSize := uint64(key.ExponentSize / 8)
+	modulusSize := uint64(key.ModulusSize / 8)
+	if uint64(len(b)) < exponentSize+modulusSize {
+		return nil, fmt.Errorf("the key token is too short: %d < %d", len(b), exponentSize+modulusSize)
+	}
+	key.Exponent = b[:exponentSize]
+	key.Modulus = b[exponentSize : exponentSize+modulusSize]
+	if !key.IsSelfSigned() {
+		key.Signature = b[exponentSize+modulusSize:]
+	}
+	return key, nil
+}
+
+// IsSelfSigned returns true if the key is certified by itself.
+func (key *Key) IsSelfSigned() bool {
+	return key.KeyID == key.CertifyingKeyID
+}
+
+// SignedDataSize returns the size of the token data covered by
+// the signature (everything except the signature itself).
+func (key *Key) SignedDataSize() uint64 {
+	return keyHeaderSize + uint64(len(key.Exponent)) + uint64(len(key.Modulus))
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (key *Key) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	if err := binary.Write(buf, binary.LittleEndian, key.KeyHeader); err != nil {
+		return nil, fmt.Errorf("unable to write the key header: %w", err)
+	}
+	buf.Write(key.Exponent)
+	buf.Write(key.Modulus)
+	buf.Write(key.Signature)
+	return buf.Bytes(), nil
+}
+
+// PublicKey returns the key as *rsa.PublicKey.
+func (key *Key) PublicKey() (*rsa.PublicKey, error) {
+	exponent := new(big.Int).SetBytes(reverseBytes(key.Exponent))
+	if !exponent.IsInt64() || exponent.Int64() > int64(^uint32(0)>>1) || exponent.Int64() < 3 {
+		return nil, fmt.Errorf("invalid public exponent: %s", exponent)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(reverseBytes(key.Modulus)),
+		E: int(exponent.Int64()),
+	}, nil
+}
+
+// HashAlgo returns the hash algorithm used in signatures made by this key:
+// SHA256 for 2048-bit keys and SHA384 for 4096-bit keys.
+func (key *Key) HashAlgo() (crypto.Hash, error) {
+	switch key.ModulusSize {
+	case 2048:
+		return crypto.SHA256, nil
+	case 4096:
+		return crypto.SHA384, nil
+	}
+	return 0, fmt.Errorf("unsupported key size: %d bits", key.ModulusSize)
+}
+
+// VerifySignature verifies `signature` (stored in the little-endian byte
+// order) of `data` made by this key. PSB uses RSASSA-PSS with the salt
+// length equal to the hash length.
+func (key *Key) VerifySignature(data, signature []byte) error {
+	pubKey, err := key.PublicKey()
+	if err != nil {
+		return err
+	}
+	hashAlgo, err := key.HashAlgo()
+	if err != nil {
+		return err
+	}
+	if len(signature) != len(key.Modulus) {
+		return fmt.Errorf("invalid signature size: %d != %d", len(signature), len(key.Modulus))
+	}
+	h := hashAlgo.New()
+	h.Write(data)
+	err = rsa.VerifyPSS(pubKey, hashAlgo, h.Sum(nil), reverseBytes(signature), &rsa.PSSOptions{
+		SaltLength: rsa.PSSSaltLengthEqualsHash,
+		Hash:       hashAlgo,
+	})
+	if err != nil {
+		return fmt.Errorf("signature is invalid: %w", err)
+	}
+	return nil
+}
+
+// VerifyCertifiedBy verifies that the key token is signed by `certifyingKey`.
+// `tokenBytes` is the binary form of the token as it is stored in the
+// firmware.
+func (key *Key) VerifyCertifiedBy(certifyingKey *Key, tokenBytes []byte) error {
+	if key.CertifyingKeyID != certifyingKey.KeyID {
+		return fmt.Errorf("key %s is certified by %s, not by %s", key.KeyID, key.CertifyingKeyID, certifyingKey.KeyID)
+	}
+	signedDataSize := key.SignedDataSize()
+	if uint64(len(tokenBytes)) < signedDataSize {
+		return fmt.Errorf("the key token is too short: %d < %d", len(tokenBytes), signedDataSize)
+	}
+	signature := key.Signature
+	if uint64(len(signature)) > uint64(len(certifyingKey.Modulus)) {
+		// the token could be padded
+		signature = signature[:len(certifyingKey.Modulus)]
+	}
+	return certifyingKey.VerifySignature(tokenBytes[:signedDataSize], signature)
+}
+
+// NewKey returns a key token for `pubKey`. The public exponent is stored
+// with the same size as the modulus (as AMD does). The signature is not set,
+// see SetSignature.
+func NewKey(pubKey *rsa.PublicKey, keyID, certifyingKeyID KeyID, usage KeyUsage) *Key {
+	modulus := reverseBytes(pubKey.N.Bytes())
+	exponent := make([]byte, len(modulus))
+	copy(exponent, reverseBytes(big.NewInt(int64(pubKey.E)).Bytes()))
+	return &Key{
+		KeyHeader: KeyHeader{
+			Version:         1,
+			KeyID:           keyID,
+			CertifyingKeyID: certifyingKeyID,
+			KeyUsage:        usage,
+			ExponentSize:    uint32(len(exponent) * 8),
+			ModulusSize:     uint32(len(modulus) * 8),
+		},
+		Exponent: exponent,
+		Modulus:  modulus,
+	}
+}
+
+// SetSignature signs the key token with `certifyingPrivKey` and sets
+// field Signature accordingly.
+func (key *Key) SetSignature(certifyingPrivKey *rsa.PrivateKey) error {
+	key.Signature = nil
+	b, err := key.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	signature, err := Sign(certifyingPrivKey, b)
+	if err != nil {
+		return err
+	}
+	key.Signature = signature
+	return nil
+}
+
+// Sign signs `data` with `privKey` and returns the signature in the
+// little-endian byte order (as it is stored in the firmware). The hash
+// algorithm is chosen the same way as HashAlgo does.
+func Sign(privKey *rsa.PrivateKey, data []byte) ([]byte, error) {
+	hashAlgo, err := (&Key{KeyHeader: KeyHeader{ModulusSize: uint32(privKey.N.BitLen())}}).HashAlgo()
+	if err != nil {
+		return nil, err
+	}
+	h := hashAlgo.New()
+	h.Write(data)
+	signature, err := rsa.SignPSS(rand.Reader, privKey, hashAlgo, h.Sum(nil), &rsa.PSSOptions{
+		SaltLength: rsa.PSSSaltLengthEqualsHash,
+		Hash:       hashAlgo,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign: %w", err)
+	}
+	return reverseBytes(signature), nil
+}
+
+func reverseBytes(b []byte) []byte {
+	r := make([]byte, len(b))
+	for idx := range b {
+		r[len(b)-1-idx] = b[idx]
+	}
+	return r
+}
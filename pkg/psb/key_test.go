@@ -0,0 +1,65 @@
+package psb
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyChain(t *testing.T) {
+	rootPrivKey, err := rsa.GenerateKey(rand.Reader, 4096)
+	require.NoError(t, err)
+	oemPrivKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	rootKeyID := KeyID{1, 2, 3}
+	rootKey := NewKey(&rootPrivKey.PublicKey, rootKeyID, rootKeyID, KeyUsageAMDSigning)
+	oemKey := NewKey(&oemPrivKey.PublicKey, KeyID{4, 5, 6}, rootKeyID, KeyUsageOEMSigning)
+	require.NoError(t, oemKey.SetSignature(rootPrivKey))
+
+	rootKeyBytes, err := rootKey.MarshalBinary()
+	require.NoError(t, err)
+	oemKeyBytes, err := oemKey.MarshalBinary()
+	require.NoError(t, err)
+	require.Len(t, oemKeyBytes, keyHeaderSize+256+256+512)
+
+	parsedRootKey, err := ParseKey(rootKeyBytes)
+	require.NoError(t, err)
+	require.Equal(t, rootKey, parsedRootKey)
+	require.True(t, parsedRootKey.IsSelfSigned())
+
+	parsedOEMKey, err := ParseKey(oemKeyBytes)
+	require.NoError(t, err)
+	require.Equal(t, oemKey, parsedOEMKey)
+	require.NoError(t, parsedOEMKey.VerifyCertifiedBy(parsedRootKey, oemKeyBytes))
+	require.Error(t, parsedOEMKey.VerifyCertifiedBy(parsedOEMKey, oemKeyBytes))
+
+	pubKey, err := parsedOEMKey.PublicKey()
+	require.NoError(t, err)
+	require.Equal(t, &oemPrivKey.PublicKey, pubKey)
+
+	data := []byte("BIOS RTM volume")
+	signature, err := Sign(oemPrivKey, data)
+	require.NoError(t, err)
+	require.NoError(t, parsedOEMKey.VerifySignature(data, signature))
+	require.Error(t, parsedOEMKey.VerifySignature(data[1:], signature))
+
+	require.Len(t, parsedOEMKey.FuseHashCandidates(), 6)
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := append([]byte{}, oemKeyBytes...)
+		tampered[keyHeaderSize] ^= 1
+		tamperedKey, err := ParseKey(tampered)
+		require.NoError(t, err)
+		require.Error(t, tamperedKey.VerifyCertifiedBy(parsedRootKey, tampered))
+	})
+
+	t.Run("too_short", func(t *testing.T) {
+		_, err := ParseKey(oemKeyBytes[:keyHeaderSize+100])
+		require.Error(t, err)
+		_, err = ParseKey(oemKeyBytes[:10])
+		require.Error(t, err)
+	})
+}
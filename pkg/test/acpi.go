@@ -128,12 +128,12 @@ var (
 	testTXTHeapSizeFitsMADTCopy = Test{
 		Name:                    "ACPI MADT copy fits into TXT heap",
 		Required:                true,
-		function:                notImplemented,
-		Status:                  NotImplemented,
+		function:                CheckTXTHeapFitsMADTCopy,
+		Status:                  Implemented,
 		SpecificationChapter:    "SINIT Class 9 Major 7 Minor 1",
 		SpecificiationTitle:     ServerGrantleyPlatformSpecificationTitle,
 		SpecificationDocumentID: ServerGrantleyPlatformDocumentID,
-		dependencies:            []*Test{&testMADTPresent},
+		dependencies:            []*Test{&testMADTPresent, &testbiosdataregionpresent},
 	}
 	testTXTHeapSizeFitsDynamicMadt = Test{
 		Name:                    "Dynamic ACPI MADT fits into TXT heap",
@@ -148,21 +148,22 @@ var (
 	testTXTHeapSizeFitsDMARCopy = Test{
 		Name:                    "ACPI DMAR copy fits into TXT heap",
 		Required:                true,
-		function:                notImplemented,
-		Status:                  NotImplemented,
+		function:                CheckTXTHeapFitsDMARCopy,
+		Status:                  Implemented,
 		SpecificationChapter:    "SINIT Class 9 Major 7 Minor 3",
 		SpecificiationTitle:     ServerGrantleyPlatformSpecificationTitle,
 		SpecificationDocumentID: ServerGrantleyPlatformDocumentID,
-		dependencies:            []*Test{&testRSDTorXSDTValid},
+		dependencies:            []*Test{&testDMARPresent, &testbiosdataregionpresent},
 	}
 	testACPIRSDPInOSToSINITData = Test{
 		Name:                    "ACPI RSDP in 'OS to SINIT data' points to address below 4 GiB",
 		Required:                true,
-		function:                notImplemented,
-		Status:                  NotImplemented,
+		function:                CheckACPIRSDPInOSToSINITData,
+		Status:                  Implemented,
 		SpecificationChapter:    "SINIT Class 9 Major 0xc",
 		SpecificiationTitle:     ServerGrantleyPlatformSpecificationTitle,
 		SpecificationDocumentID: ServerGrantleyPlatformDocumentID,
+		dependencies:            []*Test{&testbiosdataregionpresent},
 	}
 	testACPIDMARValidHPET = Test{
 		Name:                    "ACPI DMAR table has valid HPET configuration",
@@ -318,6 +319,9 @@ var (
 		&testXSDTPresent,
 		&testXSDTValid,
 		&testRSDTorXSDTValid,
+		&testTXTHeapSizeFitsMADTCopy,
+		&testTXTHeapSizeFitsDMARCopy,
+		&testACPIRSDPInOSToSINITData,
 	}
 )
 
@@ -772,3 +776,45 @@ func CheckDMARValid(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Confi
 	//FIXME: Additional checks here
 	return true, nil, nil
 }
+
+// checkTXTHeapFitsTable checks if SINIT could place a copy of the ACPI table
+// with the given size in the SinitMleData region
+func checkTXTHeapFitsTable(name string, required uint64) (bool, error, error) {
+	space := txtheap.SinitMleDataSpace()
+	if required > space {
+		return false, fmt.Errorf("ACPI %s copy needs 0x%x bytes, but only 0x%x bytes are available for SinitMleData in TXT heap", name, required, space), nil
+	}
+	return true, nil, nil
+}
+
+//CheckTXTHeapFitsMADTCopy tests if the copy of the MADT ACPI table fits into the TXT heap
+func CheckTXTHeapFitsMADTCopy(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+	madt, err := hwapi.GetACPITableDevMem(txtAPI, "APIC")
+	if err != nil {
+		return false, nil, err
+	}
+	// The copy is stored in an extended data element, followed by the END element
+	required := uint64(tools.TXTSinitMleDataHeaderSize + 2*tools.TXTHeapExtDataHeaderSize + len(madt))
+	return checkTXTHeapFitsTable("MADT", required)
+}
+
+//CheckTXTHeapFitsDMARCopy tests if the copy of the DMAR ACPI table fits into the TXT heap
+func CheckTXTHeapFitsDMARCopy(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+	dmar, err := hwapi.GetACPITableDevMem(txtAPI, "DMAR")
+	if err != nil {
+		return false, nil, err
+	}
+	required := uint64(tools.TXTSinitMleDataHeaderSize + len(dmar))
+	return checkTXTHeapFitsTable("DMAR", required)
+}
+
+//CheckACPIRSDPInOSToSINITData tests if the ACPI RSDP pointer passed to SINIT points below 4 GiB
+func CheckACPIRSDPInOSToSINITData(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+	if txtheap.OsSinitData == nil {
+		return false, nil, fmt.Errorf("OS to SINIT data is not available, no measured launch was performed: %v", txtheaperr)
+	}
+	if txtheap.OsSinitData.RSDPPtr >= FourGiB {
+		return false, fmt.Errorf("ACPI RSDP in OS to SINIT data points to 0x%x, which is above 4 GiB", txtheap.OsSinitData.RSDPPtr), nil
+	}
+	return true, nil, nil
+}
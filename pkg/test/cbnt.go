@@ -0,0 +1,416 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/pcr"
+	"github.com/9elements/converged-security-suite/v2/pkg/registers"
+	"github.com/9elements/converged-security-suite/v2/pkg/tools"
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi"
+	"github.com/9elements/go-linux-lowlevel-hw/pkg/hwapi"
+	pkgbytes "github.com/linuxboot/fiano/pkg/bytes"
+	"github.com/linuxboot/fiano/pkg/intel/metadata/fit"
+	"github.com/linuxboot/fiano/pkg/intel/metadata/manifest"
+	"github.com/linuxboot/fiano/pkg/intel/metadata/manifest/bootpolicy"
+	"github.com/linuxboot/fiano/pkg/intel/metadata/manifest/key"
+	fianoUEFI "github.com/linuxboot/fiano/pkg/uefi"
+)
+
+var (
+	// set by CBnTFirmwareAccessible
+	cbntFirmware   *uefi.UEFI
+	cbntFITEntries []fit.Entry
+	// set by CBnTHasKM
+	cbntKM *key.Manifest
+	// set by CBnTHasBPM
+	cbntBPM *bootpolicy.Manifest
+
+	testcbntfirmwareaccessible = Test{
+		Name:     "Firmware image accessible",
+		Required: true,
+		function: CBnTFirmwareAccessible,
+		Status:   Implemented,
+		Spec:     CBnT,
+	}
+	testcbnthaskm = Test{
+		Name:                    "Key Manifest entry in FIT",
+		Required:                true,
+		function:                CBnTHasKM,
+		dependencies:            []*Test{&testcbntfirmwareaccessible},
+		Status:                  Implemented,
+		Spec:                    CBnT,
+		SpecificiationTitle:     IntelCBnTSpecificationTitle,
+		SpecificationDocumentID: IntelCBnTSpecificationDocumentID,
+	}
+	testcbnthasbpm = Test{
+		Name:                    "Boot Policy Manifest entry in FIT",
+		Required:                true,
+		function:                CBnTHasBPM,
+		dependencies:            []*Test{&testcbntfirmwareaccessible},
+		Status:                  Implemented,
+		Spec:                    CBnT,
+		SpecificiationTitle:     IntelCBnTSpecificationTitle,
+		SpecificationDocumentID: IntelCBnTSpecificationDocumentID,
+	}
+	testcbntmanifestsvalid = Test{
+		Name:                    "KM and BPM signatures and key chain valid",
+		Required:                true,
+		function:                CBnTManifestsValid,
+		dependencies:            []*Test{&testcbnthaskm, &testcbnthasbpm},
+		Status:                  Implemented,
+		Spec:                    CBnT,
+		SpecificiationTitle:     IntelCBnTSpecificationTitle,
+		SpecificationDocumentID: IntelCBnTSpecificationDocumentID,
+	}
+	testcbntkmhashprovisioned = Test{
+		Name:                    "KM public key hash provisioned in ME region",
+		Required:                true,
+		function:                CBnTKMHashProvisioned,
+		dependencies:            []*Test{&testcbnthaskm},
+		Status:                  Implemented,
+		Spec:                    CBnT,
+		SpecificiationTitle:     IntelCBnTSpecificationTitle,
+		SpecificationDocumentID: IntelCBnTSpecificationDocumentID,
+	}
+	testcbntibbcoversresetvector = Test{
+		Name:                    "IBB segments cover reset vector",
+		Required:                true,
+		function:                CBnTIBBCoversResetVector,
+		dependencies:            []*Test{&testcbnthasbpm},
+		Status:                  Implemented,
+		Spec:                    CBnT,
+		SpecificiationTitle:     IntelCBnTSpecificationTitle,
+		SpecificationDocumentID: IntelCBnTSpecificationDocumentID,
+	}
+	testcbntibbcoversfit = Test{
+		Name:                    "IBB segments cover FIT",
+		Required:                true,
+		function:                CBnTIBBCoversFIT,
+		dependencies:            []*Test{&testcbnthasbpm},
+		Status:                  Implemented,
+		Spec:                    CBnT,
+		SpecificiationTitle:     IntelCBnTSpecificationTitle,
+		SpecificationDocumentID: IntelCBnTSpecificationDocumentID,
+	}
+	testcbntacmsvn = Test{
+		Name:                    "BIOS ACM SVN is not below BPM ACMSVN",
+		Required:                true,
+		function:                CBnTACMSVNValid,
+		dependencies:            []*Test{&testcbnthasbpm},
+		Status:                  Implemented,
+		Spec:                    CBnT,
+		SpecificiationTitle:     IntelCBnTSpecificationTitle,
+		SpecificationDocumentID: IntelCBnTSpecificationDocumentID,
+	}
+	testcbntbpmelementsvalid = Test{
+		Name:                    "BPM PCD, PM and TXT elements valid",
+		Required:                true,
+		function:                CBnTBPMElementsValid,
+		dependencies:            []*Test{&testcbnthasbpm},
+		Status:                  Implemented,
+		Spec:                    CBnT,
+		SpecificiationTitle:     IntelCBnTSpecificationTitle,
+		SpecificationDocumentID: IntelCBnTSpecificationDocumentID,
+	}
+	testcbntbtgsacminfo = Test{
+		Name:         "BtG SACM info reports verified or measured boot",
+		Required:     true,
+		function:     CBnTBtGSACMInfoValid,
+		dependencies: []*Test{&testcheckforintelcpu},
+		Status:       Implemented,
+		Spec:         CBnT,
+	}
+	testcbntpbetstopped = Test{
+		Name:         "Boot Guard PBE timer is stopped",
+		Required:     true,
+		function:     CBnTPBETStopped,
+		dependencies: []*Test{&testcheckforintelcpu},
+		Status:       Implemented,
+		Spec:         CBnT,
+	}
+
+	// TestsCBnTSpecific exports the Slice with CBnT specific tests
+	TestsCBnTSpecific = [...]*Test{
+		&testcbntfirmwareaccessible,
+		&testcbnthaskm,
+		&testcbnthasbpm,
+		&testcbntmanifestsvalid,
+		&testcbntkmhashprovisioned,
+		&testcbntibbcoversresetvector,
+		&testcbntibbcoversfit,
+		&testcbntacmsvn,
+		&testcbntbpmelementsvalid,
+		&testcbntbtgsacminfo,
+		&testcbntpbetstopped,
+	}
+)
+
+// msrReader reads MSR registers through hwapi.LowLevelHardwareInterfaces.
+type msrReader struct {
+	txtAPI hwapi.LowLevelHardwareInterfaces
+}
+
+// Read implements registers.MSRReader. It returns an error if the value
+// differs between cores.
+func (r msrReader) Read(msr int64) (uint64, error) {
+	values := r.txtAPI.ReadMSR(msr)
+	if len(values) == 0 {
+		return 0, fmt.Errorf("unable to read MSR 0x%X", msr)
+	}
+	for _, value := range values[1:] {
+		if value != values[0] {
+			return 0, fmt.Errorf("MSR 0x%X differs between cores", msr)
+		}
+	}
+	return values[0], nil
+}
+
+// CBnTFirmwareAccessible reads and parses the firmware image: either the file
+// given in the configuration or the BIOS region mapped below 4GiB.
+func CBnTFirmwareAccessible(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+	var err error
+	if config.FirmwareImage != "" {
+		cbntFirmware, err = uefi.ParseUEFIFirmwareFile(config.FirmwareImage)
+		if err != nil {
+			return false, nil, err
+		}
+	} else {
+		image := make([]byte, FITSize)
+		if err := txtAPI.ReadPhysBuf(FourGiB-FITSize, image); err != nil {
+			return false, nil, fmt.Errorf("unable to read the BIOS region: %w", err)
+		}
+		cbntFirmware, err = uefi.ParseUEFIFirmwareBytes(image)
+		if err != nil {
+			return false, fmt.Errorf("unable to parse the BIOS region: %w", err), nil
+		}
+	}
+
+	cbntFITEntries, err = cbntFirmware.GetFIT()
+	if err != nil {
+		return false, fmt.Errorf("unable to parse FIT: %w", err), nil
+	}
+	return true, nil, nil
+}
+
+// CBnTHasKM checks if FIT has a valid Key Manifest entry
+func CBnTHasKM(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+	for _, entry := range cbntFITEntries {
+		if entry, ok := entry.(*fit.EntryKeyManifestRecord); ok {
+			km, err := entry.ParseData()
+			if err != nil {
+				return false, fmt.Errorf("unable to parse Key Manifest: %w", err), nil
+			}
+			cbntKM = km
+			return true, nil, nil
+		}
+	}
+	return false, fmt.Errorf("FIT has no Key Manifest entry"), nil
+}
+
+// CBnTHasBPM checks if FIT has a valid Boot Policy Manifest entry
+func CBnTHasBPM(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+	for _, entry := range cbntFITEntries {
+		if entry, ok := entry.(*fit.EntryBootPolicyManifestRecord); ok {
+			bpm, err := entry.ParseData()
+			if err != nil {
+				return false, fmt.Errorf("unable to parse Boot Policy Manifest: %w", err), nil
+			}
+			if len(bpm.SE) == 0 {
+				return false, fmt.Errorf("Boot Policy Manifest has no IBB element"), nil
+			}
+			cbntBPM = bpm
+			return true, nil, nil
+		}
+	}
+	return false, fmt.Errorf("FIT has no Boot Policy Manifest entry"), nil
+}
+
+// CBnTManifestsValid checks the signatures of KM and BPM, if the BPM key is
+// authorized by KM and if the IBB digest in BPM is correct
+func CBnTManifestsValid(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+	if err := (pcr.ValidateManifests{}).Validate(cbntFirmware); err != nil {
+		return false, err, nil
+	}
+	return true, nil, nil
+}
+
+// kmPublicKeyHashes returns the possible values of the KM public key hash
+// (the value fused into FPF): the hash of modulus and exponent, and the hash
+// of modulus only (Skylake and Kabylake).
+func kmPublicKeyHashes(km *key.Manifest) ([][]byte, error) {
+	k := km.KeyAndSignature.Key
+	if k.KeyAlg != manifest.AlgRSA {
+		return nil, fmt.Errorf("KM key algorithm %v is not supported", k.KeyAlg)
+	}
+	if len(k.Data) <= 4 {
+		return nil, fmt.Errorf("KM public key is not set")
+	}
+	h, err := km.PubKeyHashAlg.Hash()
+	if err != nil {
+		return nil, fmt.Errorf("invalid KM public key hash algorithm %v: %w", km.PubKeyHashAlg, err)
+	}
+
+	var hashes [][]byte
+	for _, data := range [][]byte{append(append([]byte{}, k.Data[4:]...), k.Data[:4]...), k.Data[4:]} {
+		h.Reset()
+		h.Write(data)
+		hashes = append(hashes, h.Sum(nil))
+	}
+	return hashes, nil
+}
+
+// CBnTKMHashProvisioned checks if the hash of KM public key is provisioned
+// in the FPF configuration stored in ME region.
+//
+// This is a heuristic: the FPF configuration is stored in the ME file system
+// in an undocumented format, so the ME region is only searched for the hash
+// bytes. It neither checks which field contains the hash nor if the FPFs
+// are already fused.
+func CBnTKMHashProvisioned(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+	image := cbntFirmware.Buf()
+	meOffset, meSize, err := tools.GetRegion(image, fianoUEFI.RegionTypeME)
+	if err != nil {
+		return false, nil, fmt.Errorf("ME region is not accessible (a full SPI flash image is required): %w", err)
+	}
+	me := image[meOffset : meOffset+meSize]
+
+	hashes, err := kmPublicKeyHashes(cbntKM)
+	if err != nil {
+		return false, nil, err
+	}
+	for _, hash := range hashes {
+		if bytes.Contains(me, hash) {
+			return true, nil, nil
+		}
+	}
+	return false, fmt.Errorf("KM public key hash %X is not found in ME region (searched heuristically)", hashes[0]), nil
+}
+
+// ibbCovers returns true if hashed IBB segments cover the whole range
+// [physAddr, physAddr+length).
+func ibbCovers(bpm *bootpolicy.Manifest, imageSize uint64, physAddr uint64, length uint64) bool {
+	ranges := bpm.IBBDataRanges(imageSize)
+	ranges.SortAndMerge()
+	r := pkgbytes.Range{
+		Offset: fit.CalculateOffsetFromPhysAddr(physAddr, imageSize),
+		Length: length,
+	}
+	for _, ibbRange := range ranges {
+		if ibbRange.Offset <= r.Offset && ibbRange.End() >= r.End() {
+			return true
+		}
+	}
+	return false
+}
+
+// CBnTIBBCoversResetVector checks if IBB segments cover the reset vector
+func CBnTIBBCoversResetVector(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+	if !ibbCovers(cbntBPM, uint64(len(cbntFirmware.Buf())), ResetVector, 4) {
+		return false, fmt.Errorf("IBB segments must cover Reset Vector"), nil
+	}
+	return true, nil, nil
+}
+
+// CBnTIBBCoversFIT checks if IBB segments cover FIT and FIT vector
+func CBnTIBBCoversFIT(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+	image := cbntFirmware.Buf()
+	imageSize := uint64(len(image))
+	if !ibbCovers(cbntBPM, imageSize, FITVector, 4) {
+		return false, fmt.Errorf("IBB segments must cover Firmware Interface Table Vector"), nil
+	}
+	startIdx, endIdx, err := fit.GetHeadersTableRangeFrom(bytes.NewReader(image))
+	if err != nil {
+		return false, nil, err
+	}
+	if !ibbCovers(cbntBPM, imageSize, fit.CalculatePhysAddrFromOffset(startIdx, imageSize), endIdx-startIdx) {
+		return false, fmt.Errorf("IBB segments must cover Firmware Interface Table"), nil
+	}
+	return true, nil, nil
+}
+
+// CBnTACMSVNValid checks if the SVN of BIOS ACM is not below the minimal
+// ACM SVN allowed by BPM
+func CBnTACMSVNValid(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+	for _, entry := range cbntFITEntries {
+		if entry, ok := entry.(*fit.EntrySACM); ok {
+			acm, err := entry.ParseData()
+			if err != nil {
+				return false, fmt.Errorf("unable to parse BIOS ACM: %w", err), nil
+			}
+			acmSVN := uint16(acm.GetTXTSVN())
+			if acmSVN < uint16(cbntBPM.BPMH.ACMSVNAuth.SVN()) {
+				return false, fmt.Errorf("BIOS ACM SVN %d is below ACMSVN %d required by BPM", acmSVN, cbntBPM.BPMH.ACMSVNAuth.SVN()), nil
+			}
+			return true, nil, nil
+		}
+	}
+	return false, fmt.Errorf("no BIOS ACM in FIT"), nil
+}
+
+// CBnTBPMElementsValid checks if PCD, PM and TXT elements of BPM are sane
+func CBnTBPMElementsValid(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+	if err := cbntBPM.Validate(); err != nil {
+		return false, fmt.Errorf("BPM is not valid: %w", err), nil
+	}
+	if pcd := cbntBPM.PCDE; pcd != nil {
+		if err := pcd.Validate(); err != nil {
+			return false, fmt.Errorf("PCD element is not valid: %w", err), nil
+		}
+		if len(pcd.Data) == 0 {
+			return false, fmt.Errorf("PCD element has no data"), nil
+		}
+	}
+	if pm := cbntBPM.PME; pm != nil {
+		if err := pm.Validate(); err != nil {
+			return false, fmt.Errorf("PM element is not valid: %w", err), nil
+		}
+		if len(pm.Data) == 0 {
+			return false, fmt.Errorf("PM element has no data"), nil
+		}
+	}
+	if txt := cbntBPM.TXTE; txt != nil {
+		if err := txt.Validate(); err != nil {
+			return false, fmt.Errorf("TXT element is not valid: %w", err), nil
+		}
+		for _, digest := range txt.DigestList.List {
+			h, err := digest.HashAlg.Hash()
+			if err != nil {
+				return false, fmt.Errorf("TXT element has a digest of invalid algorithm %v", digest.HashAlg), nil
+			}
+			if len(digest.HashBuffer) != h.Size() {
+				return false, fmt.Errorf("TXT element has a %v digest of invalid size %d", digest.HashAlg, len(digest.HashBuffer)), nil
+			}
+		}
+	}
+	return true, nil, nil
+}
+
+// CBnTBtGSACMInfoValid checks if BTG_SACM_INFO MSR reports that Boot Guard
+// is supported and the IBB was verified or measured
+func CBnTBtGSACMInfoValid(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+	info, err := registers.ReadBTGSACMInfo(msrReader{txtAPI: txtAPI})
+	if err != nil {
+		return false, nil, err
+	}
+	if !info.BootGuardCapability() {
+		return false, fmt.Errorf("Boot Guard is not supported by the platform"), nil
+	}
+	if !info.Verified() && !info.Measured() {
+		return false, fmt.Errorf("neither verified nor measured boot is enabled"), nil
+	}
+	return true, nil, nil
+}
+
+// CBnTPBETStopped checks if BOOT_GUARD_PBEC MSR reports that the firmware
+// stopped the Protect BIOS Environment timer
+func CBnTPBETStopped(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+	pbec, err := registers.ReadBootGuardPBEC(msrReader{txtAPI: txtAPI})
+	if err != nil {
+		return false, nil, err
+	}
+	if !pbec.StopPBET() {
+		return false, fmt.Errorf("PBE timer is not stopped by the firmware"), nil
+	}
+	return true, nil, nil
+}
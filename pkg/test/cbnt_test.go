@@ -0,0 +1,44 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/tools"
+	"github.com/9elements/converged-security-suite/v2/testdata/firmware"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCBnTFirmwareTests(t *testing.T) {
+	f, err := ioutil.TempFile("", "cbnt-firmware")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.Write(firmware.FakeIntelFirmware)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	config := &tools.Configuration{FirmwareImage: f.Name()}
+
+	for _, tc := range []struct {
+		test   *Test
+		result Result
+	}{
+		{&testcbntfirmwareaccessible, ResultPass},
+		{&testcbnthaskm, ResultPass},
+		{&testcbnthasbpm, ResultPass},
+		{&testcbntmanifestsvalid, ResultPass},
+		{&testcbntacmsvn, ResultPass},
+		{&testcbntbpmelementsvalid, ResultPass},
+		// The fake firmware has the IBB in the middle of the image
+		{&testcbntibbcoversresetvector, ResultFail},
+		{&testcbntibbcoversfit, ResultFail},
+		// The fake firmware has no flash descriptor and ME region
+		{&testcbntkmhashprovisioned, ResultInternalError},
+	} {
+		t.Run(tc.test.Name, func(t *testing.T) {
+			tc.test.Run(nil, config)
+			require.Equal(t, tc.result, tc.test.Result, tc.test.ErrorText)
+		})
+	}
+}
@@ -172,13 +172,30 @@ func WeybridgeOrLater(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Con
 
 // CPUSupportsTXT Check if the CPU supports TXT
 func CPUSupportsTXT(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
-	if hwInternal.CPUWhitelistTXTSupport(txtAPI) {
-		return true, nil, nil
+	db, err := cpuDatabase(config)
+	if err != nil {
+		return false, nil, err
+	}
+	cpu, err := db.LookupCPU(txtAPI)
+	if err != nil {
+		return false, err, nil
 	}
-	if hwInternal.CPUBlacklistTXTSupport(txtAPI) {
-		return false, fmt.Errorf("CPU does not support TXT - on blacklist"), nil
+	if !cpu.Capabilities.TXT {
+		return false, fmt.Errorf("CPU (%s) does not support TXT", cpu.Name), nil
+	}
+	// TXT is fused off on non-vPro SKUs, which then don't support SMX either
+	if cpu.Capabilities.SMX && !txtAPI.HasSMX() {
+		return false, fmt.Errorf("TXT is disabled on this SKU of %s", cpu.Name), nil
+	}
+	return true, nil, nil
+}
+
+// cpuDatabase returns the CPU database set in the configuration or the embedded one
+func cpuDatabase(config *tools.Configuration) (*hwInternal.CPUDatabase, error) {
+	if config == nil || config.CPUDatabase == "" {
+		return hwInternal.DefaultCPUDatabase(), nil
 	}
-	return false, fmt.Errorf("CPU is not listed in any Intel CPU list"), nil
+	return hwInternal.LoadCPUDatabase(config.CPUDatabase)
 }
 
 // TXTRegisterSpaceAccessible Check if the TXT register space is accessible
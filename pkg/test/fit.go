@@ -165,6 +165,16 @@ var (
 		SpecificiationTitle:     IntelTXTSpecificationTitle,
 		SpecificationDocumentID: IntelTXTSpecificationDocumentID,
 	}
+	testbiosacmsignaturevalid = Test{
+		Name:                    "BIOSACM signature valid",
+		Required:                true,
+		function:                BIOSACMSignatureValid,
+		dependencies:            []*Test{&testhasfit, &testhasbiosacm, &testbiosacmvalid},
+		Status:                  Implemented,
+		SpecificationChapter:    "A.1 Authenticated Code Module Format",
+		SpecificiationTitle:     IntelTXTSpecificationTitle,
+		SpecificationDocumentID: IntelTXTSpecificationDocumentID,
+	}
 	testbiosacmsizecorrect = Test{
 		Name:                    "BIOSACM size check",
 		Required:                true,
@@ -248,6 +258,7 @@ var (
 		&testbiosacmmatchescpu,
 		&testacmsfornpw,
 		&testsinitacmupporttpm,
+		&testbiosacmsignaturevalid,
 	}
 )
 
@@ -511,6 +522,26 @@ func BIOSACMValid(txtAPI hwapi.LowLevelHardwareInterfaces, _ *tools.Configuratio
 	return acm != nil, err, internalerr
 }
 
+// BIOSACMSignatureValid checks if BIOS ACM is signed by the key embedded into its header
+func BIOSACMSignatureValid(txtAPI hwapi.LowLevelHardwareInterfaces, _ *tools.Configuration) (bool, error, error) {
+	data, err, internalerr := biosACMData(txtAPI, fitHeaders)
+	if internalerr != nil {
+		return false, nil, internalerr
+	}
+	if err != nil {
+		return false, err, nil
+	}
+
+	sig, err := tools.VerifyACM(data)
+	if err != nil {
+		if sig != nil {
+			return false, fmt.Errorf("BIOS ACM (key hash %x): %w", sig.PubKeyHash, err), nil
+		}
+		return false, err, nil
+	}
+	return true, nil, nil
+}
+
 // BIOSACMSizeCorrect checks if BIOS ACM size is correct
 func BIOSACMSizeCorrect(txtAPI hwapi.LowLevelHardwareInterfaces, _ *tools.Configuration) (bool, error, error) {
 	acm, _, _, _, err, internalerr := biosACM(txtAPI, fitHeaders)
@@ -614,21 +645,23 @@ func BIOSACMMatchesCPU(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Co
 		return false, nil, err
 	}
 
-	fms := txtAPI.CPUSignature()
-
-	for _, cpu := range cpus.IDList {
-		a := fms&cpu.FMSMask == cpu.FMS
-		b := platform&cpu.PlatformMask == cpu.PlatformID
-
-		if a && b {
-			return true, nil, nil
-		}
+	if cpus.Supports(txtAPI.CPUSignature(), platform) {
+		return true, nil, nil
 	}
 
 	return false, fmt.Errorf("BIOS Startup Module and CPU doesn't match"), nil
 }
 
 func biosACM(txtAPI hwapi.LowLevelHardwareInterfaces, fitHeaders fit.Table) (*tools.ACM, *tools.Chipsets, *tools.Processors, *tools.TPMs, error, error) {
+	buf, err, internalerr := biosACMData(txtAPI, fitHeaders)
+	if err != nil || internalerr != nil {
+		return nil, nil, nil, nil, err, internalerr
+	}
+	return tools.ParseACM(buf)
+}
+
+// biosACMData reads the BIOS ACM referenced by FIT after validating its header
+func biosACMData(txtAPI hwapi.LowLevelHardwareInterfaces, fitHeaders fit.Table) ([]byte, error, error) {
 	for _, hdr := range fitHeaders {
 		if hdr.Type() == fit.EntryTypeStartupACModuleEntry {
 			buf1 := make([]byte, tools.ACMheaderLen*4)
@@ -636,32 +669,32 @@ func biosACM(txtAPI hwapi.LowLevelHardwareInterfaces, fitHeaders fit.Table) (*to
 			err := txtAPI.ReadPhysBuf(int64(hdr.Address), buf1)
 
 			if err != nil {
-				return nil, nil, nil, nil, nil, fmt.Errorf("ReadPhysBuf failed at %v with error: %v", hdr.Address, err)
+				return nil, nil, fmt.Errorf("ReadPhysBuf failed at %v with error: %v", hdr.Address, err)
 			}
 
 			acm, err := tools.ParseACMHeader(buf1)
 			if err != nil {
-				return nil, nil, nil, nil, fmt.Errorf("cannot Parse BIOS ACM header correctly"), nil
+				return nil, fmt.Errorf("cannot Parse BIOS ACM header correctly"), nil
 			}
 
 			ret, err := tools.ValidateACMHeader(acm)
 
 			if !ret {
-				return nil, nil, nil, nil, fmt.Errorf("validating BIOS ACM Header failed: %v", err), nil
+				return nil, fmt.Errorf("validating BIOS ACM Header failed: %v", err), nil
 			}
 
 			buf2 := make([]byte, acm.Size*4)
 			err = txtAPI.ReadPhysBuf(int64(hdr.Address), buf2)
 
 			if err != nil {
-				return nil, nil, nil, nil, nil, fmt.Errorf("cannot read BIOS ACM completly")
+				return nil, nil, fmt.Errorf("cannot read BIOS ACM completly")
 			}
 
-			return tools.ParseACM(buf2)
+			return buf2, nil, nil
 		}
 	}
 
-	return nil, nil, nil, nil, fmt.Errorf("no BIOS ACM in FIT"), nil
+	return nil, fmt.Errorf("no BIOS ACM in FIT"), nil
 }
 
 // SINITandBIOSACMnoNPW checks that in BIOS integrated ACMs (SINIT, BIOS) are production worthy
@@ -156,6 +156,16 @@ var (
 		SpecificiationTitle:     IntelTXTSpecificationTitle,
 		SpecificationDocumentID: IntelTXTSpecificationDocumentID,
 	}
+	testtxtheapvalid = Test{
+		Name:                    "TXT heap data regions valid",
+		Required:                true,
+		function:                TXTHeapDataRegionsValid,
+		dependencies:            []*Test{&testbiosdataregionpresent},
+		Status:                  Implemented,
+		SpecificationChapter:    "Appendix C Intel TXT Heap Memory",
+		SpecificiationTitle:     IntelTXTSpecificationTitle,
+		SpecificationDocumentID: IntelTXTSpecificationDocumentID,
+	}
 	testhasmtrr = Test{
 		Name:                    "CPU supports MTRRs",
 		Required:                true,
@@ -225,11 +235,16 @@ var (
 		&testactivesmrr,
 		&testactiveiommu,
 		&testservermodetext,
+		&testtxtheapvalid,
 	}
 )
 
 var (
 	biosdata tools.TXTBiosData
+	txtheap  *tools.TXTHeap
+	// txtheaperr is the error returned while parsing the regions after
+	// BiosData, which are only populated after a measured launch
+	txtheaperr error
 )
 
 //nolint
@@ -581,15 +596,8 @@ func SINITMatchesCPU(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Conf
 		return false, nil, err
 	}
 
-	fms := txtAPI.CPUSignature()
-
-	for _, cpu := range cpus.IDList {
-		a := fms&cpu.FMSMask == cpu.FMS
-		b := platform&cpu.PlatformMask == cpu.PlatformID
-
-		if a && b {
-			return true, nil, nil
-		}
+	if cpus.Supports(txtAPI.CPUSignature(), platform) {
+		return true, nil, nil
 	}
 
 	return false, fmt.Errorf("CPU signature not found in SINIT processor ID list"), nil
@@ -623,8 +631,7 @@ func BIOSDATAREGIONPresent(txtAPI hwapi.LowLevelHardwareInterfaces, config *tool
 		return false, nil, err
 	}
 
-	txtHeap := make([]byte, regs.HeapSize)
-	err = txtAPI.ReadPhysBuf(int64(regs.HeapBase), txtHeap)
+	txtHeap, err := tools.ReadTXTHeap(txtAPI, regs)
 	if err != nil {
 		return false, nil, err
 	}
@@ -633,6 +640,7 @@ func BIOSDATAREGIONPresent(txtAPI hwapi.LowLevelHardwareInterfaces, config *tool
 	if err != nil {
 		return false, nil, err
 	}
+	txtheap, txtheaperr = tools.ParseTXTHeap(txtHeap)
 
 	return true, nil, nil
 }
@@ -653,6 +661,27 @@ func BIOSDATAREGIONValid(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.
 	return true, nil, nil
 }
 
+// TXTHeapDataRegionsValid checks if the regions of the TXT heap are valid. The
+// regions after BiosData are checked only if a measured launch was performed.
+func TXTHeapDataRegionsValid(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+	buf, err := tools.FetchTXTRegs(txtAPI)
+	if err != nil {
+		return false, nil, err
+	}
+	regs, err := tools.ParseTXTRegs(buf)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if regs.Sts.SenterDone && txtheaperr != nil {
+		return false, txtheaperr, nil
+	}
+	if err := txtheap.Validate(regs.Sts.SenterDone); err != nil {
+		return false, err, nil
+	}
+	return true, nil, nil
+}
+
 // HasMTRR checks if MTRR is supported by CPU
 func HasMTRR(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
 	if !txtAPI.HasMTRR() {
@@ -0,0 +1,16 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONWriter stores the report as JSON
+type JSONWriter struct{}
+
+// Write implements Writer
+func (JSONWriter) Write(w io.Writer, r *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,135 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JUnitWriter stores the report as JUnit XML as understood by most CI systems
+type JUnitWriter struct{}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name       string          `xml:"name,attr"`
+	ClassName  string          `xml:"classname,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Failure    *junitMessage   `xml:"failure,omitempty"`
+	Error      *junitMessage   `xml:"error,omitempty"`
+	Skipped    *junitMessage   `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// Write implements Writer
+func (JUnitWriter) Write(w io.Writer, r *Report) error {
+	suite := junitTestSuite{
+		Name: r.TestGroup,
+		Time: fmt.Sprintf("%.3f", r.Duration.Seconds()),
+		Properties: []junitProperty{
+			{Name: "tool", Value: r.Tool},
+			{Name: "version", Value: r.Version},
+		},
+	}
+	if !r.Started.IsZero() {
+		suite.Timestamp = r.Started.Format("2006-01-02T15:04:05")
+	}
+	for _, t := range r.Tests {
+		tc := junitTestCase{
+			Name:      t.Name,
+			ClassName: r.Tool + "." + t.Spec,
+			Time:      fmt.Sprintf("%.3f", t.Duration.Seconds()),
+			Properties: []junitProperty{
+				{Name: "required", Value: fmt.Sprint(t.Required)},
+			},
+		}
+		if t.SpecificationTitle != "" {
+			tc.Properties = append(tc.Properties, junitProperty{Name: "specification", Value: t.SpecificationTitle})
+		}
+		if t.SpecificationDocumentID != "" {
+			tc.Properties = append(tc.Properties, junitProperty{Name: "document", Value: t.SpecificationDocumentID})
+		}
+		if t.SpecificationChapter != "" {
+			tc.Properties = append(tc.Properties, junitProperty{Name: "chapter", Value: t.SpecificationChapter})
+		}
+
+		text := strings.TrimSpace(t.ErrorText + "\n" + t.ErrorTextSpec)
+		switch {
+		case !t.Ran():
+			tc.Skipped = &junitMessage{Message: "test was not run"}
+		case t.ErrorKind == ErrorKindTest:
+			tc.Failure = &junitMessage{Message: t.ErrorText, Type: t.Result, Text: text}
+		case t.ErrorKind == ErrorKindInternal:
+			tc.Error = &junitMessage{Message: t.ErrorText, Type: t.Result, Text: text}
+		case t.ErrorKind == ErrorKindDependency:
+			tc.Skipped = &junitMessage{
+				Message: t.ErrorText,
+				Text:    "dependency chain: " + strings.Join(t.DependencyChain, " -> "),
+			}
+		}
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		if tc.Error != nil {
+			suite.Errors++
+		}
+		if tc.Skipped != nil {
+			suite.Skipped++
+		}
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	suites := junitTestSuites{
+		Name:     r.Tool,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
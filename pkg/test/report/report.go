@@ -0,0 +1,208 @@
+// Package report contains the model of a txt-suite test report and
+// writers to store it in different formats.
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/test"
+)
+
+// ErrorKind describes why a test did not pass
+type ErrorKind string
+
+const (
+	// ErrorKindNone means the test passed or was not run
+	ErrorKindNone ErrorKind = ""
+	// ErrorKindTest means the platform does not fulfill the test
+	ErrorKindTest ErrorKind = "test"
+	// ErrorKindInternal means the test could not determine a result
+	ErrorKindInternal ErrorKind = "internal"
+	// ErrorKindDependency means a dependency of the test did not pass
+	ErrorKindDependency ErrorKind = "dependency"
+)
+
+// Report is the result of a test run
+type Report struct {
+	Tool      string
+	Version   string
+	TestGroup string
+	Started   time.Time
+	Duration  time.Duration
+	Tests     []TestResult
+}
+
+// TestResult is the result of a single test
+type TestResult struct {
+	// ID is the ID of the test within the suite (see "txt-suite list")
+	ID                      int
+	Name                    string
+	Required                bool
+	Result                  string
+	Status                  string
+	Spec                    string
+	ErrorKind               ErrorKind `json:",omitempty"`
+	ErrorText               string    `json:",omitempty"`
+	ErrorTextSpec           string    `json:",omitempty"`
+	SpecificationTitle      string    `json:",omitempty"`
+	SpecificationDocumentID string    `json:",omitempty"`
+	SpecificationChapter    string    `json:",omitempty"`
+	// Dependencies are the names of the tests this test depends on
+	Dependencies []string `json:",omitempty"`
+	// DependencyChain is the chain of failed dependencies down to the
+	// test which caused this test not to be run.
+	DependencyChain []string `json:",omitempty"`
+	Duration        time.Duration
+}
+
+// Passed returns true if the test passed
+func (r TestResult) Passed() bool {
+	return r.Result == test.ResultPass.String()
+}
+
+// Ran returns true if the test was run
+func (r TestResult) Ran() bool {
+	return r.Result != test.ResultNotRun.String()
+}
+
+// New returns a report with the results of the tests run by a
+// test.Scheduler. Tests which are not implemented are omitted.
+//
+// testIDs maps the tests to their IDs within the suite. If it is nil, then
+// the index of the test within the results is used as the ID.
+func New(testGroup string, results *test.Results, testIDs map[*test.Test]int) *Report {
+	r := &Report{
+		TestGroup: testGroup,
+	}
+	for idx, result := range results.All() {
+		t := result.Test
+		if t.Status == test.NotImplemented {
+			continue
+		}
+		id := idx
+		if testIDs != nil {
+			id = testIDs[t]
+		}
+		testResult := TestResult{
+			ID:                      id,
+			Name:                    t.Name,
+			Required:                t.Required,
+			Result:                  result.Result.String(),
+			Status:                  t.Status.String(),
+			Spec:                    t.Spec.String(),
+			ErrorKind:               errorKind(result.Result),
+			ErrorText:               result.ErrorText,
+			ErrorTextSpec:           result.ErrorTextSpec,
+			SpecificationTitle:      t.SpecificiationTitle,
+			SpecificationDocumentID: t.SpecificationDocumentID,
+			SpecificationChapter:    t.SpecificationChapter,
+			DependencyChain:         dependencyChain(t, results),
+			Duration:                result.Duration,
+		}
+		for _, dep := range t.Dependencies() {
+			testResult.Dependencies = append(testResult.Dependencies, dep.Name)
+		}
+		r.Tests = append(r.Tests, testResult)
+	}
+	return r
+}
+
+func errorKind(result test.Result) ErrorKind {
+	switch result {
+	case test.ResultFail:
+		return ErrorKindTest
+	case test.ResultInternalError:
+		return ErrorKindInternal
+	case test.ResultDependencyFailed:
+		return ErrorKindDependency
+	}
+	return ErrorKindNone
+}
+
+// dependencyChain follows the first failed dependency of each test
+// until a test is reached which failed by itself.
+func dependencyChain(t *test.Test, results *test.Results) []string {
+	var chain []string
+	visited := map[*test.Test]bool{t: true}
+	for results.Get(t).Result == test.ResultDependencyFailed {
+		var failed *test.Test
+		for _, dep := range t.Dependencies() {
+			if dep.Status != test.NotImplemented && results.Get(dep).Result != test.ResultPass && !visited[dep] {
+				failed = dep
+				break
+			}
+		}
+		if failed == nil {
+			break
+		}
+		chain = append(chain, failed.Name)
+		visited[failed] = true
+		t = failed
+	}
+	return chain
+}
+
+// Exit codes of a test run, see ExitCode
+const (
+	ExitCodePass          = 0
+	ExitCodeFail          = 1
+	ExitCodeInternalError = 2
+)
+
+// ExitCode returns the exit code of a test run depending on the results
+// of the required tests. Tests which were not run are ignored.
+//
+//	ExitCodePass: all required tests passed
+//	ExitCodeFail: at least one required test failed or its dependencies failed
+//	ExitCodeInternalError: no required test failed, but at least one
+//	                       could not be completed due to an internal error
+func (r *Report) ExitCode() int {
+	code := ExitCodePass
+	for _, t := range r.Tests {
+		if !t.Required || !t.Ran() {
+			continue
+		}
+		switch t.ErrorKind {
+		case ErrorKindTest, ErrorKindDependency:
+			return ExitCodeFail
+		case ErrorKindInternal:
+			code = ExitCodeInternalError
+		}
+	}
+	return code
+}
+
+// Writer stores a report in a specific format
+type Writer interface {
+	Write(w io.Writer, r *Report) error
+}
+
+// Writers contains all supported report formats
+var Writers = map[string]Writer{
+	"json":  JSONWriter{},
+	"junit": JUnitWriter{},
+	"sarif": SARIFWriter{},
+}
+
+// Formats returns the names of all supported report formats
+func Formats() []string {
+	var formats []string
+	for format := range Writers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// GetWriter returns the writer for the given report format
+func GetWriter(format string) (Writer, error) {
+	w, ok := Writers[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown report format '%s', supported formats: %s", format, strings.Join(Formats(), ", "))
+	}
+	return w, nil
+}
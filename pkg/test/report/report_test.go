@@ -0,0 +1,118 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/hwapi"
+	"github.com/9elements/converged-security-suite/v2/pkg/test"
+	"github.com/9elements/converged-security-suite/v2/pkg/tools"
+	"github.com/stretchr/testify/require"
+)
+
+func uefiReport(t *testing.T) *Report {
+	s, err := test.NewScheduler(test.TestsUEFI)
+	require.NoError(t, err)
+	results := s.Run(hwapi.GetPcMock(hwapi.MockPCReadMemory), &tools.Configuration{})
+	testIDs := map[*test.Test]int{}
+	for idx, tst := range test.TestsUEFI {
+		testIDs[tst] = 100 + idx
+	}
+	r := New("UEFI", results, testIDs)
+	r.Tool = "txt-suite"
+	r.Version = "test"
+	r.Started = time.Now()
+	return r
+}
+
+func TestReport(t *testing.T) {
+	r := uefiReport(t)
+	implemented := 0
+	for _, tst := range test.TestsUEFI {
+		if tst.Status != test.NotImplemented {
+			implemented++
+		}
+	}
+	require.Len(t, r.Tests, implemented)
+	for _, tst := range r.Tests {
+		require.Equal(t, test.TestsUEFI[tst.ID-100].Name, tst.Name)
+	}
+
+	var rsdp, dmar TestResult
+	for _, tst := range r.Tests {
+		switch tst.Name {
+		case "ACPI RSDP exists and has valid checksum":
+			rsdp = tst
+		case "ACPI DMAR is valid":
+			dmar = tst
+		}
+	}
+	// The mock has no ACPI tables at all
+	require.Equal(t, ErrorKindInternal, rsdp.ErrorKind)
+	require.Equal(t, ErrorKindDependency, dmar.ErrorKind)
+	require.NotEmpty(t, dmar.Dependencies)
+	require.NotEmpty(t, dmar.DependencyChain)
+	require.Equal(t, ExitCodeFail, r.ExitCode())
+}
+
+func TestExitCode(t *testing.T) {
+	r := &Report{Tests: []TestResult{
+		{Required: true, Result: test.ResultPass.String()},
+		{Required: false, Result: test.ResultFail.String(), ErrorKind: ErrorKindTest},
+		{Required: true, Result: test.ResultNotRun.String()},
+	}}
+	require.Equal(t, ExitCodePass, r.ExitCode())
+
+	r.Tests = append(r.Tests, TestResult{Required: true, Result: test.ResultInternalError.String(), ErrorKind: ErrorKindInternal})
+	require.Equal(t, ExitCodeInternalError, r.ExitCode())
+
+	r.Tests = append(r.Tests, TestResult{Required: true, Result: test.ResultDependencyFailed.String(), ErrorKind: ErrorKindDependency})
+	require.Equal(t, ExitCodeFail, r.ExitCode())
+}
+
+func TestWriters(t *testing.T) {
+	r := uefiReport(t)
+
+	for _, format := range Formats() {
+		t.Run(format, func(t *testing.T) {
+			w, err := GetWriter(format)
+			require.NoError(t, err)
+			var buf bytes.Buffer
+			require.NoError(t, w.Write(&buf, r))
+
+			switch format {
+			case "json":
+				var decoded Report
+				require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+				require.Equal(t, r.Tests, decoded.Tests)
+			case "junit":
+				var decoded junitTestSuites
+				require.NoError(t, xml.Unmarshal(buf.Bytes(), &decoded))
+				require.Len(t, decoded.Suites, 1)
+				require.Len(t, decoded.Suites[0].TestCases, len(r.Tests))
+				require.NotZero(t, decoded.Errors)
+				require.NotZero(t, decoded.Skipped)
+			case "sarif":
+				var decoded sarifLog
+				require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+				require.Equal(t, sarifVersion, decoded.Version)
+				require.Len(t, decoded.Runs[0].Tool.Driver.Rules, len(r.Tests))
+				require.Len(t, decoded.Runs[0].Results, len(r.Tests))
+				for _, result := range decoded.Runs[0].Results {
+					require.Equal(t, "fail", result.Kind)
+				}
+			}
+		})
+	}
+
+	_, err := GetWriter("yaml")
+	require.Error(t, err)
+}
+
+func TestRuleID(t *testing.T) {
+	require.Equal(t, "acpi-rsdt-or-xsdt-is-valid", ruleID("ACPI RSDT or XSDT is valid"))
+	require.Equal(t, "sinit-acm-supports-used-tpm-2-0", ruleID("SINIT ACM supports used TPM (2.0)"))
+}
@@ -0,0 +1,154 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"unicode"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// SARIFWriter stores the report in the Static Analysis Results Interchange
+// Format 2.1.0. Every test is a rule, every test which was run is a result.
+type SARIFWriter struct{}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string                 `json:"id"`
+	Name             string                 `json:"name"`
+	ShortDescription sarifMessage           `json:"shortDescription"`
+	FullDescription  *sarifMessage          `json:"fullDescription,omitempty"`
+	Properties       map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	RuleIndex  int                    `json:"ruleIndex"`
+	Kind       string                 `json:"kind"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// ruleID returns a stable identifier of a test derived from its name
+func ruleID(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// Write implements Writer
+func (SARIFWriter) Write(w io.Writer, r *Report) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           r.Tool,
+			Version:        r.Version,
+			InformationURI: "https://github.com/9elements/converged-security-suite",
+		}},
+		Results: []sarifResult{},
+	}
+	for _, t := range r.Tests {
+		rule := sarifRule{
+			ID:               ruleID(t.Name),
+			Name:             t.Name,
+			ShortDescription: sarifMessage{Text: t.Name},
+			Properties: map[string]interface{}{
+				"required": t.Required,
+				"spec":     t.Spec,
+			},
+		}
+		var spec []string
+		if t.SpecificationTitle != "" {
+			spec = append(spec, t.SpecificationTitle)
+		}
+		if t.SpecificationDocumentID != "" {
+			spec = append(spec, "document ID "+t.SpecificationDocumentID)
+		}
+		if t.SpecificationChapter != "" {
+			spec = append(spec, "chapter "+t.SpecificationChapter)
+		}
+		if len(spec) > 0 {
+			rule.FullDescription = &sarifMessage{Text: strings.Join(spec, ", ")}
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+
+		if !t.Ran() {
+			continue
+		}
+		result := sarifResult{
+			RuleID:    rule.ID,
+			RuleIndex: len(run.Tool.Driver.Rules) - 1,
+			Kind:      "pass",
+			Level:     "none",
+			Message:   sarifMessage{Text: t.Name + ": " + t.Result},
+			Properties: map[string]interface{}{
+				"result":   t.Result,
+				"duration": t.Duration.Seconds(),
+			},
+		}
+		if !t.Passed() {
+			result.Kind = "fail"
+			result.Level = "warning"
+			if t.Required {
+				result.Level = "error"
+			}
+			if t.ErrorText != "" {
+				result.Message.Text += " (" + t.ErrorText + ")"
+			}
+			if t.ErrorTextSpec != "" {
+				result.Message.Text += ". " + t.ErrorTextSpec
+			}
+			result.Properties["errorKind"] = t.ErrorKind
+			if len(t.DependencyChain) > 0 {
+				result.Properties["dependencyChain"] = t.DependencyChain
+			}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
@@ -0,0 +1,275 @@
+package test
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/tools"
+	"github.com/9elements/go-linux-lowlevel-hw/pkg/hwapi"
+)
+
+// RunResult is the result of a test run by a Scheduler
+type RunResult struct {
+	Test          *Test
+	Result        Result
+	ErrorText     string
+	ErrorTextSpec string
+	Duration      time.Duration
+}
+
+// Results contains the results of a single run of a Scheduler
+type Results struct {
+	tests   []*Test
+	results map[*Test]RunResult
+}
+
+// Get returns the result of the test, tests which were not run
+// have the result ResultNotRun
+func (r *Results) Get(t *Test) RunResult {
+	if result, ok := r.results[t]; ok {
+		return result
+	}
+	return RunResult{Test: t, Result: ResultNotRun}
+}
+
+// All returns the results of the selected tests in the order
+// they were given to NewScheduler
+func (r *Results) All() []RunResult {
+	all := make([]RunResult, 0, len(r.tests))
+	for _, t := range r.tests {
+		all = append(all, r.Get(t))
+	}
+	return all
+}
+
+// Passed returns true if all required tests passed, tests which are
+// not implemented are ignored
+func (r *Results) Passed() bool {
+	for _, t := range r.tests {
+		if t.Required && t.Status != NotImplemented && r.Get(t).Result != ResultPass {
+			return false
+		}
+	}
+	return true
+}
+
+// Scheduler runs tests in the order given by their dependencies. Tests
+// whose dependencies have passed are run concurrently. In contrast to
+// Test.Run the results are not stored in the tests, so a Scheduler could
+// be run any number of times.
+type Scheduler struct {
+	// Workers is the number of tests run concurrently, if it is below 1
+	// runtime.NumCPU() is used
+	Workers int
+	// StopOnFailure stops starting new tests as soon as
+	// a required test did not pass
+	StopOnFailure bool
+
+	tests []*Test
+	// order contains the selected tests and all of their dependencies
+	// in topological order
+	order []*Test
+}
+
+// runLock serializes the runs of all schedulers, as the tests share
+// package level state (e.g. the parsed FIT)
+var runLock sync.Mutex
+
+// exclusiveTags are the tags of tests which access a resource that
+// could not be used concurrently. Only one test per tag is run at a time.
+var exclusiveTags = []string{"tpm"}
+
+// NewScheduler returns a scheduler for the given tests. Dependencies
+// of the tests are run as well, but aren't part of Results.All. An error
+// is returned if the dependencies contain a cycle.
+func NewScheduler(tests []*Test) (*Scheduler, error) {
+	s := &Scheduler{tests: tests}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := map[*Test]int{}
+	var path []*Test
+	var visit func(t *Test) error
+	visit = func(t *Test) error {
+		switch state[t] {
+		case visited:
+			return nil
+		case visiting:
+			var names []string
+			for idx := range path {
+				if path[idx] == t {
+					for _, p := range path[idx:] {
+						names = append(names, p.Name)
+					}
+					break
+				}
+			}
+			return fmt.Errorf("dependency cycle: %s -> %s", strings.Join(names, " -> "), t.Name)
+		}
+		state[t] = visiting
+		path = append(path, t)
+		for _, dep := range t.dependencies {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[t] = visited
+		s.order = append(s.order, t)
+		return nil
+	}
+	for _, t := range tests {
+		if err := visit(t); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Run runs the tests and returns their results
+func (s *Scheduler) Run(txtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) *Results {
+	runLock.Lock()
+	defer runLock.Unlock()
+	tagsOnce.Do(initTags)
+
+	workers := s.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	results := &Results{tests: s.tests, results: map[*Test]RunResult{}}
+	position := map[*Test]int{}
+	pendingDeps := map[*Test]int{}
+	dependents := map[*Test][]*Test{}
+	var ready []*Test
+	for idx, t := range s.order {
+		position[t] = idx
+		if t.Status == NotImplemented {
+			continue
+		}
+		for _, dep := range t.dependencies {
+			if dep.Status == NotImplemented {
+				continue
+			}
+			pendingDeps[t]++
+			dependents[dep] = append(dependents[dep], t)
+		}
+		if pendingDeps[t] == 0 {
+			ready = append(ready, t)
+		}
+	}
+
+	jobs := make(chan *Test)
+	done := make(chan RunResult)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range jobs {
+				start := time.Now()
+				result, errorText, errorTextSpec := t.evaluate(txtAPI, config)
+				done <- RunResult{
+					Test:          t,
+					Result:        result,
+					ErrorText:     errorText,
+					ErrorTextSpec: errorTextSpec,
+					Duration:      time.Since(start),
+				}
+			}
+		}()
+	}
+
+	busy := map[string]bool{}
+	running := 0
+	stopped := false
+	for {
+		// Tests with failed dependencies are finished without running them
+		for idx := 0; !stopped && idx < len(ready); idx++ {
+			t := ready[idx]
+			for _, dep := range t.dependencies {
+				if dep.Status == NotImplemented || results.results[dep].Result == ResultPass {
+					continue
+				}
+				ready = append(ready[:idx], ready[idx+1:]...)
+				idx--
+				result := RunResult{Test: t, Result: ResultDependencyFailed, ErrorText: dep.Name + " failed"}
+				ready, stopped = s.finish(results, result, dependents, pendingDeps, ready, stopped)
+				break
+			}
+		}
+
+		sort.Slice(ready, func(i, j int) bool {
+			return position[ready[i]] < position[ready[j]]
+		})
+		for idx := 0; !stopped && running < workers && idx < len(ready); {
+			t := ready[idx]
+			tags := exclusiveTagsOf(t)
+			if anyBusy(busy, tags) {
+				idx++
+				continue
+			}
+			for _, tag := range tags {
+				busy[tag] = true
+			}
+			ready = append(ready[:idx], ready[idx+1:]...)
+			running++
+			jobs <- t
+		}
+
+		if running == 0 {
+			break
+		}
+		result := <-done
+		running--
+		for _, tag := range exclusiveTagsOf(result.Test) {
+			busy[tag] = false
+		}
+		ready, stopped = s.finish(results, result, dependents, pendingDeps, ready, stopped)
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// finish stores the result of a test and returns the tests which are
+// ready to run afterwards
+func (s *Scheduler) finish(results *Results, result RunResult, dependents map[*Test][]*Test, pendingDeps map[*Test]int, ready []*Test, stopped bool) ([]*Test, bool) {
+	results.results[result.Test] = result
+	if s.StopOnFailure && result.Test.Required && result.Result != ResultPass {
+		stopped = true
+	}
+	for _, t := range dependents[result.Test] {
+		pendingDeps[t]--
+		if pendingDeps[t] == 0 {
+			ready = append(ready, t)
+		}
+	}
+	return ready, stopped
+}
+
+func exclusiveTagsOf(t *Test) []string {
+	var tags []string
+	for _, tag := range exclusiveTags {
+		if t.HasTag(tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func anyBusy(busy map[string]bool, tags []string) bool {
+	for _, tag := range tags {
+		if busy[tag] {
+			return true
+		}
+	}
+	return false
+}
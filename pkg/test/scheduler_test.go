@@ -0,0 +1,160 @@
+package test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/hwapi"
+	"github.com/9elements/converged-security-suite/v2/pkg/tools"
+	lowlevelhwapi "github.com/9elements/go-linux-lowlevel-hw/pkg/hwapi"
+	"github.com/stretchr/testify/require"
+)
+
+type testFunc = func(lowlevelhwapi.LowLevelHardwareInterfaces, *tools.Configuration) (bool, error, error)
+
+func passing(lowlevelhwapi.LowLevelHardwareInterfaces, *tools.Configuration) (bool, error, error) {
+	return true, nil, nil
+}
+
+func failing(lowlevelhwapi.LowLevelHardwareInterfaces, *tools.Configuration) (bool, error, error) {
+	return false, fmt.Errorf("failed"), nil
+}
+
+func newTest(name string, function testFunc, deps ...*Test) *Test {
+	return &Test{
+		Name:         name,
+		Required:     true,
+		function:     function,
+		dependencies: deps,
+		Status:       Implemented,
+	}
+}
+
+func TestSchedulerDependencies(t *testing.T) {
+	var (
+		lock  sync.Mutex
+		order []string
+	)
+	recording := func(name string, function testFunc) testFunc {
+		return func(api lowlevelhwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (bool, error, error) {
+			lock.Lock()
+			order = append(order, name)
+			lock.Unlock()
+			return function(api, config)
+		}
+	}
+
+	notImplemented := newTest("not implemented", failing)
+	notImplemented.Status = NotImplemented
+	a := newTest("a", recording("a", passing))
+	b := newTest("b", recording("b", failing), a)
+	c := newTest("c", recording("c", passing), b)
+	d := newTest("d", recording("d", passing), a, notImplemented)
+
+	s, err := NewScheduler([]*Test{d, c})
+	require.NoError(t, err)
+	s.Workers = 4
+
+	for i := 0; i < 2; i++ {
+		order = nil
+		results := s.Run(nil, &tools.Configuration{})
+
+		all := results.All()
+		require.Len(t, all, 2)
+		require.Equal(t, d, all[0].Test)
+		require.Equal(t, ResultPass, all[0].Result)
+		require.Equal(t, c, all[1].Test)
+		require.Equal(t, ResultDependencyFailed, all[1].Result)
+		require.Equal(t, "b failed", all[1].ErrorText)
+		require.Equal(t, ResultFail, results.Get(b).Result)
+		require.Equal(t, "failed", results.Get(b).ErrorText)
+		require.Equal(t, ResultNotRun, results.Get(notImplemented).Result)
+		require.False(t, results.Passed())
+
+		require.ElementsMatch(t, []string{"a", "b", "d"}, order)
+		require.Equal(t, "a", order[0])
+	}
+
+	// results are not stored in the tests
+	for _, tst := range []*Test{a, b, c, d} {
+		require.Equal(t, ResultNotRun, tst.Result)
+	}
+}
+
+func TestSchedulerConcurrency(t *testing.T) {
+	var started sync.WaitGroup
+	started.Add(2)
+	waitForOther := func(lowlevelhwapi.LowLevelHardwareInterfaces, *tools.Configuration) (bool, error, error) {
+		started.Done()
+		done := make(chan struct{})
+		go func() {
+			started.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return true, nil, nil
+		case <-time.After(5 * time.Second):
+			return false, fmt.Errorf("tests were not run concurrently"), nil
+		}
+	}
+
+	s, err := NewScheduler([]*Test{newTest("a", waitForOther), newTest("b", waitForOther)})
+	require.NoError(t, err)
+	s.Workers = 2
+	results := s.Run(nil, &tools.Configuration{})
+	require.True(t, results.Passed(), results.All())
+}
+
+func TestSchedulerStopOnFailure(t *testing.T) {
+	a := newTest("a", failing)
+	b := newTest("b", passing)
+
+	s, err := NewScheduler([]*Test{a, b})
+	require.NoError(t, err)
+	s.Workers = 1
+	s.StopOnFailure = true
+	results := s.Run(nil, &tools.Configuration{})
+	require.Equal(t, ResultFail, results.Get(a).Result)
+	require.Equal(t, ResultNotRun, results.Get(b).Result)
+}
+
+func TestSchedulerCycle(t *testing.T) {
+	a := newTest("a", passing)
+	b := newTest("b", passing, a)
+	c := newTest("c", passing, b)
+	a.dependencies = []*Test{c}
+
+	_, err := NewScheduler([]*Test{newTest("d", passing, a)})
+	require.EqualError(t, err, "dependency cycle: a -> c -> b -> a")
+}
+
+func TestSchedulerTestSuite(t *testing.T) {
+	api := hwapi.GetPcMock(hwapi.MockPCReadMemory)
+
+	s, err := NewScheduler(TestsUEFI)
+	require.NoError(t, err)
+	first := s.Run(api, &tools.Configuration{})
+	second := s.Run(api, &tools.Configuration{})
+	for idx, result := range first.All() {
+		require.Equal(t, result.Result, second.All()[idx].Result)
+		require.Equal(t, result.ErrorText, second.All()[idx].ErrorText)
+	}
+}
+
+func TestSelectTests(t *testing.T) {
+	tests, err := SelectTests(TestsCBnT, "tpm")
+	require.Error(t, err)
+	require.Nil(t, tests)
+
+	tests, err = SelectTests(TestsCBnT, "Key Manifest entry in FIT", "CPU")
+	require.NoError(t, err)
+	require.Equal(t, []*Test{&testcheckforintelcpu, &testcbnthaskm}, tests)
+
+	require.Contains(t, testtpmconnection.Tags(), "tpm")
+	require.Contains(t, testtpmconnection.Tags(), TagRequired)
+	require.True(t, testcbnthaskm.HasTag("CBnT"))
+	require.Contains(t, AllTags(TestsUEFI), "acpi")
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TagRequired is the tag of all required tests
+const TagRequired = "required"
+
+var (
+	tagsOnce sync.Once
+	tags     map[*Test][]string
+)
+
+// initTags derives the tags of the tests from the groups
+// and sets they are part of
+func initTags() {
+	tags = map[*Test][]string{}
+	add := func(tag string, tests []*Test) {
+		for _, t := range tests {
+			if !hasTag(t, tag) {
+				tags[t] = append(tags[t], tag)
+			}
+		}
+	}
+	add("cpu", TestsCPU[:])
+	add("tpm", TestsTPM[:])
+	add("fit", TestsFIT[:])
+	add("memory", TestsMemory[:])
+	add("acpi", TestsACPI[:])
+	add("cbnt", TestsCBnTSpecific[:])
+	add("txtready", TestsTXTReady)
+	add("legacy", TestsLegacy)
+	add("uefi", TestsUEFI)
+	add("tboot", TestsTBoot)
+	add("cbnt", TestsCBnT)
+}
+
+// Tags returns the tags of the test. The tags are the names of the groups
+// (cpu, tpm, fit, memory, acpi, cbnt) and sets (txtready, legacy, uefi,
+// tboot, cbnt) the test is part of and "required" for required tests.
+func (t *Test) Tags() []string {
+	tagsOnce.Do(initTags)
+	result := append([]string{}, tags[t]...)
+	if t.Required {
+		result = append(result, TagRequired)
+	}
+	return result
+}
+
+// HasTag returns true if the test has the given tag
+func (t *Test) HasTag(tag string) bool {
+	tagsOnce.Do(initTags)
+	if strings.EqualFold(tag, TagRequired) {
+		return t.Required
+	}
+	return hasTag(t, tag)
+}
+
+func hasTag(t *Test, tag string) bool {
+	for _, tt := range tags[t] {
+		if strings.EqualFold(tt, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectTests returns the tests which match any of the selectors. A selector
+// is either the name of a test or a tag (see Test.Tags), both are matched
+// case insensitive. The order of tests is kept. An error is returned if a
+// selector matches no test.
+func SelectTests(tests []*Test, selectors ...string) ([]*Test, error) {
+	selected := map[*Test]bool{}
+	for _, selector := range selectors {
+		selector = strings.TrimSpace(selector)
+		found := false
+		for _, t := range tests {
+			if strings.EqualFold(t.Name, selector) || t.HasTag(selector) {
+				selected[t] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no test with name or tag '%s'", selector)
+		}
+	}
+	var result []*Test
+	for _, t := range tests {
+		if selected[t] {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+// AllTags returns the tags of all the given tests
+func AllTags(tests []*Test) []string {
+	unique := map[string]bool{}
+	for _, t := range tests {
+		for _, tag := range t.Tags() {
+			unique[tag] = true
+		}
+	}
+	var result []string
+	for tag := range unique {
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -33,6 +33,11 @@ const (
 	//CBtGTXTPlatformDocumentID is an empty string
 	CBtGTXTPlatformDocumentID = ""
 
+	//IntelCBnTSpecificationTitle is the title of Intel CBnT BIOS Specification
+	IntelCBnTSpecificationTitle = "Intel Converged Boot Guard and Intel Trusted Execution Technology BIOS Specification"
+	//IntelCBnTSpecificationDocumentID the document ID of Intel CBnT BIOS Specification
+	IntelCBnTSpecificationDocumentID = "575623"
+
 	//ACPISpecificationTitle is the title of the ACPI spec
 	ACPISpecificationTitle = "Advanced Configuration and PowerInterface (ACPI) Specification 6.3"
 	//ACPISpecificationDocumentID s an empty string
@@ -122,6 +127,16 @@ type Test struct {
 	SpecificationDocumentID string
 }
 
+// TestsAppended lists the tests which were added to a group after the test
+// IDs had been published, in the order they were added. The test IDs are
+// the positions in the list of all the tests, so these tests are placed
+// after the tests of all the groups to keep the published IDs. A new test
+// is added to the end of its group and to the end of this list.
+var TestsAppended = []*Test{
+	&testbiosacmsignaturevalid,
+	&testtxtheapvalid,
+}
+
 // Define tests for API usage
 
 // TestsTXTReady - Summarizes all test for TXT Ready platforms
@@ -150,6 +165,7 @@ var TestsTXTReady = []*Test{
 	&testsinitmatchescpu,
 	&testbiosdataregionpresent,
 	&testbiosdataregionvalid,
+	&testtxtheapvalid,
 	&testhasmtrr,
 	&testhassmrr,
 	&testvalidsmrr,
@@ -186,6 +202,7 @@ var TestsLegacy = []*Test{
 	&testsinitmatchescpu,
 	&testbiosdataregionpresent,
 	&testbiosdataregionvalid,
+	&testtxtheapvalid,
 	&testhasmtrr,
 	&testhassmrr,
 	&testvalidsmrr,
@@ -219,6 +236,30 @@ var TestsLegacy = []*Test{
 	&testpcr00valid,
 }
 
+// TestsCBnT - Summarizes all test for CBnT platforms
+var TestsCBnT = []*Test{
+	// CPU tests
+	&testcheckforintelcpu,
+	&testcbntbtgsacminfo,
+	&testcbntpbetstopped,
+
+	// FIT tests
+	&testfitvectorisset,
+	&testhasfit,
+	&testhasbiosacm,
+
+	// CBnT tests
+	&testcbntfirmwareaccessible,
+	&testcbnthaskm,
+	&testcbnthasbpm,
+	&testcbntmanifestsvalid,
+	&testcbntkmhashprovisioned,
+	&testcbntibbcoversresetvector,
+	&testcbntibbcoversfit,
+	&testcbntacmsvn,
+	&testcbntbpmelementsvalid,
+}
+
 // TestsUEFI - Summarizes all test for TXT UEFI boot
 var TestsUEFI = []*Test{
 	// ACPI tests
@@ -233,6 +274,8 @@ var TestsUEFI = []*Test{
 	&testXSDTPresent,
 	&testXSDTValid,
 	&testRSDTorXSDTValid,
+	&testTXTHeapSizeFitsMADTCopy,
+	&testTXTHeapSizeFitsDMARCopy,
 }
 
 // TestsTBoot - Summarizes all test for the tboot hypervisor
@@ -242,6 +285,8 @@ var TestsTBoot = []*Test{
 	&testibbistrusted,
 	&testhostbridgeDPRcorrect,
 	&testhostbridgeDPRislocked,
+	&testtxtheapvalid,
+	&testACPIRSDPInOSToSINITData,
 }
 
 // Run implements the genereal test function and exposes it.
@@ -264,36 +309,40 @@ func (t *Test) Run(TxtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Config
 
 	if DepsPassed {
 		// Now run the test itself
-		rc, testerror, internalerror := t.function(TxtAPI, config)
-		if internalerror != nil && testerror == nil {
-			t.Result = ResultInternalError
-			t.ErrorText = internalerror.Error()
-		} else if testerror != nil && internalerror == nil {
-			t.ErrorText = testerror.Error()
-			if t.SpecificiationTitle != "" || t.SpecificationDocumentID != "" {
-				t.ErrorTextSpec = "Please have a look at "
-				if t.SpecificiationTitle != "" {
-					t.ErrorTextSpec += t.SpecificiationTitle + " "
-				}
-				if t.SpecificationDocumentID != "" {
-					t.ErrorTextSpec += "document ID '" + t.SpecificationDocumentID + "' "
-				}
-				if t.SpecificationChapter != "" {
-					t.ErrorTextSpec += "chapter '" + t.SpecificationChapter + "' "
-				}
-				t.ErrorTextSpec += "for implementation details."
-			}
-			t.Result = ResultFail
-		} else if rc {
-			t.Result = ResultPass
-		} else {
-			t.Result = ResultFail
-		}
+		t.Result, t.ErrorText, t.ErrorTextSpec = t.evaluate(TxtAPI, config)
 	}
 
 	return t.Result == ResultPass
 }
 
+// evaluate runs the test function without checking the dependencies and
+// returns the result, the error text and the hint to the specification
+func (t *Test) evaluate(TxtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration) (Result, string, string) {
+	rc, testerror, internalerror := t.function(TxtAPI, config)
+	if internalerror != nil && testerror == nil {
+		return ResultInternalError, internalerror.Error(), ""
+	} else if testerror != nil && internalerror == nil {
+		var errorTextSpec string
+		if t.SpecificiationTitle != "" || t.SpecificationDocumentID != "" {
+			errorTextSpec = "Please have a look at "
+			if t.SpecificiationTitle != "" {
+				errorTextSpec += t.SpecificiationTitle + " "
+			}
+			if t.SpecificationDocumentID != "" {
+				errorTextSpec += "document ID '" + t.SpecificationDocumentID + "' "
+			}
+			if t.SpecificationChapter != "" {
+				errorTextSpec += "chapter '" + t.SpecificationChapter + "' "
+			}
+			errorTextSpec += "for implementation details."
+		}
+		return ResultFail, testerror.Error(), errorTextSpec
+	} else if rc {
+		return ResultPass, "", ""
+	}
+	return ResultFail, "", ""
+}
+
 //RunTestsSilent Runs the specified tests and returns false on the first error encountered
 func RunTestsSilent(TxtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Configuration, Tests []*Test) (bool, string, error) {
 
@@ -312,3 +361,8 @@ func RunTestsSilent(TxtAPI hwapi.LowLevelHardwareInterfaces, config *tools.Confi
 	}
 	return true, "", nil
 }
+
+// Dependencies returns the tests which have to pass before this test is run
+func (t *Test) Dependencies() []*Test {
+	return t.dependencies
+}
@@ -203,7 +203,11 @@ func ValidateACMHeader(acmheader *ACMHeader) (bool, error) {
 	if acmheader.ModuleVendor != ACMVendorIntel {
 		return false, fmt.Errorf("AC Module Vendor is not Intel. Only Intel as Vendor is allowed")
 	}
-	if acmheader.KeySize*4 != uint32(len(acmheader.PubKey)) {
+	keySize, _, err := acmSignatureLayout(acmheader.HeaderVersion)
+	if err != nil {
+		return false, err
+	}
+	if acmheader.KeySize*4 != uint32(keySize) {
 		return false, fmt.Errorf("ACM keysize of 0x%x not supported yet", acmheader.KeySize*4)
 	}
 	if acmheader.ScratchSize > acmheader.Size {
@@ -422,6 +426,23 @@ func (c *Chipsets) PrettyPrint() {
 	}
 }
 
+// Matches returns true if the CPU signature (CPUID.01H:EAX) and the value of
+// IA32_PLATFORM_ID match the processor ID
+func (p ProcessorID) Matches(signature uint32, platformID uint64) bool {
+	return signature&p.FMSMask == p.FMS && platformID&p.PlatformMask == p.PlatformID
+}
+
+// Supports returns true if any processor ID of the list matches the CPU
+// signature (CPUID.01H:EAX) and the value of IA32_PLATFORM_ID
+func (p *Processors) Supports(signature uint32, platformID uint64) bool {
+	for _, id := range p.IDList {
+		if id.Matches(signature, platformID) {
+			return true
+		}
+	}
+	return false
+}
+
 //PrettyPrint prints a human readable representation of the Processors
 func (p *Processors) PrettyPrint() {
 	fmt.Println("   --Processor List--")
@@ -445,3 +466,54 @@ func (t *TPMs) PrettyPrint() {
 		fmt.Printf("         %v\n", algo.String())
 	}
 }
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (a *ACMHeader) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	if err := binary.Write(buf, binary.LittleEndian, *a); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Field Count is
+// recalculated.
+func (c *Chipsets) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	if err := binary.Write(buf, binary.LittleEndian, uint32(len(c.IDList))); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(buf, binary.LittleEndian, c.IDList); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Field Count is
+// recalculated.
+func (p *Processors) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	if err := binary.Write(buf, binary.LittleEndian, uint32(len(p.IDList))); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(buf, binary.LittleEndian, p.IDList); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. Field Count is
+// recalculated.
+func (t *TPMs) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	if err := binary.Write(buf, binary.LittleEndian, t.Capabilities); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(buf, binary.LittleEndian, uint16(len(t.AlgID))); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(buf, binary.LittleEndian, t.AlgID); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
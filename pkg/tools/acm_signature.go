@@ -0,0 +1,174 @@
+package tools
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+)
+
+const (
+	//ACMHeaderVersion0 as defined in Document 315168-016 Chapter A.1 Table 8. Authenticated Code Module Format (RSA-2048 key)
+	ACMHeaderVersion0 uint32 = 0x00000000
+	//ACMHeaderVersion3 as defined in Document 315168-016 Chapter A.1 Table 8. Authenticated Code Module Format (RSA-3072 key)
+	ACMHeaderVersion3 uint32 = 0x00030000
+
+	// acmCommonHeaderSize is the size of the ACM header fields before the public key
+	acmCommonHeaderSize = 128
+	// acmPubExpDefault is the RSA exponent of ACMs without field RSAPubExp
+	acmPubExpDefault = 0x10001
+)
+
+// ACMSignatureScheme is the RSA signature scheme of an ACM
+type ACMSignatureScheme string
+
+const (
+	// ACMSignatureSchemePKCS1v15 is RSASSA-PKCS1-v1_5 (used with header version 0.0)
+	ACMSignatureSchemePKCS1v15 ACMSignatureScheme = "RSASSA-PKCS1-v1_5"
+	// ACMSignatureSchemePSS is RSASSA-PSS (used with header version 3.0)
+	ACMSignatureSchemePSS ACMSignatureScheme = "RSASSA-PSS"
+)
+
+// ACMSignature describes the signature of an ACM
+type ACMSignature struct {
+	HeaderVersion uint32
+	Scheme        ACMSignatureScheme
+	HashAlg       crypto.Hash
+	PubKey        *rsa.PublicKey
+	// PubKeyHash is SHA256 of the public key modulus as it is stored in
+	// the ACM header (little-endian), to be compared with the allowed Intel
+	// key hashes.
+	PubKeyHash []byte
+	// Signature is the signature as it is stored in the ACM header (little-endian)
+	Signature []byte
+}
+
+// acmSignatureLayout returns the size of the key (and of the signature)
+// and if field RSAPubExp is present for the header version
+func acmSignatureLayout(headerVersion uint32) (int, bool, error) {
+	switch headerVersion {
+	case ACMHeaderVersion0:
+		return 256, true, nil
+	case ACMHeaderVersion3:
+		return 384, false, nil
+	}
+	return 0, false, fmt.Errorf("unknown ACM header version: 0x%08x", headerVersion)
+}
+
+// ACMSignedData returns the data covered by the signature of the ACM: the
+// header fields before the public key and the module body after the header
+// and the scratch area.
+func ACMSignedData(data []byte) ([]byte, error) {
+	if len(data) < acmCommonHeaderSize {
+		return nil, fmt.Errorf("ACM is truncated: %d bytes", len(data))
+	}
+	// ACMHeader is not used, since its layout matches header version 0.0 only
+	headerLen := binary.LittleEndian.Uint32(data[4:])
+	scratchSize := binary.LittleEndian.Uint32(data[124:])
+	size := uint64(binary.LittleEndian.Uint32(data[ACMSizeOffset:])) * 4
+	bodyStart := (uint64(headerLen) + uint64(scratchSize)) * 4
+	if size > uint64(len(data)) {
+		return nil, fmt.Errorf("ACM is truncated: the size is 0x%x, but there are only 0x%x bytes", size, len(data))
+	}
+	if bodyStart > size {
+		return nil, fmt.Errorf("ACM header and scratch area (0x%x) are bigger than the module (0x%x)", bodyStart, size)
+	}
+	signedData := make([]byte, 0, acmCommonHeaderSize+size-bodyStart)
+	signedData = append(signedData, data[:acmCommonHeaderSize]...)
+	return append(signedData, data[bodyStart:size]...), nil
+}
+
+// VerifyACM verifies the signature of the ACM by the public key embedded
+// into its header. ACMs with header version 0.0 are signed by RSA-2048 with
+// RSASSA-PKCS1-v1_5 over SHA256, ACMs with header version 3.0 are signed by
+// RSA-3072 with RSASSA-PSS over SHA384.
+//
+// The returned ACMSignature is not nil if the signature fields could be
+// parsed, even if the verification fails.
+func VerifyACM(data []byte) (*ACMSignature, error) {
+	if len(data) < acmCommonHeaderSize {
+		return nil, fmt.Errorf("ACM is truncated: %d bytes", len(data))
+	}
+	headerVersion := binary.LittleEndian.Uint32(data[8:])
+	keySize, hasPubExp, err := acmSignatureLayout(headerVersion)
+	if err != nil {
+		return nil, err
+	}
+	if keySizeField := binary.LittleEndian.Uint32(data[120:]); int(keySizeField)*4 != keySize {
+		return nil, fmt.Errorf("ACM key size 0x%x does not match header version 0x%08x", keySizeField*4, headerVersion)
+	}
+
+	offset := acmCommonHeaderSize
+	sigEnd := offset + 2*keySize
+	if hasPubExp {
+		sigEnd += 4
+	}
+	if len(data) < sigEnd {
+		return nil, fmt.Errorf("ACM is truncated: %d bytes", len(data))
+	}
+	pubKeyValue := data[offset : offset+keySize]
+	offset += keySize
+	pubExp := acmPubExpDefault
+	if hasPubExp {
+		pubExp = int(binary.LittleEndian.Uint32(data[offset:]))
+		offset += 4
+	}
+
+	pubKeyHash := sha256.Sum256(pubKeyValue)
+	result := &ACMSignature{
+		HeaderVersion: headerVersion,
+		PubKey: &rsa.PublicKey{
+			N: new(big.Int).SetBytes(reverseCopy(pubKeyValue)),
+			E: pubExp,
+		},
+		PubKeyHash: pubKeyHash[:],
+		Signature:  append([]byte{}, data[offset:offset+keySize]...),
+	}
+	if hasPubExp {
+		result.Scheme, result.HashAlg = ACMSignatureSchemePKCS1v15, crypto.SHA256
+	} else {
+		result.Scheme, result.HashAlg = ACMSignatureSchemePSS, crypto.SHA384
+	}
+
+	signedData, err := ACMSignedData(data)
+	if err != nil {
+		return result, err
+	}
+	h := result.HashAlg.New()
+	h.Write(signedData)
+	digest := h.Sum(nil)
+	sig := reverseCopy(result.Signature)
+
+	switch result.Scheme {
+	case ACMSignatureSchemePKCS1v15:
+		// the padded message is little-endian as well and has no DigestInfo,
+		// so the digest is reversed
+		err = rsa.VerifyPKCS1v15(result.PubKey, 0, reverseCopy(digest), sig)
+	case ACMSignatureSchemePSS:
+		err = rsa.VerifyPSS(result.PubKey, result.HashAlg, digest, sig, nil)
+	}
+	if err != nil {
+		return result, fmt.Errorf("ACM signature is invalid: %w", err)
+	}
+	return result, nil
+}
+
+// PrintACMSignature verifies the signature of the ACM and prints the result
+// in a human readable format
+func PrintACMSignature(data []byte) {
+	fmt.Println("   --Signature--")
+	sig, err := VerifyACM(data)
+	if sig != nil {
+		fmt.Printf("      Scheme: %s with %s\n", sig.Scheme, sig.HashAlg)
+		fmt.Printf("      Key Size: %d\n", sig.PubKey.N.BitLen())
+		fmt.Printf("      Key Exponent: 0x%x\n", sig.PubKey.E)
+		fmt.Printf("      Key Hash (SHA256): %x\n", sig.PubKeyHash)
+	}
+	if err != nil {
+		fmt.Printf("      Status: INVALID (%v)\n", err)
+		return
+	}
+	fmt.Println("      Status: valid")
+}
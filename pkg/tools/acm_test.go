@@ -1,9 +1,15 @@
 package tools
 
 import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
 	"io/ioutil"
 	"os"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseandValidateACMHeader(t *testing.T) {
@@ -107,3 +113,111 @@ func TestACMSize2(t *testing.T) {
 		t.Errorf("ACMSize() failed: Wrong size returned, %d", size)
 	}
 }
+
+func TestACMMarshalBinary(t *testing.T) {
+	for _, filename := range []string{"sinit_acm.bin", "bios_acm.bin", "bios_acm2.bin"} {
+		t.Run(filename, func(t *testing.T) {
+			file, err := ioutil.ReadFile("./tests/" + filename)
+			require.NoError(t, err)
+
+			acm, chipsets, processors, tpms, err, internalerr := ParseACM(file)
+			require.NoError(t, internalerr)
+			require.NoError(t, err)
+
+			b, err := acm.Header.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, file[:len(b)], b)
+			header, err := ParseACMHeader(b)
+			require.NoError(t, err)
+			require.Equal(t, acm.Header, *header)
+
+			if (acm.Header.ModuleSubType & ACMModuleSubtypeAncModule) > 0 {
+				return
+			}
+			b, err = chipsets.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, file[acm.Info.ChipsetIDList:int(acm.Info.ChipsetIDList)+len(b)], b)
+			b, err = processors.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, file[acm.Info.ProcessorIDList:int(acm.Info.ProcessorIDList)+len(b)], b)
+			if acm.Info.ACMVersion >= 5 {
+				b, err = tpms.MarshalBinary()
+				require.NoError(t, err)
+				require.Equal(t, file[acm.Info.TPMInfoList:int(acm.Info.TPMInfoList)+len(b)], b)
+			}
+		})
+	}
+}
+
+func TestVerifyACM(t *testing.T) {
+	for _, filename := range []string{"sinit_acm.bin", "bios_acm.bin", "bios_acm2.bin"} {
+		t.Run(filename, func(t *testing.T) {
+			file, err := ioutil.ReadFile("./tests/" + filename)
+			require.NoError(t, err)
+
+			sig, err := VerifyACM(file)
+			require.NoError(t, err)
+			require.Equal(t, ACMSignatureSchemePKCS1v15, sig.Scheme)
+			require.Equal(t, 2048, sig.PubKey.N.BitLen())
+			require.Len(t, sig.PubKeyHash, 32)
+
+			hdr, err := ParseACMHeader(file)
+			require.NoError(t, err)
+			scratchOffset := hdr.HeaderLen * 4
+
+			// the scratch area is not signed
+			tampered := append([]byte{}, file...)
+			tampered[scratchOffset] ^= 1
+			_, err = VerifyACM(tampered)
+			require.NoError(t, err)
+
+			for _, offset := range []int{20, int(scratchOffset + hdr.ScratchSize*4), len(file) - 1} {
+				tampered := append([]byte{}, file...)
+				tampered[offset] ^= 1
+				sig, err := VerifyACM(tampered)
+				require.Error(t, err, "offset 0x%x", offset)
+				require.NotNil(t, sig)
+			}
+
+			_, err = VerifyACM(file[:len(file)-1])
+			require.Error(t, err)
+			_, err = VerifyACM(file[:100])
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("header_version_3", func(t *testing.T) {
+		privKey, err := rsa.GenerateKey(rand.Reader, 3072)
+		require.NoError(t, err)
+
+		const headerLen, scratchSize, bodySize = 896, 0x100, 0x1000
+		acm := make([]byte, headerLen+scratchSize+bodySize)
+		_, err = rand.Read(acm[headerLen:])
+		require.NoError(t, err)
+		binary.LittleEndian.PutUint16(acm[0:], ACMTypeChipset)
+		binary.LittleEndian.PutUint32(acm[4:], headerLen/4)
+		binary.LittleEndian.PutUint32(acm[8:], ACMHeaderVersion3)
+		binary.LittleEndian.PutUint32(acm[24:], uint32(len(acm)/4))
+		binary.LittleEndian.PutUint32(acm[120:], 384/4)
+		binary.LittleEndian.PutUint32(acm[124:], scratchSize/4)
+		copy(acm[128:], reverseCopy(privKey.N.Bytes()))
+
+		signedData, err := ACMSignedData(acm)
+		require.NoError(t, err)
+		require.Len(t, signedData, 128+bodySize)
+		h := crypto.SHA384.New()
+		h.Write(signedData)
+		sigBlock, err := rsa.SignPSS(rand.Reader, privKey, crypto.SHA384, h.Sum(nil), nil)
+		require.NoError(t, err)
+		copy(acm[128+384:], reverseCopy(sigBlock))
+
+		sig, err := VerifyACM(acm)
+		require.NoError(t, err)
+		require.Equal(t, ACMSignatureSchemePSS, sig.Scheme)
+		require.Equal(t, privKey.PublicKey, *sig.PubKey)
+
+		acm[len(acm)-1] ^= 1
+		_, err = VerifyACM(acm)
+		require.Error(t, err)
+	})
+}
@@ -14,6 +14,12 @@ type Configuration struct {
 	TPM     hwapi.TPMVersion
 	TXTMode TXTMode
 	LCPHash tpm2.Algorithm
+	// FirmwareImage is the path to a full SPI flash image. If empty, then
+	// the memory mapped BIOS region is used (and the ME region is not available).
+	FirmwareImage string
+	// CPUDatabase is the path to a CPU database overriding entries of the
+	// embedded one. If empty, then the embedded database is used.
+	CPUDatabase string
 }
 
 // Configuration input
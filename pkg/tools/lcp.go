@@ -193,6 +193,10 @@ type LCPPolicyElement struct {
 	SBIOS            *LCPPolicySBIOS
 	PCONF            *LCPPolicyPCONF
 	Custom           *LCPPolicyCustom
+	MLE2             *LCPPolicyMLE2
+	SBIOS2           *LCPPolicySBIOS2
+	PCONF2           *LCPPolicyPCONF2
+	STM2             *LCPPolicySTM2
 }
 
 //LCPPolicyMLE represents a MLE policy element as defined in Document 315168-016 Chapter D.4.4 LCP_MLE_ELEMENT
@@ -250,6 +254,7 @@ type LCPPolicyList2 struct {
 	SignaturAlg       uint16
 	PolicyElementSize uint32
 	PolicyElements    []LCPPolicyElement
+	Signature         *LCPSignature2
 }
 
 //LCPSignature as defined in Document 315168-016 Chapter D.3.2.1 LCP_POLICY_LIST2 Structure
@@ -563,11 +568,35 @@ func parsePolicyElement(buf *bytes.Reader, element *LCPPolicyElement) error {
 		element.PCONF = &pol
 	case LCPPolicyElementCustom:
 		var pol LCPPolicyCustom
-		err = parsePolicyElementCustom(buf, int(element.Size)-16, &pol)
+		err = parsePolicyElementCustom(buf, int(element.Size)-LCPPolicyElementHeaderSize, &pol)
 		if err != nil {
 			return err
 		}
 		element.Custom = &pol
+	case LCPPolicyElementMLE2:
+		var pol LCPPolicyMLE2
+		if err := parsePolicyElementMLE2(buf, &pol); err != nil {
+			return err
+		}
+		element.MLE2 = &pol
+	case LCPPolicyElementSBIOS2:
+		var pol LCPPolicySBIOS2
+		if err := parsePolicyElementSBIOS2(buf, &pol); err != nil {
+			return err
+		}
+		element.SBIOS2 = &pol
+	case LCPPolicyElementPCONF2:
+		var pol LCPPolicyPCONF2
+		if err := parsePolicyElementPCONF2(buf, &pol); err != nil {
+			return err
+		}
+		element.PCONF2 = &pol
+	case LCPPolicyElementSTM2:
+		var pol LCPPolicySTM2
+		if err := parsePolicyElementSTM2(buf, &pol); err != nil {
+			return err
+		}
+		element.STM2 = &pol
 	default:
 		return fmt.Errorf("unknown policy element type: %d, See: Intel TXT Software Development Guide, Document: 315168-010, P. 116", element.Type)
 	}
@@ -827,11 +856,24 @@ func parsePolicyList2(buf *bytes.Reader, list *LCPPolicyList2) error {
 		return err
 	}
 
-	list.PolicyElements = make([]LCPPolicyElement, list.PolicyElementSize)
-	for i := 0; i < int(list.PolicyElementSize); i++ {
-		if err := parsePolicyElement(buf, &list.PolicyElements[i]); err != nil {
-			return fmt.Errorf("unable to parse policy element %d: %w", i, err)
+	for i := 0; i < int(list.PolicyElementSize); {
+		var elt LCPPolicyElement
+		if err := parsePolicyElement(buf, &elt); err != nil {
+			return fmt.Errorf("unable to parse policy element %d: %w", len(list.PolicyElements), err)
+		}
+		if elt.Size < LCPPolicyElementHeaderSize {
+			return fmt.Errorf("invalid size of policy element %d: %d", len(list.PolicyElements), elt.Size)
+		}
+		list.PolicyElements = append(list.PolicyElements, elt)
+		i += int(elt.Size)
+	}
+
+	if tpm2.Algorithm(list.SignaturAlg) != tpm2.AlgNull {
+		var sig LCPSignature2
+		if err := parseLCPSignature2(buf, tpm2.Algorithm(list.SignaturAlg), &sig); err != nil {
+			return fmt.Errorf("unable to parse the signature: %w", err)
 		}
+		list.Signature = &sig
 	}
 
 	return nil
@@ -937,12 +979,18 @@ func ParsePolicyData(policyData []byte) (*LCPPolicyData, error) {
 
 	polData.PolicyLists = make([]LCPList, polData.NumLists)
 	for i := 0; i < int(polData.NumLists); i++ {
-		err = parsePolicyList(buf, &polData.PolicyLists[i].TPM12PolicyList)
-		if err != nil {
+		// LCP_POLICY_LIST and LCP_POLICY_LIST2 are distinguished by the major version
+		var version uint16
+		if err := binary.Read(bytes.NewReader(policyData[len(policyData)-buf.Len():]), binary.LittleEndian, &version); err != nil {
+			return nil, err
+		}
+		if version>>8 == LCPPolicyList2Version>>8 {
 			err = parsePolicyList2(buf, &polData.PolicyLists[i].TPM20PolicyList)
-			if err != nil {
-				return nil, err
-			}
+		} else {
+			err = parsePolicyList(buf, &polData.PolicyLists[i].TPM12PolicyList)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse list %d: %w", i, err)
 		}
 	}
 
@@ -982,6 +1030,10 @@ func (pd *LCPPolicyData) PrettyPrint() {
 	log.Printf("\tLists: %d\n", pd.NumLists)
 	for idx, pol := range pd.PolicyLists {
 		log.Printf("\tList %d:\n", idx)
+		if pol.IsTPM20() {
+			pol.TPM20PolicyList.prettyPrint()
+			continue
+		}
 		log.Printf("\t\tVersion: 0x%04x\n", pol.TPM12PolicyList.Version)
 		log.Printf("\t\tReserved: % 02x\n", pol.TPM12PolicyList.Reserved)
 		log.Printf("\t\tSignature Algorithm: 0x%02x\n", pol.TPM12PolicyList.SignaturAlg)
@@ -0,0 +1,461 @@
+package tools
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/binary"
+	"fmt"
+	"log"
+
+	"github.com/google/go-tpm/tpm2"
+)
+
+const (
+	//LCPPolicyList2Version as defined in Document 315168-016 Chapter D.3.2.1 LCP_POLICY_LIST2 Structure
+	LCPPolicyList2Version uint16 = 0x0200
+	//LCPPolicyElementHeaderSize is the size of Size, Type and PolicyEltControl of LCP_POLICY_ELEMENT
+	LCPPolicyElementHeaderSize = 12
+
+	// LCPSignatureAlgSM2 is TPM_ALG_SM2, it is not defined by go-tpm
+	LCPSignatureAlgSM2 tpm2.Algorithm = 0x001B
+)
+
+// LCPPolicyMLE2 represents a MLE policy element as defined in Document 315168-016 Chapter D.4.7 LCP_MLE_ELEMENT2
+type LCPPolicyMLE2 struct {
+	SINITMinVersion uint8
+	Reserved        uint8
+	HashAlg         tpm2.Algorithm
+	Hashes          [][]byte
+}
+
+// LCPPolicyPCONF2 represents a PCONF policy element as defined in Document 315168-016 Chapter D.4.8 LCP_PCONF_ELEMENT2
+type LCPPolicyPCONF2 struct {
+	HashAlg  tpm2.Algorithm
+	PCRInfos []TPMSQuoteInfo
+}
+
+// TPMSQuoteInfo represents TPMS_QUOTE_INFO of TPM 2.0 (it is encoded in big-endian)
+type TPMSQuoteInfo struct {
+	// TPML_PCR_SELECTION
+	PCRSelections []tpm2.PCRSelection
+	// TPM2B_DIGEST, the hash of the concatenated values of the selected PCRs
+	PCRDigest []byte
+}
+
+// LCPPolicySBIOS2 represents a SBIOS policy element for TPM 2.0 (LCP_SBIOS_ELEMENT2)
+type LCPPolicySBIOS2 struct {
+	HashAlg      tpm2.Algorithm
+	Reserved1    [2]uint8
+	FallbackHash []byte
+	Reserved2    uint16
+	Hashes       [][]byte
+}
+
+// LCPPolicySTM2 represents a STM policy element as defined in Document 315168-016 Chapter D.4.9 LCP_STM_ELEMENT2
+type LCPPolicySTM2 struct {
+	HashAlg tpm2.Algorithm
+	Hashes  [][]byte
+}
+
+// LCPSignature2 as defined in Document 315168-016 Chapter D.3.2.1 LCP_POLICY_LIST2 Structure.
+//
+// For RSA PubkeyValue is the modulus and SigBlock is the signature. For ECC (ECDSA and SM2)
+// PubkeyValue is Qx|Qy and SigBlock is R|S, and field Reserved is present.
+// All values are little-endian.
+type LCPSignature2 struct {
+	RevocationCounter uint16
+	PubkeySize        uint16
+	Reserved          uint32
+	PubkeyValue       []byte
+	SigBlock          []byte
+}
+
+func isLCPECCSignatureAlg(alg tpm2.Algorithm) bool {
+	return alg == tpm2.AlgECDSA || alg == LCPSignatureAlgSM2
+}
+
+func readLCPDigests(buf *bytes.Reader, alg tpm2.Algorithm, count uint16) ([][]byte, error) {
+	h, err := alg.Hash()
+	if err != nil {
+		return nil, err
+	}
+	result := make([][]byte, count)
+	for i := range result {
+		result[i] = make([]byte, h.Size())
+		if err := binary.Read(buf, binary.LittleEndian, result[i]); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func writeLCPDigests(buf *bytes.Buffer, alg tpm2.Algorithm, digests ...[]byte) error {
+	h, err := alg.Hash()
+	if err != nil {
+		return err
+	}
+	for _, digest := range digests {
+		if len(digest) != h.Size() {
+			return fmt.Errorf("invalid %s digest size: %d", alg, len(digest))
+		}
+		buf.Write(digest)
+	}
+	return nil
+}
+
+func parsePolicyElementMLE2(buf *bytes.Reader, pol *LCPPolicyMLE2) error {
+	var hdr struct {
+		SINITMinVersion uint8
+		Reserved        uint8
+		HashAlg         tpm2.Algorithm
+		NumHashes       uint16
+	}
+	if err := binary.Read(buf, binary.LittleEndian, &hdr); err != nil {
+		return err
+	}
+	pol.SINITMinVersion, pol.Reserved, pol.HashAlg = hdr.SINITMinVersion, hdr.Reserved, hdr.HashAlg
+	hashes, err := readLCPDigests(buf, pol.HashAlg, hdr.NumHashes)
+	if err != nil {
+		return err
+	}
+	pol.Hashes = hashes
+	return nil
+}
+
+func parsePolicyElementSTM2(buf *bytes.Reader, pol *LCPPolicySTM2) error {
+	var numHashes uint16
+	if err := binary.Read(buf, binary.LittleEndian, &pol.HashAlg); err != nil {
+		return err
+	}
+	if err := binary.Read(buf, binary.LittleEndian, &numHashes); err != nil {
+		return err
+	}
+	hashes, err := readLCPDigests(buf, pol.HashAlg, numHashes)
+	if err != nil {
+		return err
+	}
+	pol.Hashes = hashes
+	return nil
+}
+
+func parsePolicyElementSBIOS2(buf *bytes.Reader, pol *LCPPolicySBIOS2) error {
+	if err := binary.Read(buf, binary.LittleEndian, &pol.HashAlg); err != nil {
+		return err
+	}
+	if err := binary.Read(buf, binary.LittleEndian, &pol.Reserved1); err != nil {
+		return err
+	}
+	fallbackHash, err := readLCPDigests(buf, pol.HashAlg, 1)
+	if err != nil {
+		return err
+	}
+	pol.FallbackHash = fallbackHash[0]
+	if err := binary.Read(buf, binary.LittleEndian, &pol.Reserved2); err != nil {
+		return err
+	}
+	var numHashes uint16
+	if err := binary.Read(buf, binary.LittleEndian, &numHashes); err != nil {
+		return err
+	}
+	hashes, err := readLCPDigests(buf, pol.HashAlg, numHashes)
+	if err != nil {
+		return err
+	}
+	pol.Hashes = hashes
+	return nil
+}
+
+func parsePolicyElementPCONF2(buf *bytes.Reader, pol *LCPPolicyPCONF2) error {
+	var numPCRInfos uint16
+	if err := binary.Read(buf, binary.LittleEndian, &pol.HashAlg); err != nil {
+		return err
+	}
+	if err := binary.Read(buf, binary.LittleEndian, &numPCRInfos); err != nil {
+		return err
+	}
+	pol.PCRInfos = make([]TPMSQuoteInfo, numPCRInfos)
+	for i := range pol.PCRInfos {
+		if err := parseTPMSQuoteInfo(buf, &pol.PCRInfos[i]); err != nil {
+			return fmt.Errorf("unable to parse PCR info %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func parseTPMSQuoteInfo(buf *bytes.Reader, info *TPMSQuoteInfo) error {
+	var count uint32
+	if err := binary.Read(buf, binary.BigEndian, &count); err != nil {
+		return err
+	}
+	if uint64(count) > uint64(buf.Len()) {
+		return fmt.Errorf("invalid PCR selections count: %d", count)
+	}
+	for i := uint32(0); i < count; i++ {
+		var sel tpm2.PCRSelection
+		var sizeOfSelect uint8
+		if err := binary.Read(buf, binary.BigEndian, &sel.Hash); err != nil {
+			return err
+		}
+		if err := binary.Read(buf, binary.BigEndian, &sizeOfSelect); err != nil {
+			return err
+		}
+		bitmap := make([]byte, sizeOfSelect)
+		if err := binary.Read(buf, binary.BigEndian, bitmap); err != nil {
+			return err
+		}
+		sel.PCRs = []int{}
+		for byteIdx, b := range bitmap {
+			for bitIdx := 0; bitIdx < 8; bitIdx++ {
+				if b&(1<<uint(bitIdx)) != 0 {
+					sel.PCRs = append(sel.PCRs, byteIdx*8+bitIdx)
+				}
+			}
+		}
+		info.PCRSelections = append(info.PCRSelections, sel)
+	}
+	var digestSize uint16
+	if err := binary.Read(buf, binary.BigEndian, &digestSize); err != nil {
+		return err
+	}
+	info.PCRDigest = make([]byte, digestSize)
+	return binary.Read(buf, binary.BigEndian, info.PCRDigest)
+}
+
+func parseLCPSignature2(buf *bytes.Reader, sigAlg tpm2.Algorithm, sig *LCPSignature2) error {
+	if err := binary.Read(buf, binary.LittleEndian, &sig.RevocationCounter); err != nil {
+		return err
+	}
+	if err := binary.Read(buf, binary.LittleEndian, &sig.PubkeySize); err != nil {
+		return err
+	}
+	if isLCPECCSignatureAlg(sigAlg) {
+		if err := binary.Read(buf, binary.LittleEndian, &sig.Reserved); err != nil {
+			return err
+		}
+	}
+	sig.PubkeyValue = make([]byte, sig.PubkeySize)
+	if err := binary.Read(buf, binary.LittleEndian, sig.PubkeyValue); err != nil {
+		return err
+	}
+	sig.SigBlock = make([]byte, sig.PubkeySize)
+	return binary.Read(buf, binary.LittleEndian, sig.SigBlock)
+}
+
+func (pol *LCPPolicyMLE2) writeTo(buf *bytes.Buffer) error {
+	hdr := struct {
+		SINITMinVersion uint8
+		Reserved        uint8
+		HashAlg         tpm2.Algorithm
+		NumHashes       uint16
+	}{pol.SINITMinVersion, pol.Reserved, pol.HashAlg, uint16(len(pol.Hashes))}
+	if err := binary.Write(buf, binary.LittleEndian, hdr); err != nil {
+		return err
+	}
+	return writeLCPDigests(buf, pol.HashAlg, pol.Hashes...)
+}
+
+func (pol *LCPPolicySTM2) writeTo(buf *bytes.Buffer) error {
+	if err := binary.Write(buf, binary.LittleEndian, []uint16{uint16(pol.HashAlg), uint16(len(pol.Hashes))}); err != nil {
+		return err
+	}
+	return writeLCPDigests(buf, pol.HashAlg, pol.Hashes...)
+}
+
+func (pol *LCPPolicySBIOS2) writeTo(buf *bytes.Buffer) error {
+	if err := binary.Write(buf, binary.LittleEndian, pol.HashAlg); err != nil {
+		return err
+	}
+	buf.Write(pol.Reserved1[:])
+	if err := writeLCPDigests(buf, pol.HashAlg, pol.FallbackHash); err != nil {
+		return err
+	}
+	if err := binary.Write(buf, binary.LittleEndian, []uint16{pol.Reserved2, uint16(len(pol.Hashes))}); err != nil {
+		return err
+	}
+	return writeLCPDigests(buf, pol.HashAlg, pol.Hashes...)
+}
+
+func (pol *LCPPolicyPCONF2) writeTo(buf *bytes.Buffer) error {
+	if err := binary.Write(buf, binary.LittleEndian, []uint16{uint16(pol.HashAlg), uint16(len(pol.PCRInfos))}); err != nil {
+		return err
+	}
+	for _, info := range pol.PCRInfos {
+		if err := binary.Write(buf, binary.BigEndian, uint32(len(info.PCRSelections))); err != nil {
+			return err
+		}
+		for _, sel := range info.PCRSelections {
+			bitmap := make([]byte, 3)
+			for _, pcr := range sel.PCRs {
+				if pcr < 0 || pcr >= 8*len(bitmap) {
+					return fmt.Errorf("invalid PCR index: %d", pcr)
+				}
+				bitmap[pcr/8] |= 1 << uint(pcr%8)
+			}
+			if err := binary.Write(buf, binary.BigEndian, sel.Hash); err != nil {
+				return err
+			}
+			buf.WriteByte(uint8(len(bitmap)))
+			buf.Write(bitmap)
+		}
+		if err := binary.Write(buf, binary.BigEndian, uint16(len(info.PCRDigest))); err != nil {
+			return err
+		}
+		buf.Write(info.PCRDigest)
+	}
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// Fields PolicyElementSize and LCPSignature2.PubkeySize are recalculated.
+func (list *LCPPolicyList2) MarshalBinary() ([]byte, error) {
+	elements := bytes.NewBuffer(nil)
+	for idx := range list.PolicyElements {
+		b, err := list.PolicyElements[idx].MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal policy element %d: %w", idx, err)
+		}
+		elements.Write(b)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	hdr := struct {
+		Version           uint16
+		SignaturAlg       uint16
+		PolicyElementSize uint32
+	}{list.Version, list.SignaturAlg, uint32(elements.Len())}
+	if err := binary.Write(buf, binary.LittleEndian, hdr); err != nil {
+		return nil, err
+	}
+	buf.Write(elements.Bytes())
+
+	sigAlg := tpm2.Algorithm(list.SignaturAlg)
+	if sigAlg == tpm2.AlgNull {
+		return buf.Bytes(), nil
+	}
+	sig := list.Signature
+	if sig == nil {
+		return nil, fmt.Errorf("signature algorithm is %s, but there is no signature", sigAlg)
+	}
+	if len(sig.SigBlock) != len(sig.PubkeyValue) {
+		return nil, fmt.Errorf("the sizes of the public key (%d) and the signature (%d) do not match", len(sig.PubkeyValue), len(sig.SigBlock))
+	}
+	if err := binary.Write(buf, binary.LittleEndian, []uint16{sig.RevocationCounter, uint16(len(sig.PubkeyValue))}); err != nil {
+		return nil, err
+	}
+	if isLCPECCSignatureAlg(sigAlg) {
+		if err := binary.Write(buf, binary.LittleEndian, sig.Reserved); err != nil {
+			return nil, err
+		}
+	}
+	buf.Write(sig.PubkeyValue)
+	buf.Write(sig.SigBlock)
+	return buf.Bytes(), nil
+}
+
+// IsTPM20 returns true if the list is LCP_POLICY_LIST2 (field TPM20PolicyList
+// is used), otherwise it is LCP_POLICY_LIST (field TPM12PolicyList is used).
+func (l *LCPList) IsTPM20() bool {
+	return l.TPM20PolicyList.Version>>8 == LCPPolicyList2Version>>8
+}
+
+// Measurement returns the hash of the list which is included into the policy
+// hash: the hash of the public key for signed lists and the hash of the whole
+// list for unsigned ones.
+func (list *LCPPolicyList2) Measurement(hashAlg crypto.Hash) ([]byte, error) {
+	if !hashAlg.Available() {
+		return nil, fmt.Errorf("hash algorithm %v is not available", hashAlg)
+	}
+	h := hashAlg.New()
+	if tpm2.Algorithm(list.SignaturAlg) == tpm2.AlgNull {
+		b, err := list.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		h.Write(b)
+	} else {
+		if list.Signature == nil {
+			return nil, fmt.Errorf("the list is signed, but there is no signature")
+		}
+		h.Write(list.Signature.PubkeyValue)
+	}
+	return h.Sum(nil), nil
+}
+
+// PolicyHash calculates the value of field PolicyHash of LCP_POLICY2 (to be
+// written into the PO or PS index) for the policy data: the hash of the
+// concatenated measurements of the lists.
+func (pd *LCPPolicyData) PolicyHash(hashAlg crypto.Hash) ([]byte, error) {
+	if len(pd.PolicyLists) == 0 || len(pd.PolicyLists) > int(LCPMaxLists) {
+		return nil, fmt.Errorf("invalid amount of lists: %d", len(pd.PolicyLists))
+	}
+	var measurements []byte
+	for idx := range pd.PolicyLists {
+		list := &pd.PolicyLists[idx]
+		if !list.IsTPM20() {
+			return nil, fmt.Errorf("list %d: LCP_POLICY_LIST is not supported", idx)
+		}
+		m, err := list.TPM20PolicyList.Measurement(hashAlg)
+		if err != nil {
+			return nil, fmt.Errorf("unable to measure list %d: %w", idx, err)
+		}
+		measurements = append(measurements, m...)
+	}
+	h := hashAlg.New()
+	h.Write(measurements)
+	return h.Sum(nil), nil
+}
+
+func (list *LCPPolicyList2) prettyPrint() {
+	log.Printf("\t\tVersion: 0x%04x\n", list.Version)
+	log.Printf("\t\tSignature Algorithm: %s\n", tpm2.Algorithm(list.SignaturAlg))
+	log.Printf("\t\tEntries: %d bytes\n", list.PolicyElementSize)
+
+	for jdx, ent := range list.PolicyElements {
+		log.Printf("\t\tPolicy %d:\n", jdx)
+		log.Printf("\t\t\tSize: %d bytes\n", ent.Size)
+		log.Printf("\t\t\tType: %#v\n", ent.Type)
+		log.Printf("\t\t\tPolicyEltControl: %#v\n", ent.PolicyEltControl)
+
+		switch {
+		case ent.MLE2 != nil:
+			log.Printf("\t\t\tSINITMinVersion: %d\n", ent.MLE2.SINITMinVersion)
+			log.Printf("\t\t\tHashAlg: %s\n", ent.MLE2.HashAlg)
+			for kdx, h := range ent.MLE2.Hashes {
+				log.Printf("\t\t\tHash %2d: %02x\n", kdx, h)
+			}
+		case ent.SBIOS2 != nil:
+			log.Printf("\t\t\tHashAlg: %s\n", ent.SBIOS2.HashAlg)
+			log.Printf("\t\t\tFallbackHash: %02x\n", ent.SBIOS2.FallbackHash)
+			for kdx, h := range ent.SBIOS2.Hashes {
+				log.Printf("\t\t\tHash %2d: %02x\n", kdx, h)
+			}
+		case ent.PCONF2 != nil:
+			log.Printf("\t\t\tHashAlg: %s\n", ent.PCONF2.HashAlg)
+			for kdx, info := range ent.PCONF2.PCRInfos {
+				log.Printf("\t\t\tPCR Info %d:\n", kdx)
+				for _, sel := range info.PCRSelections {
+					log.Printf("\t\t\t\tPCR Select: %s %v\n", sel.Hash, sel.PCRs)
+				}
+				log.Printf("\t\t\t\tDigest: %02x\n", info.PCRDigest)
+			}
+		case ent.STM2 != nil:
+			log.Printf("\t\t\tHashAlg: %s\n", ent.STM2.HashAlg)
+			for kdx, h := range ent.STM2.Hashes {
+				log.Printf("\t\t\tHash %2d: %02x\n", kdx, h)
+			}
+		default:
+			log.Printf("\t\t\tError: Unknown Policy Element type\n")
+		}
+	}
+
+	if list.Signature != nil {
+		log.Printf("\t\tSignature:\n")
+		log.Printf("\t\t\tRevocation Counter: %#v\n", list.Signature.RevocationCounter)
+		log.Printf("\t\t\tPubkey Size: %d\n", list.Signature.PubkeySize)
+		log.Printf("\t\t\tPubkey Value: %02x\n", list.Signature.PubkeyValue)
+		log.Printf("\t\t\tSig Block: %02x\n", list.Signature.SigBlock)
+	} else {
+		log.Printf("\t\tSignature: (None)\n")
+	}
+}
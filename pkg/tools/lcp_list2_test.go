@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/stretchr/testify/require"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+func newTestPolicyList2() LCPPolicyList2 {
+	digest := func(s string) []byte {
+		h := sha256.Sum256([]byte(s))
+		return h[:]
+	}
+	return LCPPolicyList2{
+		Version:     LCPPolicyList2Version,
+		SignaturAlg: uint16(tpm2.AlgNull),
+		PolicyElements: []LCPPolicyElement{
+			{
+				Type: LCPPolicyElementMLE2,
+				MLE2: &LCPPolicyMLE2{SINITMinVersion: 3, HashAlg: tpm2.AlgSHA256, Hashes: [][]byte{digest("mle0"), digest("mle1")}},
+			},
+			{
+				Type: LCPPolicyElementPCONF2,
+				PCONF2: &LCPPolicyPCONF2{HashAlg: tpm2.AlgSHA256, PCRInfos: []TPMSQuoteInfo{{
+					PCRSelections: []tpm2.PCRSelection{{Hash: tpm2.AlgSHA256, PCRs: []int{0, 2, 17}}},
+					PCRDigest:     digest("pcrs"),
+				}}},
+			},
+			{
+				Type:   LCPPolicyElementSBIOS2,
+				SBIOS2: &LCPPolicySBIOS2{HashAlg: tpm2.AlgSHA256, FallbackHash: digest("fallback"), Hashes: [][]byte{digest("sbios")}},
+			},
+			{
+				Type:             LCPPolicyElementSTM2,
+				PolicyEltControl: 1,
+				STM2:             &LCPPolicySTM2{HashAlg: tpm2.AlgSHA256, Hashes: [][]byte{digest("stm")}},
+			},
+		},
+	}
+}
+
+func TestLCPPolicyList2(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	sm2Key, err := sm2.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	unsigned := newTestPolicyList2()
+	lists := []LCPList{{TPM20PolicyList: unsigned}}
+	for _, key := range []crypto.Signer{rsaKey, ecdsaKey, sm2Key} {
+		list := newTestPolicyList2()
+		require.NoError(t, list.Sign(key, 5, crypto.SHA256), "%T", key)
+		require.NoError(t, list.Verify(), "%T", key)
+		lists = append(lists, LCPList{TPM20PolicyList: list})
+	}
+
+	var fileSignature [32]uint8
+	copy(fileSignature[:], LCPDataFileSignature)
+	policyData := LCPPolicyData{
+		FileSignature: fileSignature,
+		NumLists:      uint8(len(lists)),
+		PolicyLists:   lists,
+	}
+	b, err := policyData.MarshalBinary()
+	require.NoError(t, err)
+
+	parsed, err := ParsePolicyData(b)
+	require.NoError(t, err)
+	require.Len(t, parsed.PolicyLists, len(lists))
+	for idx := range parsed.PolicyLists {
+		list := &parsed.PolicyLists[idx].TPM20PolicyList
+		require.True(t, parsed.PolicyLists[idx].IsTPM20())
+		require.Len(t, list.PolicyElements, 4)
+		require.Equal(t, []int{0, 2, 17}, list.PolicyElements[1].PCONF2.PCRInfos[0].PCRSelections[0].PCRs)
+		require.Equal(t, lists[idx].TPM20PolicyList.PolicyElements[3].STM2, list.PolicyElements[3].STM2)
+		if idx > 0 {
+			require.NoError(t, list.Verify())
+		} else {
+			require.Error(t, list.Verify())
+		}
+	}
+	reencoded, err := parsed.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, b, reencoded)
+
+	policyHash, err := parsed.PolicyHash(crypto.SHA256)
+	require.NoError(t, err)
+	unsignedBytes, err := unsigned.MarshalBinary()
+	require.NoError(t, err)
+	h := sha256.New()
+	m := sha256.Sum256(unsignedBytes)
+	h.Write(m[:])
+	for _, list := range lists[1:] {
+		m := sha256.Sum256(list.TPM20PolicyList.Signature.PubkeyValue)
+		h.Write(m[:])
+	}
+	require.Equal(t, h.Sum(nil), policyHash)
+
+	t.Run("tampered", func(t *testing.T) {
+		for _, list := range lists[1:] {
+			list := list.TPM20PolicyList
+			list.PolicyElements[0].MLE2.SINITMinVersion++
+			require.Error(t, list.Verify())
+		}
+	})
+}
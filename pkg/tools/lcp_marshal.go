@@ -0,0 +1,263 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (p *LCPPolicy) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	if err := binary.Write(buf, binary.LittleEndian, *p); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// Only the first digest-size bytes of PolicyHash are written (the size
+// depends on HashAlg), the same as it is stored in the PO/PS index.
+func (p *LCPPolicy2) MarshalBinary() ([]byte, error) {
+	h, err := p.HashAlg.Hash()
+	if err != nil {
+		return nil, err
+	}
+	if h.Size() > len(p.PolicyHash) {
+		return nil, fmt.Errorf("policy hash algorithm %s is not supported", p.HashAlg)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	hdr := struct {
+		Version                uint16
+		HashAlg                uint16
+		PolicyType             LCPPolicyType
+		SINITMinVersion        uint8
+		DataRevocationCounters [LCPMaxLists]uint16
+		PolicyControl          uint32
+		MaxSINITMinVersion     uint8
+		Reserved               uint8
+		LcpHashAlgMask         uint16
+		LcpSignAlgMask         LCPPol2Sig
+		Reserved2              uint32
+	}{
+		p.Version, uint16(p.HashAlg), p.PolicyType, p.SINITMinVersion, p.DataRevocationCounters,
+		p.PolicyControl, p.MaxSINITMinVersion, p.Reserved, p.LcpHashAlgMask, p.LcpSignAlgMask, p.Reserved2,
+	}
+	if err := binary.Write(buf, binary.LittleEndian, hdr); err != nil {
+		return nil, err
+	}
+	buf.Write(p.PolicyHash[:h.Size()])
+	return buf.Bytes(), nil
+}
+
+// digest returns the value of the hash set in LCPHash.
+func (p *LCPHash) digest() ([]byte, error) {
+	switch {
+	case p.Sha1 != nil:
+		return p.Sha1[:], nil
+	case p.Sha256 != nil:
+		return p.Sha256[:], nil
+	case p.Sha384 != nil:
+		return p.Sha384[:], nil
+	case p.Sha512 != nil:
+		return p.Sha512[:], nil
+	case p.SM3 != nil:
+		return p.SM3[:], nil
+	}
+	return nil, fmt.Errorf("no hash is set")
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// Field Size and the element counters (like NumHashes) are recalculated.
+func (element *LCPPolicyElement) MarshalBinary() ([]byte, error) {
+	body := bytes.NewBuffer(nil)
+	var err error
+	switch {
+	case element.MLE != nil:
+		err = element.MLE.writeTo(body)
+	case element.SBIOS != nil:
+		err = element.SBIOS.writeTo(body)
+	case element.PCONF != nil:
+		err = element.PCONF.writeTo(body)
+	case element.Custom != nil:
+		err = element.Custom.writeTo(body)
+	case element.MLE2 != nil:
+		err = element.MLE2.writeTo(body)
+	case element.PCONF2 != nil:
+		err = element.PCONF2.writeTo(body)
+	case element.SBIOS2 != nil:
+		err = element.SBIOS2.writeTo(body)
+	case element.STM2 != nil:
+		err = element.STM2.writeTo(body)
+	default:
+		return nil, fmt.Errorf("policy element type 0x%X has no content", element.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal policy element type 0x%X: %w", element.Type, err)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	hdr := []uint32{uint32(LCPPolicyElementHeaderSize + body.Len()), element.Type, element.PolicyEltControl}
+	if err := binary.Write(buf, binary.LittleEndian, hdr); err != nil {
+		return nil, err
+	}
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+func (pol *LCPPolicyMLE) writeTo(buf *bytes.Buffer) error {
+	hdr := struct {
+		SINITMinVersion uint8
+		HashAlg         uint8
+		NumHashes       uint16
+	}{pol.SINITMinVersion, pol.HashAlg, uint16(len(pol.Hashes))}
+	if err := binary.Write(buf, binary.LittleEndian, hdr); err != nil {
+		return err
+	}
+	return binary.Write(buf, binary.LittleEndian, pol.Hashes)
+}
+
+func (pol *LCPPolicySBIOS) writeTo(buf *bytes.Buffer) error {
+	if pol.HashAlg != LCPPolHAlgSHA1 {
+		return fmt.Errorf("unsupported hash algorithm: %x", pol.HashAlg)
+	}
+	buf.WriteByte(pol.HashAlg)
+	buf.Write(pol.Reserved1[:])
+	fallbackHash, err := pol.FallbackHash.digest()
+	if err != nil {
+		return fmt.Errorf("invalid fallback hash: %w", err)
+	}
+	buf.Write(fallbackHash)
+	if err := binary.Write(buf, binary.LittleEndian, []uint16{pol.Reserved2, uint16(len(pol.Hashes))}); err != nil {
+		return err
+	}
+	for idx := range pol.Hashes {
+		hash, err := pol.Hashes[idx].digest()
+		if err != nil {
+			return fmt.Errorf("invalid hash %d: %w", idx, err)
+		}
+		buf.Write(hash)
+	}
+	return nil
+}
+
+func (pol *LCPPolicyPCONF) writeTo(buf *bytes.Buffer) error {
+	if err := binary.Write(buf, binary.LittleEndian, uint16(len(pol.PCRInfos))); err != nil {
+		return err
+	}
+	for idx := range pol.PCRInfos {
+		if err := pol.PCRInfos[idx].writeTo(buf); err != nil {
+			return fmt.Errorf("invalid PCR info %d: %w", idx, err)
+		}
+	}
+	return nil
+}
+
+func (info *TPMPCRInfoShort) writeTo(buf *bytes.Buffer) error {
+	// TPM 1.2 has 24 PCRs, so the selection is at least 3 bytes
+	bitmap := make([]byte, 3)
+	for _, pcr := range info.PCRSelect {
+		if pcr < 0 || pcr >= 0x10000 {
+			return fmt.Errorf("invalid PCR index: %d", pcr)
+		}
+		for pcr/8 >= len(bitmap) {
+			bitmap = append(bitmap, 0)
+		}
+		bitmap[pcr/8] |= 1 << uint(pcr%8)
+	}
+	if err := binary.Write(buf, binary.BigEndian, uint16(len(bitmap))); err != nil {
+		return err
+	}
+	buf.Write(bitmap)
+	buf.WriteByte(info.LocalityAtRelease)
+	buf.Write(info.DigestAtRelease[:])
+	return nil
+}
+
+func (pol *LCPPolicyCustom) writeTo(buf *bytes.Buffer) error {
+	uuid := &pol.UUID
+	if err := binary.Write(buf, binary.LittleEndian, []uint32{uuid.data1}); err != nil {
+		return err
+	}
+	if err := binary.Write(buf, binary.LittleEndian, []uint16{uuid.data2, uuid.data3, uuid.data4}); err != nil {
+		return err
+	}
+	buf.Write(uuid.data5[:])
+	buf.Write(pol.Data)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// Fields PolicyElementSize and LCPSignature.PubkeySize are recalculated.
+func (list *LCPPolicyList) MarshalBinary() ([]byte, error) {
+	elements := bytes.NewBuffer(nil)
+	for idx := range list.PolicyElements {
+		b, err := list.PolicyElements[idx].MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal policy element %d: %w", idx, err)
+		}
+		elements.Write(b)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	hdr := struct {
+		Version           uint16
+		Reserved          uint8
+		SignaturAlg       uint8
+		PolicyElementSize uint32
+	}{list.Version, list.Reserved, list.SignaturAlg, uint32(elements.Len())}
+	if err := binary.Write(buf, binary.LittleEndian, hdr); err != nil {
+		return nil, err
+	}
+	buf.Write(elements.Bytes())
+
+	switch list.SignaturAlg {
+	case LCPSignatureAlgNone:
+		return buf.Bytes(), nil
+	case LCPSignatureAlgRSAPKCS15:
+	default:
+		return nil, fmt.Errorf("unknown signature algorithm: %x", list.SignaturAlg)
+	}
+	sig := list.Signature
+	if sig == nil {
+		return nil, fmt.Errorf("the list is signed, but there is no signature")
+	}
+	if len(sig.SigBlock) != len(sig.PubkeyValue) {
+		return nil, fmt.Errorf("the sizes of the public key (%d) and the signature (%d) do not match", len(sig.PubkeyValue), len(sig.SigBlock))
+	}
+	if err := binary.Write(buf, binary.LittleEndian, []uint16{sig.RevocationCounter, uint16(len(sig.PubkeyValue))}); err != nil {
+		return nil, err
+	}
+	buf.Write(sig.PubkeyValue)
+	buf.Write(sig.SigBlock)
+	return buf.Bytes(), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// Field NumLists is recalculated.
+func (pd *LCPPolicyData) MarshalBinary() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	buf.Write(pd.FileSignature[:])
+	buf.Write(pd.Reserved[:])
+	buf.WriteByte(uint8(len(pd.PolicyLists)))
+	for idx := range pd.PolicyLists {
+		list := &pd.PolicyLists[idx]
+		var b []byte
+		var err error
+		if list.IsTPM20() {
+			b, err = list.TPM20PolicyList.MarshalBinary()
+		} else {
+			b, err = list.TPM12PolicyList.MarshalBinary()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal list %d: %w", idx, err)
+		}
+		buf.Write(b)
+	}
+	return buf.Bytes(), nil
+}
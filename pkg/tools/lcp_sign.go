@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"math/big"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/tjfoc/gmsm/sm2"
+)
+
+// lcpRSAHashAlgs are the hash algorithms could be used in RSA signatures of
+// policy lists. The algorithm is not stored in LCP_POLICY_LIST2, so all of
+// them are tried on verification.
+var lcpRSAHashAlgs = []crypto.Hash{crypto.SHA256, crypto.SHA384, crypto.SHA1, crypto.SHA512}
+
+// SignedData returns the part of the marshaled list which is covered by the
+// signature: everything except SigBlock.
+func (list *LCPPolicyList2) SignedData() ([]byte, error) {
+	b, err := list.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if list.Signature == nil {
+		return b, nil
+	}
+	return b[:len(b)-len(list.Signature.SigBlock)], nil
+}
+
+// Sign signs the list with the private key and sets fields SignaturAlg
+// and Signature accordingly. Supported keys are *rsa.PrivateKey
+// (RSASSA-PKCS1-v1_5 with `hashAlg`), *ecdsa.PrivateKey (ECDSA on P-256
+// with SHA256 or on P-384 with SHA384) and *sm2.PrivateKey (SM2 with SM3).
+// `hashAlg` is ignored for ECC keys.
+func (list *LCPPolicyList2) Sign(privKey crypto.Signer, revocationCounter uint16, hashAlg crypto.Hash) error {
+	sig := &LCPSignature2{
+		RevocationCounter: revocationCounter,
+	}
+	switch privKey := privKey.(type) {
+	case *rsa.PrivateKey:
+		list.SignaturAlg = uint16(tpm2.AlgRSASSA)
+		sig.PubkeyValue = reverseCopy(privKey.N.Bytes())
+	case *ecdsa.PrivateKey:
+		list.SignaturAlg = uint16(tpm2.AlgECDSA)
+		sig.PubkeyValue = marshalLCPECCPoint(privKey.Curve, privKey.X, privKey.Y)
+	case *sm2.PrivateKey:
+		list.SignaturAlg = uint16(LCPSignatureAlgSM2)
+		sig.PubkeyValue = marshalLCPECCPoint(privKey.Curve, privKey.X, privKey.Y)
+	default:
+		return fmt.Errorf("unsupported key type: %T", privKey)
+	}
+	sig.PubkeySize = uint16(len(sig.PubkeyValue))
+	sig.SigBlock = make([]byte, len(sig.PubkeyValue))
+	list.Signature = sig
+
+	data, err := list.SignedData()
+	if err != nil {
+		return fmt.Errorf("unable to marshal the list: %w", err)
+	}
+
+	switch privKey := privKey.(type) {
+	case *rsa.PrivateKey:
+		if !hashAlg.Available() {
+			return fmt.Errorf("hash algorithm %v is not available", hashAlg)
+		}
+		h := hashAlg.New()
+		h.Write(data)
+		sigBlock, err := rsa.SignPKCS1v15(rand.Reader, privKey, hashAlg, h.Sum(nil))
+		if err != nil {
+			return fmt.Errorf("unable to sign: %w", err)
+		}
+		sig.SigBlock = reverseCopy(sigBlock)
+	case *ecdsa.PrivateKey:
+		hashAlg, err := lcpECDSAHashAlg(privKey.Curve)
+		if err != nil {
+			return err
+		}
+		h := hashAlg.New()
+		h.Write(data)
+		r, s, err := ecdsa.Sign(rand.Reader, privKey, h.Sum(nil))
+		if err != nil {
+			return fmt.Errorf("unable to sign: %w", err)
+		}
+		sig.SigBlock = marshalLCPECCPoint(privKey.Curve, r, s)
+	case *sm2.PrivateKey:
+		r, s, err := sm2.Sm2Sign(privKey, data, nil, rand.Reader)
+		if err != nil {
+			return fmt.Errorf("unable to sign: %w", err)
+		}
+		sig.SigBlock = marshalLCPECCPoint(privKey.Curve, r, s)
+	}
+	return nil
+}
+
+// PublicKey returns the public key the list is signed with.
+func (list *LCPPolicyList2) PublicKey() (crypto.PublicKey, error) {
+	sig := list.Signature
+	if sig == nil {
+		return nil, fmt.Errorf("the list is not signed")
+	}
+	switch sigAlg := tpm2.Algorithm(list.SignaturAlg); sigAlg {
+	case tpm2.AlgRSASSA:
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(reverseCopy(sig.PubkeyValue)),
+			E: 0x10001,
+		}, nil
+	case tpm2.AlgECDSA, LCPSignatureAlgSM2:
+		var curve elliptic.Curve
+		switch {
+		case sigAlg == LCPSignatureAlgSM2:
+			curve = sm2.P256Sm2()
+		case len(sig.PubkeyValue) == 64:
+			curve = elliptic.P256()
+		case len(sig.PubkeyValue) == 96:
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported ECC public key size: %d", len(sig.PubkeyValue))
+		}
+		x, y, err := unmarshalLCPECCPoint(sig.PubkeyValue)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("the public key is not on the curve")
+		}
+		if sigAlg == LCPSignatureAlgSM2 {
+			return &sm2.PublicKey{Curve: curve, X: x, Y: y}, nil
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported signature algorithm: %s", sigAlg)
+	}
+}
+
+// Verify verifies the signature of the list.
+func (list *LCPPolicyList2) Verify() error {
+	pubKey, err := list.PublicKey()
+	if err != nil {
+		return err
+	}
+	if len(list.Signature.SigBlock) != len(list.Signature.PubkeyValue) {
+		return fmt.Errorf("invalid signature size: %d", len(list.Signature.SigBlock))
+	}
+	data, err := list.SignedData()
+	if err != nil {
+		return fmt.Errorf("unable to marshal the list: %w", err)
+	}
+
+	switch pubKey := pubKey.(type) {
+	case *rsa.PublicKey:
+		sigBlock := reverseCopy(list.Signature.SigBlock)
+		for _, hashAlg := range lcpRSAHashAlgs {
+			h := hashAlg.New()
+			h.Write(data)
+			if rsa.VerifyPKCS1v15(pubKey, hashAlg, h.Sum(nil), sigBlock) == nil {
+				return nil
+			}
+		}
+	case *ecdsa.PublicKey:
+		hashAlg, err := lcpECDSAHashAlg(pubKey.Curve)
+		if err != nil {
+			return err
+		}
+		h := hashAlg.New()
+		h.Write(data)
+		r, s, err := unmarshalLCPECCPoint(list.Signature.SigBlock)
+		if err != nil {
+			return err
+		}
+		if ecdsa.Verify(pubKey, h.Sum(nil), r, s) {
+			return nil
+		}
+	case *sm2.PublicKey:
+		r, s, err := unmarshalLCPECCPoint(list.Signature.SigBlock)
+		if err != nil {
+			return err
+		}
+		if sm2.Sm2Verify(pubKey, data, nil, r, s) {
+			return nil
+		}
+	}
+	return fmt.Errorf("the signature of the list is invalid")
+}
+
+func lcpECDSAHashAlg(curve elliptic.Curve) (crypto.Hash, error) {
+	switch curve {
+	case elliptic.P256():
+		return crypto.SHA256, nil
+	case elliptic.P384():
+		return crypto.SHA384, nil
+	}
+	return 0, fmt.Errorf("unsupported curve: %s", curve.Params().Name)
+}
+
+// marshalLCPECCPoint encodes a pair of values (a point or a signature) as
+// two little-endian numbers of the curve size.
+func marshalLCPECCPoint(curve elliptic.Curve, a, b *big.Int) []byte {
+	size := (curve.Params().BitSize + 7) / 8
+	result := make([]byte, 2*size)
+	copy(result, reverseCopy(a.Bytes()))
+	copy(result[size:], reverseCopy(b.Bytes()))
+	return result
+}
+
+func unmarshalLCPECCPoint(b []byte) (*big.Int, *big.Int, error) {
+	if len(b) == 0 || len(b)%2 != 0 {
+		return nil, nil, fmt.Errorf("invalid ECC value size: %d", len(b))
+	}
+	size := len(b) / 2
+	return new(big.Int).SetBytes(reverseCopy(b[:size])), new(big.Int).SetBytes(reverseCopy(b[size:])), nil
+}
+
+func reverseCopy(b []byte) []byte {
+	r := make([]byte, len(b))
+	for idx := range b {
+		r[len(b)-1-idx] = b[idx]
+	}
+	return r
+}
@@ -1,9 +1,12 @@
 package tools
 
 import (
+	"bytes"
 	"crypto"
 	"io/ioutil"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestLCPParser(t *testing.T) {
@@ -85,3 +88,85 @@ func TestLCPPolv2Gen(t *testing.T) {
 	}
 	lcp2.PrettyPrint()
 }
+
+func TestLCPPolicyMarshalBinary(t *testing.T) {
+	for _, filename := range []string{"pol.bin", "pol2.bin", "pol3.bin"} {
+		t.Run(filename, func(t *testing.T) {
+			file, err := ioutil.ReadFile("./tests/" + filename)
+			require.NoError(t, err)
+
+			pol, pol2, err := ParsePolicy(file)
+			require.NoError(t, err)
+			var b []byte
+			if pol != nil {
+				b, err = pol.MarshalBinary()
+			} else {
+				b, err = pol2.MarshalBinary()
+			}
+			require.NoError(t, err)
+			// pol3.bin is truncated: it has no PolicyHash (which is zero after parsing)
+			require.Equal(t, file, b[:len(file)])
+			require.Equal(t, make([]byte, len(b)-len(file)), b[len(file):])
+
+			reparsed, reparsed2, err := ParsePolicy(b)
+			require.NoError(t, err)
+			require.Equal(t, pol, reparsed)
+			require.Equal(t, pol2, reparsed2)
+		})
+	}
+
+	t.Run("patch", func(t *testing.T) {
+		file, err := ioutil.ReadFile("./tests/pol3.bin")
+		require.NoError(t, err)
+		_, pol2, err := ParsePolicy(file)
+		require.NoError(t, err)
+
+		pol2.SINITMinVersion = 7
+		b, err := pol2.MarshalBinary()
+		require.NoError(t, err)
+		_, patched, err := ParsePolicy(b)
+		require.NoError(t, err)
+		require.Equal(t, uint8(7), patched.SINITMinVersion)
+	})
+}
+
+func TestLCPPolicyDataMarshalBinary(t *testing.T) {
+	for _, filename := range []string{"poldata.bin", "poldata2.bin"} {
+		t.Run(filename, func(t *testing.T) {
+			file, err := ioutil.ReadFile("./tests/" + filename)
+			require.NoError(t, err)
+
+			poldata, err := ParsePolicyData(file)
+			require.NoError(t, err)
+			b, err := poldata.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, file, b)
+
+			for idx := range poldata.PolicyLists {
+				list := &poldata.PolicyLists[idx].TPM12PolicyList
+				for _, element := range list.PolicyElements {
+					b, err := element.MarshalBinary()
+					require.NoError(t, err)
+					require.Len(t, b, int(element.Size))
+				}
+			}
+		})
+	}
+
+	t.Run("custom_element", func(t *testing.T) {
+		element := LCPPolicyElement{
+			Type: LCPPolicyElementCustom,
+			Custom: &LCPPolicyCustom{
+				UUID: LCPUUID{data1: 1, data2: 2, data3: 3, data4: 4, data5: [6]uint8{5, 6}},
+				Data: []byte{1, 2, 3, 4, 5},
+			},
+		}
+		b, err := element.MarshalBinary()
+		require.NoError(t, err)
+		require.Len(t, b, LCPPolicyElementHeaderSize+16+5)
+
+		var parsed LCPPolicyElement
+		require.NoError(t, parsePolicyElement(bytes.NewReader(b), &parsed))
+		require.Equal(t, element.Custom, parsed.Custom)
+	})
+}
@@ -116,6 +116,8 @@ type TXTBiosData struct {
 	NumLogProcs   uint32
 	SinitFlags    uint32
 	MleFlags      *TXTBiosMLEFlags
+	// Version >= 4
+	ExtDataElements []TXTHeapExtDataElement
 }
 
 //TXTBiosMLEFlags holds the decoded BIOSDATA region MLE flags as read from TXT config space
@@ -334,26 +336,43 @@ func ParseBIOSDataRegion(heap []byte) (TXTBiosData, error) {
 		return ret, err
 	}
 
-	if ret.Version >= 3 && ret.Version < 5 {
+	if ret.Version >= 3 {
+		var mleFlags uint32
+
 		err = binary.Read(buf, binary.LittleEndian, &ret.SinitFlags)
 		if err != nil {
 			return ret, err
 		}
-	}
-
-	if ret.Version >= 5 {
-		var mleFlags uint32
-		var flags TXTBiosMLEFlags
 
+		// The upper half of the flags is reserved before version 5
 		err = binary.Read(buf, binary.LittleEndian, &mleFlags)
 		if err != nil {
 			return ret, err
 		}
 
-		flags.SupportsACPIPPI = mleFlags&1 != 0
-		flags.IsClientState = mleFlags&6 == 2
-		flags.IsServerState = mleFlags&6 == 4
-		ret.MleFlags = &flags
+		if ret.Version >= 5 {
+			var flags TXTBiosMLEFlags
+			flags.SupportsACPIPPI = mleFlags&1 != 0
+			flags.IsLegacyState = mleFlags&6 == 0
+			flags.IsClientState = mleFlags&6 == 2
+			flags.IsServerState = mleFlags&6 == 4
+			ret.MleFlags = &flags
+		}
+	}
+
+	if ret.Version >= 4 {
+		end := uint64(len(heap))
+		if biosDataSize < end {
+			end = biosDataSize
+		}
+		start := uint64(len(heap) - buf.Len())
+		if start > end {
+			return ret, fmt.Errorf("BIOS DATA region size 0x%x is too small", biosDataSize)
+		}
+		ret.ExtDataElements, err = parseTXTHeapExtDataElements(heap[start:end])
+		if err != nil {
+			return ret, err
+		}
 	}
 
 	return ret, nil
@@ -0,0 +1,662 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/errors"
+	"github.com/9elements/go-linux-lowlevel-hw/pkg/hwapi"
+)
+
+// The TXT heap consists of four regions which are placed one after
+// another. Each region starts with a 64 bit size field, which includes
+// the size field itself.
+//
+// See "Intel TXT Software Development Guide", Appendix C "Intel TXT Heap
+// Memory" and tboot's include/txt/heap.h.
+const (
+	txtHeapRegionSizeFieldSize = 8
+	txtHeapMDRSize             = 24
+)
+
+// TXTSinitMleDataHeaderSize is the size of the fixed part of the latest
+// supported SinitMleData version including the size field. SINIT places
+// the MDRs, the copy of the ACPI DMAR table and the extended data elements
+// (e.g. the copy of the ACPI MADT) after it.
+const TXTSinitMleDataHeaderSize = 8 + 4 + 20 + 4 + 8 + 4*20 + 4*7 + 4
+
+// TXTHeapExtDataHeaderSize is the size of the header of an extended data element
+const TXTHeapExtDataHeaderSize = 8
+
+// Supported versions of the TXT heap regions
+const (
+	TXTBiosDataMinVersion     = 2
+	TXTBiosDataMaxVersion     = 6
+	TXTOsSinitDataMinVersion  = 4
+	TXTOsSinitDataMaxVersion  = 7
+	TXTSinitMleDataMinVersion = 5
+	TXTSinitMleDataMaxVersion = 9
+)
+
+// TXTHeapRegion describes the position of a region inside the TXT heap
+type TXTHeapRegion struct {
+	// Offset is the offset of the size field of the region
+	// from the start of the heap
+	Offset uint64
+	// Size is the size of the region including the size field
+	Size uint64
+}
+
+// TXTHeap holds the decoded TXT heap
+type TXTHeap struct {
+	// Size is the size of the whole heap
+	Size uint64
+	// Regions contains the BiosData, OsMleData, OsSinitData and SinitMleData
+	// regions in this order
+	Regions [4]TXTHeapRegion
+
+	BiosData TXTBiosData
+	// OsMleData is defined by the MLE, hence it is kept as raw data
+	// (without the size field)
+	OsMleData    []byte
+	OsSinitData  *TXTOsSinitData
+	SinitMleData *TXTSinitMleData
+}
+
+// TXTOsSinitData holds the decoded OS to SINIT data region
+type TXTOsSinitData struct {
+	Version          uint32
+	Flags            uint32
+	MLEPageTableBase uint64
+	MLESize          uint64
+	MLEHeaderBase    uint64
+	PMRLowBase       uint64
+	PMRLowSize       uint64
+	PMRHighBase      uint64
+	PMRHighSize      uint64
+	LCPPOBase        uint64
+	LCPPOSize        uint64
+	Capabilities     uint32
+	// Version >= 5
+	RSDPPtr uint64
+	// Version >= 6
+	ExtDataElements []TXTHeapExtDataElement
+}
+
+// TXTSinitMleData holds the decoded SINIT to MLE data region
+type TXTSinitMleData struct {
+	Version          uint32
+	BiosACMID        [20]byte
+	EdxSenterFlags   uint32
+	MsegValid        uint64
+	SinitHash        [20]byte
+	MleHash          [20]byte
+	StmHash          [20]byte
+	LcpPolicyHash    [20]byte
+	LcpPolicyControl uint32
+	RlpWakeupAddr    uint32
+	Reserved         uint32
+	NumMDRs          uint32
+	// MDRsOffset is relative to the size field of the region
+	MDRsOffset uint32
+	// NumVtdDmars is the size of the DMAR table copy in bytes
+	NumVtdDmars uint32
+	// VtdDmarsOffset is relative to the size field of the region
+	VtdDmarsOffset uint32
+	// Version >= 8
+	ProcScrtmStatus uint32
+	// Version >= 9
+	ExtDataElements []TXTHeapExtDataElement
+
+	MDRs []TXTHeapMDR
+	// DMAR is the copy of the ACPI DMAR table made by SINIT
+	DMAR []byte
+}
+
+// TXTHeapMDRType is the memory type of a SINIT memory descriptor record
+type TXTHeapMDRType uint8
+
+// Memory types of SINIT memory descriptor records
+const (
+	TXTHeapMDRGood             TXTHeapMDRType = 0
+	TXTHeapMDRSMRAMOverlay     TXTHeapMDRType = 1
+	TXTHeapMDRSMRAMNonOverlay  TXTHeapMDRType = 2
+	TXTHeapMDRPCIeConfigSpace  TXTHeapMDRType = 3
+	TXTHeapMDRProtectedFromDMA TXTHeapMDRType = 4
+	txtHeapMDRTypeMaxKnown                    = TXTHeapMDRProtectedFromDMA
+)
+
+func (t TXTHeapMDRType) String() string {
+	switch t {
+	case TXTHeapMDRGood:
+		return "good"
+	case TXTHeapMDRSMRAMOverlay:
+		return "SMRAM overlay"
+	case TXTHeapMDRSMRAMNonOverlay:
+		return "SMRAM non-overlay"
+	case TXTHeapMDRPCIeConfigSpace:
+		return "PCIe config space"
+	case TXTHeapMDRProtectedFromDMA:
+		return "protected from DMA"
+	}
+	return fmt.Sprintf("unknown (%d)", uint8(t))
+}
+
+// TXTHeapMDR is a SINIT memory descriptor record
+type TXTHeapMDR struct {
+	Base     uint64
+	Length   uint64
+	MemType  TXTHeapMDRType
+	Reserved [7]byte
+}
+
+// TXTHeapExtDataType is the type of an extended data element
+type TXTHeapExtDataType uint32
+
+// Types of extended data elements
+const (
+	TXTHeapExtDataEnd               TXTHeapExtDataType = 0
+	TXTHeapExtDataBIOSSpecVer       TXTHeapExtDataType = 1
+	TXTHeapExtDataACM               TXTHeapExtDataType = 2
+	TXTHeapExtDataSTM               TXTHeapExtDataType = 3
+	TXTHeapExtDataCustom            TXTHeapExtDataType = 4
+	TXTHeapExtDataTPMEventLogPtr    TXTHeapExtDataType = 5
+	TXTHeapExtDataMADT              TXTHeapExtDataType = 6
+	TXTHeapExtDataTPMEventLogPtr2   TXTHeapExtDataType = 7
+	TXTHeapExtDataTPMEventLogPtr2_1 TXTHeapExtDataType = 8 //nolint
+	TXTHeapExtDataMCFG              TXTHeapExtDataType = 9
+)
+
+func (t TXTHeapExtDataType) String() string {
+	switch t {
+	case TXTHeapExtDataEnd:
+		return "END"
+	case TXTHeapExtDataBIOSSpecVer:
+		return "BIOS_SPEC_VER"
+	case TXTHeapExtDataACM:
+		return "ACM"
+	case TXTHeapExtDataSTM:
+		return "STM"
+	case TXTHeapExtDataCustom:
+		return "CUSTOM"
+	case TXTHeapExtDataTPMEventLogPtr:
+		return "TPM_EVENT_LOG_PTR"
+	case TXTHeapExtDataMADT:
+		return "MADT"
+	case TXTHeapExtDataTPMEventLogPtr2:
+		return "TPM_EVENT_LOG_PTR_2"
+	case TXTHeapExtDataTPMEventLogPtr2_1:
+		return "TPM_EVENT_LOG_PTR_2_1"
+	case TXTHeapExtDataMCFG:
+		return "MCFG"
+	}
+	return fmt.Sprintf("unknown (%d)", uint32(t))
+}
+
+// TXTHeapExtDataElement is an extended data element of a TXT heap region.
+// Value holds the decoded data for known types, which is one of
+// TXTHeapBIOSSpecVersion, TXTHeapACMs, TXTHeapCustom, TXTHeapEventLogPointer,
+// TXTHeapEventLogPointer2 or TXTHeapEventLogPointer2_1. The data of the
+// other types (e.g. the ACPI table copies) is only available in Data.
+type TXTHeapExtDataElement struct {
+	Type TXTHeapExtDataType
+	// Data is the payload of the element without the header
+	Data  []byte
+	Value interface{} `json:",omitempty" yaml:",omitempty"`
+}
+
+// TXTHeapBIOSSpecVersion is the value of a BIOS_SPEC_VER element
+type TXTHeapBIOSSpecVersion struct {
+	Major    uint16
+	Minor    uint16
+	Revision uint16
+}
+
+// TXTHeapACMs is the value of an ACM element and
+// contains the addresses of the ACMs
+type TXTHeapACMs []uint64
+
+// TXTHeapCustom is the value of a CUSTOM element
+type TXTHeapCustom struct {
+	UUID [16]byte
+	Data []byte
+}
+
+// TXTHeapEventLogPointer is the value of a TPM_EVENT_LOG_PTR element
+// and contains the address of the TPM 1.2 event log container
+type TXTHeapEventLogPointer uint64
+
+// TXTHeapEventLogDescriptor describes the event log of a single
+// hash algorithm in a TPM_EVENT_LOG_PTR_2 element
+type TXTHeapEventLogDescriptor struct {
+	Algorithm       uint16
+	Reserved        uint16
+	PhysAddr        uint64
+	Size            uint32
+	PCREventsOffset uint32
+	NextEventOffset uint32
+}
+
+// TXTHeapEventLogPointer2 is the value of a TPM_EVENT_LOG_PTR_2 element
+type TXTHeapEventLogPointer2 []TXTHeapEventLogDescriptor
+
+// TXTHeapEventLogPointer2_1 is the value of a TPM_EVENT_LOG_PTR_2_1 element,
+// which points to an event log in the TCG crypto agile format
+type TXTHeapEventLogPointer2_1 struct { //nolint
+	PhysAddr          uint64
+	AllocatedSize     uint32
+	FirstRecordOffset uint32
+	NextRecordOffset  uint32
+}
+
+// FetchTXTHeap returns a raw copy of the TXT heap
+func FetchTXTHeap(txtAPI hwapi.LowLevelHardwareInterfaces) ([]byte, error) {
+	buf, err := FetchTXTRegs(txtAPI)
+	if err != nil {
+		return nil, err
+	}
+	regs, err := ParseTXTRegs(buf)
+	if err != nil {
+		return nil, err
+	}
+	return ReadTXTHeap(txtAPI, regs)
+}
+
+// ReadTXTHeap returns a raw copy of the TXT heap described by the registers
+func ReadTXTHeap(txtAPI hwapi.LowLevelHardwareInterfaces, regs TXTRegisterSpace) ([]byte, error) {
+	if regs.HeapBase == 0 || regs.HeapSize == 0 {
+		return nil, fmt.Errorf("TXT heap is not configured (base: 0x%x, size: 0x%x)", regs.HeapBase, regs.HeapSize)
+	}
+	heap := make([]byte, regs.HeapSize)
+	if err := txtAPI.ReadPhysBuf(int64(regs.HeapBase), heap); err != nil {
+		return nil, err
+	}
+	return heap, nil
+}
+
+// ParseTXTHeap decodes a raw copy of the TXT heap. The regions are decoded
+// one after another. If a region could not be decoded an error is returned
+// together with the regions decoded so far. Before the MLE was launched only
+// the BiosData region is populated, so an error for the other regions is
+// expected in that case.
+func ParseTXTHeap(heap []byte) (*TXTHeap, error) {
+	ret := &TXTHeap{Size: uint64(len(heap))}
+
+	var offset uint64
+	for idx := range ret.Regions {
+		region, err := txtHeapRegionAt(heap, offset)
+		if err != nil {
+			return ret, fmt.Errorf("%s region: %w", txtHeapRegionNames[idx], err)
+		}
+		ret.Regions[idx] = TXTHeapRegion{Offset: offset, Size: uint64(len(region))}
+		offset += uint64(len(region))
+
+		switch idx {
+		case 0:
+			ret.BiosData, err = ParseBIOSDataRegion(region)
+		case 1:
+			ret.OsMleData = region[txtHeapRegionSizeFieldSize:]
+		case 2:
+			ret.OsSinitData, err = ParseOsSinitDataRegion(region)
+		case 3:
+			ret.SinitMleData, err = ParseSinitMleDataRegion(region)
+		}
+		if err != nil {
+			return ret, fmt.Errorf("%s region: %w", txtHeapRegionNames[idx], err)
+		}
+	}
+	return ret, nil
+}
+
+var txtHeapRegionNames = [...]string{"BiosData", "OsMleData", "OsSinitData", "SinitMleData"}
+
+// txtHeapRegionAt returns the region at the given offset including its size field
+func txtHeapRegionAt(heap []byte, offset uint64) ([]byte, error) {
+	if offset+txtHeapRegionSizeFieldSize > uint64(len(heap)) {
+		return nil, fmt.Errorf("size field at offset 0x%x is outside of the heap (size 0x%x)", offset, len(heap))
+	}
+	size := binary.LittleEndian.Uint64(heap[offset:])
+	if size < txtHeapRegionSizeFieldSize {
+		return nil, fmt.Errorf("invalid size 0x%x at offset 0x%x", size, offset)
+	}
+	if size > uint64(len(heap))-offset {
+		return nil, fmt.Errorf("size 0x%x at offset 0x%x exceeds the heap (size 0x%x)", size, offset, len(heap))
+	}
+	return heap[offset : offset+size], nil
+}
+
+// ParseOsSinitDataRegion decodes a raw copy of the OsSinitData region
+// including its size field
+func ParseOsSinitDataRegion(region []byte) (*TXTOsSinitData, error) {
+	var ret TXTOsSinitData
+	buf := bytes.NewReader(region)
+
+	var size uint64
+	if err := binary.Read(buf, binary.LittleEndian, &size); err != nil {
+		return nil, err
+	}
+
+	fields := []interface{}{
+		&ret.Version,
+		&ret.Flags,
+		&ret.MLEPageTableBase,
+		&ret.MLESize,
+		&ret.MLEHeaderBase,
+		&ret.PMRLowBase,
+		&ret.PMRLowSize,
+		&ret.PMRHighBase,
+		&ret.PMRHighSize,
+		&ret.LCPPOBase,
+		&ret.LCPPOSize,
+		&ret.Capabilities,
+	}
+	for _, field := range fields {
+		if err := binary.Read(buf, binary.LittleEndian, field); err != nil {
+			return nil, err
+		}
+	}
+
+	if ret.Version >= 5 {
+		if err := binary.Read(buf, binary.LittleEndian, &ret.RSDPPtr); err != nil {
+			return nil, err
+		}
+	}
+
+	if ret.Version >= 6 {
+		var err error
+		ret.ExtDataElements, err = parseTXTHeapExtDataElements(region[len(region)-buf.Len():])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &ret, nil
+}
+
+// ParseSinitMleDataRegion decodes a raw copy of the SinitMleData region
+// including its size field
+func ParseSinitMleDataRegion(region []byte) (*TXTSinitMleData, error) {
+	var ret TXTSinitMleData
+	buf := bytes.NewReader(region)
+
+	var size uint64
+	if err := binary.Read(buf, binary.LittleEndian, &size); err != nil {
+		return nil, err
+	}
+
+	fields := []interface{}{
+		&ret.Version,
+		&ret.BiosACMID,
+		&ret.EdxSenterFlags,
+		&ret.MsegValid,
+		&ret.SinitHash,
+		&ret.MleHash,
+		&ret.StmHash,
+		&ret.LcpPolicyHash,
+		&ret.LcpPolicyControl,
+		&ret.RlpWakeupAddr,
+		&ret.Reserved,
+		&ret.NumMDRs,
+		&ret.MDRsOffset,
+		&ret.NumVtdDmars,
+		&ret.VtdDmarsOffset,
+	}
+	for _, field := range fields {
+		if err := binary.Read(buf, binary.LittleEndian, field); err != nil {
+			return nil, err
+		}
+	}
+
+	if ret.Version >= 8 {
+		if err := binary.Read(buf, binary.LittleEndian, &ret.ProcScrtmStatus); err != nil {
+			return nil, err
+		}
+	}
+
+	if ret.Version >= 9 {
+		var err error
+		ret.ExtDataElements, err = parseTXTHeapExtDataElements(region[len(region)-buf.Len():])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ret.NumMDRs > 0 {
+		mdrs, err := txtHeapSubslice(region, uint64(ret.MDRsOffset), uint64(ret.NumMDRs)*txtHeapMDRSize)
+		if err != nil {
+			return nil, fmt.Errorf("MDR table: %w", err)
+		}
+		ret.MDRs = make([]TXTHeapMDR, ret.NumMDRs)
+		if err := binary.Read(bytes.NewReader(mdrs), binary.LittleEndian, ret.MDRs); err != nil {
+			return nil, fmt.Errorf("MDR table: %w", err)
+		}
+	}
+
+	if ret.NumVtdDmars > 0 {
+		dmar, err := txtHeapSubslice(region, uint64(ret.VtdDmarsOffset), uint64(ret.NumVtdDmars))
+		if err != nil {
+			return nil, fmt.Errorf("DMAR copy: %w", err)
+		}
+		ret.DMAR = dmar
+	}
+	return &ret, nil
+}
+
+func txtHeapSubslice(region []byte, offset, size uint64) ([]byte, error) {
+	if offset < txtHeapRegionSizeFieldSize || offset > uint64(len(region)) || size > uint64(len(region))-offset {
+		return nil, fmt.Errorf("range [0x%x, 0x%x) is outside of the region (size 0x%x)", offset, offset+size, len(region))
+	}
+	return region[offset : offset+size], nil
+}
+
+// parseTXTHeapExtDataElements decodes the extended data elements until
+// the END element or the end of data is reached
+func parseTXTHeapExtDataElements(data []byte) ([]TXTHeapExtDataElement, error) {
+	var ret []TXTHeapExtDataElement
+	for offset := uint64(0); offset < uint64(len(data)); {
+		if offset+TXTHeapExtDataHeaderSize > uint64(len(data)) {
+			return ret, fmt.Errorf("extended data element header at offset 0x%x is truncated", offset)
+		}
+		elementType := TXTHeapExtDataType(binary.LittleEndian.Uint32(data[offset:]))
+		size := uint64(binary.LittleEndian.Uint32(data[offset+4:]))
+		if size < TXTHeapExtDataHeaderSize || size > uint64(len(data))-offset {
+			return ret, fmt.Errorf("extended data element %s at offset 0x%x has invalid size 0x%x", elementType, offset, size)
+		}
+
+		element := TXTHeapExtDataElement{
+			Type: elementType,
+			Data: data[offset+TXTHeapExtDataHeaderSize : offset+size],
+		}
+		value, err := decodeTXTHeapExtDataElement(element)
+		if err != nil {
+			return ret, fmt.Errorf("extended data element %s at offset 0x%x: %w", elementType, offset, err)
+		}
+		element.Value = value
+		ret = append(ret, element)
+
+		if elementType == TXTHeapExtDataEnd {
+			break
+		}
+		offset += size
+	}
+	return ret, nil
+}
+
+func decodeTXTHeapExtDataElement(element TXTHeapExtDataElement) (interface{}, error) {
+	buf := bytes.NewReader(element.Data)
+	switch element.Type {
+	case TXTHeapExtDataBIOSSpecVer:
+		var v TXTHeapBIOSSpecVersion
+		err := binary.Read(buf, binary.LittleEndian, &v)
+		return v, err
+	case TXTHeapExtDataACM:
+		var count uint32
+		if err := binary.Read(buf, binary.LittleEndian, &count); err != nil {
+			return nil, err
+		}
+		if uint64(count)*8 > uint64(buf.Len()) {
+			return nil, fmt.Errorf("%d ACM addresses do not fit into the element", count)
+		}
+		v := make(TXTHeapACMs, count)
+		err := binary.Read(buf, binary.LittleEndian, v)
+		return v, err
+	case TXTHeapExtDataCustom:
+		var v TXTHeapCustom
+		if err := binary.Read(buf, binary.LittleEndian, &v.UUID); err != nil {
+			return nil, err
+		}
+		v.Data = element.Data[len(v.UUID):]
+		return v, nil
+	case TXTHeapExtDataTPMEventLogPtr:
+		var v TXTHeapEventLogPointer
+		err := binary.Read(buf, binary.LittleEndian, &v)
+		return v, err
+	case TXTHeapExtDataTPMEventLogPtr2:
+		var count uint32
+		if err := binary.Read(buf, binary.LittleEndian, &count); err != nil {
+			return nil, err
+		}
+		if uint64(count)*uint64(binary.Size(TXTHeapEventLogDescriptor{})) > uint64(buf.Len()) {
+			return nil, fmt.Errorf("%d event log descriptors do not fit into the element", count)
+		}
+		v := make(TXTHeapEventLogPointer2, count)
+		err := binary.Read(buf, binary.LittleEndian, v)
+		return v, err
+	case TXTHeapExtDataTPMEventLogPtr2_1:
+		var v TXTHeapEventLogPointer2_1
+		err := binary.Read(buf, binary.LittleEndian, &v)
+		return v, err
+	}
+	return nil, nil
+}
+
+// FindExtDataElement returns the first element of the given type
+func FindExtDataElement(elements []TXTHeapExtDataElement, elementType TXTHeapExtDataType) *TXTHeapExtDataElement {
+	for idx := range elements {
+		if elements[idx].Type == elementType {
+			return &elements[idx]
+		}
+	}
+	return nil
+}
+
+// SinitMleDataSpace returns the number of bytes available for the
+// SinitMleData region. If the OsMleData and OsSinitData regions are not
+// populated yet, the space of the heap after the BiosData region is returned.
+func (h *TXTHeap) SinitMleDataSpace() uint64 {
+	var used uint64
+	for _, region := range h.Regions[:3] {
+		used += region.Size
+	}
+	if used > h.Size {
+		return 0
+	}
+	return h.Size - used
+}
+
+// Validate checks the decoded TXT heap for consistency. If the MLE was not
+// launched yet, the OsMleData, OsSinitData and SinitMleData regions aren't
+// populated and launched should be false to check the BiosData region only.
+func (h *TXTHeap) Validate(launched bool) error {
+	mErr := &errors.MultiError{}
+	mErr.Add(h.BiosData.Validate())
+	if !launched {
+		return mErr.ReturnValue()
+	}
+
+	if h.OsSinitData == nil {
+		mErr.Add(fmt.Errorf("OsSinitData region is missing"))
+	} else {
+		mErr.Add(h.OsSinitData.Validate())
+	}
+	if h.SinitMleData == nil {
+		mErr.Add(fmt.Errorf("SinitMleData region is missing"))
+	} else {
+		mErr.Add(h.SinitMleData.Validate())
+	}
+	return mErr.ReturnValue()
+}
+
+// Validate checks the BiosData region for consistency
+func (d TXTBiosData) Validate() error {
+	mErr := &errors.MultiError{}
+	if d.Version < TXTBiosDataMinVersion || d.Version > TXTBiosDataMaxVersion {
+		mErr.Add(fmt.Errorf("BiosData version %d is not supported", d.Version))
+	}
+	if d.NumLogProcs == 0 {
+		mErr.Add(fmt.Errorf("BiosData has no logical processors"))
+	}
+	if d.Version >= 4 {
+		mErr.Add(validateTXTHeapExtDataElements("BiosData", d.ExtDataElements))
+	}
+	return mErr.ReturnValue()
+}
+
+// Validate checks the OsSinitData region for consistency
+func (d *TXTOsSinitData) Validate() error {
+	mErr := &errors.MultiError{}
+	if d.Version < TXTOsSinitDataMinVersion || d.Version > TXTOsSinitDataMaxVersion {
+		mErr.Add(fmt.Errorf("OsSinitData version %d is not supported", d.Version))
+	}
+
+	// The PMRs have a granularity of 2 MiB, the low PMR has to be below 4 GiB
+	// and the high PMR above 4 GiB.
+	const pmrAlignment = 2 << 20
+	if d.PMRLowBase%pmrAlignment != 0 || d.PMRLowSize%pmrAlignment != 0 {
+		mErr.Add(fmt.Errorf("low PMR 0x%x+0x%x is not 2 MiB aligned", d.PMRLowBase, d.PMRLowSize))
+	}
+	if d.PMRLowBase+d.PMRLowSize > 1<<32 {
+		mErr.Add(fmt.Errorf("low PMR 0x%x+0x%x exceeds 4 GiB", d.PMRLowBase, d.PMRLowSize))
+	}
+	if d.PMRHighSize != 0 {
+		if d.PMRHighBase%pmrAlignment != 0 || d.PMRHighSize%pmrAlignment != 0 {
+			mErr.Add(fmt.Errorf("high PMR 0x%x+0x%x is not 2 MiB aligned", d.PMRHighBase, d.PMRHighSize))
+		}
+		if d.PMRHighBase < 1<<32 {
+			mErr.Add(fmt.Errorf("high PMR base 0x%x is below 4 GiB", d.PMRHighBase))
+		}
+	}
+	if d.MLESize == 0 {
+		mErr.Add(fmt.Errorf("MLE size is zero"))
+	}
+	if d.RSDPPtr >= 1<<32 {
+		mErr.Add(fmt.Errorf("ACPI RSDP pointer 0x%x is above 4 GiB", d.RSDPPtr))
+	}
+	if d.Version >= 6 {
+		mErr.Add(validateTXTHeapExtDataElements("OsSinitData", d.ExtDataElements))
+	}
+	return mErr.ReturnValue()
+}
+
+// Validate checks the SinitMleData region for consistency
+func (d *TXTSinitMleData) Validate() error {
+	mErr := &errors.MultiError{}
+	if d.Version < TXTSinitMleDataMinVersion || d.Version > TXTSinitMleDataMaxVersion {
+		mErr.Add(fmt.Errorf("SinitMleData version %d is not supported", d.Version))
+	}
+	if d.NumMDRs == 0 {
+		mErr.Add(fmt.Errorf("SinitMleData has no memory descriptor records"))
+	}
+	for idx, mdr := range d.MDRs {
+		if mdr.MemType > txtHeapMDRTypeMaxKnown {
+			mErr.Add(fmt.Errorf("MDR %d has unknown memory type %d", idx, mdr.MemType))
+		}
+		if mdr.Length == 0 {
+			mErr.Add(fmt.Errorf("MDR %d is empty", idx))
+		}
+	}
+	if len(d.DMAR) < 4 || string(d.DMAR[:4]) != "DMAR" {
+		mErr.Add(fmt.Errorf("SinitMleData does not contain a copy of the ACPI DMAR table"))
+	}
+	if d.Version >= 9 {
+		mErr.Add(validateTXTHeapExtDataElements("SinitMleData", d.ExtDataElements))
+	}
+	return mErr.ReturnValue()
+}
+
+func validateTXTHeapExtDataElements(region string, elements []TXTHeapExtDataElement) error {
+	if len(elements) == 0 || elements[len(elements)-1].Type != TXTHeapExtDataEnd {
+		return fmt.Errorf("extended data elements of %s are not terminated by an END element", region)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func txtHeapRegion(fields ...interface{}) []byte {
+	var buf bytes.Buffer
+	for _, field := range fields {
+		if err := binary.Write(&buf, binary.LittleEndian, field); err != nil {
+			panic(err)
+		}
+	}
+	size := make([]byte, 8)
+	binary.LittleEndian.PutUint64(size, uint64(buf.Len()+8))
+	return append(size, buf.Bytes()...)
+}
+
+func txtHeapExtDataElement(elementType TXTHeapExtDataType, data ...interface{}) []byte {
+	var buf bytes.Buffer
+	for _, field := range data {
+		if err := binary.Write(&buf, binary.LittleEndian, field); err != nil {
+			panic(err)
+		}
+	}
+	var header [8]byte
+	binary.LittleEndian.PutUint32(header[:], uint32(elementType))
+	binary.LittleEndian.PutUint32(header[4:], uint32(buf.Len()+8))
+	return append(header[:], buf.Bytes()...)
+}
+
+func testTXTHeap() []byte {
+	var heap []byte
+
+	// BiosData version 5
+	heap = append(heap, txtHeapRegion(
+		uint32(5), uint32(0x10000), uint64(0), uint64(0), uint32(8),
+		uint32(0x1), uint32(0x3),
+		txtHeapExtDataElement(TXTHeapExtDataBIOSSpecVer, TXTHeapBIOSSpecVersion{2, 1, 0}),
+		txtHeapExtDataElement(TXTHeapExtDataACM, uint32(2), []uint64{0xff000000, 0xff100000}),
+		txtHeapExtDataElement(TXTHeapExtDataEnd),
+	)...)
+
+	// OsMleData
+	heap = append(heap, txtHeapRegion([]byte("MLE data"))...)
+
+	// OsSinitData version 7
+	heap = append(heap, txtHeapRegion(
+		uint32(7), uint32(0),
+		uint64(0x800000), uint64(0x100000), uint64(0x800100),
+		uint64(0), uint64(0x40000000), uint64(0x100000000), uint64(0x200000),
+		uint64(0), uint64(0),
+		uint32(0x3),
+		uint64(0xf0000),
+		txtHeapExtDataElement(TXTHeapExtDataTPMEventLogPtr2_1, TXTHeapEventLogPointer2_1{0x10000000, 0x10000, 0x40, 0x80}),
+		txtHeapExtDataElement(TXTHeapExtDataEnd),
+	)...)
+
+	// SinitMleData version 9, the MDRs and the DMAR copy follow
+	// the extended data elements
+	dmar := append([]byte("DMAR"), make([]byte, 44)...)
+	mdrs := []TXTHeapMDR{
+		{Base: 0, Length: 0xa0000, MemType: TXTHeapMDRGood},
+		{Base: 0x100000, Length: 0x7ff00000, MemType: TXTHeapMDRGood},
+	}
+	ext := append(
+		txtHeapExtDataElement(TXTHeapExtDataMADT, []byte("APIC")),
+		txtHeapExtDataElement(TXTHeapExtDataEnd)...,
+	)
+	const fixedSize = TXTSinitMleDataHeaderSize
+	mdrsOffset := uint32(fixedSize + len(ext))
+	dmarOffset := mdrsOffset + uint32(len(mdrs)*txtHeapMDRSize)
+	heap = append(heap, txtHeapRegion(
+		uint32(9), [20]byte{}, uint32(0), uint64(0),
+		[20]byte{1}, [20]byte{2}, [20]byte{}, [20]byte{3},
+		uint32(0), uint32(0), uint32(0),
+		uint32(len(mdrs)), mdrsOffset, uint32(len(dmar)), dmarOffset,
+		uint32(0),
+		ext, mdrs, dmar,
+	)...)
+
+	// free space
+	return append(heap, make([]byte, 0x100)...)
+}
+
+func TestParseTXTHeap(t *testing.T) {
+	heap, err := ParseTXTHeap(testTXTHeap())
+	require.NoError(t, err)
+
+	require.Equal(t, uint32(5), heap.BiosData.Version)
+	require.Equal(t, uint32(8), heap.BiosData.NumLogProcs)
+	require.Equal(t, uint32(1), heap.BiosData.SinitFlags)
+	require.Equal(t, &TXTBiosMLEFlags{SupportsACPIPPI: true, IsClientState: true}, heap.BiosData.MleFlags)
+	require.Len(t, heap.BiosData.ExtDataElements, 3)
+	require.Equal(t, TXTHeapBIOSSpecVersion{2, 1, 0}, heap.BiosData.ExtDataElements[0].Value)
+	require.Equal(t, TXTHeapACMs{0xff000000, 0xff100000}, heap.BiosData.ExtDataElements[1].Value)
+
+	require.Equal(t, []byte("MLE data"), heap.OsMleData)
+
+	require.NotNil(t, heap.OsSinitData)
+	require.Equal(t, uint64(0x40000000), heap.OsSinitData.PMRLowSize)
+	require.Equal(t, uint64(0xf0000), heap.OsSinitData.RSDPPtr)
+	elem := FindExtDataElement(heap.OsSinitData.ExtDataElements, TXTHeapExtDataTPMEventLogPtr2_1)
+	require.NotNil(t, elem)
+	require.Equal(t, TXTHeapEventLogPointer2_1{0x10000000, 0x10000, 0x40, 0x80}, elem.Value)
+
+	require.NotNil(t, heap.SinitMleData)
+	require.Equal(t, [20]byte{1}, heap.SinitMleData.SinitHash)
+	require.Len(t, heap.SinitMleData.MDRs, 2)
+	require.Equal(t, uint64(0x7ff00000), heap.SinitMleData.MDRs[1].Length)
+	require.Equal(t, "DMAR", string(heap.SinitMleData.DMAR[:4]))
+	require.Equal(t, []byte("APIC"), FindExtDataElement(heap.SinitMleData.ExtDataElements, TXTHeapExtDataMADT).Data)
+
+	require.Equal(t, heap.Size-heap.Regions[3].Offset, heap.SinitMleDataSpace())
+	require.NoError(t, heap.Validate(true))
+}
+
+func TestParseTXTHeapNotLaunched(t *testing.T) {
+	raw := testTXTHeap()
+	biosDataSize := binary.LittleEndian.Uint64(raw)
+	// The OsMleData region isn't populated before the launch
+	for idx := biosDataSize; idx < uint64(len(raw)); idx++ {
+		raw[idx] = 0
+	}
+
+	heap, err := ParseTXTHeap(raw)
+	require.Error(t, err)
+	require.Equal(t, uint32(5), heap.BiosData.Version)
+	require.Nil(t, heap.OsSinitData)
+	require.Equal(t, uint64(len(raw))-biosDataSize, heap.SinitMleDataSpace())
+	require.NoError(t, heap.Validate(false))
+	require.Error(t, heap.Validate(true))
+}
+
+func TestTXTHeapValidate(t *testing.T) {
+	heap, err := ParseTXTHeap(testTXTHeap())
+	require.NoError(t, err)
+
+	heap.OsSinitData.PMRLowBase = 0x1000
+	heap.SinitMleData.DMAR = nil
+	heap.SinitMleData.ExtDataElements = heap.SinitMleData.ExtDataElements[:1]
+	err = heap.Validate(true)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "low PMR")
+	require.Contains(t, err.Error(), "DMAR")
+	require.Contains(t, err.Error(), "END")
+}
+
+func TestParseTXTHeapInvalid(t *testing.T) {
+	raw := testTXTHeap()
+	biosDataSize := binary.LittleEndian.Uint64(raw)
+
+	// region exceeds the heap
+	invalid := append([]byte{}, raw...)
+	binary.LittleEndian.PutUint64(invalid[biosDataSize:], uint64(len(raw)))
+	_, err := ParseTXTHeap(invalid)
+	require.Error(t, err)
+
+	// extended data element exceeds the region
+	invalid = append([]byte{}, raw...)
+	binary.LittleEndian.PutUint32(invalid[8+36+4:], 0x1000)
+	_, err = ParseTXTHeap(invalid)
+	require.Error(t, err)
+}
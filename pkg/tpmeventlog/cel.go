@@ -0,0 +1,330 @@
+package tpmeventlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	pcr "github.com/9elements/converged-security-suite/v2/pkg/pcr/types"
+)
+
+// celType is the type of a TLV of TCG Canonical Event Log.
+//
+// See also: https://trustedcomputinggroup.org/wp-content/uploads/TCG_IWG_CEL_v1_r0p41_pub.pdf#page=21
+type celType uint8
+
+const (
+	celTypeRecNum      = celType(0)
+	celTypePCR         = celType(1)
+	celTypeNVIndex     = celType(2)
+	celTypeDigests     = celType(3)
+	celTypeCELMgt      = celType(4)
+	celTypePCClientStd = celType(5)
+	celTypeIMATemplate = celType(7)
+	celTypeIMATLV      = celType(8)
+)
+
+// Types of the nested TLVs of the "pcclient_std" content.
+const (
+	celTypePCClientStdEventType = celType(0)
+	celTypePCClientStdEventData = celType(1)
+)
+
+// celContentTypeName returns the name of the content type as it is used
+// in the JSON encoding of CEL.
+func celContentTypeName(t celType) string {
+	switch t {
+	case celTypeCELMgt:
+		return "cel_mgt"
+	case celTypePCClientStd:
+		return "pcclient_std"
+	case celTypeIMATemplate:
+		return "ima_template"
+	case celTypeIMATLV:
+		return "ima_tlv"
+	}
+	return fmt.Sprintf("unknown_content_type_%d", t)
+}
+
+type celTLV struct {
+	Type  celType
+	Value []byte
+}
+
+func parseCELTLVs(b []byte) ([]celTLV, error) {
+	var result []celTLV
+	for len(b) > 0 {
+		if len(b) < 5 {
+			return nil, fmt.Errorf("unexpected end of data: TLV header requires 5 bytes, but only %d left", len(b))
+		}
+		t := celType(b[0])
+		length := binary.BigEndian.Uint32(b[1:5])
+		b = b[5:]
+		if uint64(length) > uint64(len(b)) {
+			return nil, fmt.Errorf("unexpected end of data: TLV of type %d has length %d, but only %d bytes left", t, length, len(b))
+		}
+		result = append(result, celTLV{Type: t, Value: b[:length]})
+		b = b[length:]
+	}
+	return result, nil
+}
+
+func writeCELTLV(buf *bytes.Buffer, t celType, value []byte) {
+	buf.WriteByte(byte(t))
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(value)))
+	buf.Write(value)
+}
+
+func celUint(value []byte) (uint64, error) {
+	if len(value) == 0 || len(value) > 8 {
+		return 0, fmt.Errorf("invalid length of an integer value: %d", len(value))
+	}
+	var result uint64
+	for _, b := range value {
+		result = result<<8 | uint64(b)
+	}
+	return result, nil
+}
+
+// ParseCEL parses an EventLog in the TLV encoding of TCG Canonical Event Log
+// (see FormatCELTLV).
+//
+// Only records of PCClient content type are supported, the management
+// records ("cel_mgt") are skipped.
+func ParseCEL(input io.Reader) (*TPMEventLog, error) {
+	b, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, ErrRead{Err: err}
+	}
+	tlvs, err := parseCELTLVs(b)
+	if err != nil {
+		return nil, ErrParse{Err: err}
+	}
+
+	var records []eventRecord
+	var record *eventRecord
+	skip := false
+	for _, tlv := range tlvs {
+		if tlv.Type == celTypeRecNum {
+			if record != nil && !skip {
+				records = append(records, *record)
+			}
+			record = &eventRecord{}
+			skip = false
+			continue
+		}
+		if record == nil {
+			return nil, ErrParse{Err: fmt.Errorf("the log does not start with a record number")}
+		}
+		switch tlv.Type {
+		case celTypePCR:
+			pcrIndex, err := celUint(tlv.Value)
+			if err != nil {
+				return nil, ErrParse{Err: fmt.Errorf("unable to parse the PCR index: %w", err)}
+			}
+			record.PCRIndex = pcr.ID(pcrIndex)
+		case celTypeNVIndex:
+			return nil, ErrParse{Err: fmt.Errorf("NV index records are not supported")}
+		case celTypeDigests:
+			digests, err := parseCELTLVs(tlv.Value)
+			if err != nil {
+				return nil, ErrParse{Err: fmt.Errorf("unable to parse digests: %w", err)}
+			}
+			for _, digest := range digests {
+				record.Digests = append(record.Digests, Digest{
+					HashAlgo: TPMAlgorithm(digest.Type),
+					Digest:   digest.Value,
+				})
+			}
+		case celTypeCELMgt:
+			skip = true
+		case celTypePCClientStd:
+			fields, err := parseCELTLVs(tlv.Value)
+			if err != nil {
+				return nil, ErrParse{Err: fmt.Errorf("unable to parse PCClient content: %w", err)}
+			}
+			for _, field := range fields {
+				switch field.Type {
+				case celTypePCClientStdEventType:
+					eventType, err := celUint(field.Value)
+					if err != nil {
+						return nil, ErrParse{Err: fmt.Errorf("unable to parse the event type: %w", err)}
+					}
+					record.Type = EventType(eventType)
+				case celTypePCClientStdEventData:
+					record.Data = field.Value
+				}
+			}
+		default:
+			return nil, ErrParse{Err: fmt.Errorf("unsupported content type: %s", celContentTypeName(tlv.Type))}
+		}
+	}
+	if record != nil && !skip {
+		records = append(records, *record)
+	}
+
+	return newTPMEventLogFromRecords(records), nil
+}
+
+// EncodeCEL writes the EventLog in the TLV encoding of TCG Canonical
+// Event Log (see FormatCELTLV).
+func (eventLog *TPMEventLog) EncodeCEL(output io.Writer) error {
+	records, err := eventLog.records()
+	if err != nil {
+		return ErrEncode{Err: err}
+	}
+
+	var buf bytes.Buffer
+	for idx, record := range records {
+		var recNum [8]byte
+		binary.BigEndian.PutUint64(recNum[:], uint64(idx))
+		writeCELTLV(&buf, celTypeRecNum, recNum[:])
+
+		var pcrIndex [4]byte
+		binary.BigEndian.PutUint32(pcrIndex[:], uint32(record.PCRIndex))
+		writeCELTLV(&buf, celTypePCR, pcrIndex[:])
+
+		var digests bytes.Buffer
+		for _, digest := range record.Digests {
+			if digest.HashAlgo > 0xff {
+				return ErrEncode{Err: ErrNotSupportedHashAlgo{TPMAlgo: digest.HashAlgo}}
+			}
+			writeCELTLV(&digests, celType(digest.HashAlgo), digest.Digest)
+		}
+		writeCELTLV(&buf, celTypeDigests, digests.Bytes())
+
+		var content bytes.Buffer
+		var eventType [4]byte
+		binary.BigEndian.PutUint32(eventType[:], uint32(record.Type))
+		writeCELTLV(&content, celTypePCClientStdEventType, eventType[:])
+		writeCELTLV(&content, celTypePCClientStdEventData, record.Data)
+		writeCELTLV(&buf, celTypePCClientStd, content.Bytes())
+	}
+
+	if _, err := output.Write(buf.Bytes()); err != nil {
+		return ErrEncode{Err: err}
+	}
+	return nil
+}
+
+type celJSONRecord struct {
+	RecNum      uint64          `json:"recnum"`
+	PCR         *uint32         `json:"pcr,omitempty"`
+	NVIndex     *uint32         `json:"nv_index,omitempty"`
+	Digests     []celJSONDigest `json:"digests"`
+	ContentType string          `json:"content_type"`
+	Content     json.RawMessage `json:"content"`
+}
+
+type celJSONDigest struct {
+	HashAlg string `json:"hashAlg"`
+	Digest  string `json:"digest"`
+}
+
+type celJSONPCClientStd struct {
+	EventType EventType `json:"event_type"`
+	EventData []byte    `json:"event_data"`
+}
+
+// ParseCELJSON parses an EventLog in the JSON encoding of TCG Canonical
+// Event Log (see FormatCELJSON).
+//
+// Only records of PCClient content type are supported, the management
+// records ("cel_mgt") are skipped.
+func ParseCELJSON(input io.Reader) (*TPMEventLog, error) {
+	b, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, ErrRead{Err: err}
+	}
+	var celRecords []celJSONRecord
+	if err := json.Unmarshal(b, &celRecords); err != nil {
+		return nil, ErrParse{Err: err}
+	}
+
+	records := make([]eventRecord, 0, len(celRecords))
+	for _, celRecord := range celRecords {
+		switch celRecord.ContentType {
+		case celContentTypeName(celTypeCELMgt):
+			continue
+		case celContentTypeName(celTypePCClientStd):
+		default:
+			return nil, ErrParse{Err: fmt.Errorf("record #%d: unsupported content type: %s", celRecord.RecNum, celRecord.ContentType)}
+		}
+		if celRecord.PCR == nil {
+			return nil, ErrParse{Err: fmt.Errorf("record #%d: only PCR records are supported", celRecord.RecNum)}
+		}
+
+		var content celJSONPCClientStd
+		if err := json.Unmarshal(celRecord.Content, &content); err != nil {
+			return nil, ErrParse{Err: fmt.Errorf("record #%d: unable to parse the content: %w", celRecord.RecNum, err)}
+		}
+		record := eventRecord{
+			PCRIndex: pcr.ID(*celRecord.PCR),
+			Type:     content.EventType,
+			Data:     content.EventData,
+		}
+		for _, celDigest := range celRecord.Digests {
+			hashAlgo, err := HashAlgorithmFromName(celDigest.HashAlg)
+			if err != nil {
+				return nil, ErrParse{Err: fmt.Errorf("record #%d: %w", celRecord.RecNum, err)}
+			}
+			digest, err := hex.DecodeString(celDigest.Digest)
+			if err != nil {
+				return nil, ErrParse{Err: fmt.Errorf("record #%d: unable to parse the digest: %w", celRecord.RecNum, err)}
+			}
+			record.Digests = append(record.Digests, Digest{
+				HashAlgo: hashAlgo,
+				Digest:   digest,
+			})
+		}
+		records = append(records, record)
+	}
+
+	return newTPMEventLogFromRecords(records), nil
+}
+
+// EncodeCELJSON writes the EventLog in the JSON encoding of TCG Canonical
+// Event Log (see FormatCELJSON).
+func (eventLog *TPMEventLog) EncodeCELJSON(output io.Writer) error {
+	records, err := eventLog.records()
+	if err != nil {
+		return ErrEncode{Err: err}
+	}
+
+	celRecords := make([]celJSONRecord, 0, len(records))
+	for idx, record := range records {
+		content, err := json.Marshal(celJSONPCClientStd{
+			EventType: record.Type,
+			EventData: record.Data,
+		})
+		if err != nil {
+			return ErrEncode{Err: err}
+		}
+		pcrIndex := uint32(record.PCRIndex)
+		celRecord := celJSONRecord{
+			RecNum:      uint64(idx),
+			PCR:         &pcrIndex,
+			Digests:     []celJSONDigest{},
+			ContentType: celContentTypeName(celTypePCClientStd),
+			Content:     content,
+		}
+		for _, digest := range record.Digests {
+			celRecord.Digests = append(celRecord.Digests, celJSONDigest{
+				HashAlg: HashAlgorithmName(digest.HashAlgo),
+				Digest:  hex.EncodeToString(digest.Digest),
+			})
+		}
+		celRecords = append(celRecords, celRecord)
+	}
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(celRecords); err != nil {
+		return ErrEncode{Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package tpmeventlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// specIDEventSignature is the signature of TCG_EfiSpecIDEvent, which is
+// the first event of a crypto-agile EventLog.
+//
+// See also: https://trustedcomputinggroup.org/wp-content/uploads/TCG_PCClient_PFP_r1p05_v23_pub.pdf#page=117
+var specIDEventSignature = [16]byte{'S', 'p', 'e', 'c', ' ', 'I', 'D', ' ', 'E', 'v', 'e', 'n', 't', '0', '3', 0}
+
+type specIDEventHeader struct {
+	Signature        [16]byte
+	PlatformClass    uint32
+	SpecVersionMinor uint8
+	SpecVersionMajor uint8
+	SpecErrata       uint8
+	UintnSize        uint8
+	NumberOfAlgs     uint32
+}
+
+type specIDEventAlgorithmSize struct {
+	AlgorithmID TPMAlgorithm
+	DigestSize  uint16
+}
+
+// EncodeTCG writes the EventLog in the binary crypto-agile format
+// (see FormatTCG). The result could be parsed by Parse.
+func (eventLog *TPMEventLog) EncodeTCG(output io.Writer) error {
+	records, err := eventLog.records()
+	if err != nil {
+		return ErrEncode{Err: err}
+	}
+	hashAlgos := eventLog.hashAlgos()
+
+	var buf bytes.Buffer
+	write := func(value interface{}) {
+		// bytes.Buffer never returns an error on write.
+		_ = binary.Write(&buf, binary.LittleEndian, value)
+	}
+
+	// The first event is in the legacy (SHA1-only) format and describes
+	// the hash algorithms used in the rest of the log.
+	var specIDEvent bytes.Buffer
+	_ = binary.Write(&specIDEvent, binary.LittleEndian, specIDEventHeader{
+		Signature:        specIDEventSignature,
+		SpecVersionMajor: 2,
+		UintnSize:        2, // UINT64
+		NumberOfAlgs:     uint32(len(hashAlgos)),
+	})
+	for _, hashAlgo := range hashAlgos {
+		h, err := NewHasher(hashAlgo)
+		if err != nil {
+			return ErrEncode{Err: err}
+		}
+		_ = binary.Write(&specIDEvent, binary.LittleEndian, specIDEventAlgorithmSize{
+			AlgorithmID: hashAlgo,
+			DigestSize:  uint16(h.Size()),
+		})
+	}
+	specIDEvent.WriteByte(0) // vendorInfoSize
+
+	write(uint32(0))    // PCRIndex
+	write(EV_NO_ACTION) // EventType
+	write([20]byte{})   // Digest
+	write(uint32(specIDEvent.Len()))
+	buf.Write(specIDEvent.Bytes())
+
+	for idx, record := range records {
+		write(uint32(record.PCRIndex))
+		write(record.Type)
+		write(uint32(len(hashAlgos)))
+		for _, hashAlgo := range hashAlgos {
+			h, _ := NewHasher(hashAlgo)
+			digest := make([]byte, h.Size())
+			found := false
+			for _, d := range record.Digests {
+				if d.HashAlgo != hashAlgo {
+					continue
+				}
+				if len(d.Digest) != h.Size() {
+					return ErrEncode{Err: fmt.Errorf("event #%d: %w", idx, ErrInvalidDigestLength{Expected: h.Size(), Received: len(d.Digest)})}
+				}
+				copy(digest, d.Digest)
+				found = true
+			}
+			if !found && record.Type != EV_NO_ACTION {
+				return ErrEncode{Err: fmt.Errorf("event #%d has no digest of hash algorithm %s", idx, hashAlgo)}
+			}
+			write(hashAlgo)
+			buf.Write(digest)
+		}
+		write(uint32(len(record.Data)))
+		buf.Write(record.Data)
+	}
+
+	if _, err := output.Write(buf.Bytes()); err != nil {
+		return ErrEncode{Err: err}
+	}
+	return nil
+}
@@ -66,3 +66,18 @@ type ErrNotSupportedHashAlgo struct {
 func (err ErrNotSupportedHashAlgo) Error() string {
 	return fmt.Sprintf("not supported hash algorithm: 0x%x", err.TPMAlgo)
 }
+
+// ErrEncode means unable to serialize the EventLog.
+type ErrEncode struct {
+	Err error
+}
+
+// Error implements interface `error`.
+func (err ErrEncode) Error() string {
+	return fmt.Sprintf("unable to encode the EventLog: %v", err.Err)
+}
+
+// Unwrap implements `xerrors.Wrapper`.
+func (err ErrEncode) Unwrap() error {
+	return err.Err
+}
@@ -0,0 +1,121 @@
+package tpmeventlog
+
+import (
+	"bytes"
+	"fmt"
+
+	pcr "github.com/9elements/converged-security-suite/v2/pkg/pcr/types"
+)
+
+// eventRecord is a single entry of an EventLog with digests of all the hash
+// algorithms (as it is stored in the crypto-agile and CEL formats).
+type eventRecord struct {
+	PCRIndex pcr.ID
+	Type     EventType
+	Data     []byte
+	Digests  []Digest
+}
+
+// hashAlgos returns the list of hash algorithms used in the EventLog in
+// order of the first appearance.
+func (eventLog *TPMEventLog) hashAlgos() []TPMAlgorithm {
+	var result []TPMAlgorithm
+	m := map[TPMAlgorithm]struct{}{}
+	for _, event := range eventLog.Events {
+		if event.Digest == nil {
+			continue
+		}
+		if _, ok := m[event.Digest.HashAlgo]; ok {
+			continue
+		}
+		m[event.Digest.HashAlgo] = struct{}{}
+		result = append(result, event.Digest.HashAlgo)
+	}
+	return result
+}
+
+// records merges the events of different hash algorithms into records.
+//
+// Events of each hash algorithm are expected to be a complete sequence
+// of the EventLog entries (as it is returned by Parse), the N-th event of
+// one hash algorithm is merged with the N-th event of another one.
+func (eventLog *TPMEventLog) records() ([]eventRecord, error) {
+	hashAlgos := eventLog.hashAlgos()
+	eventsByAlgo := map[TPMAlgorithm][]*Event{}
+	for _, event := range eventLog.Events {
+		if event.Digest == nil {
+			return nil, fmt.Errorf("event %v has no digest", *event)
+		}
+		eventsByAlgo[event.Digest.HashAlgo] = append(eventsByAlgo[event.Digest.HashAlgo], event)
+	}
+	if len(hashAlgos) == 0 {
+		return nil, nil
+	}
+
+	count := len(eventsByAlgo[hashAlgos[0]])
+	for _, hashAlgo := range hashAlgos[1:] {
+		if len(eventsByAlgo[hashAlgo]) != count {
+			return nil, fmt.Errorf("amount of events of hash algorithm %s (%d) is not equal to amount of events of hash algorithm %s (%d)",
+				hashAlgo, len(eventsByAlgo[hashAlgo]), hashAlgos[0], count)
+		}
+	}
+
+	records := make([]eventRecord, 0, count)
+	for idx := 0; idx < count; idx++ {
+		first := eventsByAlgo[hashAlgos[0]][idx]
+		record := eventRecord{
+			PCRIndex: first.PCRIndex,
+			Type:     first.Type,
+			Data:     first.Data,
+		}
+		for _, hashAlgo := range hashAlgos {
+			event := eventsByAlgo[hashAlgo][idx]
+			if event.PCRIndex != first.PCRIndex || event.Type != first.Type || !bytes.Equal(event.Data, first.Data) {
+				return nil, fmt.Errorf("event #%d of hash algorithm %s does not match the event of hash algorithm %s", idx, hashAlgo, hashAlgos[0])
+			}
+			if len(event.Digest.Digest) == 0 {
+				continue
+			}
+			record.Digests = append(record.Digests, *event.Digest)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// newTPMEventLogFromRecords is the reverse of method "records". A record
+// without a digest of some hash algorithm results in an event with an
+// empty digest (the same as Parse does).
+func newTPMEventLogFromRecords(records []eventRecord) *TPMEventLog {
+	var hashAlgos []TPMAlgorithm
+	m := map[TPMAlgorithm]struct{}{}
+	for _, record := range records {
+		for _, digest := range record.Digests {
+			if _, ok := m[digest.HashAlgo]; ok {
+				continue
+			}
+			m[digest.HashAlgo] = struct{}{}
+			hashAlgos = append(hashAlgos, digest.HashAlgo)
+		}
+	}
+
+	result := &TPMEventLog{}
+	for _, hashAlgo := range hashAlgos {
+		for _, record := range records {
+			event := &Event{
+				PCRIndex: record.PCRIndex,
+				Type:     record.Type,
+				Data:     record.Data,
+				Digest:   &Digest{HashAlgo: hashAlgo},
+			}
+			for _, digest := range record.Digests {
+				if digest.HashAlgo == hashAlgo {
+					event.Digest.Digest = digest.Digest
+					break
+				}
+			}
+			result.Events = append(result.Events, event)
+		}
+	}
+	return result
+}
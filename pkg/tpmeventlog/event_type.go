@@ -37,6 +37,8 @@ const (
 	EV_EFI_PLATFORM_FIRMWARE_BLOB    = EventType(0x80000008)
 	EV_EFI_HANDOFF_TABLES            = EventType(0x80000009)
 	EV_EFI_PLATFORM_FIRMWARE_BLOB2   = EventType(0x8000000A)
+	EV_EFI_HANDOFF_TABLES2           = EventType(0x8000000B)
+	EV_EFI_VARIABLE_BOOT2            = EventType(0x8000000C)
 	EV_EFI_HCRTM_EVENT               = EventType(0x80000010)
 	EV_EFI_VARIABLE_AUTHORITY        = EventType(0x800000E0)
 )
@@ -0,0 +1,84 @@
+package tpmeventlog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is a serialization format of an EventLog.
+type Format int
+
+const (
+	// FormatUndefined is a zero-value of Format.
+	FormatUndefined = Format(iota)
+
+	// FormatTCG is the binary format defined in TCG PC Client Platform Firmware
+	// Profile Specification (as it is provided by Linux in
+	// "/sys/kernel/security/tpm0/binary_bios_measurements").
+	//
+	// See also: https://trustedcomputinggroup.org/wp-content/uploads/TCG_PCClient_PFP_r1p05_v23_pub.pdf#page=113
+	FormatTCG
+
+	// FormatCELTLV is the TLV encoding of TCG Canonical Event Log.
+	//
+	// See also: https://trustedcomputinggroup.org/wp-content/uploads/TCG_IWG_CEL_v1_r0p41_pub.pdf
+	FormatCELTLV
+
+	// FormatCELJSON is the JSON encoding of TCG Canonical Event Log.
+	FormatCELJSON
+
+	// EndOfFormat is just a terminator of the list of formats.
+	EndOfFormat
+)
+
+// String implements fmt.Stringer.
+func (format Format) String() string {
+	switch format {
+	case FormatUndefined:
+		return "undefined"
+	case FormatTCG:
+		return "tcg"
+	case FormatCELTLV:
+		return "cel-tlv"
+	case FormatCELJSON:
+		return "cel-json"
+	}
+	return fmt.Sprintf("unknown_format_%d", int(format))
+}
+
+// FormatFromString is the inverse function of Format.String.
+func FormatFromString(in string) (Format, error) {
+	for format := FormatUndefined + 1; format < EndOfFormat; format++ {
+		if format.String() == strings.ToLower(in) {
+			return format, nil
+		}
+	}
+	return FormatUndefined, fmt.Errorf("unknown EventLog format: '%s'", in)
+}
+
+// ParseFormat parses an EventLog in the specified format.
+func ParseFormat(input io.Reader, format Format) (*TPMEventLog, error) {
+	switch format {
+	case FormatTCG:
+		return Parse(input)
+	case FormatCELTLV:
+		return ParseCEL(input)
+	case FormatCELJSON:
+		return ParseCELJSON(input)
+	}
+	return nil, fmt.Errorf("unsupported EventLog format: %s", format)
+}
+
+// Encode writes the EventLog in the specified format.
+func (eventLog *TPMEventLog) Encode(output io.Writer, format Format) error {
+	switch format {
+	case FormatTCG:
+		return eventLog.EncodeTCG(output)
+	case FormatCELTLV:
+		return eventLog.EncodeCEL(output)
+	case FormatCELJSON:
+		return eventLog.EncodeCELJSON(output)
+	}
+	return fmt.Errorf("unsupported EventLog format: %s", format)
+}
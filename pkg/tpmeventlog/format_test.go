@@ -0,0 +1,125 @@
+package tpmeventlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeParse(t *testing.T) {
+	eventLog := &TPMEventLog{}
+	for _, hashAlgo := range HashAlgorithms() {
+		digest := func(s string) *Digest {
+			hasher, err := NewHasher(hashAlgo)
+			require.NoError(t, err)
+			hasher.Write([]byte(s))
+			return &Digest{HashAlgo: hashAlgo, Digest: hasher.Sum(nil)}
+		}
+		eventLog.Events = append(eventLog.Events,
+			&Event{PCRIndex: 0, Type: EV_S_CRTM_CONTENTS, Data: []byte("contents"), Digest: digest("contents")},
+			&Event{PCRIndex: 0, Type: EV_POST_CODE, Data: []byte("POST CODE"), Digest: digest("code")},
+			&Event{PCRIndex: 7, Type: EV_SEPARATOR, Data: []byte{0, 0, 0, 0}, Digest: digest("\x00\x00\x00\x00")},
+		)
+	}
+
+	for format := FormatUndefined + 1; format < EndOfFormat; format++ {
+		t.Run(format.String(), func(t *testing.T) {
+			parsedFormat, err := FormatFromString(format.String())
+			require.NoError(t, err)
+			require.Equal(t, format, parsedFormat)
+
+			var buf bytes.Buffer
+			require.NoError(t, eventLog.Encode(&buf, format))
+
+			parsed, err := ParseFormat(&buf, format)
+			require.NoError(t, err)
+			require.Equal(t, eventLog, parsed)
+		})
+	}
+}
+
+func TestEncodeMismatchingEvents(t *testing.T) {
+	eventLog := &TPMEventLog{
+		Events: []*Event{
+			{PCRIndex: 0, Type: EV_POST_CODE, Data: []byte{1}, Digest: &Digest{HashAlgo: TPMAlgorithmSHA1, Digest: make([]byte, 20)}},
+			{PCRIndex: 0, Type: EV_SEPARATOR, Data: []byte{1}, Digest: &Digest{HashAlgo: TPMAlgorithmSHA256, Digest: make([]byte, 32)}},
+		},
+	}
+	require.Error(t, eventLog.Encode(&bytes.Buffer{}, FormatTCG))
+}
+
+func TestParseLegacy(t *testing.T) {
+	var buf bytes.Buffer
+	for _, header := range []legacyEventHeader{
+		{PCRIndex: 0, Type: EV_S_CRTM_VERSION, Digest: [20]byte{1}, EventSize: 2},
+		{PCRIndex: 0, Type: EV_SEPARATOR, Digest: [20]byte{2}, EventSize: 2},
+	} {
+		require.NoError(t, binary.Write(&buf, binary.LittleEndian, header))
+		buf.Write([]byte{0, 0})
+	}
+
+	eventLog, err := Parse(&buf)
+	require.NoError(t, err)
+	require.Len(t, eventLog.Events, 2)
+	for idx, event := range eventLog.Events {
+		require.Equal(t, TPMAlgorithmSHA1, event.Digest.HashAlgo)
+		require.Len(t, event.Digest.Digest, 20)
+		require.Equal(t, byte(idx+1), event.Digest.Digest[0])
+	}
+}
+
+// TestParseCryptoAgileBanks checks that digests of the banks other than
+// SHA1 and SHA256 are not lost, including the banks of unknown algorithms.
+func TestParseCryptoAgileBanks(t *testing.T) {
+	unknownAlgo := TPMAlgorithm(0x1234)
+	var specIDEvent bytes.Buffer
+	write := func(buf *bytes.Buffer, value interface{}) {
+		require.NoError(t, binary.Write(buf, binary.LittleEndian, value))
+	}
+	write(&specIDEvent, specIDEventHeader{
+		Signature:        specIDEventSignature,
+		SpecVersionMajor: 2,
+		UintnSize:        2,
+		NumberOfAlgs:     2,
+	})
+	write(&specIDEvent, []specIDEventAlgorithmSize{
+		{AlgorithmID: TPMAlgorithmSHA384, DigestSize: 48},
+		{AlgorithmID: unknownAlgo, DigestSize: 4},
+	})
+	specIDEvent.WriteByte(0)
+
+	var buf bytes.Buffer
+	write(&buf, legacyEventHeader{Type: EV_NO_ACTION, EventSize: uint32(specIDEvent.Len())})
+	buf.Write(specIDEvent.Bytes())
+	write(&buf, []uint32{0, uint32(EV_SEPARATOR), 2})
+	write(&buf, TPMAlgorithmSHA384)
+	buf.Write(bytes.Repeat([]byte{1}, 48))
+	write(&buf, unknownAlgo)
+	buf.Write([]byte{2, 2, 2, 2})
+	write(&buf, uint32(4))
+	buf.Write([]byte{0, 0, 0, 0})
+	raw := buf.Bytes()
+
+	eventLog, err := Parse(bytes.NewReader(raw))
+	require.NoError(t, err)
+	require.Equal(t, []*Event{
+		{PCRIndex: 0, Type: EV_SEPARATOR, Data: []byte{0, 0, 0, 0}, Digest: &Digest{HashAlgo: TPMAlgorithmSHA384, Digest: bytes.Repeat([]byte{1}, 48)}},
+		{PCRIndex: 0, Type: EV_SEPARATOR, Data: []byte{0, 0, 0, 0}, Digest: &Digest{HashAlgo: unknownAlgo, Digest: []byte{2, 2, 2, 2}}},
+	}, eventLog.Events)
+
+	// truncated digest
+	_, err = Parse(bytes.NewReader(raw[:len(raw)-10]))
+	require.Error(t, err)
+}
+
+func TestHashAlgorithmName(t *testing.T) {
+	for _, hashAlgo := range append(HashAlgorithms(), TPMAlgorithm(0x1234)) {
+		parsed, err := HashAlgorithmFromName(HashAlgorithmName(hashAlgo))
+		require.NoError(t, err)
+		require.Equal(t, hashAlgo, parsed)
+	}
+	_, err := HashAlgorithmFromName("md5")
+	require.Error(t, err)
+}
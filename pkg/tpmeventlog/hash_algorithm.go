@@ -0,0 +1,85 @@
+package tpmeventlog
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/tjfoc/gmsm/sm3"
+)
+
+// HashAlgorithms returns the list of hash algorithms of PCR banks
+// supported by this package.
+func HashAlgorithms() []TPMAlgorithm {
+	return []TPMAlgorithm{
+		TPMAlgorithmSHA1,
+		TPMAlgorithmSHA256,
+		TPMAlgorithmSHA384,
+		TPMAlgorithmSHA512,
+		TPMAlgorithmSM3_256,
+	}
+}
+
+// NewHasher returns a new hasher of the specified hash algorithm.
+//
+// Unlike tpm2.Algorithm.Hash it also supports SM3_256.
+func NewHasher(hashAlgo TPMAlgorithm) (hash.Hash, error) {
+	switch hashAlgo {
+	case TPMAlgorithmSHA1:
+		return sha1.New(), nil
+	case TPMAlgorithmSHA256:
+		return sha256.New(), nil
+	case TPMAlgorithmSHA384:
+		return sha512.New384(), nil
+	case TPMAlgorithmSHA512:
+		return sha512.New(), nil
+	case TPMAlgorithmSM3_256:
+		return sm3.New(), nil
+	}
+	return nil, ErrNotSupportedHashAlgo{TPMAlgo: hashAlgo}
+}
+
+// HashAlgorithmName returns the name of the hash algorithm as it is used
+// by TCG (for example in TCG Canonical Event Log), like "sha256" or "sm3_256".
+func HashAlgorithmName(hashAlgo TPMAlgorithm) string {
+	switch hashAlgo {
+	case TPMAlgorithmSHA1:
+		return "sha1"
+	case TPMAlgorithmSHA256:
+		return "sha256"
+	case TPMAlgorithmSHA384:
+		return "sha384"
+	case TPMAlgorithmSHA512:
+		return "sha512"
+	case TPMAlgorithmSM3_256:
+		return "sm3_256"
+	}
+	return fmt.Sprintf("0x%04x", uint16(hashAlgo))
+}
+
+// HashAlgorithmFromName is the inverse function of HashAlgorithmName.
+func HashAlgorithmFromName(name string) (TPMAlgorithm, error) {
+	for _, hashAlgo := range HashAlgorithms() {
+		if HashAlgorithmName(hashAlgo) == strings.ToLower(name) {
+			return hashAlgo, nil
+		}
+	}
+	var hashAlgo uint16
+	if _, err := fmt.Sscanf(name, "0x%04x", &hashAlgo); err != nil {
+		return 0, fmt.Errorf("unknown hash algorithm '%s'", name)
+	}
+	return TPMAlgorithm(hashAlgo), nil
+}
+
+// HashAlgorithmNames returns the names of HashAlgorithms joined with ", "
+// (could be used in descriptions of command line options).
+func HashAlgorithmNames() string {
+	var names []string
+	for _, hashAlgo := range HashAlgorithms() {
+		names = append(names, HashAlgorithmName(hashAlgo))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,192 @@
+package tpmeventlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	pcr "github.com/9elements/converged-security-suite/v2/pkg/pcr/types"
+)
+
+// legacyEventHeader is the header of an event in the SHA1-only format
+// (TCG_PCClientPCREvent).
+type legacyEventHeader struct {
+	PCRIndex  uint32
+	Type      EventType
+	Digest    [20]byte
+	EventSize uint32
+}
+
+// Parse parses a binary EventLog (see FormatTCG).
+//
+// Both the legacy (SHA1-only) and the crypto-agile formats are supported.
+// In the crypto-agile format digests of any hash algorithms listed in the
+// Spec ID event are returned (including the ones not supported by NewHasher).
+func Parse(input io.Reader) (*TPMEventLog, error) {
+	b, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, ErrRead{Err: err}
+	}
+	r := bytes.NewReader(b)
+
+	first, err := parseLegacyEvent(r)
+	if err != nil {
+		return nil, ErrParse{Err: fmt.Errorf("unable to parse the first event: %w", err)}
+	}
+
+	if first.Type != EV_NO_ACTION || !bytes.HasPrefix(first.Data, specIDEventSignature[:]) {
+		records := []eventRecord{*first}
+		for r.Len() > 0 {
+			record, err := parseLegacyEvent(r)
+			if err != nil {
+				return nil, ErrParse{Err: fmt.Errorf("unable to parse event #%d: %w", len(records), err)}
+			}
+			records = append(records, *record)
+		}
+		return newTPMEventLogFromRecords(records), nil
+	}
+
+	digestSizes, err := parseSpecIDEvent(first.Data)
+	if err != nil {
+		return nil, ErrParse{Err: fmt.Errorf("unable to parse the Spec ID event: %w", err)}
+	}
+
+	// The Spec ID event itself is not included into the result, since it does
+	// not extend PCRs.
+	var records []eventRecord
+	for r.Len() > 0 {
+		record, err := parseCryptoAgileEvent(r, digestSizes)
+		if err != nil {
+			return nil, ErrParse{Err: fmt.Errorf("unable to parse event #%d: %w", len(records)+1, err)}
+		}
+		records = append(records, *record)
+	}
+
+	result := &TPMEventLog{}
+	for _, algSize := range digestSizes {
+		for _, record := range records {
+			event := &Event{
+				PCRIndex: record.PCRIndex,
+				Type:     record.Type,
+				Data:     record.Data,
+				Digest:   &Digest{HashAlgo: algSize.AlgorithmID},
+			}
+			for _, digest := range record.Digests {
+				if digest.HashAlgo == algSize.AlgorithmID {
+					event.Digest.Digest = digest.Digest
+					break
+				}
+			}
+			result.Events = append(result.Events, event)
+		}
+	}
+	return result, nil
+}
+
+func parseLegacyEvent(r *bytes.Reader) (*eventRecord, error) {
+	var header legacyEventHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("unable to read the header: %w", err)
+	}
+	data, err := readEventData(r, header.EventSize)
+	if err != nil {
+		return nil, err
+	}
+	return &eventRecord{
+		PCRIndex: pcr.ID(header.PCRIndex),
+		Type:     header.Type,
+		Data:     data,
+		Digests: []Digest{{
+			HashAlgo: TPMAlgorithmSHA1,
+			Digest:   header.Digest[:],
+		}},
+	}, nil
+}
+
+func parseSpecIDEvent(data []byte) ([]specIDEventAlgorithmSize, error) {
+	r := bytes.NewReader(data)
+	var header specIDEventHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("unable to read the header: %w", err)
+	}
+	if header.SpecVersionMajor != 2 {
+		return nil, fmt.Errorf("unsupported spec version: %d.%d", header.SpecVersionMajor, header.SpecVersionMinor)
+	}
+	if uint64(header.NumberOfAlgs)*uint64(binary.Size(specIDEventAlgorithmSize{})) > uint64(r.Len()) {
+		return nil, fmt.Errorf("invalid amount of algorithms: %d", header.NumberOfAlgs)
+	}
+	digestSizes := make([]specIDEventAlgorithmSize, header.NumberOfAlgs)
+	if err := binary.Read(r, binary.LittleEndian, digestSizes); err != nil {
+		return nil, fmt.Errorf("unable to read digest sizes: %w", err)
+	}
+	if len(digestSizes) == 0 {
+		return nil, fmt.Errorf("no hash algorithms defined")
+	}
+	return digestSizes, nil
+}
+
+func parseCryptoAgileEvent(r *bytes.Reader, digestSizes []specIDEventAlgorithmSize) (*eventRecord, error) {
+	var header struct {
+		PCRIndex     uint32
+		Type         EventType
+		DigestsCount uint32
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("unable to read the header: %w", err)
+	}
+	if uint64(header.DigestsCount) > uint64(len(digestSizes)) {
+		return nil, fmt.Errorf("too many digests: %d > %d", header.DigestsCount, len(digestSizes))
+	}
+
+	record := &eventRecord{
+		PCRIndex: pcr.ID(header.PCRIndex),
+		Type:     header.Type,
+	}
+	for idx := uint32(0); idx < header.DigestsCount; idx++ {
+		var hashAlgo TPMAlgorithm
+		if err := binary.Read(r, binary.LittleEndian, &hashAlgo); err != nil {
+			return nil, fmt.Errorf("unable to read the hash algorithm of digest #%d: %w", idx, err)
+		}
+		digestSize := -1
+		for _, algSize := range digestSizes {
+			if algSize.AlgorithmID == hashAlgo {
+				digestSize = int(algSize.DigestSize)
+				break
+			}
+		}
+		if digestSize < 0 {
+			return nil, fmt.Errorf("hash algorithm %s of digest #%d is not defined in the Spec ID event", hashAlgo, idx)
+		}
+		if digestSize > r.Len() {
+			return nil, fmt.Errorf("unable to read digest #%d: %w", idx, io.ErrUnexpectedEOF)
+		}
+		digest := make([]byte, digestSize)
+		_, _ = r.Read(digest)
+		record.Digests = append(record.Digests, Digest{
+			HashAlgo: hashAlgo,
+			Digest:   digest,
+		})
+	}
+
+	var eventSize uint32
+	if err := binary.Read(r, binary.LittleEndian, &eventSize); err != nil {
+		return nil, fmt.Errorf("unable to read the event size: %w", err)
+	}
+	data, err := readEventData(r, eventSize)
+	if err != nil {
+		return nil, err
+	}
+	record.Data = data
+	return record, nil
+}
+
+func readEventData(r *bytes.Reader, size uint32) ([]byte, error) {
+	if uint64(size) > uint64(r.Len()) {
+		return nil, fmt.Errorf("event data size %d exceeds the amount of bytes left %d", size, r.Len())
+	}
+	data := make([]byte, size)
+	_, _ = r.Read(data)
+	return data, nil
+}
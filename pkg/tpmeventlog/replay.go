@@ -26,11 +26,10 @@ func ParseLocality(eventData []byte) (uint8, error) {
 // FilterEvents returns only the events which has a specified PCR index and
 // a digest of a specified hash algorithm.
 func (eventLog *TPMEventLog) FilterEvents(pcrIndex pcr.ID, hashAlgo TPMAlgorithm) ([]*Event, error) {
-	hash, err := hashAlgo.Hash()
+	hasher, err := NewHasher(hashAlgo)
 	if err != nil {
-		return nil, ErrNotSupportedHashAlgo{TPMAlgo: hashAlgo}
+		return nil, err
 	}
-	hasher := hash.HashFunc()
 
 	var result []*Event
 	for _, event := range eventLog.Events {
@@ -1,10 +1,6 @@
 package tpmeventlog
 
 import (
-	"io"
-	"io/ioutil"
-
-	"github.com/google/go-attestation/attest"
 	"github.com/google/go-tpm/tpm2"
 
 	pcr "github.com/9elements/converged-security-suite/v2/pkg/pcr/types"
@@ -40,33 +36,13 @@ const (
 
 	// TPMAlgorithmSHA256 is the identified of SHA256 algorithm.
 	TPMAlgorithmSHA256 = tpm2.AlgSHA256
-)
 
-// Parse parses a binary EventLog.
-func Parse(input io.Reader) (*TPMEventLog, error) {
-	b, err := ioutil.ReadAll(input)
-	if err != nil {
-		return nil, ErrRead{Err: err}
-	}
-	eventLog, err := attest.ParseEventLog(b)
-	if err != nil {
-		return nil, ErrParse{Err: err}
-	}
+	// TPMAlgorithmSHA384 is the identified of SHA384 algorithm.
+	TPMAlgorithmSHA384 = tpm2.AlgSHA384
 
-	result := &TPMEventLog{}
-	for _, alg := range eventLog.Algs {
-		for _, entry := range eventLog.Events(alg) {
-			result.Events = append(result.Events, &Event{
-				PCRIndex: pcr.ID(entry.Index),
-				Type:     EventType(entry.Type),
-				Data:     entry.Data,
-				Digest: &Digest{
-					HashAlgo: TPMAlgorithm(alg),
-					Digest:   entry.Digest,
-				},
-			})
-		}
-	}
+	// TPMAlgorithmSHA512 is the identified of SHA512 algorithm.
+	TPMAlgorithmSHA512 = tpm2.AlgSHA512
 
-	return result, nil
-}
+	// TPMAlgorithmSM3_256 is the identified of SM3_256 algorithm.
+	TPMAlgorithmSM3_256 = TPMAlgorithm(0x0012)
+)
@@ -0,0 +1,60 @@
+package tpmquote
+
+import (
+	"fmt"
+
+	pcr "github.com/9elements/converged-security-suite/v2/pkg/pcr/types"
+	"github.com/google/go-tpm/tpm2"
+)
+
+// ErrInvalidSignature means the quote signature does not match the
+// attestation data and the AK public key.
+type ErrInvalidSignature struct {
+	Err error
+}
+
+// Error implements interface `error`.
+func (err ErrInvalidSignature) Error() string {
+	return fmt.Sprintf("invalid quote signature: %v", err.Err)
+}
+
+// Unwrap implements `xerrors.Wrapper`.
+func (err ErrInvalidSignature) Unwrap() error {
+	return err.Err
+}
+
+// ErrNonceMismatch means the quote was made for another nonce
+// (qualifying data).
+type ErrNonceMismatch struct {
+	Expected []byte
+	Received []byte
+}
+
+// Error implements interface `error`.
+func (err ErrNonceMismatch) Error() string {
+	return fmt.Sprintf("nonce mismatch, expected:%X, received:%X", err.Expected, err.Received)
+}
+
+// ErrMissingPCRValue means a PCR is selected by the quote, but its value
+// was not provided.
+type ErrMissingPCRValue struct {
+	PCRIndex pcr.ID
+}
+
+// Error implements interface `error`.
+func (err ErrMissingPCRValue) Error() string {
+	return fmt.Sprintf("the value of %s is selected by the quote, but not provided", err.PCRIndex)
+}
+
+// ErrPCRDigestMismatch means the PCR composite digest of the quote does not
+// match the provided PCR values.
+type ErrPCRDigestMismatch struct {
+	HashAlgo tpm2.Algorithm
+	Expected []byte
+	Received []byte
+}
+
+// Error implements interface `error`.
+func (err ErrPCRDigestMismatch) Error() string {
+	return fmt.Sprintf("PCR digest (%s) mismatch, expected:%X, quoted:%X", err.HashAlgo, err.Expected, err.Received)
+}
@@ -0,0 +1,186 @@
+// Package tpmquote verifies TPM2 quotes (signed PCR values) against PCR
+// values predicted by other means (for example by package `pcr`).
+package tpmquote
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"sort"
+
+	pcr "github.com/9elements/converged-security-suite/v2/pkg/pcr/types"
+	"github.com/9elements/converged-security-suite/v2/pkg/tpmeventlog"
+	"github.com/google/go-tpm/tpm2"
+)
+
+// Quote is a TPM2 quote as returned by TPM2_Quote.
+type Quote struct {
+	// Attest is the quoted data: TPMS_ATTEST structure (without the
+	// TPM2B size prefix).
+	Attest []byte
+
+	// Signature is the signature of Attest: TPMT_SIGNATURE structure.
+	Signature []byte
+}
+
+// PCRValues is a set of PCR values of a single PCR bank.
+type PCRValues map[pcr.ID][]byte
+
+// ParseAKPublicKey parses the public key of an attestation key. Supported
+// formats are: TPMT_PUBLIC, TPM2B_PUBLIC, PEM and DER (PKIX).
+func ParseAKPublicKey(b []byte) (crypto.PublicKey, error) {
+	if block, _ := pem.Decode(b); block != nil {
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if key, err := x509.ParsePKIXPublicKey(b); err == nil {
+		return key, nil
+	}
+
+	if len(b) > 2 && int(binary.BigEndian.Uint16(b)) == len(b)-2 {
+		// TPM2B_PUBLIC
+		b = b[2:]
+	}
+	pub, err := tpm2.DecodePublic(b)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the key as neither PKIX nor TPMT_PUBLIC: %w", err)
+	}
+	return pub.Key()
+}
+
+// ParseAttest parses the quoted data.
+func (q Quote) ParseAttest() (*tpm2.AttestationData, error) {
+	attest, err := tpm2.DecodeAttestationData(q.Attest)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse TPMS_ATTEST: %w", err)
+	}
+	if attest.Type != tpm2.TagAttestQuote || attest.AttestedQuoteInfo == nil {
+		return nil, fmt.Errorf("the attestation data is not a quote (type: 0x%X)", attest.Type)
+	}
+	return attest, nil
+}
+
+// ParseSignature parses the signature of the quote.
+func (q Quote) ParseSignature() (*tpm2.Signature, error) {
+	sig, err := tpm2.DecodeSignature(bytes.NewBuffer(q.Signature))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse TPMT_SIGNATURE: %w", err)
+	}
+	return sig, nil
+}
+
+// Verify checks the signature of the quote using the AK public key and
+// checks that the quote was made for the nonce. Returns the quoted
+// PCR selection and the PCR composite digest.
+func (q Quote) Verify(akPublicKey crypto.PublicKey, nonce []byte) (*tpm2.QuoteInfo, error) {
+	sig, err := q.ParseSignature()
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(akPublicKey, q.Attest, sig); err != nil {
+		return nil, ErrInvalidSignature{Err: err}
+	}
+
+	attest, err := q.ParseAttest()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(attest.ExtraData, nonce) {
+		return nil, ErrNonceMismatch{Expected: nonce, Received: attest.ExtraData}
+	}
+	return attest.AttestedQuoteInfo, nil
+}
+
+func verifySignature(key crypto.PublicKey, data []byte, sig *tpm2.Signature) error {
+	var hashAlgo tpm2.Algorithm
+	switch {
+	case sig.RSA != nil:
+		hashAlgo = sig.RSA.HashAlg
+	case sig.ECC != nil:
+		hashAlgo = sig.ECC.HashAlg
+	default:
+		return fmt.Errorf("no signature")
+	}
+	h, err := hashAlgo.Hash()
+	if err != nil {
+		return fmt.Errorf("unsupported hash algorithm %s: %w", hashAlgo, err)
+	}
+	hasher := h.New()
+	_, _ = hasher.Write(data)
+	digest := hasher.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		switch {
+		case sig.RSA == nil:
+			return fmt.Errorf("RSA key, but signature algorithm is %s", sig.Alg)
+		case sig.Alg == tpm2.AlgRSAPSS:
+			return rsa.VerifyPSS(key, h, digest, sig.RSA.Signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+		default:
+			return rsa.VerifyPKCS1v15(key, h, digest, sig.RSA.Signature)
+		}
+	case *ecdsa.PublicKey:
+		if sig.ECC == nil {
+			return fmt.Errorf("ECC key, but signature algorithm is %s", sig.Alg)
+		}
+		if !ecdsa.Verify(key, digest, sig.ECC.R, sig.ECC.S) {
+			return fmt.Errorf("ECDSA verification failed")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported key type: %T", key)
+}
+
+// PCRDigest calculates the PCR composite digest: the hash of the
+// concatenation of the values of the selected PCRs (in ascending order).
+func PCRDigest(selection tpm2.PCRSelection, values PCRValues, hashAlgo tpm2.Algorithm) ([]byte, error) {
+	hasher, err := tpmeventlog.NewHasher(hashAlgo)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported hash algorithm %s: %w", tpmeventlog.HashAlgorithmName(hashAlgo), err)
+	}
+	indexes := append([]int{}, selection.PCRs...)
+	sort.Ints(indexes)
+
+	for _, idx := range indexes {
+		value, ok := values[pcr.ID(idx)]
+		if !ok {
+			return nil, ErrMissingPCRValue{PCRIndex: pcr.ID(idx)}
+		}
+		_, _ = hasher.Write(value)
+	}
+	return hasher.Sum(nil), nil
+}
+
+// VerifyPCRs checks if the PCR composite digest of the quote matches the
+// PCR values. The digest algorithm is the hash algorithm of the signing
+// scheme of the quote.
+func VerifyPCRs(quoteInfo *tpm2.QuoteInfo, values PCRValues, digestAlgo tpm2.Algorithm) error {
+	digest, err := PCRDigest(quoteInfo.PCRSelection, values, digestAlgo)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(digest, quoteInfo.PCRDigest) {
+		return ErrPCRDigestMismatch{HashAlgo: digestAlgo, Expected: digest, Received: quoteInfo.PCRDigest}
+	}
+	return nil
+}
+
+// DigestAlgo returns the hash algorithm used to sign the quote (which is
+// also used to calculate the PCR composite digest).
+func (q Quote) DigestAlgo() (tpm2.Algorithm, error) {
+	sig, err := q.ParseSignature()
+	if err != nil {
+		return tpm2.AlgUnknown, err
+	}
+	switch {
+	case sig.RSA != nil:
+		return sig.RSA.HashAlg, nil
+	case sig.ECC != nil:
+		return sig.ECC.HashAlg, nil
+	}
+	return tpm2.AlgUnknown, fmt.Errorf("no signature")
+}
@@ -0,0 +1,144 @@
+package tpmquote
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+	"github.com/stretchr/testify/require"
+)
+
+func buildAttest(t *testing.T, nonce []byte, pcrs []int, pcrDigest []byte) []byte {
+	var bitmap [3]byte
+	for _, idx := range pcrs {
+		bitmap[idx/8] |= 1 << (idx % 8)
+	}
+	b, err := tpmutil.Pack(
+		uint32(0xff544347),                // magic
+		tpm2.TagAttestQuote,               // type
+		tpmutil.U16Bytes(nil),             // qualifiedSigner
+		tpmutil.U16Bytes(nonce),           // extraData
+		tpm2.ClockInfo{Clock: 1, Safe: 1}, // clockInfo
+		uint64(0),                         // firmwareVersion
+		uint32(1),                         // pcrSelect.count
+		tpm2.AlgSHA256,                    // pcrSelect.hash
+		byte(len(bitmap)),                 // pcrSelect.sizeofSelect
+		tpmutil.RawBytes(bitmap[:]),       // pcrSelect.pcrSelect
+		tpmutil.U16Bytes(pcrDigest),       // pcrDigest
+	)
+	require.NoError(t, err)
+	return b
+}
+
+func TestQuoteVerify(t *testing.T) {
+	nonce := []byte("nonce")
+	pcrValues := PCRValues{
+		0: make([]byte, 32),
+		7: append(make([]byte, 31), 1),
+	}
+	digest, err := PCRDigest(tpm2.PCRSelection{Hash: tpm2.AlgSHA256, PCRs: []int{7, 0}}, pcrValues, tpm2.AlgSHA256)
+	require.NoError(t, err)
+	expectedDigest := sha256.Sum256(append(append([]byte{}, pcrValues[0]...), pcrValues[7]...))
+	require.Equal(t, expectedDigest[:], digest)
+
+	attest := buildAttest(t, nonce, []int{0, 7}, digest)
+	attestDigest := sha256.Sum256(attest)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaSignature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, attestDigest[:])
+	require.NoError(t, err)
+	rsaSignatureBytes, err := tpmutil.Pack(tpm2.AlgRSASSA, tpm2.AlgSHA256, tpmutil.U16Bytes(rsaSignature))
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	r, s, err := ecdsa.Sign(rand.Reader, ecKey, attestDigest[:])
+	require.NoError(t, err)
+	ecSignatureBytes, err := tpmutil.Pack(tpm2.AlgECDSA, tpm2.AlgSHA256, tpmutil.U16Bytes(r.Bytes()), tpmutil.U16Bytes(s.Bytes()))
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		key       crypto.PublicKey
+		signature []byte
+	}{
+		"RSA":   {key: &rsaKey.PublicKey, signature: rsaSignatureBytes},
+		"ECDSA": {key: &ecKey.PublicKey, signature: ecSignatureBytes},
+	} {
+		t.Run(name, func(t *testing.T) {
+			q := Quote{Attest: attest, Signature: tc.signature}
+			digestAlgo, err := q.DigestAlgo()
+			require.NoError(t, err)
+			require.Equal(t, tpm2.AlgSHA256, digestAlgo)
+
+			quoteInfo, err := q.Verify(tc.key, nonce)
+			require.NoError(t, err)
+			require.Equal(t, []int{0, 7}, quoteInfo.PCRSelection.PCRs)
+			require.NoError(t, VerifyPCRs(quoteInfo, pcrValues, digestAlgo))
+
+			_, err = q.Verify(tc.key, []byte("another nonce"))
+			require.True(t, errors.As(err, &ErrNonceMismatch{}), err)
+
+			brokenAttest := append([]byte{}, attest...)
+			brokenAttest[len(brokenAttest)-1] ^= 1
+			_, err = Quote{Attest: brokenAttest, Signature: tc.signature}.Verify(tc.key, nonce)
+			require.True(t, errors.As(err, &ErrInvalidSignature{}), err)
+		})
+	}
+
+	t.Run("pcr_mismatch", func(t *testing.T) {
+		quoteInfo, err := Quote{Attest: attest, Signature: rsaSignatureBytes}.Verify(&rsaKey.PublicKey, nonce)
+		require.NoError(t, err)
+
+		err = VerifyPCRs(quoteInfo, PCRValues{0: make([]byte, 32), 7: make([]byte, 32)}, tpm2.AlgSHA256)
+		require.True(t, errors.As(err, &ErrPCRDigestMismatch{}), err)
+
+		err = VerifyPCRs(quoteInfo, PCRValues{0: make([]byte, 32)}, tpm2.AlgSHA256)
+		require.True(t, errors.As(err, &ErrMissingPCRValue{}), err)
+	})
+}
+
+func TestParseAKPublicKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	tpmPublic, err := tpm2.Public{
+		Type:       tpm2.AlgRSA,
+		NameAlg:    tpm2.AlgSHA256,
+		Attributes: tpm2.FlagSign | tpm2.FlagRestricted,
+		RSAParameters: &tpm2.RSAParams{
+			Sign:       &tpm2.SigScheme{Alg: tpm2.AlgRSASSA, Hash: tpm2.AlgSHA256},
+			KeyBits:    2048,
+			ModulusRaw: key.PublicKey.N.Bytes(),
+		},
+	}.Encode()
+	require.NoError(t, err)
+	tpm2bPublic, err := tpmutil.Pack(tpmutil.U16Bytes(tpmPublic))
+	require.NoError(t, err)
+
+	for name, b := range map[string][]byte{
+		"DER":          der,
+		"PEM":          pemBytes,
+		"TPMT_PUBLIC":  tpmPublic,
+		"TPM2B_PUBLIC": tpm2bPublic,
+	} {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := ParseAKPublicKey(b)
+			require.NoError(t, err)
+			require.Equal(t, key.PublicKey.N, parsed.(*rsa.PublicKey).N)
+		})
+	}
+}
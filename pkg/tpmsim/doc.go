@@ -0,0 +1,9 @@
+// Package tpmsim provides an in-process TPM 2.0 simulator (Microsoft TPM 2.0
+// reference implementation) with an optionally persistent state.
+//
+// The simulator is intended for tests and for dry runs of provisioning
+// tools only: its seeds are fixed, so it provides no security at all.
+//
+// The simulator requires cgo and OpenSSL (libcrypto) headers, so the package
+// is built only with the "tpmsim" build tag.
+package tpmsim
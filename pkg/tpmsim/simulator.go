@@ -0,0 +1,150 @@
+//go:build tpmsim
+// +build tpmsim
+
+package tpmsim
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/google/go-tpm-tools/simulator"
+)
+
+// Seed is the seed of the simulated TPM hierarchies. It is fixed
+// to make the state of the simulator reproducible.
+const Seed = 0x7470_6d73_696d
+
+// Simulator is an io.ReadWriteCloser to a simulated TPM 2.0.
+//
+// The simulator does not support exporting its internal state, so if a state
+// file is used then all the commands sent to the simulator are journaled into
+// the file. On Open the journal is replayed to restore the state, and then
+// the simulated TPM is reset (as if the machine was rebooted).
+//
+// Only one Simulator could be opened at a time (within a process), Open blocks
+// until the previous Simulator is closed.
+type Simulator struct {
+	sim       *simulator.Simulator
+	stateFile string
+	journal   bytes.Buffer
+}
+
+var _ io.ReadWriteCloser = (*Simulator)(nil)
+
+// Open starts a simulated TPM 2.0. If stateFile is not empty, then the state
+// is restored from the file (if it exists) and saved on Close.
+func Open(stateFile string) (*Simulator, error) {
+	sim, err := simulator.GetWithFixedSeedInsecure(Seed)
+	if err != nil {
+		return nil, fmt.Errorf("unable to start the TPM simulator: %w", err)
+	}
+	s := &Simulator{
+		sim:       sim,
+		stateFile: stateFile,
+	}
+	if stateFile == "" {
+		return s, nil
+	}
+
+	journal, err := ioutil.ReadFile(stateFile)
+	switch {
+	case os.IsNotExist(err):
+		return s, nil
+	case err != nil:
+		_ = sim.Close()
+		return nil, fmt.Errorf("unable to read the state file '%s': %w", stateFile, err)
+	}
+	if err := s.replay(journal); err != nil {
+		_ = sim.Close()
+		return nil, fmt.Errorf("unable to restore the state from file '%s': %w", stateFile, err)
+	}
+	if err := s.Reset(); err != nil {
+		_ = sim.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// replay executes the journaled commands. Each record of the journal is
+// a command prefixed by its length (uint32, little endian); a record of zero
+// length means a reset.
+func (s *Simulator) replay(journal []byte) error {
+	r := bytes.NewReader(journal)
+	for r.Len() > 0 {
+		var length uint32
+		if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+			return fmt.Errorf("unable to read the length of a record: %w", err)
+		}
+		if length == 0 {
+			if err := s.sim.Reset(); err != nil {
+				return fmt.Errorf("unable to reset the simulator: %w", err)
+			}
+			continue
+		}
+		if uint64(length) > uint64(r.Len()) {
+			return fmt.Errorf("record of length %d is out of the journal", length)
+		}
+		cmd := make([]byte, length)
+		_, _ = r.Read(cmd)
+		if _, err := s.sim.Write(cmd); err != nil {
+			return fmt.Errorf("unable to execute a command: %w", err)
+		}
+		if _, err := ioutil.ReadAll(s.sim); err != nil {
+			return fmt.Errorf("unable to read a response: %w", err)
+		}
+	}
+	return nil
+}
+
+// Write implements io.Writer: it executes a TPM command.
+func (s *Simulator) Write(cmd []byte) (int, error) {
+	n, err := s.sim.Write(cmd)
+	if err == nil && s.stateFile != "" {
+		_ = binary.Write(&s.journal, binary.LittleEndian, uint32(len(cmd)))
+		s.journal.Write(cmd)
+	}
+	return n, err
+}
+
+// Read implements io.Reader: it returns the response of the last command.
+func (s *Simulator) Read(b []byte) (int, error) {
+	return s.sim.Read(b)
+}
+
+// Reset resets the simulated TPM as if the machine was rebooted.
+func (s *Simulator) Reset() error {
+	if err := s.sim.Reset(); err != nil {
+		return fmt.Errorf("unable to reset the simulator: %w", err)
+	}
+	if s.stateFile != "" {
+		_ = binary.Write(&s.journal, binary.LittleEndian, uint32(0))
+	}
+	return nil
+}
+
+// Close implements io.Closer: it stops the simulator and saves the state
+// (if a state file is used).
+func (s *Simulator) Close() error {
+	closeErr := s.sim.Close()
+	if s.stateFile == "" || s.journal.Len() == 0 {
+		return closeErr
+	}
+
+	f, err := os.OpenFile(s.stateFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to open the state file '%s': %w", s.stateFile, err)
+	}
+	if _, err := f.Write(s.journal.Bytes()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to write the state file '%s': %w", s.stateFile, err)
+	}
+	s.journal.Reset()
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,49 @@
+//go:build tpmsim
+// +build tpmsim
+
+package tpmsim
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulatorStateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tpmsim")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "state")
+
+	nvIndex := tpmutil.Handle(0x01500000)
+	platformAuth := tpm2.AuthCommand{Session: tpm2.HandlePasswordSession, Attributes: tpm2.AttrContinueSession}
+
+	sim, err := Open(stateFile)
+	require.NoError(t, err)
+	require.NoError(t, tpm2.NVDefineSpaceEx(sim, tpm2.HandlePlatform, "", tpm2.NVPublic{
+		NVIndex:    nvIndex,
+		NameAlg:    tpm2.AlgSHA256,
+		Attributes: tpm2.AttrPlatformCreate | tpm2.AttrPPWrite | tpm2.AttrAuthRead | tpm2.AttrNoDA,
+		DataSize:   4,
+	}, platformAuth))
+	require.NoError(t, tpm2.NVWriteEx(sim, tpm2.HandlePlatform, nvIndex, platformAuth, []byte{1, 2, 3, 4}, 0))
+	require.NoError(t, sim.Close())
+
+	sim, err = Open(stateFile)
+	require.NoError(t, err)
+	data, err := tpm2.NVRead(sim, nvIndex)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3, 4}, data)
+	require.NoError(t, sim.Close())
+
+	sim, err = Open("")
+	require.NoError(t, err)
+	_, err = tpm2.NVReadPublic(sim, nvIndex)
+	require.Error(t, err)
+	require.NoError(t, sim.Close())
+}
@@ -0,0 +1,24 @@
+package consts
+
+import (
+	"github.com/linuxboot/fiano/pkg/guid"
+)
+
+var (
+	// GUIDVariableStore is the signature of a variable store
+	// with non-authenticated variables ("gEfiVariableGuid").
+	GUIDVariableStore = *guid.MustParse("DDCF3616-3275-4164-98B6-FE85707FFE7D")
+
+	// GUIDAuthenticatedVariableStore is the signature of a variable store
+	// with authenticated variables ("gEfiAuthenticatedVariableGuid").
+	GUIDAuthenticatedVariableStore = *guid.MustParse("AAF32C78-947B-439A-A180-2E144EC37792")
+
+	// GUIDGlobalVariable is the vendor GUID of the variables defined by
+	// the UEFI specification ("EFI_GLOBAL_VARIABLE"), for example "PK",
+	// "KEK", "SecureBoot", "BootOrder".
+	GUIDGlobalVariable = *guid.MustParse("8BE4DF61-93CA-11D2-AA0D-00E098032B8C")
+
+	// GUIDImageSecurityDatabase is the vendor GUID of the signature
+	// databases "db" and "dbx" ("EFI_IMAGE_SECURITY_DATABASE_GUID").
+	GUIDImageSecurityDatabase = *guid.MustParse("D719B2CB-3D3A-4596-A3BC-DAD00E67656F")
+)
@@ -0,0 +1,251 @@
+package uefi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+
+	pkgbytes "github.com/linuxboot/fiano/pkg/bytes"
+	fianoGUID "github.com/linuxboot/fiano/pkg/guid"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi/consts"
+)
+
+// See "MdeModulePkg/Include/Guid/VariableFormat.h" of EDK2 for the format
+// of a variable store.
+const (
+	variableStoreHeaderSize       = 28
+	variableStoreFormatted        = 0x5A
+	variableStoreHealthy          = 0xFE
+	variableStartID               = 0x55AA
+	variableHeaderSize            = 32
+	authenticatedVariableHeadSize = 60
+	variableStateAdded            = 0x3F
+	variableStateInDeletedTrans   = 0xFE
+)
+
+// EFIVariable is a variable found in an UEFI variable store.
+type EFIVariable struct {
+	// GUID is the vendor GUID of the variable.
+	GUID fianoGUID.GUID
+
+	// Name is the name of the variable (for example "PK" or "BootOrder").
+	Name string
+
+	// Attributes is the EFI_VARIABLE_* attributes of the variable.
+	Attributes uint32
+
+	// DataRange is the range of the variable data within the parsed buffer.
+	DataRange pkgbytes.Range
+
+	// Data is the content of the variable.
+	Data []byte
+}
+
+// String implements fmt.Stringer.
+func (v EFIVariable) String() string {
+	return fmt.Sprintf("%s-%s", v.Name, v.GUID.String())
+}
+
+// EFIVariables is a set of EFIVariable-s.
+type EFIVariables []*EFIVariable
+
+// Find returns the variable with the specified vendor GUID and name.
+// Or returns nil if such variable was not found.
+func (s EFIVariables) Find(guid fianoGUID.GUID, name string) *EFIVariable {
+	for _, v := range s {
+		if v.GUID == guid && v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// GetEFIVariables returns the variables of all variable stores found
+// in the image. DataRange-s of the variables are relative to the
+// beginning of the image.
+func (uefi *UEFI) GetEFIVariables() (EFIVariables, error) {
+	return FindEFIVariables(uefi.Buf())
+}
+
+// FindEFIVariables scans `b` for variable stores (EDK2 "VSS" format,
+// both authenticated and non-authenticated) and returns all the valid
+// variables found in them. DataRange-s of the variables are relative to
+// the beginning of `b`.
+//
+// If a variable has multiple records (for example it was updated
+// without the store being reclaimed), only the latest valid record is
+// returned.
+func FindEFIVariables(b []byte) (EFIVariables, error) {
+	var (
+		result EFIVariables
+		found  bool
+	)
+	for offset := 0; offset+variableStoreHeaderSize <= len(b); {
+		idx, isAuthenticated := findVariableStoreSignature(b[offset:])
+		if idx < 0 {
+			break
+		}
+		storeOffset := offset + idx
+		offset = storeOffset + 1
+
+		storeSize, ok := checkVariableStoreHeader(b[storeOffset:])
+		if !ok {
+			continue
+		}
+		found = true
+
+		variables := parseVariableStore(b[storeOffset:storeOffset+int(storeSize)], isAuthenticated)
+		for _, v := range variables {
+			v.DataRange.Offset += uint64(storeOffset)
+		}
+		result = append(result, variables...)
+		offset = storeOffset + int(storeSize)
+	}
+
+	if !found {
+		return nil, ErrNoVariableStore{}
+	}
+	return result, nil
+}
+
+// ParseEFIVariableStore parses a single variable store which starts at
+// the beginning of `b`.
+func ParseEFIVariableStore(b []byte) (EFIVariables, error) {
+	idx, isAuthenticated := findVariableStoreSignature(b)
+	if idx != 0 {
+		return nil, ErrNoVariableStore{}
+	}
+	storeSize, ok := checkVariableStoreHeader(b)
+	if !ok {
+		return nil, ErrInvalidVariableStore{}
+	}
+	return parseVariableStore(b[:storeSize], isAuthenticated), nil
+}
+
+func findVariableStoreSignature(b []byte) (int, bool) {
+	idxPlain := bytes.Index(b, consts.GUIDVariableStore[:])
+	idxAuth := bytes.Index(b, consts.GUIDAuthenticatedVariableStore[:])
+	switch {
+	case idxPlain < 0:
+		return idxAuth, true
+	case idxAuth < 0:
+		return idxPlain, false
+	case idxAuth < idxPlain:
+		return idxAuth, true
+	}
+	return idxPlain, false
+}
+
+// checkVariableStoreHeader returns the size of the store if the header
+// looks like a header of a formatted and healthy variable store.
+func checkVariableStoreHeader(b []byte) (uint32, bool) {
+	if len(b) < variableStoreHeaderSize {
+		return 0, false
+	}
+	size := binary.LittleEndian.Uint32(b[16:])
+	format := b[20]
+	state := b[21]
+	if format != variableStoreFormatted || state != variableStoreHealthy {
+		return 0, false
+	}
+	if size < variableStoreHeaderSize || uint64(size) > uint64(len(b)) {
+		return 0, false
+	}
+	return size, true
+}
+
+func alignVariable(v uint64) uint64 {
+	return (v + 3) &^ 3
+}
+
+func parseVariableStore(store []byte, isAuthenticated bool) EFIVariables {
+	headerSize := uint64(variableHeaderSize)
+	if isAuthenticated {
+		headerSize = authenticatedVariableHeadSize
+	}
+
+	type record struct {
+		variable *EFIVariable
+		state    uint8
+	}
+	var records []record
+
+	storeSize := uint64(len(store))
+	for offset := alignVariable(variableStoreHeaderSize); offset+headerSize <= storeSize; {
+		hdr := store[offset:]
+		if binary.LittleEndian.Uint16(hdr[0:]) != variableStartID {
+			break
+		}
+		state := hdr[2]
+		attributes := binary.LittleEndian.Uint32(hdr[4:])
+		fieldsOffset := uint64(8)
+		if isAuthenticated {
+			// Skip MonotonicCount, TimeStamp and PubKeyIndex.
+			fieldsOffset += 8 + 16 + 4
+		}
+		nameSize := uint64(binary.LittleEndian.Uint32(hdr[fieldsOffset:]))
+		dataSize := uint64(binary.LittleEndian.Uint32(hdr[fieldsOffset+4:]))
+		var vendorGUID fianoGUID.GUID
+		copy(vendorGUID[:], hdr[fieldsOffset+8:])
+
+		nameOffset := offset + headerSize
+		dataOffset := nameOffset + nameSize + ((4 - nameSize&3) & 3)
+		if nameOffset+nameSize > storeSize || dataOffset+dataSize > storeSize {
+			break
+		}
+		nextOffset := alignVariable(dataOffset + dataSize)
+
+		if state == variableStateAdded || state == variableStateAdded&variableStateInDeletedTrans {
+			records = append(records, record{
+				variable: &EFIVariable{
+					GUID:       vendorGUID,
+					Name:       decodeUCS2(store[nameOffset : nameOffset+nameSize]),
+					Attributes: attributes,
+					DataRange: pkgbytes.Range{
+						Offset: dataOffset,
+						Length: dataSize,
+					},
+					Data: store[dataOffset : dataOffset+dataSize],
+				},
+				state: state,
+			})
+		}
+
+		offset = nextOffset
+	}
+
+	// A record "in deleted transition" is valid only if there is no other
+	// (already added) record of the same variable.
+	var result EFIVariables
+	for idx, rec := range records {
+		overridden := false
+		for _, cmp := range records[idx+1:] {
+			if cmp.variable.GUID != rec.variable.GUID || cmp.variable.Name != rec.variable.Name {
+				continue
+			}
+			if rec.state == variableStateAdded && cmp.state != variableStateAdded {
+				continue
+			}
+			overridden = true
+			break
+		}
+		if !overridden {
+			result = append(result, rec.variable)
+		}
+	}
+	return result
+}
+
+func decodeUCS2(b []byte) string {
+	u16 := make([]uint16, 0, len(b)/2)
+	for idx := 0; idx+1 < len(b); idx += 2 {
+		c := binary.LittleEndian.Uint16(b[idx:])
+		if c == 0 {
+			break
+		}
+		u16 = append(u16, c)
+	}
+	return string(utf16.Decode(u16))
+}
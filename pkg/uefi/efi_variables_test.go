@@ -0,0 +1,86 @@
+package uefi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+
+	fianoGUID "github.com/linuxboot/fiano/pkg/guid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/9elements/converged-security-suite/v2/pkg/uefi/consts"
+)
+
+type testVariable struct {
+	guid  fianoGUID.GUID
+	name  string
+	state uint8
+	data  []byte
+}
+
+func buildAuthenticatedVariableStore(t *testing.T, size uint32, variables ...testVariable) []byte {
+	var buf bytes.Buffer
+	buf.Write(consts.GUIDAuthenticatedVariableStore[:])
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, size))
+	buf.Write([]byte{variableStoreFormatted, variableStoreHealthy, 0, 0, 0, 0, 0, 0})
+
+	pad := func() {
+		for buf.Len()%4 != 0 {
+			buf.WriteByte(0xff)
+		}
+	}
+
+	for _, v := range variables {
+		pad()
+		var name []byte
+		for _, c := range utf16.Encode([]rune(v.name + "\x00")) {
+			name = append(name, byte(c), byte(c>>8))
+		}
+		require.NoError(t, binary.Write(&buf, binary.LittleEndian, uint16(variableStartID)))
+		buf.Write([]byte{v.state, 0})
+		require.NoError(t, binary.Write(&buf, binary.LittleEndian, uint32(0x27)))
+		buf.Write(make([]byte, 8+16+4))
+		require.NoError(t, binary.Write(&buf, binary.LittleEndian, uint32(len(name))))
+		require.NoError(t, binary.Write(&buf, binary.LittleEndian, uint32(len(v.data))))
+		buf.Write(v.guid[:])
+		buf.Write(name)
+		pad()
+		buf.Write(v.data)
+	}
+
+	for uint32(buf.Len()) < size {
+		buf.WriteByte(0xff)
+	}
+	return buf.Bytes()
+}
+
+func TestFindEFIVariables(t *testing.T) {
+	store := buildAuthenticatedVariableStore(t, 0x1000,
+		testVariable{guid: consts.GUIDGlobalVariable, name: "PK", state: variableStateAdded, data: []byte{1, 2, 3}},
+		testVariable{guid: consts.GUIDGlobalVariable, name: "BootOrder", state: variableStateAdded, data: []byte{0, 0}},
+		testVariable{guid: consts.GUIDImageSecurityDatabase, name: "db", state: 0xFD, data: []byte{4}},
+		testVariable{guid: consts.GUIDGlobalVariable, name: "BootOrder", state: variableStateAdded, data: []byte{1, 0, 0, 0}},
+		testVariable{guid: consts.GUIDImageSecurityDatabase, name: "dbx", state: variableStateAdded & variableStateInDeletedTrans, data: []byte{5}},
+	)
+	image := append(bytes.Repeat([]byte{0xff}, 0x100), store...)
+
+	variables, err := FindEFIVariables(image)
+	require.NoError(t, err)
+	require.Len(t, variables, 3)
+
+	pk := variables.Find(consts.GUIDGlobalVariable, "PK")
+	require.NotNil(t, pk)
+	require.Equal(t, []byte{1, 2, 3}, pk.Data)
+	require.Equal(t, pk.Data, image[pk.DataRange.Offset:pk.DataRange.End()])
+
+	bootOrder := variables.Find(consts.GUIDGlobalVariable, "BootOrder")
+	require.NotNil(t, bootOrder)
+	require.Equal(t, []byte{1, 0, 0, 0}, bootOrder.Data)
+
+	require.Nil(t, variables.Find(consts.GUIDImageSecurityDatabase, "db"))
+	require.NotNil(t, variables.Find(consts.GUIDImageSecurityDatabase, "dbx"))
+
+	_, err = FindEFIVariables(make([]byte, 0x100))
+	require.Error(t, err)
+}
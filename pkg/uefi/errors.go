@@ -14,3 +14,18 @@ type ErrZeroImage struct{}
 func (err ErrZeroImage) Error() string {
 	return `an empty image: it consists of zero bytes only`
 }
+
+// ErrNoVariableStore means no UEFI variable store was found.
+type ErrNoVariableStore struct{}
+
+func (err ErrNoVariableStore) Error() string {
+	return `no variable store found`
+}
+
+// ErrInvalidVariableStore means the header of the UEFI variable store
+// is invalid (for example, the store is not formatted).
+type ErrInvalidVariableStore struct{}
+
+func (err ErrInvalidVariableStore) Error() string {
+	return `invalid variable store header`
+}